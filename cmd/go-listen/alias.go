@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/services/spotify"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+var (
+	aliasArtistName string
+	aliasReplace    bool
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage artist aliases",
+	Long: `Manage the dictionary of artist names that always resolve to a given Spotify
+artist. Searches and scrapes consult it before fuzzy matching, so a name the
+matcher keeps getting wrong can be pinned to the right artist.
+
+Artists may be given as an ID, a spotify:artist: URI or an open.spotify.com URL.
+Aliases are stored in ALIASES_FILE; a running server picks up changes made here
+when it restarts, while changes made through /api/aliases apply immediately.
+
+Examples:
+  # Pin a name to an artist
+  go-listen alias add "Kanye" "https://open.spotify.com/artist/5K4W6rqBFWDnAN6FQUkS6x"
+
+  # List every alias
+  go-listen alias list
+
+  # Remove an alias
+  go-listen alias remove "Kanye"
+
+  # Back up the aliases and restore them, replacing any made since
+  go-listen alias export aliases.yaml
+  go-listen alias import aliases.yaml --replace`,
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List artist aliases",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dict := openAliasesOrExit()
+		listAliases(os.Stdout, dict.List())
+	},
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add NAME ARTIST",
+	Short: "Add or replace an artist alias",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		dict := openAliasesOrExit()
+		entry := types.ArtistAlias{Name: args[0], ArtistID: args[1], ArtistName: aliasArtistName}
+		if err := addAlias(cmd.Context(), dict, lookupAliasArtist, entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:     "remove NAME",
+	Aliases: []string{"rm", "delete"},
+	Short:   "Remove an artist alias",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dict := openAliasesOrExit()
+		if err := dict.Delete(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed alias %s\n", args[0])
+	},
+}
+
+var aliasImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import artist aliases from a YAML file (- reads standard input)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dict := openAliasesOrExit()
+		count, err := importAliases(dict, args[0], aliasReplace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Imported %d aliases (%d in total)\n", count, len(dict.List()))
+	},
+}
+
+var aliasExportCmd = &cobra.Command{
+	Use:   "export [FILE]",
+	Short: "Export artist aliases as YAML to a file or standard output",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dict := openAliasesOrExit()
+		path := "-"
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if err := exportAliases(dict.List(), path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// openAliasesOrExit opens the configured alias dictionary, exiting if it can't be loaded
+func openAliasesOrExit() *alias.Dictionary {
+	dict, err := alias.Open(conf.Aliases)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return dict
+}
+
+// listAliases prints aliases as a table
+func listAliases(w io.Writer, aliases []types.ArtistAlias) {
+	if len(aliases) == 0 {
+		fmt.Fprintln(w, "No aliases")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tARTIST ID\tARTIST")
+	for _, a := range aliases {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", a.Name, a.ArtistID, a.ArtistName)
+	}
+	_ = tw.Flush()
+}
+
+// artistLookup returns the Spotify artist with an ID
+type artistLookup func(ctx context.Context, artistID string) (*types.Artist, error)
+
+// lookupAliasArtist looks an artist up on Spotify when the CLI has been
+// authenticated, returning a nil artist when it hasn't
+func lookupAliasArtist(ctx context.Context, artistID string) (*types.Artist, error) {
+	logger := log.New()
+	if !debug {
+		logger.SetLevel(log.WarnLevel)
+	}
+
+	spotifyService := spotify.NewService(conf.Spotify, logger)
+	if !spotifyService.IsAuthenticated() {
+		return nil, nil
+	}
+	return spotifyService.GetArtist(ctx, artistID)
+}
+
+// addAlias saves an alias, first checking its artist exists on Spotify and
+// recording the artist's name when lookup can reach Spotify
+func addAlias(ctx context.Context, dict *alias.Dictionary, lookup artistLookup, entry types.ArtistAlias) error {
+	entry, err := alias.Normalize(entry)
+	if err != nil {
+		return err
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	artist, err := lookup(ctx, entry.ArtistID)
+	switch {
+	case errors.Is(err, spotify.ErrNotFound):
+		return fmt.Errorf("artist %s not found on Spotify", entry.ArtistID)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: could not look up artist %s, saving the alias unverified: %v\n", entry.ArtistID, err)
+	case artist != nil && entry.ArtistName == "":
+		entry.ArtistName = artist.Name
+	}
+
+	saved, replaced, err := dict.Set(entry)
+	if err != nil {
+		return err
+	}
+
+	action := "Added"
+	if replaced {
+		action = "Replaced"
+	}
+	target := saved.ArtistID
+	if saved.ArtistName != "" {
+		target = fmt.Sprintf("%s (%s)", saved.ArtistName, saved.ArtistID)
+	}
+	fmt.Printf("%s alias %s → %s\n", action, saved.Name, target)
+	return nil
+}
+
+// importAliases imports the aliases in a YAML file, or standard input for "-"
+func importAliases(dict *alias.Dictionary, path string, replace bool) (int, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path) // #nosec G304 -- the user names the file to import
+		if err != nil {
+			return 0, fmt.Errorf("failed to open alias file: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	aliases, err := alias.ReadYAML(r)
+	if err != nil {
+		return 0, err
+	}
+	return dict.Import(aliases, replace)
+}
+
+// exportAliases writes aliases as YAML to a file, or standard output for "-"
+func exportAliases(aliases []types.ArtistAlias, path string) error {
+	if path == "-" {
+		return alias.WriteYAML(os.Stdout, aliases)
+	}
+
+	file, err := os.Create(path) // #nosec G304 -- the user names the file to export to
+	if err != nil {
+		return fmt.Errorf("failed to create alias file: %w", err)
+	}
+	if err := alias.WriteYAML(file, aliases); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write alias file: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	aliasAddCmd.Flags().StringVar(&aliasArtistName, "artist-name", "", "Artist name to record with the alias (looked up on Spotify when authenticated)")
+	aliasImportCmd.Flags().BoolVar(&aliasReplace, "replace", false, "Remove every existing alias before importing")
+
+	aliasCmd.AddCommand(aliasListCmd, aliasAddCmd, aliasRemoveCmd, aliasImportCmd, aliasExportCmd)
+	rootCmd.AddCommand(aliasCmd)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/services/spotify"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+const testCLIArtistID = "5K4W6rqBFWDnAN6FQUkS6x"
+
+func TestAddAlias(t *testing.T) {
+	tests := []struct {
+		name           string
+		entry          types.ArtistAlias
+		lookup         artistLookup
+		wantErr        bool
+		wantArtistName string
+	}{
+		{
+			name:  "records the Spotify artist name",
+			entry: types.ArtistAlias{Name: "Kanye", ArtistID: "spotify:artist:" + testCLIArtistID},
+			lookup: func(ctx context.Context, artistID string) (*types.Artist, error) {
+				return &types.Artist{ID: artistID, Name: "Ye"}, nil
+			},
+			wantArtistName: "Ye",
+		},
+		{
+			name:  "keeps an explicit artist name",
+			entry: types.ArtistAlias{Name: "Kanye", ArtistID: testCLIArtistID, ArtistName: "Kanye West"},
+			lookup: func(ctx context.Context, artistID string) (*types.Artist, error) {
+				return &types.Artist{ID: artistID, Name: "Ye"}, nil
+			},
+			wantArtistName: "Kanye West",
+		},
+		{
+			name:  "saves unverified when not authenticated",
+			entry: types.ArtistAlias{Name: "Kanye", ArtistID: testCLIArtistID},
+			lookup: func(ctx context.Context, artistID string) (*types.Artist, error) {
+				return nil, nil
+			},
+		},
+		{
+			name:  "saves unverified when Spotify fails",
+			entry: types.ArtistAlias{Name: "Kanye", ArtistID: testCLIArtistID},
+			lookup: func(ctx context.Context, artistID string) (*types.Artist, error) {
+				return nil, errors.New("connection refused")
+			},
+		},
+		{
+			name:  "rejects an unknown artist",
+			entry: types.ArtistAlias{Name: "Kanye", ArtistID: testCLIArtistID},
+			lookup: func(ctx context.Context, artistID string) (*types.Artist, error) {
+				return nil, fmt.Errorf("%w: artist %s", spotify.ErrNotFound, artistID)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "rejects an invalid artist reference",
+			entry:   types.ArtistAlias{Name: "Kanye", ArtistID: "not-an-id"},
+			lookup:  func(ctx context.Context, artistID string) (*types.Artist, error) { return nil, nil },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dict, err := alias.NewDictionary(alias.NewMemoryStore())
+			if err != nil {
+				t.Fatalf("NewDictionary() unexpected error: %v", err)
+			}
+
+			err = addAlias(context.Background(), dict, tt.lookup, tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addAlias() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			saved, ok := dict.Resolve("kanye")
+			if tt.wantErr {
+				if ok {
+					t.Errorf("addAlias() saved %+v despite failing", saved)
+				}
+				return
+			}
+			if !ok || saved.ArtistID != testCLIArtistID || saved.ArtistName != tt.wantArtistName {
+				t.Errorf("addAlias() saved %+v, %v, want artist %s named %q", saved, ok, testCLIArtistID, tt.wantArtistName)
+			}
+		})
+	}
+}
+
+func TestImportExportAliases(t *testing.T) {
+	source, err := alias.NewDictionary(alias.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("NewDictionary() unexpected error: %v", err)
+	}
+	if _, _, err := source.Set(types.ArtistAlias{Name: "Kanye", ArtistID: testCLIArtistID, ArtistName: "Ye"}); err != nil {
+		t.Fatalf("Set() unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "aliases.yaml")
+	if err := exportAliases(source.List(), path); err != nil {
+		t.Fatalf("exportAliases() unexpected error: %v", err)
+	}
+
+	target, err := alias.NewDictionary(alias.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("NewDictionary() unexpected error: %v", err)
+	}
+	count, err := importAliases(target, path, false)
+	if err != nil || count != 1 {
+		t.Fatalf("importAliases() = %d, %v, want 1 alias imported", count, err)
+	}
+	if saved, ok := target.Resolve("Kanye"); !ok || saved.ArtistName != "Ye" {
+		t.Errorf("importAliases() imported %+v, %v, want the exported alias", saved, ok)
+	}
+
+	if _, err := importAliases(target, filepath.Join(t.TempDir(), "missing.yaml"), false); err == nil {
+		t.Error("importAliases() expected error for a missing file")
+	}
+}
+
+func TestListAliases(t *testing.T) {
+	var buf bytes.Buffer
+	listAliases(&buf, nil)
+	if buf.String() != "No aliases\n" {
+		t.Errorf("listAliases() with no aliases = %q", buf.String())
+	}
+
+	buf.Reset()
+	listAliases(&buf, []types.ArtistAlias{{Name: "Kanye", ArtistID: testCLIArtistID, ArtistName: "Ye"}})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "NAME") || !strings.Contains(lines[1], testCLIArtistID) {
+		t.Errorf("listAliases() = %q, want a header and one row", buf.String())
+	}
+}
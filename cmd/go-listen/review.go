@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/services/blocklist"
+	"github.com/toozej/go-listen/internal/services/duplicate"
+	"github.com/toozej/go-listen/internal/services/history"
+	"github.com/toozej/go-listen/internal/services/playlist"
+	"github.com/toozej/go-listen/internal/services/review"
+	"github.com/toozej/go-listen/internal/services/spotify"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+var (
+	reviewStatus   string
+	reviewPlaylist string
+	reviewLimit    int
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Review uncertain artist matches from scrapes",
+	Long: `Work through the queue of scraped artists whose match was not confident
+enough to add them straight away. Matches below REVIEW_AUTO_ADD_CONFIDENCE, or
+with candidates too close to pick between, wait here with their candidates and
+the page they were scraped from.
+
+Accepting an item adds its best candidate to the playlist it was scraped for,
+with the scrape's track selection and add mode; remapping adds another artist
+instead. Artists may be given as an ID, a spotify:artist: URI or an
+open.spotify.com URL. Items are stored in REVIEW_FILE, shared with the server.
+
+Examples:
+  # List the items waiting for review
+  go-listen review list
+
+  # Show an item's candidates and where it was found
+  go-listen review show 12
+
+  # Add the best candidate of items 12 and 13
+  go-listen review accept 12 13
+
+  # Add another artist than the best candidate
+  go-listen review remap 14 "https://open.spotify.com/artist/4Z8W4fKeB5YxbusRsdQVPb"
+
+  # Dismiss an item without adding anything
+  go-listen review reject 15`,
+}
+
+var reviewListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List review queue items, oldest first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := reviewFilter(reviewStatus, reviewPlaylist, reviewLimit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		store := openReviewOrExit()
+		items, err := store.List(filter)
+		store.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		listReviewItems(os.Stdout, items)
+	},
+}
+
+var reviewShowCmd = &cobra.Command{
+	Use:   "show ID",
+	Short: "Show a review queue item with its candidates",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseReviewID(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		store := openReviewOrExit()
+		item, err := store.Get(id)
+		store.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		showReviewItem(os.Stdout, item)
+	},
+}
+
+var reviewAcceptCmd = &cobra.Command{
+	Use:   "accept ID...",
+	Short: "Add the best candidate of review queue items",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ids, err := parseReviewIDs(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		runReviewDecisions(cmd.Context(), func(ctx context.Context, service *review.Service, source types.AddSource) error {
+			failed := 0
+			for _, id := range ids {
+				if err := acceptReviewItem(ctx, os.Stdout, service, id, "", source); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: item %d: %v\n", id, err)
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d items could not be accepted", failed, len(ids))
+			}
+			return nil
+		})
+	},
+}
+
+var reviewRemapCmd = &cobra.Command{
+	Use:   "remap ID ARTIST",
+	Short: "Add another artist than the best candidate of a review queue item",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseReviewID(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		artistID, err := alias.ParseArtistID(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		runReviewDecisions(cmd.Context(), func(ctx context.Context, service *review.Service, source types.AddSource) error {
+			return acceptReviewItem(ctx, os.Stdout, service, id, artistID, source)
+		})
+	},
+}
+
+var reviewRejectCmd = &cobra.Command{
+	Use:   "reject ID...",
+	Short: "Dismiss review queue items without adding anything",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ids, err := parseReviewIDs(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		store := openReviewOrExit()
+		service := review.NewService(store, nil, log.New())
+		failed := 0
+		for _, id := range ids {
+			if err := rejectReviewItem(cmd.Context(), os.Stdout, service, id); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: item %d: %v\n", id, err)
+				failed++
+			}
+		}
+		store.Close()
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// openReviewOrExit opens the configured review database, exiting if it can't be opened
+func openReviewOrExit() *review.SQLiteStore {
+	store, err := review.Open(conf.Review.File)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return store
+}
+
+// runReviewDecisions runs decide with a review service adding artists through an
+// authenticated playlist manager, exiting with an error status if decide fails
+func runReviewDecisions(ctx context.Context, decide func(ctx context.Context, service *review.Service, source types.AddSource) error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := log.New()
+	if debug {
+		logger.SetLevel(log.DebugLevel)
+	} else {
+		logger.SetLevel(log.WarnLevel)
+	}
+
+	spotifyService := spotify.NewService(conf.Spotify, logger)
+	if !spotifyService.IsAuthenticated() {
+		fmt.Fprintln(os.Stderr, "Error: Not authenticated with Spotify. Please run 'go-listen serve' and authenticate first (requires SPOTIFY_TOKEN_STORE=file).")
+		os.Exit(1)
+	}
+
+	// Add artists like a scrape would, with the same duplicate checks, blocklist and routing
+	duplicateDetector := duplicate.NewDuplicateService(spotifyService, logger)
+	duplicateDetector.SetSiblingPlaylists(conf.Duplicates.SiblingPlaylists)
+	duplicateDetector.SetIndexTTL(time.Duration(conf.Duplicates.IndexTTLSeconds) * time.Second)
+	playlistManager := playlist.NewPlaylistService(spotifyService, duplicateDetector, logger)
+
+	blocked, err := blocklist.New(conf.Blocklist)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	playlistManager.SetBlocklist(blocked)
+
+	if conf.Routing.Enabled() {
+		router, err := playlist.NewRouter(conf.Routing, spotifyService)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		playlistManager.SetRouter(router)
+	}
+
+	var historyStore *history.SQLiteStore
+	if conf.History.Enabled {
+		historyStore, err = history.Open(conf.History.File)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to open history database, additions will not be recorded")
+		} else {
+			duplicateDetector.SetHistory(historyStore)
+			playlistManager.SetHistory(historyStore)
+		}
+	}
+
+	source := types.AddSource{Kind: types.SourceCLI}
+	if source.User, err = spotifyService.CurrentUserID(ctx); err != nil {
+		logger.WithError(err).Debug("Failed to get Spotify user for history")
+	}
+
+	store := openReviewOrExit()
+	err = decide(ctx, review.NewService(store, playlistManager, logger), source)
+
+	// Closed explicitly since os.Exit skips deferred calls
+	store.Close()
+	if historyStore != nil {
+		historyStore.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// reviewFilter builds the filter of review list from its flags; status "all" lists every item
+func reviewFilter(status, playlistID string, limit int) (types.ReviewFilter, error) {
+	filter := types.ReviewFilter{Status: types.ReviewStatus(status), PlaylistID: playlistID, Limit: limit}
+	switch filter.Status {
+	case "all":
+		filter.Status = ""
+	case types.ReviewPending, types.ReviewAccepted, types.ReviewRemapped, types.ReviewRejected:
+	default:
+		return filter, fmt.Errorf("--status must be one of pending, accepted, remapped, rejected, all")
+	}
+	if limit < 0 || limit > types.MaxReviewLimit {
+		return filter, fmt.Errorf("--limit must be between 0 and %d", types.MaxReviewLimit)
+	}
+	return filter, nil
+}
+
+// parseReviewID parses the ID of a review queue item
+func parseReviewID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid review item ID %q", arg)
+	}
+	return id, nil
+}
+
+// parseReviewIDs parses the IDs of review queue items
+func parseReviewIDs(args []string) ([]int64, error) {
+	ids := make([]int64, len(args))
+	for i, arg := range args {
+		id, err := parseReviewID(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// listReviewItems prints review queue items as a table
+func listReviewItems(w io.Writer, items []types.ReviewItem) {
+	if len(items) == 0 {
+		fmt.Fprintln(w, "No review items")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tNAME\tBEST MATCH\tCONFIDENCE\tPLAYLIST\tSOURCE")
+	for _, item := range items {
+		source := item.SourceURL
+		if source == "" {
+			source = string(item.Source)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%.2f\t%s\t%s\n",
+			item.ID, item.Status, item.Query, item.Artist.Name, item.Confidence, item.PlaylistID, source)
+	}
+	_ = tw.Flush()
+}
+
+// showReviewItem prints a review queue item with its candidates and where it was found
+func showReviewItem(w io.Writer, item *types.ReviewItem) {
+	fmt.Fprintf(w, "Review item %d (%s)\n", item.ID, item.Status)
+	fmt.Fprintf(w, "Name:       %s\n", item.Query)
+	fmt.Fprintf(w, "Playlist:   %s\n", item.PlaylistID)
+	if item.SourceURL != "" {
+		fmt.Fprintf(w, "Scraped:    %s (%s)\n", item.SourceURL, item.FoundBy)
+	}
+	fmt.Fprintf(w, "Queued:     %s by %s\n", item.CreatedAt.Local().Format("2006-01-02 15:04"), item.Source)
+	if item.ResolvedAt != nil {
+		fmt.Fprintf(w, "Resolved:   %s", item.ResolvedAt.Local().Format("2006-01-02 15:04"))
+		if item.ResolvedArtistID != "" {
+			fmt.Fprintf(w, " with %s", item.ResolvedArtistID)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "Candidates:")
+	candidates := item.Candidates
+	if len(candidates) == 0 {
+		candidates = []types.ArtistCandidate{{Artist: item.Artist, Confidence: item.Confidence}}
+	}
+	for i, candidate := range candidates {
+		marker := " "
+		if i == 0 {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "  %s %s (%s, confidence: %.2f)\n", marker, candidate.Artist.Name, candidate.Artist.ID, candidate.Confidence)
+	}
+}
+
+// acceptReviewItem accepts an item, remapping it to artistID when that is set, and prints the outcome
+func acceptReviewItem(ctx context.Context, w io.Writer, service *review.Service, id int64, artistID string, source types.AddSource) error {
+	item, result, err := service.Accept(ctx, id, artistID, source)
+	if err != nil {
+		if errors.Is(err, review.ErrResolved) && item != nil {
+			return fmt.Errorf("already %s", item.Status)
+		}
+		return err
+	}
+
+	fmt.Fprintf(w, "%s item %d (%s): %s\n", reviewAction(item.Status), item.ID, item.Query, result.Message)
+	return nil
+}
+
+// rejectReviewItem rejects an item and prints the outcome
+func rejectReviewItem(ctx context.Context, w io.Writer, service *review.Service, id int64) error {
+	item, err := service.Reject(ctx, id)
+	if err != nil {
+		if errors.Is(err, review.ErrResolved) && item != nil {
+			return fmt.Errorf("already %s", item.Status)
+		}
+		return err
+	}
+
+	fmt.Fprintf(w, "Rejected item %d (%s)\n", item.ID, item.Query)
+	return nil
+}
+
+// reviewAction names the decision that left an item with a status
+func reviewAction(status types.ReviewStatus) string {
+	if status == types.ReviewRemapped {
+		return "Remapped"
+	}
+	return "Accepted"
+}
+
+func init() {
+	reviewListCmd.Flags().StringVar(&reviewStatus, "status", string(types.ReviewPending), "Items to list: pending, accepted, remapped, rejected or all")
+	reviewListCmd.Flags().StringVar(&reviewPlaylist, "playlist", "", "Only list items for this playlist ID")
+	reviewListCmd.Flags().IntVar(&reviewLimit, "limit", types.DefaultReviewLimit, fmt.Sprintf("Maximum number of items to list (at most %d)", types.MaxReviewLimit))
+
+	reviewCmd.AddCommand(reviewListCmd, reviewShowCmd, reviewAcceptCmd, reviewRemapCmd, reviewRejectCmd)
+	rootCmd.AddCommand(reviewCmd)
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/review"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// reviewPlaylistManager records the artists added from the review queue
+type reviewPlaylistManager struct {
+	types.PlaylistManager
+	added []string
+}
+
+func (m *reviewPlaylistManager) AddArtistByID(ctx context.Context, artistID, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	m.added = append(m.added, artistID)
+	return &types.AddResult{Success: true, Message: "Added 5 tracks"}, nil
+}
+
+func newReviewTestService(t *testing.T, queries ...string) (*review.Service, *reviewPlaylistManager) {
+	t.Helper()
+	store, err := review.Open(filepath.Join(t.TempDir(), "review.db"))
+	if err != nil {
+		t.Fatalf("review.Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	for _, query := range queries {
+		item := &types.ReviewItem{
+			Query:      query,
+			Artist:     types.Artist{ID: testCLIArtistID, Name: query},
+			Confidence: 0.55,
+			PlaylistID: "playlist1",
+			Source:     types.SourceCLI,
+			SourceURL:  "https://example.com/lineup",
+			FoundBy:    "text",
+		}
+		if err := store.Enqueue(item); err != nil {
+			t.Fatalf("Enqueue() unexpected error: %v", err)
+		}
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	playlist := &reviewPlaylistManager{}
+	return review.NewService(store, playlist, logger), playlist
+}
+
+func TestReviewFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		limit   int
+		want    types.ReviewStatus
+		wantErr bool
+	}{
+		{name: "pending", status: "pending", limit: 10, want: types.ReviewPending},
+		{name: "all", status: "all", limit: 10, want: ""},
+		{name: "unknown status", status: "maybe", wantErr: true},
+		{name: "limit too large", status: "pending", limit: types.MaxReviewLimit + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reviewFilter(tt.status, "", tt.limit)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("reviewFilter() expected error but got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("reviewFilter() unexpected error: %v", err)
+			}
+			if got.Status != tt.want || got.Limit != tt.limit {
+				t.Errorf("reviewFilter() = %+v, want status %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReviewIDs(t *testing.T) {
+	ids, err := parseReviewIDs([]string{"3", "12"})
+	if err != nil || len(ids) != 2 || ids[0] != 3 || ids[1] != 12 {
+		t.Errorf("parseReviewIDs() = %v, %v, want [3 12]", ids, err)
+	}
+	for _, arg := range []string{"0", "-1", "abc"} {
+		if _, err := parseReviewIDs([]string{"1", arg}); err == nil {
+			t.Errorf("parseReviewIDs(%q) expected error but got none", arg)
+		}
+	}
+}
+
+func TestListReviewItems(t *testing.T) {
+	var empty bytes.Buffer
+	listReviewItems(&empty, nil)
+	if !strings.Contains(empty.String(), "No review items") {
+		t.Errorf("listReviewItems() with no items = %q", empty.String())
+	}
+
+	service, _ := newReviewTestService(t, "Big Thief")
+	items, err := service.Store().List(types.ReviewFilter{})
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	listReviewItems(&buf, items)
+	for _, want := range []string{"ID", "CONFIDENCE", "Big Thief", "0.55", "playlist1", "https://example.com/lineup"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("listReviewItems() output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestShowReviewItem(t *testing.T) {
+	service, _ := newReviewTestService(t, "Big Thief")
+	item, err := service.Store().Get(1)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	showReviewItem(&buf, item)
+	for _, want := range []string{"Review item 1 (pending)", "https://example.com/lineup (text)", "* Big Thief (" + testCLIArtistID} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("showReviewItem() output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestAcceptReviewItem(t *testing.T) {
+	const otherArtistID = "4Z8W4fKeB5YxbusRsdQVPb"
+	service, playlist := newReviewTestService(t, "First", "Second")
+	source := types.AddSource{Kind: types.SourceCLI}
+
+	var buf bytes.Buffer
+	if err := acceptReviewItem(context.Background(), &buf, service, 1, "", source); err != nil {
+		t.Fatalf("acceptReviewItem() unexpected error: %v", err)
+	}
+	if err := acceptReviewItem(context.Background(), &buf, service, 2, otherArtistID, source); err != nil {
+		t.Fatalf("acceptReviewItem() remap unexpected error: %v", err)
+	}
+	if len(playlist.added) != 2 || playlist.added[0] != testCLIArtistID || playlist.added[1] != otherArtistID {
+		t.Errorf("Added %v, want the best candidate then the remapped artist", playlist.added)
+	}
+	for _, want := range []string{"Accepted item 1 (First): Added 5 tracks", "Remapped item 2 (Second)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("acceptReviewItem() output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	err := acceptReviewItem(context.Background(), &buf, service, 1, "", source)
+	if err == nil || !strings.Contains(err.Error(), "already accepted") {
+		t.Errorf("acceptReviewItem() twice error = %v, want already accepted", err)
+	}
+	if err := acceptReviewItem(context.Background(), &buf, service, 9, "", source); err == nil {
+		t.Error("acceptReviewItem() unknown item expected error but got none")
+	}
+}
+
+func TestRejectReviewItem(t *testing.T) {
+	service, playlist := newReviewTestService(t, "First")
+
+	var buf bytes.Buffer
+	if err := rejectReviewItem(context.Background(), &buf, service, 1); err != nil {
+		t.Fatalf("rejectReviewItem() unexpected error: %v", err)
+	}
+	if len(playlist.added) != 0 || !strings.Contains(buf.String(), "Rejected item 1 (First)") {
+		t.Errorf("rejectReviewItem() added %v and printed %q", playlist.added, buf.String())
+	}
+	if err := rejectReviewItem(context.Background(), &buf, service, 1); err == nil || !strings.Contains(err.Error(), "already rejected") {
+		t.Errorf("rejectReviewItem() twice error = %v, want already rejected", err)
+	}
+}
@@ -1,28 +1,49 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/services/blocklist"
+	"github.com/toozej/go-listen/internal/services/duplicate"
+	"github.com/toozej/go-listen/internal/services/history"
 	"github.com/toozej/go-listen/internal/services/playlist"
+	"github.com/toozej/go-listen/internal/services/review"
 	"github.com/toozej/go-listen/internal/services/scraper"
 	"github.com/toozej/go-listen/internal/services/search"
 	"github.com/toozej/go-listen/internal/services/spotify"
+	"github.com/toozej/go-listen/internal/types"
 )
 
 var (
-	scrapeURL   string
-	cssSelector string
-	playlistID  string
-	forceAdd    bool
+	scrapeURL     string
+	cssSelector   string
+	playlistID    string
+	forceAdd      bool
+	addMode       string
+	trackCount    int
+	strategy      string
+	market        string
+	showLive      bool
+	scrapeTimeout time.Duration
+	dryRun        bool
+	commitIDs     []string
 )
 
 var scrapeCmd = &cobra.Command{
 	Use:   "scrape",
 	Short: "Scrape artists from a website and add to playlist",
-	Long: `Scrape artist names from a website URL and add their top 5 songs to a playlist.
+	Long: `Scrape artist names from a website URL and add their songs to a playlist.
+By default each artist's top 5 songs are added; use --tracks and --strategy
+to change how many songs are added and how they are chosen.
 Optionally use a CSS selector to target specific page sections.
 
 Examples:
@@ -32,8 +53,29 @@ Examples:
   # Scrape with CSS selector
   go-listen scrape --url "https://example.com" --selector "div.content" --playlist "playlist_id"
 
+  # Add a random sample of 3 songs from each artist's catalog
+  go-listen scrape --url "https://example.com" --playlist "playlist_id" --tracks 3 --strategy random
+
+  # Add only the tracks that aren't already in the playlist
+  go-listen scrape --url "https://example.com" --playlist "playlist_id" --mode fill-gaps
+
   # Force add even if duplicates exist
-  go-listen scrape --url "https://example.com" --playlist "playlist_id" --force`,
+  go-listen scrape --url "https://example.com" --playlist "playlist_id" --force
+
+  # Print each artist as it is matched and added
+  go-listen scrape --url "https://example.com" --playlist "playlist_id" --live
+
+  # Spread artists across playlists with the ROUTING_RULES genre rules
+  go-listen scrape --url "https://example.com" --playlist auto
+
+  # Preview the artists and their matches without adding anything
+  go-listen scrape --url "https://example.com" --playlist "playlist_id" --dry-run
+
+  # Add the artists picked from the preview by their Spotify IDs
+  go-listen scrape --url "https://example.com" --playlist "playlist_id" --commit "artist_id,artist_id"
+
+  # Give up after five minutes, keeping the artists added so far
+  go-listen scrape --url "https://example.com" --playlist "playlist_id" --timeout 5m`,
 	Run: runScrapeCommand,
 }
 
@@ -49,6 +91,37 @@ func runScrapeCommand(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if playlistID == types.AutoPlaylistID && !conf.Routing.Enabled() {
+		fmt.Fprintln(os.Stderr, "Error: --playlist auto needs ROUTING_RULES or ROUTING_DEFAULT_PLAYLIST to be set")
+		os.Exit(1)
+	}
+
+	selection, err := scrapeTrackSelection(trackCount, strategy, market)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	mode, err := scrapeAddMode(addMode, cmd.Flags().Changed("mode"), forceAdd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// With --commit the artists approved from a preview are added instead of scraping again
+	var approved []types.ApprovedArtist
+	if cmd.Flags().Changed("commit") {
+		approved, err = scrapeApprovedArtists(commitIDs, dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Stop the scrape on Ctrl-C, SIGTERM or once --timeout passes
+	ctx, cancel := scrapeContext(context.Background(), scrapeTimeout)
+	defer cancel()
+
 	// Initialize logger
 	logger := log.New()
 	if debug {
@@ -58,17 +131,27 @@ func runScrapeCommand(cmd *cobra.Command, args []string) {
 	// Initialize Spotify service
 	spotifyService := spotify.NewService(conf.Spotify, logger)
 
-	// Check if authenticated
+	// Check if authenticated (tokens are restored from the configured token store)
 	if !spotifyService.IsAuthenticated() {
-		fmt.Fprintln(os.Stderr, "Error: Not authenticated with Spotify. Please run 'go-listen serve' and authenticate first.")
+		fmt.Fprintln(os.Stderr, "Error: Not authenticated with Spotify. Please run 'go-listen serve' and authenticate first (requires SPOTIFY_TOKEN_STORE=file).")
 		os.Exit(1)
 	}
 
-	// Initialize playlist manager
-	playlistManager := playlist.NewService(spotifyService, logger)
+	// Initialize duplicate detection and playlist manager
+	duplicateDetector := duplicate.NewDuplicateService(spotifyService, logger)
+	duplicateDetector.SetSiblingPlaylists(conf.Duplicates.SiblingPlaylists)
+	duplicateDetector.SetIndexTTL(time.Duration(conf.Duplicates.IndexTTLSeconds) * time.Second)
+	playlistManager := playlist.NewPlaylistService(spotifyService, duplicateDetector, logger)
 
 	// Initialize fuzzy artist searcher
 	fuzzySearcher := search.NewFuzzyArtistSearcher(spotifyService, logger)
+	fuzzySearcher.SetConcurrency(conf.Scraper.MatchConcurrency)
+	fuzzySearcher.SetWeights(search.WeightsFromConfig(conf.Search))
+	if aliases, err := alias.Open(conf.Aliases); err != nil {
+		logger.WithError(err).Warn("Failed to load artist aliases, matching without them")
+	} else {
+		fuzzySearcher.SetAliases(aliases)
+	}
 
 	// Initialize scraper components
 	parser := scraper.NewGoqueryParser(logger)
@@ -76,6 +159,7 @@ func runScrapeCommand(cmd *cobra.Command, args []string) {
 
 	// Create scraper service
 	scraperConfig := scraper.DefaultScraperConfig()
+	scraperConfig.MatchConcurrency = conf.Scraper.MatchConcurrency
 	scraperService := scraper.NewWebScraper(
 		scraperConfig,
 		parser,
@@ -84,18 +168,128 @@ func runScrapeCommand(cmd *cobra.Command, args []string) {
 		playlistManager,
 		logger,
 	)
+	scraperService.SetDuplicateDetector(duplicateDetector)
+
+	// Read known sites with their extractors, including the ones declared in config
+	extractors, err := scraper.LoadExtractorRegistry(conf.Scraper.ExtractorsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	scraperService.SetExtractors(extractors)
+
+	// Leave out blocklisted artists; the scraper screens matches held for review and
+	// previews, and the playlist manager the artists it adds
+	blocked, err := blocklist.New(conf.Blocklist)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	scraperService.SetBlocklist(blocked)
+	playlistManager.SetBlocklist(blocked)
+
+	// Route artists by genre when scraping into the auto playlist
+	if conf.Routing.Enabled() {
+		router, err := playlist.NewRouter(conf.Routing, spotifyService)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		scraperService.SetRouter(router)
+		playlistManager.SetRouter(router)
+	}
 
 	// Perform scraping operation
 	logger.WithFields(log.Fields{
 		"url":          scrapeURL,
 		"css_selector": cssSelector,
 		"playlist_id":  playlistID,
-		"force":        forceAdd,
+		"mode":         mode,
+		"track_count":  selection.Count,
+		"strategy":     selection.Strategy,
+		"market":       selection.Market,
 	}).Info("Starting scraping operation")
 
-	result, err := scraperService.ScrapeAndAddToPlaylist(scrapeURL, cssSelector, playlistID, forceAdd)
+	// Record additions in the history database shared with the server
+	var historyStore *history.SQLiteStore
+	if conf.History.Enabled {
+		historyStore, err = history.Open(conf.History.File)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to open history database, additions will not be recorded")
+		} else {
+			duplicateDetector.SetHistory(historyStore)
+			playlistManager.SetHistory(historyStore)
+			scraperService.SetHistory(historyStore)
+		}
+	}
+
+	// Hold uncertain matches in the review queue shared with the server
+	var reviewStore *review.SQLiteStore
+	if conf.Review.Enabled {
+		if err := conf.Review.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		reviewStore, err = review.Open(conf.Review.File)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to open review database, matches will be added without review")
+		} else {
+			scraperService.SetReviewQueue(reviewStore, conf.Review.MinConfidence, conf.Review.AutoAddConfidence)
+		}
+	}
+	// closeStores closes the databases explicitly since os.Exit skips deferred calls
+	closeStores := func() {
+		if historyStore != nil {
+			if closeErr := historyStore.Close(); closeErr != nil {
+				logger.WithError(closeErr).Warn("Failed to close history database")
+			}
+		}
+		if reviewStore != nil {
+			if closeErr := reviewStore.Close(); closeErr != nil {
+				logger.WithError(closeErr).Warn("Failed to close review database")
+			}
+		}
+	}
+
+	source := types.AddSource{Kind: types.SourceCLI}
+	if source.User, err = spotifyService.CurrentUserID(ctx); err != nil {
+		logger.WithError(err).Debug("Failed to get Spotify user for history")
+	}
+
+	var progress scraper.ProgressFunc
+	if showLive {
+		progress = func(p scraper.ScrapeProgress) {
+			if p.Event != nil {
+				fmt.Fprintln(os.Stderr, formatProgressEvent(*p.Event))
+			}
+		}
+	}
+
+	if dryRun {
+		preview, err := scraperService.PreviewScrape(ctx, scrapeURL, cssSelector, playlistID, progress)
+		closeStores()
+		if err != nil {
+			logger.WithError(err).Error("Scrape preview failed")
+			fmt.Fprintf(os.Stderr, "Error: Scrape preview failed: %v\n", err)
+			os.Exit(1)
+		}
+		displayScrapePreview(preview)
+		os.Exit(0)
+	}
+
+	var result *scraper.ScrapeResult
+	if approved != nil {
+		result, err = scraperService.CommitScrape(ctx, scrapeURL, playlistID, approved, mode, selection, source, progress)
+	} else {
+		result, err = scraperService.ScrapeAndAddToPlaylistWithProgress(ctx, scrapeURL, cssSelector, playlistID, mode, selection, source, progress)
+	}
+	closeStores()
 	if err != nil {
 		logger.WithError(err).Error("Scraping operation failed")
+		if result != nil && ctx.Err() != nil {
+			// Show what was added before the scrape was interrupted
+			displayScrapeResults(result)
+		}
 		fmt.Fprintf(os.Stderr, "Error: Scraping operation failed: %v\n", err)
 		os.Exit(1)
 	}
@@ -113,6 +307,126 @@ func runScrapeCommand(cmd *cobra.Command, args []string) {
 	os.Exit(0)
 }
 
+// scrapeContext returns the context a scrape runs under: cancelled on an interrupt
+// or SIGTERM, and after timeout when it is positive
+func scrapeContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// scrapeTrackSelection builds and validates the track selection from the command flags
+func scrapeTrackSelection(count int, strategyName, marketCode string) (types.TrackSelection, error) {
+	parsed, err := types.ParseTrackStrategy(strategyName)
+	if err != nil {
+		return types.TrackSelection{}, err
+	}
+
+	selection := types.TrackSelection{Count: count, Strategy: parsed, Market: strings.ToUpper(marketCode)}
+	if err := selection.Validate(); err != nil {
+		return types.TrackSelection{}, err
+	}
+	return selection, nil
+}
+
+// scrapeAddMode resolves the add mode from the command flags; --force is shorthand for --mode force
+func scrapeAddMode(modeName string, modeSet, force bool) (types.AddMode, error) {
+	mode, err := types.ParseAddMode(modeName)
+	if err != nil {
+		return "", err
+	}
+	if force {
+		if modeSet && mode != types.AddModeForce {
+			return "", fmt.Errorf("--force cannot be combined with --mode %s", modeName)
+		}
+		return types.AddModeForce, nil
+	}
+	return mode, nil
+}
+
+// scrapeApprovedArtists builds the artists to add from the --commit artist IDs, in
+// order and without repeats
+func scrapeApprovedArtists(ids []string, dryRun bool) ([]types.ApprovedArtist, error) {
+	if dryRun {
+		return nil, fmt.Errorf("--commit cannot be combined with --dry-run")
+	}
+
+	seen := make(map[string]bool, len(ids))
+	artists := make([]types.ApprovedArtist, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if !types.IsSpotifyID(id) {
+			return nil, fmt.Errorf("%q is not a Spotify artist ID", id)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		artists = append(artists, types.ApprovedArtist{ArtistID: id})
+	}
+	if len(artists) == 0 {
+		return nil, fmt.Errorf("--commit needs at least one artist ID")
+	}
+	return artists, nil
+}
+
+// formatProgressEvent renders a progress event as a single line of the live log
+func formatProgressEvent(event types.ProgressEvent) string {
+	name := event.Query
+	if event.Artist != nil {
+		name = event.Artist.Name
+	}
+
+	switch event.Type {
+	case types.EventArtistFound:
+		// Names read from structured data or by a site extractor say where they came from
+		if event.Source != "" && event.Source != "text" {
+			return fmt.Sprintf("Found %s (%s)", event.Query, event.Source)
+		}
+		return fmt.Sprintf("Found %s", event.Query)
+	case types.EventArtistMatched:
+		return fmt.Sprintf("Matched %s → %s (confidence: %.2f)", event.Query, name, event.Confidence)
+	case types.EventArtistNotMatched:
+		return fmt.Sprintf("No match for %s: %s", event.Query, event.Message)
+	case types.EventArtistBlocked:
+		return fmt.Sprintf("Blocked %s: %s", name, event.Message)
+	case types.EventArtistQueued:
+		return fmt.Sprintf("Held %s → %s for review (confidence: %.2f)", event.Query, name, event.Confidence)
+	case types.EventDuplicateSkipped:
+		return fmt.Sprintf("Skipped %s: %s", name, event.Message)
+	case types.EventTracksAdded:
+		line := fmt.Sprintf("Added %d tracks by %s", event.TracksAdded, name)
+		if event.Route != nil {
+			line += " to " + routeName(event.Route)
+		}
+		if event.TracksSkipped > 0 {
+			line += fmt.Sprintf(" (%d already present)", event.TracksSkipped)
+		}
+		return line
+	case types.EventError:
+		if name == "" {
+			return fmt.Sprintf("Error: %s", event.Message)
+		}
+		return fmt.Sprintf("Error for %s: %s", name, event.Message)
+	default:
+		return event.Message
+	}
+}
+
+// routeName names the playlist an artist was routed to
+func routeName(route *types.PlaylistRoute) string {
+	if route.PlaylistName != "" {
+		return route.PlaylistName
+	}
+	return route.PlaylistID
+}
+
 func displayScrapeResults(result *scraper.ScrapeResult) {
 	fmt.Println("\n=== Scraping Results ===")
 	fmt.Printf("URL: %s\n", result.URL)
@@ -126,8 +440,17 @@ func displayScrapeResults(result *scraper.ScrapeResult) {
 	fmt.Printf("Successfully Matched: %d\n", countMatched(result.MatchResults))
 	fmt.Printf("Successfully Added: %d\n", result.SuccessCount)
 	fmt.Printf("Duplicates Skipped: %d\n", result.DuplicateCount)
+	if result.BlockedCount > 0 {
+		fmt.Printf("Blocked: %d\n", result.BlockedCount)
+	}
+	if result.ReviewCount > 0 {
+		fmt.Printf("Held for Review: %d\n", result.ReviewCount)
+	}
 	fmt.Printf("Failed: %d\n", result.FailureCount)
 	fmt.Printf("Total Tracks Added: %d\n", result.TotalTracksAdded)
+	if result.TotalTracksSkipped > 0 {
+		fmt.Printf("Tracks Already Present: %d\n", result.TotalTracksSkipped)
+	}
 	fmt.Println()
 
 	// Detailed results
@@ -153,8 +476,12 @@ func displayMatchResult(match scraper.ArtistMatchResult) {
 	status := "✗ FAILED"
 
 	switch {
+	case match.Blocked:
+		status = "⊘ BLOCKED"
 	case match.WasDuplicate:
 		status = "⊘ DUPLICATE"
+	case match.NeedsReview:
+		status = "? REVIEW"
 	case match.Matched && match.TracksAdded > 0:
 		status = "✓ SUCCESS"
 	case match.Matched:
@@ -174,13 +501,103 @@ func displayMatchResult(match scraper.ArtistMatchResult) {
 		fmt.Printf(" - %d tracks added", match.TracksAdded)
 	}
 
-	if match.Error != "" {
+	if match.Route != nil {
+		fmt.Printf(" [%s]", routeName(match.Route))
+	}
+
+	if match.TracksSkipped > 0 {
+		fmt.Printf(" - %d tracks already present", match.TracksSkipped)
+	}
+
+	if match.ReviewID > 0 {
+		fmt.Printf(" - review item %d", match.ReviewID)
+	} else if match.Error != "" {
 		fmt.Printf(" - Error: %s", match.Error)
 	}
 
+	if match.LastAdded != nil {
+		fmt.Printf(" (last added %s)", match.LastAdded.Local().Format("2006-01-02 15:04"))
+	}
+
 	fmt.Println()
 }
 
+// displayScrapePreview prints what a scrape would add, with the candidates of
+// artists whose match should be checked
+func displayScrapePreview(preview *scraper.ScrapePreview) {
+	fmt.Println("\n=== Scrape Preview (dry run, nothing added) ===")
+	fmt.Printf("URL: %s\n", preview.URL)
+	if preview.CSSSelector != "" {
+		fmt.Printf("CSS Selector: %s\n", preview.CSSSelector)
+	}
+	fmt.Println()
+
+	fmt.Printf("Artists Found: %d\n", len(preview.ArtistsFound))
+	fmt.Printf("Matched: %d\n", preview.MatchedCount)
+	fmt.Printf("Already in Playlist: %d\n", preview.DuplicateCount)
+	if preview.BlockedCount > 0 {
+		fmt.Printf("Blocked: %d\n", preview.BlockedCount)
+	}
+	if preview.DisambiguationCount > 0 {
+		fmt.Printf("Ambiguous: %d\n", preview.DisambiguationCount)
+	}
+	if preview.ReviewCount > 0 {
+		fmt.Printf("Would Be Held for Review: %d\n", preview.ReviewCount)
+	}
+	fmt.Println()
+
+	if len(preview.Artists) > 0 {
+		fmt.Println("=== Artists ===")
+		for _, match := range preview.Artists {
+			fmt.Printf("[%s] %s", previewStatus(match), match.Query)
+			if match.Artist != nil {
+				fmt.Printf(" → %s (%s, confidence: %.2f)", match.Artist.Name, match.Artist.ID, match.Confidence)
+			}
+			if match.Route != nil {
+				fmt.Printf(" [%s]", routeName(match.Route))
+			}
+			if match.Error != "" {
+				fmt.Printf(" - %s", match.Error)
+			}
+			if match.LastAdded != nil {
+				fmt.Printf(" (last added %s)", match.LastAdded.Local().Format("2006-01-02 15:04"))
+			}
+			fmt.Println()
+
+			// Offer the other candidates when the best one may be the wrong artist
+			if match.NeedsDisambiguation || match.NeedsReview || !match.Matched {
+				for _, candidate := range match.Candidates[min(1, len(match.Candidates)):] {
+					fmt.Printf("      or %s (%s, confidence: %.2f)\n", candidate.Artist.Name, candidate.Artist.ID, candidate.Confidence)
+				}
+			}
+		}
+	}
+
+	fmt.Printf("\n%s\n", preview.Message)
+}
+
+// previewStatus labels what a scrape would do with a previewed artist
+func previewStatus(match scraper.ArtistMatchResult) string {
+	switch {
+	case match.Blocked:
+		return "⊘ BLOCKED"
+	case match.WasDuplicate:
+		return "⊘ DUPLICATE"
+	case match.Matched && match.Error != "":
+		return "✗ NOT ADDED"
+	case match.Matched && match.NeedsReview:
+		return "? NEEDS REVIEW"
+	case match.Matched && match.NeedsDisambiguation:
+		return "? AMBIGUOUS"
+	case match.Matched:
+		return "✓ WOULD ADD"
+	case match.Artist != nil:
+		return "⚠ LOW CONFIDENCE"
+	default:
+		return "✗ NO MATCH"
+	}
+}
+
 func countMatched(results []scraper.ArtistMatchResult) int {
 	count := 0
 	for _, r := range results {
@@ -195,8 +612,16 @@ func init() {
 	// Add flags
 	scrapeCmd.Flags().StringVarP(&scrapeURL, "url", "u", "", "Website URL to scrape (required)")
 	scrapeCmd.Flags().StringVarP(&cssSelector, "selector", "s", "", "CSS selector for content extraction (optional)")
-	scrapeCmd.Flags().StringVarP(&playlistID, "playlist", "p", "", "Playlist ID to add artists to (required)")
-	scrapeCmd.Flags().BoolVarP(&forceAdd, "force", "f", false, "Force add even if duplicates exist")
+	scrapeCmd.Flags().StringVarP(&playlistID, "playlist", "p", "", "Playlist ID to add artists to, or auto to route them by genre (required)")
+	scrapeCmd.Flags().BoolVarP(&forceAdd, "force", "f", false, "Force add even if duplicates exist (same as --mode force)")
+	scrapeCmd.Flags().StringVar(&addMode, "mode", string(types.AddModeSkip), "How to handle artists already in the playlist: skip, force or fill-gaps")
+	scrapeCmd.Flags().IntVarP(&trackCount, "tracks", "n", types.DefaultTrackCount, "Number of tracks to add per artist (1-10)")
+	scrapeCmd.Flags().StringVar(&strategy, "strategy", string(types.TrackStrategyTop), "Track selection strategy: top, latest or random")
+	scrapeCmd.Flags().BoolVar(&showLive, "live", false, "Print each artist to stderr as it is matched and added")
+	scrapeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the artists found, their matches and whether they are already in the playlist without adding anything")
+	scrapeCmd.Flags().StringSliceVar(&commitIDs, "commit", nil, "Add these comma-separated Spotify artist IDs, picked from a --dry-run preview, instead of scraping the page again")
+	scrapeCmd.Flags().DurationVar(&scrapeTimeout, "timeout", 0, "Give up on the scrape after this long, e.g. 5m (0 waits until it finishes)")
+	scrapeCmd.Flags().StringVar(&market, "market", "", "Two-letter market to pick playable tracks for (defaults to SPOTIFY_MARKET, then your account's country)")
 
 	// Mark required flags
 	_ = scrapeCmd.MarkFlagRequired("url")
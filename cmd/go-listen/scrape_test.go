@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
 	"github.com/toozej/go-listen/internal/services/scraper"
+	"github.com/toozej/go-listen/internal/types"
 )
 
 // **Feature: web-scraping-artist-discovery, Property 16: CLI error exit codes**
@@ -110,6 +115,280 @@ func TestProperty_CLIErrorExitCodes(t *testing.T) {
 }
 
 // Helper function to generate artist names for testing
+func TestScrapeTrackSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		strategy string
+		market   string
+		want     types.TrackSelection
+		wantErr  bool
+	}{
+		{
+			name:     "flag defaults",
+			count:    types.DefaultTrackCount,
+			strategy: "top",
+			want:     types.TrackSelection{Count: 5, Strategy: types.TrackStrategyTop},
+		},
+		{
+			name:     "latest",
+			count:    3,
+			strategy: "latest",
+			want:     types.TrackSelection{Count: 3, Strategy: types.TrackStrategyLatest},
+		},
+		{
+			name:     "strategy is case insensitive",
+			count:    10,
+			strategy: "Random",
+			want:     types.TrackSelection{Count: 10, Strategy: types.TrackStrategyRandom},
+		},
+		{
+			name:     "market override",
+			count:    5,
+			strategy: "top",
+			market:   "gb",
+			want:     types.TrackSelection{Count: 5, Strategy: types.TrackStrategyTop, Market: "GB"},
+		},
+		{name: "zero tracks", count: 0, strategy: "top", wantErr: true},
+		{name: "invalid market", count: 5, strategy: "top", market: "GBR", wantErr: true},
+		{name: "too many tracks", count: 11, strategy: "top", wantErr: true},
+		{name: "unknown strategy", count: 5, strategy: "popular", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scrapeTrackSelection(tt.count, tt.strategy, tt.market)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("scrapeTrackSelection() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scrapeTrackSelection() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("scrapeTrackSelection() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrapeAddMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		modeSet bool
+		force   bool
+		want    types.AddMode
+		wantErr bool
+	}{
+		{name: "flag defaults", mode: "skip", want: types.AddModeSkip},
+		{name: "fill gaps with hyphen", mode: "fill-gaps", modeSet: true, want: types.AddModeFillGaps},
+		{name: "fill gaps with underscore", mode: "fill_gaps", modeSet: true, want: types.AddModeFillGaps},
+		{name: "force flag", mode: "skip", force: true, want: types.AddModeForce},
+		{name: "force flag with force mode", mode: "force", modeSet: true, force: true, want: types.AddModeForce},
+		{name: "force flag conflicts with mode", mode: "fill-gaps", modeSet: true, force: true, wantErr: true},
+		{name: "unknown mode", mode: "replace", modeSet: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scrapeAddMode(tt.mode, tt.modeSet, tt.force)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("scrapeAddMode() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scrapeAddMode() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("scrapeAddMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrapeApprovedArtists(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []string
+		dryRun  bool
+		want    []types.ApprovedArtist
+		wantErr bool
+	}{
+		{
+			name: "artist IDs",
+			ids:  []string{"6olE6TJLqED3rqDCT0FyPh", " 0RWW0eF5q3PECFHgvIr6vc"},
+			want: []types.ApprovedArtist{{ArtistID: "6olE6TJLqED3rqDCT0FyPh"}, {ArtistID: "0RWW0eF5q3PECFHgvIr6vc"}},
+		},
+		{
+			name: "repeated artist added once",
+			ids:  []string{"6olE6TJLqED3rqDCT0FyPh", "6olE6TJLqED3rqDCT0FyPh"},
+			want: []types.ApprovedArtist{{ArtistID: "6olE6TJLqED3rqDCT0FyPh"}},
+		},
+		{name: "not an artist ID", ids: []string{"Nirvana"}, wantErr: true},
+		{name: "empty ID", ids: []string{""}, wantErr: true},
+		{name: "no artists", ids: nil, wantErr: true},
+		{name: "with dry run", ids: []string{"6olE6TJLqED3rqDCT0FyPh"}, dryRun: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scrapeApprovedArtists(tt.ids, tt.dryRun)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("scrapeApprovedArtists() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scrapeApprovedArtists() unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("scrapeApprovedArtists() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrapeContext(t *testing.T) {
+	t.Run("no timeout", func(t *testing.T) {
+		ctx, cancel := scrapeContext(context.Background(), 0)
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("Expected no deadline without --timeout")
+		}
+		cancel()
+		if ctx.Err() == nil {
+			t.Error("Expected the context to be cancelled by cancel()")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := scrapeContext(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, ok := ctx.Deadline(); !ok {
+			t.Fatal("Expected a deadline with --timeout")
+		}
+		select {
+		case <-ctx.Done():
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				t.Errorf("Expected deadline exceeded, got %v", ctx.Err())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the context to time out")
+		}
+	})
+}
+
+func TestFormatProgressEvent(t *testing.T) {
+	artist := &types.Artist{ID: "artist1", Name: "Artist One"}
+
+	tests := []struct {
+		name  string
+		event types.ProgressEvent
+		want  string
+	}{
+		{
+			name:  "found",
+			event: types.ProgressEvent{Type: types.EventArtistFound, Query: "artist one"},
+			want:  "Found artist one",
+		},
+		{
+			name:  "found in structured data",
+			event: types.ProgressEvent{Type: types.EventArtistFound, Query: "artist one", Source: "jsonld:MusicEvent.performer"},
+			want:  "Found artist one (jsonld:MusicEvent.performer)",
+		},
+		{
+			name:  "matched",
+			event: types.ProgressEvent{Type: types.EventArtistMatched, Query: "artist one", Artist: artist, Confidence: 0.9},
+			want:  "Matched artist one → Artist One (confidence: 0.90)",
+		},
+		{
+			name:  "not matched",
+			event: types.ProgressEvent{Type: types.EventArtistNotMatched, Query: "nobody", Message: "no artists found"},
+			want:  "No match for nobody: no artists found",
+		},
+		{
+			name:  "blocked",
+			event: types.ProgressEvent{Type: types.EventArtistBlocked, Artist: artist, Message: "Artist genre is blocklisted"},
+			want:  "Blocked Artist One: Artist genre is blocklisted",
+		},
+		{
+			name:  "queued for review",
+			event: types.ProgressEvent{Type: types.EventArtistQueued, Query: "artist 1", Artist: artist, Confidence: 0.6},
+			want:  "Held artist 1 → Artist One for review (confidence: 0.60)",
+		},
+		{
+			name:  "duplicate",
+			event: types.ProgressEvent{Type: types.EventDuplicateSkipped, Artist: artist, Message: "Artist already in playlist"},
+			want:  "Skipped Artist One: Artist already in playlist",
+		},
+		{
+			name:  "tracks added",
+			event: types.ProgressEvent{Type: types.EventTracksAdded, Artist: artist, TracksAdded: 3, TracksSkipped: 2},
+			want:  "Added 3 tracks by Artist One (2 already present)",
+		},
+		{
+			name:  "routed tracks added",
+			event: types.ProgressEvent{Type: types.EventTracksAdded, Artist: artist, TracksAdded: 5, Route: &types.PlaylistRoute{PlaylistID: "heavy123", PlaylistName: "Incoming Heavy", Genre: "metal"}},
+			want:  "Added 5 tracks by Artist One to Incoming Heavy",
+		},
+		{
+			name:  "artist error",
+			event: types.ProgressEvent{Type: types.EventError, Artist: artist, Message: "Failed to add tracks"},
+			want:  "Error for Artist One: Failed to add tracks",
+		},
+		{
+			name:  "scrape error",
+			event: types.ProgressEvent{Type: types.EventError, Message: "Failed to scrape artists"},
+			want:  "Error: Failed to scrape artists",
+		},
+		{
+			name:  "done",
+			event: types.ProgressEvent{Type: types.EventDone, Message: "Scraping complete"},
+			want:  "Scraping complete",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatProgressEvent(tt.event); got != tt.want {
+				t.Errorf("formatProgressEvent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewStatus(t *testing.T) {
+	artist := &types.Artist{ID: "artist1", Name: "Artist One"}
+
+	tests := []struct {
+		name  string
+		match scraper.ArtistMatchResult
+		want  string
+	}{
+		{name: "would add", match: scraper.ArtistMatchResult{Matched: true, Artist: artist}, want: "✓ WOULD ADD"},
+		{name: "ambiguous", match: scraper.ArtistMatchResult{Matched: true, Artist: artist, NeedsDisambiguation: true}, want: "? AMBIGUOUS"},
+		{name: "needs review", match: scraper.ArtistMatchResult{Matched: true, Artist: artist, NeedsDisambiguation: true, NeedsReview: true}, want: "? NEEDS REVIEW"},
+		{name: "duplicate", match: scraper.ArtistMatchResult{Matched: true, Artist: artist, WasDuplicate: true, Error: "Artist already in playlist"}, want: "⊘ DUPLICATE"},
+		{name: "blocked", match: scraper.ArtistMatchResult{Matched: true, Artist: artist, Blocked: true, Error: "blocked"}, want: "⊘ BLOCKED"},
+		{name: "unrouted", match: scraper.ArtistMatchResult{Matched: true, Artist: artist, Error: "No routing rule matches"}, want: "✗ NOT ADDED"},
+		{name: "low confidence", match: scraper.ArtistMatchResult{Artist: artist, Confidence: 0.3}, want: "⚠ LOW CONFIDENCE"},
+		{name: "no match", match: scraper.ArtistMatchResult{Error: "no artists found"}, want: "✗ NO MATCH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := previewStatus(tt.match); got != tt.want {
+				t.Errorf("previewStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func generateArtistNames(count int) []string {
 	if count <= 0 {
 		return []string{}
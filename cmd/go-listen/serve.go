@@ -36,6 +36,16 @@ func runServeCommand(cmd *cobra.Command, args []string) {
 	spotifyService := srv.GetSpotifyService()
 	playlistManager := srv.GetPlaylistManager()
 	fuzzySearcher := search.NewFuzzyArtistSearcher(spotifyService, logger)
+	fuzzySearcher.SetConcurrency(conf.Scraper.MatchConcurrency)
+	fuzzySearcher.SetWeights(search.WeightsFromConfig(conf.Search))
+
+	// Resolve aliased names through the server's dictionary so API edits apply immediately
+	if aliases := srv.GetAliases(); aliases != nil {
+		fuzzySearcher.SetAliases(aliases)
+	}
+
+	// Score artist candidates so ambiguous names added through the API can be disambiguated
+	srv.SetArtistSearcher(fuzzySearcher)
 
 	// Initialize scraper components
 	parser := scraper.NewGoqueryParser(logger)
@@ -43,6 +53,7 @@ func runServeCommand(cmd *cobra.Command, args []string) {
 
 	// Create scraper service using the server's authenticated services
 	scraperConfig := scraper.DefaultScraperConfig()
+	scraperConfig.MatchConcurrency = conf.Scraper.MatchConcurrency
 	scraperService := scraper.NewWebScraper(
 		scraperConfig,
 		parser,
@@ -52,9 +63,45 @@ func runServeCommand(cmd *cobra.Command, args []string) {
 		logger,
 	)
 
+	// Read known sites with their extractors, including the ones declared in config
+	if extractors, err := scraper.LoadExtractorRegistry(conf.Scraper.ExtractorsFile); err != nil {
+		logger.WithError(err).Warn("Failed to load scraper extractors, using the built-in ones")
+	} else {
+		scraperService.SetExtractors(extractors)
+	}
+
+	// Look up when duplicates were last added in the server's history database; the
+	// playlist manager records the additions
+	if historyStore := srv.GetHistoryStore(); historyStore != nil {
+		scraperService.SetHistory(historyStore)
+	}
+
+	// Leave out the artists on the server's blocklist
+	if blocked := srv.GetBlocklist(); blocked != nil {
+		scraperService.SetBlocklist(blocked)
+	}
+
+	// Spread artists scraped into the auto playlist with the server's routing rules
+	if router := srv.GetRouter(); router != nil {
+		scraperService.SetRouter(router)
+	}
+
+	// Hold uncertain matches in the server's review queue until they are approved
+	if queue := srv.GetReviewQueue(); queue != nil {
+		scraperService.SetReviewQueue(queue, conf.Review.MinConfidence, conf.Review.AutoAddConfidence)
+	}
+
+	// Share the server's duplicate detector so scrapes use its cached playlist indexes
+	scraperService.SetDuplicateDetector(srv.GetDuplicateDetector())
+
 	// Set the scraper service on the server
 	srv.SetScraperService(scraperService)
 
+	// Re-scrape the watched sources on their schedules once the server starts
+	if watcher := srv.GetWatchScheduler(); watcher != nil {
+		watcher.SetScraper(scraperService)
+	}
+
 	logger.Info("Server initialized with scraper service using authenticated Spotify service")
 
 	// Start server in a goroutine
@@ -0,0 +1,54 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "state.json")
+
+	for _, content := range []string{`{"first":true}`, `{"second":true}`} {
+		if err := WriteAtomic(path, []byte(content)); err != nil {
+			t.Fatalf("WriteAtomic() unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile() unexpected error: %v", err)
+		}
+		if string(data) != content {
+			t.Errorf("File contains %q, want %q", data, content)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() unexpected error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("File mode = %o, want 600", perm)
+	}
+
+	// Only the file itself is left behind, not the temporary files it was written through
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("ReadDir() unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Directory holds %d entries, want only the written file", len(entries))
+	}
+}
+
+func TestWriteAtomic_UnwritableDirectory(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, []byte("x"), 0o600); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+
+	if err := WriteAtomic(filepath.Join(blocker, "state.json"), []byte("{}")); err == nil {
+		t.Error("WriteAtomic() expected error when the directory is a file")
+	}
+}
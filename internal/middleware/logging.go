@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"net/http"
@@ -45,6 +44,12 @@ func (rw *responseWriter) Write(data []byte) (int, error) {
 	return rw.ResponseWriter.Write(data)
 }
 
+// Unwrap returns the wrapped writer so http.ResponseController can reach its
+// Flush and deadline methods, which streaming responses need
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // LogRequests wraps an HTTP handler with request logging and correlation ID injection
 func (lm *LoggingMiddleware) LogRequests(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -54,7 +59,7 @@ func (lm *LoggingMiddleware) LogRequests(next http.Handler) http.Handler {
 		correlationID := generateCorrelationID()
 
 		// Add correlation ID to request context
-		ctx := context.WithValue(r.Context(), logging.CorrelationIDKey, correlationID)
+		ctx := logging.ContextWithCorrelationID(r.Context(), correlationID)
 		r = r.WithContext(ctx)
 
 		// Add correlation ID to response headers for client tracking
@@ -274,6 +274,23 @@ func TestResponseWriter_Write(t *testing.T) {
 	}
 }
 
+func TestResponseWriter_Flush(t *testing.T) {
+	rr := httptest.NewRecorder()
+	rw := &responseWriter{
+		ResponseWriter: rr,
+		statusCode:     http.StatusOK,
+	}
+
+	// Streaming handlers flush through a ResponseController, which unwraps the writer
+	if err := http.NewResponseController(rw).Flush(); err != nil {
+		t.Fatalf("Unexpected error flushing: %v", err)
+	}
+
+	if !rr.Flushed {
+		t.Error("Expected the underlying writer to be flushed")
+	}
+}
+
 func TestGenerateCorrelationID(t *testing.T) {
 	// Test that correlation IDs are generated
 	id1 := generateCorrelationID()
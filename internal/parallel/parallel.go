@@ -0,0 +1,38 @@
+// Package parallel runs independent calls, such as Spotify searches, on a bounded
+// number of goroutines.
+package parallel
+
+import "sync"
+
+// Map calls fn for every item using at most workers goroutines at a time and
+// returns the results in the same order as items. fn must be safe to call
+// concurrently; with one worker the items are processed in order on the calling
+// goroutine.
+func Map[T, R any](items []T, workers int, fn func(item T) R) []R {
+	results := make([]R, len(items))
+	workers = min(max(workers, 1), len(items))
+
+	if workers <= 1 {
+		for i, item := range items {
+			results[i] = fn(item)
+		}
+		return results
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := range indexes {
+				results[i] = fn(items[i])
+			}
+		})
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,69 @@
+package parallel
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		items   []int
+		workers int
+	}{
+		{name: "no items", items: nil, workers: 4},
+		{name: "single worker", items: []int{1, 2, 3}, workers: 1},
+		{name: "zero workers runs sequentially", items: []int{1, 2, 3}, workers: 0},
+		{name: "more workers than items", items: []int{1, 2, 3}, workers: 10},
+		{name: "many items", items: []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}, workers: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Map(tt.items, tt.workers, func(item int) int {
+				// Finish later items first so results only stay in order if Map orders them
+				time.Sleep(time.Duration(10-item) * time.Millisecond)
+				return item * 2
+			})
+
+			want := make([]int, len(tt.items))
+			for i, item := range tt.items {
+				want[i] = item * 2
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("Map() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMap_BoundsWorkers(t *testing.T) {
+	const workers = 3
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	items := make([]int, 20)
+
+	Map(items, workers, func(int) struct{} {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return struct{}{}
+	})
+
+	if maxRunning > workers {
+		t.Errorf("%d calls ran at once, want at most %d", maxRunning, workers)
+	}
+	if maxRunning < 2 {
+		t.Errorf("expected calls to overlap, at most %d ran at once", maxRunning)
+	}
+}
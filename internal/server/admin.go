@@ -0,0 +1,89 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/playlist"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// handleAdminPlaylists lists all of the user's playlists with their incoming classification
+func (s *Server) handleAdminPlaylists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	playlists, err := s.playlist.ListPlaylists(ctx)
+	if err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to list playlists")
+		s.writeJSONError(w, "Failed to list playlists: "+err.Error(), incomingErrorStatus(err))
+		return
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    playlists,
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// handleAdminPlaylistIncoming marks (PUT) or unmarks (DELETE) a playlist as incoming
+func (s *Server) handleAdminPlaylistIncoming(w http.ResponseWriter, r *http.Request) {
+	playlistID := r.PathValue("id")
+	if !types.IsSpotifyID(playlistID) {
+		s.writeJSONError(w, "Invalid playlist ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	var err error
+	switch r.Method {
+	case http.MethodPut:
+		err = s.playlist.MarkIncoming(ctx, playlistID)
+	case http.MethodDelete:
+		err = s.playlist.UnmarkIncoming(ctx, playlistID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		s.logger.WithContext(r.Context()).WithError(err).WithFields(logrus.Fields{
+			"component":   "server",
+			"playlist_id": playlistID,
+			"method":      r.Method,
+		}).Error("Failed to update incoming playlist mark")
+		s.writeJSONError(w, "Failed to update playlist: "+err.Error(), incomingErrorStatus(err))
+		return
+	}
+
+	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component":   "server",
+		"playlist_id": playlistID,
+		"incoming":    r.Method == http.MethodPut,
+	}).Info("Updated incoming playlist mark")
+
+	response := types.APIResponse{
+		Success: true,
+		Data: map[string]any{
+			"playlist_id": playlistID,
+			"is_incoming": r.Method == http.MethodPut,
+		},
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// incomingErrorStatus maps incoming playlist errors to an HTTP status
+func incomingErrorStatus(err error) int {
+	if errors.Is(err, playlist.ErrNoIncomingRules) {
+		return http.StatusServiceUnavailable
+	}
+	return requestErrorStatus(err)
+}
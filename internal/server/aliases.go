@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/services/spotify"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// deleteAliasRequest names the alias to delete. Names go in the body rather than
+// the path since they often contain punctuation the input validation rejects.
+type deleteAliasRequest struct {
+	Name string `json:"name"`
+}
+
+// handleAliases lists (GET), adds or replaces (POST) and deletes (DELETE) artist aliases
+func (s *Server) handleAliases(w http.ResponseWriter, r *http.Request) {
+	if s.aliases == nil {
+		s.writeJSONError(w, "Aliases are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		response := types.APIResponse{
+			Success: true,
+			Data:    s.aliases.List(),
+		}
+		s.writeJSONResponse(w, response, http.StatusOK)
+	case http.MethodPost:
+		s.handleSetAlias(w, r)
+	case http.MethodDelete:
+		s.handleDeleteAlias(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSetAlias adds an alias, or replaces the alias of the same name. The
+// artist is looked up on Spotify so mistyped IDs are caught and the alias
+// records the artist's name.
+func (s *Server) handleSetAlias(w http.ResponseWriter, r *http.Request) {
+	var req types.ArtistAlias
+	if err := s.parseJSONRequest(r, &req); err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Warn("Invalid JSON request")
+		s.writeJSONError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	req, err := alias.Normalize(req)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	if s.spotify != nil {
+		artist, err := s.spotify.GetArtist(ctx, req.ArtistID)
+		switch {
+		case errors.Is(err, spotify.ErrNotFound):
+			s.writeJSONError(w, "Artist not found on Spotify", http.StatusBadRequest)
+			return
+		case err != nil:
+			// Spotify may be unreachable or not yet authorized; the alias is still worth keeping
+			s.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+				"component": "server",
+				"artist_id": req.ArtistID,
+			}).Warn("Failed to look up aliased artist, saving alias unverified")
+		default:
+			req.ArtistName = artist.Name
+		}
+	}
+
+	saved, replaced, err := s.aliases.Set(req)
+	if err != nil {
+		s.logger.WithContext(ctx).WithField("component", "server").WithError(err).Error("Failed to save alias")
+		s.writeJSONError(w, "Failed to save alias: "+err.Error(), aliasErrorStatus(err))
+		return
+	}
+
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component": "server",
+		"operation": "set_alias",
+		"name":      saved.Name,
+		"artist_id": saved.ArtistID,
+		"replaced":  replaced,
+	}).Info("Saved artist alias")
+
+	status := http.StatusCreated
+	if replaced {
+		status = http.StatusOK
+	}
+	response := types.APIResponse{
+		Success: true,
+		Data:    saved,
+	}
+	s.writeJSONResponse(w, response, status)
+}
+
+// handleDeleteAlias removes the alias named in the request body
+func (s *Server) handleDeleteAlias(w http.ResponseWriter, r *http.Request) {
+	var req deleteAliasRequest
+	if err := s.parseJSONRequest(r, &req); err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Warn("Invalid JSON request")
+		s.writeJSONError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.aliases.Delete(req.Name); err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Warn("Failed to delete alias")
+		s.writeJSONError(w, err.Error(), aliasErrorStatus(err))
+		return
+	}
+
+	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component": "server",
+		"operation": "delete_alias",
+		"name":      req.Name,
+	}).Info("Deleted artist alias")
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    map[string]any{"name": req.Name},
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// handleExportAliases returns every alias as a YAML file
+func (s *Server) handleExportAliases(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.aliases == nil {
+		s.writeJSONError(w, "Aliases are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := alias.WriteYAML(&buf, s.aliases.List()); err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to export aliases")
+		s.writeJSONError(w, "Failed to export aliases", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", `attachment; filename="aliases.yaml"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to write alias export")
+	}
+}
+
+// handleImportAliases adds the aliases in a YAML request body, replacing every
+// existing alias when the replace query parameter is true
+func (s *Server) handleImportAliases(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.aliases == nil {
+		s.writeJSONError(w, "Aliases are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	replace := false
+	if value := r.URL.Query().Get("replace"); value != "" {
+		var err error
+		if replace, err = strconv.ParseBool(value); err != nil {
+			s.writeJSONError(w, "replace must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	imported, err := alias.ReadYAML(r.Body)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count, err := s.aliases.Import(imported, replace)
+	if err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Warn("Failed to import aliases")
+		s.writeJSONError(w, "Failed to import aliases: "+err.Error(), aliasErrorStatus(err))
+		return
+	}
+
+	total := len(s.aliases.List())
+	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component": "server",
+		"operation": "import_aliases",
+		"imported":  count,
+		"replace":   replace,
+		"total":     total,
+	}).Info("Imported artist aliases")
+
+	response := types.APIResponse{
+		Success: true,
+		Data: map[string]any{
+			"imported": count,
+			"total":    total,
+		},
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// aliasErrorStatus maps alias errors to an HTTP status
+func aliasErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, alias.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, alias.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
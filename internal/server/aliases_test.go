@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/services/spotify"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+const (
+	testAliasArtistID = "5K4W6rqBFWDnAN6FQUkS6x"
+	testOtherArtistID = "4Z8W4fKeB5YxbusRsdQVPb"
+)
+
+// mockAliasSpotifyService looks up artists from a map for the alias handlers
+type mockAliasSpotifyService struct {
+	types.SpotifyService
+	artists map[string]string
+	err     error
+}
+
+func (m *mockAliasSpotifyService) GetArtist(ctx context.Context, artistID string) (*types.Artist, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	name, ok := m.artists[artistID]
+	if !ok {
+		return nil, fmt.Errorf("%w: artist %s", spotify.ErrNotFound, artistID)
+	}
+	return &types.Artist{ID: artistID, Name: name}, nil
+}
+
+// createAliasTestServer returns a test server with an empty in-memory alias dictionary
+func createAliasTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	server, _ := createTestServer()
+	aliases, err := alias.NewDictionary(alias.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("NewDictionary() unexpected error: %v", err)
+	}
+	server.aliases = aliases
+	server.spotify = &mockAliasSpotifyService{artists: map[string]string{testAliasArtistID: "Ye"}}
+	return server
+}
+
+func TestHandleAliases(t *testing.T) {
+	server := createAliasTestServer(t)
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		expectedCode int
+	}{
+		{name: "add by URI", method: http.MethodPost, body: `{"name":"Kanye","artist_id":"spotify:artist:` + testAliasArtistID + `"}`, expectedCode: http.StatusCreated},
+		{name: "replace", method: http.MethodPost, body: `{"name":"kanye","artist_id":"` + testAliasArtistID + `"}`, expectedCode: http.StatusOK},
+		{name: "missing name", method: http.MethodPost, body: `{"artist_id":"` + testAliasArtistID + `"}`, expectedCode: http.StatusBadRequest},
+		{name: "invalid artist", method: http.MethodPost, body: `{"name":"Kanye","artist_id":"not-an-id"}`, expectedCode: http.StatusBadRequest},
+		{name: "unknown artist", method: http.MethodPost, body: `{"name":"Kanye","artist_id":"` + testOtherArtistID + `"}`, expectedCode: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPost, body: `{"name":"Kanye","artist":"x"}`, expectedCode: http.StatusBadRequest},
+		{name: "delete missing", method: http.MethodDelete, body: `{"name":"Nobody"}`, expectedCode: http.StatusNotFound},
+		{name: "method not allowed", method: http.MethodPut, expectedCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/aliases", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			server.handleAliases(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/aliases", nil)
+	w := httptest.NewRecorder()
+	server.handleAliases(w, req)
+
+	var response struct {
+		Success bool                `json:"success"`
+		Data    []types.ArtistAlias `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	want := types.ArtistAlias{Name: "kanye", ArtistID: testAliasArtistID, ArtistName: "Ye"}
+	if len(response.Data) != 1 || response.Data[0] != want {
+		t.Fatalf("Expected aliases [%+v], got %+v", want, response.Data)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/aliases", strings.NewReader(`{"name":"KANYE"}`))
+	w = httptest.NewRecorder()
+	server.handleAliases(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if aliases := server.aliases.List(); len(aliases) != 0 {
+		t.Errorf("Expected no aliases after delete, got %+v", aliases)
+	}
+}
+
+func TestHandleAliases_SpotifyUnavailable(t *testing.T) {
+	server := createAliasTestServer(t)
+	server.spotify = &mockAliasSpotifyService{err: errors.New("not authenticated")}
+
+	body := `{"name":"Kanye","artist_id":"` + testAliasArtistID + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/aliases", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleAliases(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if saved, ok := server.aliases.Resolve("Kanye"); !ok || saved.ArtistName != "" {
+		t.Errorf("Expected the alias saved without an artist name, got %+v, %v", saved, ok)
+	}
+}
+
+func TestHandleAliases_Disabled(t *testing.T) {
+	server, _ := createTestServer()
+
+	tests := []struct {
+		name    string
+		method  string
+		handler http.HandlerFunc
+	}{
+		{name: "list", method: http.MethodGet, handler: server.handleAliases},
+		{name: "export", method: http.MethodGet, handler: server.handleExportAliases},
+		{name: "import", method: http.MethodPost, handler: server.handleImportAliases},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/aliases", nil)
+			w := httptest.NewRecorder()
+			tt.handler(w, req)
+
+			if w.Code != http.StatusServiceUnavailable {
+				t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleImportExportAliases(t *testing.T) {
+	server := createAliasTestServer(t)
+	if _, _, err := server.aliases.Set(types.ArtistAlias{Name: "Old", ArtistID: testOtherArtistID}); err != nil {
+		t.Fatalf("Set() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		body         string
+		expectedCode int
+		wantNames    []string
+	}{
+		{
+			name:         "invalid YAML",
+			body:         "aliases: [",
+			expectedCode: http.StatusBadRequest,
+			wantNames:    []string{"Old"},
+		},
+		{
+			name:         "invalid alias",
+			body:         "aliases:\n  - name: Kanye\n    artist_id: nope\n",
+			expectedCode: http.StatusBadRequest,
+			wantNames:    []string{"Old"},
+		},
+		{
+			name:         "bad replace flag",
+			query:        "?replace=maybe",
+			body:         "aliases: []\n",
+			expectedCode: http.StatusBadRequest,
+			wantNames:    []string{"Old"},
+		},
+		{
+			name:         "merge",
+			body:         "aliases:\n  - name: Kanye\n    artist_id: https://open.spotify.com/artist/" + testAliasArtistID + "?si=abc\n",
+			expectedCode: http.StatusOK,
+			wantNames:    []string{"Kanye", "Old"},
+		},
+		{
+			name:         "replace",
+			query:        "?replace=true",
+			body:         "aliases:\n  - name: Ye\n    artist_id: " + testAliasArtistID + "\n",
+			expectedCode: http.StatusOK,
+			wantNames:    []string{"Ye"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/aliases/import"+tt.query, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			server.handleImportAliases(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+
+			var names []string
+			for _, a := range server.aliases.List() {
+				names = append(names, a.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("Expected aliases %v, got %v", tt.wantNames, names)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/aliases/export", nil)
+	w := httptest.NewRecorder()
+	server.handleExportAliases(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/yaml" {
+		t.Errorf("Expected YAML content type, got %q", contentType)
+	}
+	exported, err := alias.ReadYAML(w.Body)
+	if err != nil {
+		t.Fatalf("ReadYAML() unexpected error: %v", err)
+	}
+	if len(exported) != 1 || exported[0].Name != "Ye" || exported[0].ArtistID != testAliasArtistID {
+		t.Errorf("Expected the exported aliases to round trip, got %+v", exported)
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// authStateCookieName is the cookie binding an OAuth state to the browser that started the login
+	authStateCookieName = "go_listen_oauth_state"
+	// authStateTTL is how long a user has to complete the Spotify login
+	authStateTTL = 10 * time.Minute
+)
+
+// Errors returned when the OAuth callback cannot be matched to a login session
+var (
+	errAuthStateMissing  = errors.New("login session not found, please start again from /auth")
+	errAuthStateInvalid  = errors.New("login session is invalid, please start again from /auth")
+	errAuthStateExpired  = errors.New("login session expired, please start again from /auth")
+	errAuthStateMismatch = errors.New("state parameter does not match the login session")
+)
+
+// authSession is the login state carried in the signed cookie
+type authSession struct {
+	State        string `json:"state"`
+	CodeVerifier string `json:"code_verifier,omitempty"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
+
+// authStateManager issues and verifies per-login OAuth state values
+type authStateManager struct {
+	key     []byte
+	ttl     time.Duration
+	usePKCE bool
+	now     func() time.Time
+}
+
+// newAuthStateManager creates a state manager signing cookies with secret.
+// An empty secret generates a random one, which invalidates in-flight logins on restart.
+func newAuthStateManager(secret string, usePKCE bool) *authStateManager {
+	if secret == "" {
+		secret = rand.Text()
+	}
+	return &authStateManager{
+		key:     []byte(secret),
+		ttl:     authStateTTL,
+		usePKCE: usePKCE,
+		now:     time.Now,
+	}
+}
+
+// issue creates a new login session and stores it in a signed cookie
+func (m *authStateManager) issue(w http.ResponseWriter, r *http.Request) (*authSession, error) {
+	session := &authSession{
+		State:     rand.Text(),
+		ExpiresAt: m.now().Add(m.ttl).Unix(),
+	}
+	if m.usePKCE {
+		session.CodeVerifier = oauth2.GenerateVerifier()
+	}
+
+	payload, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     authStateCookieName,
+		Value:    encoded + "." + m.sign(encoded),
+		Path:     "/",
+		MaxAge:   int(m.ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		// Lax is required so the cookie is sent on the top-level redirect back from Spotify
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return session, nil
+}
+
+// verify checks the signed cookie against the state returned by Spotify
+func (m *authStateManager) verify(r *http.Request, state string) (*authSession, error) {
+	cookie, err := r.Cookie(authStateCookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, errAuthStateMissing
+	}
+
+	encoded, signature, found := strings.Cut(cookie.Value, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(m.sign(encoded))) {
+		return nil, errAuthStateInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errAuthStateInvalid
+	}
+
+	var session authSession
+	if err := json.Unmarshal(payload, &session); err != nil {
+		return nil, errAuthStateInvalid
+	}
+
+	if m.now().Unix() > session.ExpiresAt {
+		return nil, errAuthStateExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(session.State), []byte(state)) != 1 {
+		return nil, errAuthStateMismatch
+	}
+
+	return &session, nil
+}
+
+// clear removes the login session cookie so each state can only be used once
+func (m *authStateManager) clear(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     authStateCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sign returns the base64 HMAC-SHA256 signature of value
+func (m *authStateManager) sign(value string) string {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,320 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// mockAuthSpotifyService records the arguments of the auth methods
+type mockAuthSpotifyService struct {
+	types.SpotifyService
+	authenticated    bool
+	completeErr      error
+	completedCode    string
+	completeVerifier string
+}
+
+func (m *mockAuthSpotifyService) GetAuthURL(state, codeVerifier string) string {
+	values := url.Values{"state": {state}}
+	if codeVerifier != "" {
+		values.Set("code_challenge", "challenge")
+	}
+	return "https://accounts.spotify.com/authorize?" + values.Encode()
+}
+
+func (m *mockAuthSpotifyService) IsAuthenticated() bool {
+	return m.authenticated
+}
+
+func (m *mockAuthSpotifyService) CompleteAuth(ctx context.Context, code, codeVerifier string) error {
+	m.completedCode = code
+	m.completeVerifier = codeVerifier
+	return m.completeErr
+}
+
+// issueCookie runs issue against a recorder and returns the session and cookie it set
+func issueCookie(t *testing.T, m *authStateManager) (*authSession, *http.Cookie) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	session, err := m.issue(w, httptest.NewRequest(http.MethodGet, "/auth", http.NoBody))
+	if err != nil {
+		t.Fatalf("issue() unexpected error: %v", err)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != authStateCookieName {
+		t.Fatalf("issue() cookies = %v, want single %s cookie", cookies, authStateCookieName)
+	}
+	return session, cookies[0]
+}
+
+func TestAuthStateManager_IssueAndVerify(t *testing.T) {
+	m := newAuthStateManager("test-secret", false)
+	session, cookie := issueCookie(t, m)
+
+	if session.State == "" {
+		t.Fatal("issue() returned empty state")
+	}
+	if session.CodeVerifier != "" {
+		t.Error("issue() set a code verifier with PKCE disabled")
+	}
+	if !cookie.HttpOnly {
+		t.Error("state cookie must be HttpOnly")
+	}
+	if cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("state cookie SameSite = %v, want Lax", cookie.SameSite)
+	}
+
+	// Two logins must never share a state
+	other, _ := issueCookie(t, m)
+	if other.State == session.State {
+		t.Error("issue() returned the same state twice")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/callback", http.NoBody)
+	req.AddCookie(cookie)
+
+	verified, err := m.verify(req, session.State)
+	if err != nil {
+		t.Fatalf("verify() unexpected error: %v", err)
+	}
+	if verified.State != session.State {
+		t.Errorf("verify() state = %q, want %q", verified.State, session.State)
+	}
+}
+
+func TestAuthStateManager_PKCE(t *testing.T) {
+	m := newAuthStateManager("test-secret", true)
+	session, cookie := issueCookie(t, m)
+
+	if session.CodeVerifier == "" {
+		t.Fatal("issue() did not set a code verifier with PKCE enabled")
+	}
+	if strings.Contains(cookie.Value, session.CodeVerifier) {
+		t.Error("code verifier should not appear verbatim in the cookie")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/callback", http.NoBody)
+	req.AddCookie(cookie)
+
+	verified, err := m.verify(req, session.State)
+	if err != nil {
+		t.Fatalf("verify() unexpected error: %v", err)
+	}
+	if verified.CodeVerifier != session.CodeVerifier {
+		t.Errorf("verify() code verifier = %q, want %q", verified.CodeVerifier, session.CodeVerifier)
+	}
+}
+
+func TestAuthStateManager_VerifyErrors(t *testing.T) {
+	m := newAuthStateManager("test-secret", false)
+	session, cookie := issueCookie(t, m)
+
+	tests := []struct {
+		name    string
+		cookie  *http.Cookie
+		state   string
+		manager *authStateManager
+		wantErr error
+	}{
+		{
+			name:    "missing cookie",
+			state:   session.State,
+			manager: m,
+			wantErr: errAuthStateMissing,
+		},
+		{
+			name:    "state mismatch",
+			cookie:  cookie,
+			state:   "attacker-state",
+			manager: m,
+			wantErr: errAuthStateMismatch,
+		},
+		{
+			name:    "tampered payload",
+			cookie:  &http.Cookie{Name: authStateCookieName, Value: "x" + cookie.Value},
+			state:   session.State,
+			manager: m,
+			wantErr: errAuthStateInvalid,
+		},
+		{
+			name:    "missing signature",
+			cookie:  &http.Cookie{Name: authStateCookieName, Value: strings.Split(cookie.Value, ".")[0]},
+			state:   session.State,
+			manager: m,
+			wantErr: errAuthStateInvalid,
+		},
+		{
+			name:    "signed with a different secret",
+			cookie:  cookie,
+			state:   session.State,
+			manager: newAuthStateManager("other-secret", false),
+			wantErr: errAuthStateInvalid,
+		},
+		{
+			name:   "expired",
+			cookie: cookie,
+			state:  session.State,
+			manager: &authStateManager{
+				key: m.key,
+				ttl: m.ttl,
+				now: func() time.Time { return time.Now().Add(authStateTTL + time.Minute) },
+			},
+			wantErr: errAuthStateExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/callback", http.NoBody)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+
+			_, err := tt.manager.verify(req, tt.state)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleAuth_IssuesStateCookie(t *testing.T) {
+	server, _ := createTestServer()
+	server.spotify = &mockAuthSpotifyService{}
+	server.authState = newAuthStateManager("test-secret", true)
+
+	w := httptest.NewRecorder()
+	server.handleAuth(w, httptest.NewRequest(http.MethodGet, "/auth", http.NoBody))
+
+	if w.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("Expected status %d, got %d", http.StatusTemporaryRedirect, w.Code)
+	}
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid redirect location: %v", err)
+	}
+	state := location.Query().Get("state")
+	if state == "" {
+		t.Fatal("redirect is missing the state parameter")
+	}
+	if location.Query().Get("code_challenge") == "" {
+		t.Error("redirect is missing the PKCE code challenge")
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected one state cookie, got %d", len(cookies))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/callback", http.NoBody)
+	req.AddCookie(cookies[0])
+	if _, err := server.authState.verify(req, state); err != nil {
+		t.Errorf("redirect state does not verify against cookie: %v", err)
+	}
+}
+
+func TestHandleCallback_StateVerification(t *testing.T) {
+	tests := []struct {
+		name           string
+		withCookie     bool
+		state          string
+		expectedStatus int
+		expectedBody   string
+		expectComplete bool
+	}{
+		{
+			name:           "valid state completes auth",
+			withCookie:     true,
+			expectedStatus: http.StatusOK,
+			expectComplete: true,
+		},
+		{
+			name:           "missing cookie",
+			withCookie:     false,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "login session not found",
+		},
+		{
+			name:           "mismatched state",
+			withCookie:     true,
+			state:          "forged-state",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "does not match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := createTestServer()
+			mockSpotify := &mockAuthSpotifyService{}
+			server.spotify = mockSpotify
+			server.authState = newAuthStateManager("test-secret", true)
+
+			session, cookie := issueCookie(t, server.authState)
+			state := session.State
+			if tt.state != "" {
+				state = tt.state
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/callback?code=auth-code&state="+url.QueryEscape(state), http.NoBody)
+			if tt.withCookie {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+
+			server.handleCallback(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedBody != "" && !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got %q", tt.expectedBody, w.Body.String())
+			}
+
+			if tt.expectComplete {
+				if mockSpotify.completedCode != "auth-code" {
+					t.Errorf("CompleteAuth() code = %q, want %q", mockSpotify.completedCode, "auth-code")
+				}
+				if mockSpotify.completeVerifier != session.CodeVerifier {
+					t.Error("CompleteAuth() was not given the session's PKCE verifier")
+				}
+			} else if mockSpotify.completedCode != "" {
+				t.Error("CompleteAuth() should not be called when state verification fails")
+			}
+
+			// The state cookie is always cleared so it cannot be replayed
+			cleared := false
+			for _, c := range w.Result().Cookies() {
+				if c.Name == authStateCookieName && c.MaxAge < 0 {
+					cleared = true
+				}
+			}
+			if !cleared {
+				t.Error("callback did not clear the state cookie")
+			}
+		})
+	}
+}
+
+func TestHandleAuthStatus_PointsToAuthEndpoint(t *testing.T) {
+	server, _ := createTestServer()
+	server.spotify = &mockAuthSpotifyService{authenticated: false}
+
+	w := httptest.NewRecorder()
+	server.handleAuthStatus(w, httptest.NewRequest(http.MethodGet, "/api/auth-status", http.NoBody))
+
+	if !strings.Contains(w.Body.String(), `"auth_url":"/auth"`) {
+		t.Errorf("Expected auth_url to point at /auth, got %s", w.Body.String())
+	}
+}
@@ -2,15 +2,22 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/toozej/go-listen/internal/middleware"
+	"github.com/toozej/go-listen/internal/services/playlist"
+	"github.com/toozej/go-listen/internal/services/spotify"
 	"github.com/toozej/go-listen/internal/types"
 	"github.com/toozej/go-listen/pkg/config"
 	"github.com/toozej/go-listen/pkg/logging"
@@ -18,23 +25,62 @@ import (
 
 // Mock implementations for testing
 type mockPlaylistManager struct {
-	playlists []types.Playlist
-	addResult *types.AddResult
-	addError  error
+	playlists     []types.Playlist
+	addResult     *types.AddResult
+	addError      error
+	lastSelection types.TrackSelection
+	lastSource    types.AddSource
+	lastMode      types.AddMode
+	lastCtx       context.Context
+	lastArtistID  string
+	marks         map[string]bool
+	markError     error
 }
 
-func (m *mockPlaylistManager) AddArtistToPlaylist(artistName, playlistID string, force bool) (*types.AddResult, error) {
+func (m *mockPlaylistManager) AddArtistToPlaylist(ctx context.Context, artistName, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	m.lastCtx = ctx
+	m.lastMode = mode
+	m.lastSelection = selection
+	m.lastSource = source
 	if m.addError != nil {
 		return nil, m.addError
 	}
 	return m.addResult, nil
 }
 
-func (m *mockPlaylistManager) GetIncomingPlaylists() ([]types.Playlist, error) {
+func (m *mockPlaylistManager) AddArtistByID(ctx context.Context, artistID, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	m.lastArtistID = artistID
+	return m.AddArtistToPlaylist(ctx, "", playlistID, mode, selection, source)
+}
+
+func (m *mockPlaylistManager) GetIncomingPlaylists(ctx context.Context) ([]types.Playlist, error) {
+	return m.playlists, nil
+}
+
+func (m *mockPlaylistManager) ListPlaylists(ctx context.Context) ([]types.Playlist, error) {
 	return m.playlists, nil
 }
 
-func (m *mockPlaylistManager) GetTop5Tracks(artistID string) ([]types.Track, error) {
+func (m *mockPlaylistManager) MarkIncoming(ctx context.Context, playlistID string) error {
+	return m.setMark(ctx, playlistID, true)
+}
+
+func (m *mockPlaylistManager) UnmarkIncoming(ctx context.Context, playlistID string) error {
+	return m.setMark(ctx, playlistID, false)
+}
+
+func (m *mockPlaylistManager) setMark(ctx context.Context, playlistID string, incoming bool) error {
+	if m.markError != nil {
+		return m.markError
+	}
+	if m.marks == nil {
+		m.marks = make(map[string]bool)
+	}
+	m.marks[playlistID] = incoming
+	return nil
+}
+
+func (m *mockPlaylistManager) GetArtistTracks(ctx context.Context, artistID string, selection types.TrackSelection) ([]types.Track, error) {
 	return nil, nil
 }
 
@@ -49,14 +95,14 @@ func (m *mockPlaylistManager) FilterPlaylistsBySearch(playlists []types.Playlist
 	return filtered
 }
 
-func (m *mockPlaylistManager) AddTracksToPlaylist(playlistID string, trackIDs []string) error {
+func (m *mockPlaylistManager) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
 	if m.addError != nil {
 		return m.addError
 	}
 	return nil
 }
 
-func (m *mockPlaylistManager) CheckForDuplicates(playlistID string, trackIDs []string) (*types.DuplicateResult, error) {
+func (m *mockPlaylistManager) CheckForDuplicates(ctx context.Context, playlistID string, trackIDs []string) (*types.DuplicateResult, error) {
 	if m.addError != nil {
 		return nil, m.addError
 	}
@@ -65,6 +111,10 @@ func (m *mockPlaylistManager) CheckForDuplicates(playlistID string, trackIDs []s
 	}, nil
 }
 
+func (m *mockPlaylistManager) FilterMissingTracks(ctx context.Context, playlistID string, tracks []types.Track) ([]types.Track, []types.Track, error) {
+	return tracks, nil, nil
+}
+
 func createTestServer() (*Server, *mockPlaylistManager) {
 	cfg := &config.Config{
 		Server: config.ServerConfig{
@@ -546,6 +596,22 @@ func TestValidateAddArtistRequest(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "artist ID without name",
+			request: &types.AddArtistRequest{
+				ArtistID:   "2CIMQHirSU0MQqyYHq0eOx",
+				PlaylistID: "playlist1",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid artist ID",
+			request: &types.AddArtistRequest{
+				ArtistID:   "../../me",
+				PlaylistID: "playlist1",
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty playlist ID",
 			request: &types.AddArtistRequest{
@@ -562,6 +628,79 @@ func TestValidateAddArtistRequest(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid track count and strategy",
+			request: &types.AddArtistRequest{
+				ArtistName: "Test Artist",
+				PlaylistID: "playlist1",
+				TrackCount: 10,
+				Strategy:   "Latest",
+			},
+			wantErr: false,
+		},
+		{
+			name: "track count too high",
+			request: &types.AddArtistRequest{
+				ArtistName: "Test Artist",
+				PlaylistID: "playlist1",
+				TrackCount: 11,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative track count",
+			request: &types.AddArtistRequest{
+				ArtistName: "Test Artist",
+				PlaylistID: "playlist1",
+				TrackCount: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown strategy",
+			request: &types.AddArtistRequest{
+				ArtistName: "Test Artist",
+				PlaylistID: "playlist1",
+				Strategy:   "popular",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid market",
+			request: &types.AddArtistRequest{
+				ArtistName: "Test Artist",
+				PlaylistID: "playlist1",
+				Market:     "de",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid market",
+			request: &types.AddArtistRequest{
+				ArtistName: "Test Artist",
+				PlaylistID: "playlist1",
+				Market:     "D1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fill gaps mode",
+			request: &types.AddArtistRequest{
+				ArtistName: "Test Artist",
+				PlaylistID: "playlist1",
+				Mode:       "fill_gaps",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown mode",
+			request: &types.AddArtistRequest{
+				ArtistName: "Test Artist",
+				PlaylistID: "playlist1",
+				Mode:       "replace",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -575,6 +714,475 @@ func TestValidateAddArtistRequest(t *testing.T) {
 }
 
 // TestAPIIntegration tests the integration between playlist and add-artist endpoints
+func TestHandleAddArtist_TrackSelection(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want types.TrackSelection
+	}{
+		{
+			name: "defaults",
+			body: `{"artist_name":"Test Artist","playlist_id":"playlist1"}`,
+			want: types.TrackSelection{Count: types.DefaultTrackCount, Strategy: types.TrackStrategyTop},
+		},
+		{
+			name: "count and strategy",
+			body: `{"artist_name":"Test Artist","playlist_id":"playlist1","track_count":3,"strategy":"random"}`,
+			want: types.TrackSelection{Count: 3, Strategy: types.TrackStrategyRandom},
+		},
+		{
+			name: "strategy is case insensitive",
+			body: `{"artist_name":"Test Artist","playlist_id":"playlist1","strategy":"LATEST"}`,
+			want: types.TrackSelection{Count: types.DefaultTrackCount, Strategy: types.TrackStrategyLatest},
+		},
+		{
+			name: "market override",
+			body: `{"artist_name":"Test Artist","playlist_id":"playlist1","market":"se"}`,
+			want: types.TrackSelection{Count: types.DefaultTrackCount, Strategy: types.TrackStrategyTop, Market: "SE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mockPlaylist := createTestServer()
+			mockPlaylist.addResult = &types.AddResult{Success: true}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/add-artist", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.handleAddArtist(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if mockPlaylist.lastSelection != tt.want {
+				t.Errorf("AddArtistToPlaylist() selection = %+v, want %+v", mockPlaylist.lastSelection, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleAddArtist_Mode(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want types.AddMode
+	}{
+		{
+			name: "defaults to skip",
+			body: `{"artist_name":"Test Artist","playlist_id":"playlist1"}`,
+			want: types.AddModeSkip,
+		},
+		{
+			name: "force flag",
+			body: `{"artist_name":"Test Artist","playlist_id":"playlist1","force":true}`,
+			want: types.AddModeForce,
+		},
+		{
+			name: "fill gaps",
+			body: `{"artist_name":"Test Artist","playlist_id":"playlist1","mode":"fill_gaps"}`,
+			want: types.AddModeFillGaps,
+		},
+		{
+			name: "mode takes precedence over force",
+			body: `{"artist_name":"Test Artist","playlist_id":"playlist1","mode":"fill_gaps","force":true}`,
+			want: types.AddModeFillGaps,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mockPlaylist := createTestServer()
+			mockPlaylist.addResult = &types.AddResult{Success: true}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/add-artist", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.handleAddArtist(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if mockPlaylist.lastMode != tt.want {
+				t.Errorf("AddArtistToPlaylist() mode = %q, want %q", mockPlaylist.lastMode, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleAddArtist_Context(t *testing.T) {
+	tests := []struct {
+		name         string
+		writeTimeout int
+		addError     error
+		wantStatus   int
+		wantDeadline bool
+	}{
+		{
+			name:         "deadline before write timeout",
+			writeTimeout: 10,
+			wantStatus:   http.StatusOK,
+			wantDeadline: true,
+		},
+		{
+			name:       "no write timeout",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "deadline exceeded",
+			writeTimeout: 10,
+			addError:     fmt.Errorf("failed to search for artist: %w", context.DeadlineExceeded),
+			wantStatus:   http.StatusGatewayTimeout,
+			wantDeadline: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mockPlaylist := createTestServer()
+			server.config.Server.WriteTimeout = tt.writeTimeout
+			mockPlaylist.addResult = &types.AddResult{Success: true}
+			mockPlaylist.addError = tt.addError
+
+			body := `{"artist_name":"Test Artist","playlist_id":"playlist1"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/add-artist", strings.NewReader(body))
+			req = req.WithContext(logging.ContextWithCorrelationID(req.Context(), "req-123"))
+			w := httptest.NewRecorder()
+
+			start := time.Now()
+			server.handleAddArtist(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+
+			ctx := mockPlaylist.lastCtx
+			if ctx == nil {
+				t.Fatal("Expected AddArtistToPlaylist to receive a context")
+			}
+			if got := logging.CorrelationID(ctx); got != "req-123" {
+				t.Errorf("CorrelationID() = %q, want %q", got, "req-123")
+			}
+			deadline, ok := ctx.Deadline()
+			if ok != tt.wantDeadline {
+				t.Fatalf("Deadline() ok = %v, want %v", ok, tt.wantDeadline)
+			}
+			if ok && !deadline.Before(start.Add(time.Duration(tt.writeTimeout)*time.Second)) {
+				t.Errorf("Expected deadline before the write timeout, got %v after start", deadline.Sub(start))
+			}
+			if ctx.Err() == nil {
+				t.Error("Expected the request context to be cancelled once the handler returned")
+			}
+		})
+	}
+}
+
+func TestHandleAddArtist_Disambiguation(t *testing.T) {
+	server, mockPlaylist := createTestServer()
+	mockPlaylist.addResult = &types.AddResult{
+		NeedsDisambiguation: true,
+		Message:             "Several artists match 'Low', choose the one to add",
+		Candidates: []types.ArtistCandidate{
+			{Artist: types.Artist{ID: "artist1", Name: "Low"}, NameScore: 1.0, Confidence: 1.0},
+			{Artist: types.Artist{ID: "artist2", Name: "Low"}, NameScore: 1.0, Confidence: 0.95},
+		},
+	}
+
+	body := `{"artist_name":"Low","playlist_id":"playlist1"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/add-artist", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleAddArtist(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Success             bool            `json:"success"`
+		NeedsDisambiguation bool            `json:"needs_disambiguation"`
+		Data                types.AddResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Success || !response.NeedsDisambiguation {
+		t.Errorf("Expected an unsuccessful response needing disambiguation, got %s", w.Body.String())
+	}
+	if len(response.Data.Candidates) != 2 || response.Data.Candidates[1].Artist.ID != "artist2" {
+		t.Errorf("Expected both candidates in the response, got %+v", response.Data.Candidates)
+	}
+}
+
+func TestHandleAddArtist_Blocked(t *testing.T) {
+	server, mockPlaylist := createTestServer()
+	mockPlaylist.addResult = &types.AddResult{
+		Artist:      types.Artist{ID: "artist1", Name: "Various Artists"},
+		Blocked:     true,
+		BlockReason: types.BlockReasonNamePattern,
+		Message:     types.BlockReasonNamePattern.Message(),
+	}
+
+	body := `{"artist_name":"Various Artists","playlist_id":"playlist1"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/add-artist", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleAddArtist(w, req)
+
+	// A blocked artist is reported, not treated as a failed request
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Success bool            `json:"success"`
+		Message string          `json:"message"`
+		Data    types.AddResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Success || !response.Data.Blocked || response.Data.BlockReason != types.BlockReasonNamePattern {
+		t.Errorf("Expected an unsuccessful blocked response, got %s", w.Body.String())
+	}
+	if response.Message != "Artist name is blocklisted" {
+		t.Errorf("Expected the block reason message, got %q", response.Message)
+	}
+}
+
+func TestHandleAddArtist_ArtistID(t *testing.T) {
+	server, mockPlaylist := createTestServer()
+	mockPlaylist.addResult = &types.AddResult{Success: true, Message: "Successfully added"}
+
+	body := `{"artist_id":"2CIMQHirSU0MQqyYHq0eOx","playlist_id":"playlist1"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/add-artist", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleAddArtist(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if mockPlaylist.lastArtistID != "2CIMQHirSU0MQqyYHq0eOx" {
+		t.Errorf("Expected the artist to be added by ID, got %q", mockPlaylist.lastArtistID)
+	}
+}
+
+func TestHandleAddArtist_AutoPlaylist(t *testing.T) {
+	server, mockPlaylist := createTestServer()
+	body := `{"artist_name":"Converge","playlist_id":"auto"}`
+
+	// Without routing rules there is nowhere to send the artist
+	req := httptest.NewRequest(http.MethodPost, "/api/add-artist", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleAddArtist(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d without routing, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+
+	router, err := playlist.NewRouter(config.RoutingConfig{DefaultPlaylist: "37i9dQZF1DX9qNs32fujYe"}, nil)
+	if err != nil {
+		t.Fatalf("NewRouter() unexpected error: %v", err)
+	}
+	server.playlistRouter = router
+	mockPlaylist.addResult = &types.AddResult{
+		Success: true,
+		Message: "Successfully added",
+		Route:   &types.PlaylistRoute{PlaylistID: "37i9dQZF1DX9qNs32fujYe", PlaylistName: "Incoming Heavy", Genre: "metal"},
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/add-artist", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	server.handleAddArtist(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data types.AddResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.Route == nil || response.Data.Route.PlaylistName != "Incoming Heavy" {
+		t.Errorf("Expected the route in the response, got %s", w.Body.String())
+	}
+}
+
+// mockArtistSearcher is a types.ArtistSearcher returning fixed candidates
+type mockArtistSearcher struct {
+	candidates []types.ArtistCandidate
+	err        error
+	lastLimit  int
+}
+
+func (m *mockArtistSearcher) FindBestMatch(ctx context.Context, query string) (*types.Artist, float64, error) {
+	if m.err != nil {
+		return nil, 0, m.err
+	}
+	return &m.candidates[0].Artist, m.candidates[0].Confidence, nil
+}
+
+func (m *mockArtistSearcher) FindCandidates(ctx context.Context, query string, limit int) ([]types.ArtistCandidate, error) {
+	m.lastLimit = limit
+	return m.candidates, m.err
+}
+
+func TestHandleSearchArtists(t *testing.T) {
+	candidates := []types.ArtistCandidate{
+		{Artist: types.Artist{ID: "artist1", Name: "Low", Followers: 400000}, NameScore: 1.0, Confidence: 1.0},
+		{Artist: types.Artist{ID: "artist2", Name: "Low", Followers: 120}, NameScore: 1.0, Confidence: 0.95},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		searchErr     error
+		wantStatus    int
+		wantLimit     int
+		wantAmbiguous bool
+		wantCount     int
+	}{
+		{name: "default limit", query: "?q=Low", wantStatus: http.StatusOK, wantLimit: types.DefaultCandidateLimit, wantAmbiguous: true, wantCount: 2},
+		{name: "explicit limit", query: "?q=Low&limit=10", wantStatus: http.StatusOK, wantLimit: 10, wantAmbiguous: true, wantCount: 2},
+		{name: "no matches", query: "?q=Zzyzx", searchErr: fmt.Errorf("failed to search for artist: %w", spotify.ErrNotFound), wantStatus: http.StatusOK},
+		{name: "missing query", query: "", wantStatus: http.StatusBadRequest},
+		{name: "limit too large", query: "?q=Low&limit=11", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", query: "?q=Low&limit=many", wantStatus: http.StatusBadRequest},
+		{name: "search failure", query: "?q=Low", searchErr: errors.New("spotify API error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := createTestServer()
+			searcher := &mockArtistSearcher{candidates: candidates, err: tt.searchErr}
+			server.SetArtistSearcher(searcher)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/artists/search"+tt.query, nil)
+			w := httptest.NewRecorder()
+			server.handleSearchArtists(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if tt.wantLimit != 0 && searcher.lastLimit != tt.wantLimit {
+				t.Errorf("Expected limit %d, got %d", tt.wantLimit, searcher.lastLimit)
+			}
+
+			var response struct {
+				Data struct {
+					Candidates          []types.ArtistCandidate `json:"candidates"`
+					NeedsDisambiguation bool                    `json:"needs_disambiguation"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Data.Candidates) != tt.wantCount {
+				t.Errorf("Expected %d candidates, got %d", tt.wantCount, len(response.Data.Candidates))
+			}
+			if response.Data.NeedsDisambiguation != tt.wantAmbiguous {
+				t.Errorf("Expected needs_disambiguation %v, got %v", tt.wantAmbiguous, response.Data.NeedsDisambiguation)
+			}
+		})
+	}
+}
+
+func TestValidateScrapeArtistsRequest_TrackSelection(t *testing.T) {
+	server, _ := createTestServer()
+
+	tests := []struct {
+		name       string
+		trackCount int
+		strategy   string
+		wantErr    bool
+	}{
+		{name: "defaults", wantErr: false},
+		{name: "minimum count", trackCount: 1, strategy: "top", wantErr: false},
+		{name: "maximum count", trackCount: 10, strategy: "random", wantErr: false},
+		{name: "count too high", trackCount: 11, wantErr: true},
+		{name: "unknown strategy", strategy: "everything", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := server.validateScrapeArtistsRequest(&types.ScrapeArtistsRequest{
+				URL:        "https://example.com",
+				PlaylistID: "playlist1",
+				TrackCount: tt.trackCount,
+				Strategy:   tt.strategy,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateScrapeArtistsRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleAdminPlaylists(t *testing.T) {
+	server, mockPlaylist := createTestServer()
+	mockPlaylist.playlists = []types.Playlist{
+		{ID: "playlist1", Name: "Incoming", IsIncoming: true, IncomingReason: types.IncomingReasonNamePattern},
+		{ID: "playlist2", Name: "Road Trip"},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/playlists", nil)
+	w := httptest.NewRecorder()
+	server.handleAdminPlaylists(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response struct {
+		Success bool             `json:"success"`
+		Data    []types.Playlist `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 2 || response.Data[0].IncomingReason != types.IncomingReasonNamePattern {
+		t.Errorf("Expected both playlists with their classification, got %+v", response.Data)
+	}
+}
+
+func TestHandleAdminPlaylistIncoming(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		playlistID   string
+		markError    error
+		expectedCode int
+		wantMarks    map[string]bool
+	}{
+		{name: "mark", method: http.MethodPut, playlistID: "37i9dQZF1DX0XUsuxWHRQd", expectedCode: http.StatusOK, wantMarks: map[string]bool{"37i9dQZF1DX0XUsuxWHRQd": true}},
+		{name: "unmark", method: http.MethodDelete, playlistID: "37i9dQZF1DX0XUsuxWHRQd", expectedCode: http.StatusOK, wantMarks: map[string]bool{"37i9dQZF1DX0XUsuxWHRQd": false}},
+		{name: "invalid ID", method: http.MethodPut, playlistID: "bad id!", expectedCode: http.StatusBadRequest},
+		{name: "truncated ID", method: http.MethodPut, playlistID: "37i9dQZF1DX", expectedCode: http.StatusBadRequest},
+		{name: "method not allowed", method: http.MethodPost, playlistID: "37i9dQZF1DX0XUsuxWHRQd", expectedCode: http.StatusMethodNotAllowed},
+		{name: "rules not configured", method: http.MethodPut, playlistID: "37i9dQZF1DX0XUsuxWHRQd", markError: playlist.ErrNoIncomingRules, expectedCode: http.StatusServiceUnavailable},
+		{name: "store failure", method: http.MethodDelete, playlistID: "37i9dQZF1DX0XUsuxWHRQd", markError: errors.New("disk full"), expectedCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mockPlaylist := createTestServer()
+			mockPlaylist.markError = tt.markError
+
+			req := httptest.NewRequest(tt.method, "/api/admin/playlists/"+url.PathEscape(tt.playlistID)+"/incoming", nil)
+			req.SetPathValue("id", tt.playlistID)
+			w := httptest.NewRecorder()
+			server.handleAdminPlaylistIncoming(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if !maps.Equal(mockPlaylist.marks, tt.wantMarks) {
+				t.Errorf("Expected marks %v, got %v", tt.wantMarks, mockPlaylist.marks)
+			}
+		})
+	}
+}
+
 func TestAPIIntegration(t *testing.T) {
 	server, mockPlaylist := createTestServer()
 
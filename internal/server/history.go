@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// webUIClientHeader is sent by the bundled web interface so its additions are recorded as UI rather than API
+const webUIClientHeader = "X-Go-Listen-Client"
+
+// requestSource describes where an addition request came from for the history
+func (s *Server) requestSource(ctx context.Context, r *http.Request) types.AddSource {
+	source := types.AddSource{Kind: types.SourceAPI}
+	if r.Header.Get(webUIClientHeader) == "web-ui" {
+		source.Kind = types.SourceUI
+	}
+
+	if s.spotify != nil {
+		userID, err := s.spotify.CurrentUserID(ctx)
+		if err != nil {
+			s.logger.WithContext(ctx).WithField("component", "server").WithError(err).Debug("Failed to get Spotify user for history")
+		}
+		source.User = userID
+	}
+	return source
+}
+
+// handleHistory returns recorded artist additions, newest first
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.history == nil {
+		s.writeJSONError(w, "History is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	filter, err := parseHistoryFilter(r.URL.Query())
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := s.history.List(filter)
+	if err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to query history")
+		s.writeJSONError(w, "Failed to query history", http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component":   "server",
+		"operation":   "get_history",
+		"artist_id":   filter.ArtistID,
+		"playlist_id": filter.PlaylistID,
+		"entry_count": len(entries),
+	}).Debug("Returning history entries")
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    entries,
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// parseHistoryFilter builds a history filter from query parameters
+func parseHistoryFilter(query url.Values) (types.HistoryFilter, error) {
+	filter := types.HistoryFilter{
+		ArtistID:   query.Get("artist_id"),
+		PlaylistID: query.Get("playlist_id"),
+		Source:     types.SourceKind(query.Get("source")),
+	}
+
+	switch filter.Source {
+	case "", types.SourceUI, types.SourceAPI, types.SourceCLI, types.SourceWatch:
+	default:
+		return filter, fmt.Errorf("source must be one of ui, api, cli, watch")
+	}
+
+	var err error
+	if filter.Since, err = parseTimeParam(query, "since"); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseTimeParam(query, "until"); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = parseCountParam(query, "limit"); err != nil {
+		return filter, err
+	}
+	if filter.Offset, err = parseCountParam(query, "offset"); err != nil {
+		return filter, err
+	}
+	if filter.Limit > types.MaxHistoryLimit {
+		return filter, fmt.Errorf("limit must be at most %d", types.MaxHistoryLimit)
+	}
+
+	return filter, nil
+}
+
+// parseTimeParam parses an optional RFC 3339 query parameter
+func parseTimeParam(query url.Values, name string) (time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return parsed, nil
+}
+
+// parseCountParam parses an optional non-negative integer query parameter
+func parseCountParam(query url.Values, name string) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return parsed, nil
+}
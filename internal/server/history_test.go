@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-listen/internal/services/history"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+func TestParseHistoryFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    types.HistoryFilter
+		wantErr bool
+	}{
+		{name: "empty", query: "", want: types.HistoryFilter{}},
+		{
+			name:  "all parameters",
+			query: "artist_id=a1&playlist_id=p1&source=cli&since=2024-05-01T00:00:00Z&until=2024-06-01T00:00:00Z&limit=10&offset=20",
+			want: types.HistoryFilter{
+				ArtistID:   "a1",
+				PlaylistID: "p1",
+				Source:     types.SourceCLI,
+				Since:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				Until:      time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+				Limit:      10,
+				Offset:     20,
+			},
+		},
+		{name: "unknown source", query: "source=email", wantErr: true},
+		{name: "invalid since", query: "since=yesterday", wantErr: true},
+		{name: "negative limit", query: "limit=-1", wantErr: true},
+		{name: "limit too large", query: "limit=501", wantErr: true},
+		{name: "non-numeric offset", query: "offset=ten", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseHistoryFilter(query)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseHistoryFilter() expected error but got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHistoryFilter() unexpected error: %v", err)
+			}
+			if got.ArtistID != tt.want.ArtistID || got.PlaylistID != tt.want.PlaylistID || got.Source != tt.want.Source ||
+				!got.Since.Equal(tt.want.Since) || !got.Until.Equal(tt.want.Until) ||
+				got.Limit != tt.want.Limit || got.Offset != tt.want.Offset {
+				t.Errorf("parseHistoryFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleHistory(t *testing.T) {
+	server, _ := createTestServer()
+
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("history.Open() unexpected error: %v", err)
+	}
+	defer store.Close()
+	server.history = store
+
+	for _, entry := range []*types.HistoryEntry{
+		{ArtistID: "a1", ArtistName: "First", PlaylistID: "p1", Source: types.SourceUI},
+		{ArtistID: "a2", ArtistName: "Second", PlaylistID: "p1", Source: types.SourceCLI, SourceURL: "https://example.com"},
+	} {
+		if err := store.Record(entry); err != nil {
+			t.Fatalf("Record() unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		query        string
+		expectedCode int
+		wantArtists  []string
+	}{
+		{name: "all", method: http.MethodGet, expectedCode: http.StatusOK, wantArtists: []string{"Second", "First"}},
+		{name: "filtered by source", method: http.MethodGet, query: "?source=ui", expectedCode: http.StatusOK, wantArtists: []string{"First"}},
+		{name: "bad filter", method: http.MethodGet, query: "?limit=abc", expectedCode: http.StatusBadRequest},
+		{name: "method not allowed", method: http.MethodPost, expectedCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/history"+tt.query, nil)
+			w := httptest.NewRecorder()
+			server.handleHistory(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Success bool                 `json:"success"`
+				Data    []types.HistoryEntry `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Data) != len(tt.wantArtists) {
+				t.Fatalf("Expected %d entries, got %+v", len(tt.wantArtists), response.Data)
+			}
+			for i, name := range tt.wantArtists {
+				if response.Data[i].ArtistName != name {
+					t.Errorf("Entry %d artist = %q, want %q", i, response.Data[i].ArtistName, name)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleHistory_Disabled(t *testing.T) {
+	server, _ := createTestServer()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/history", nil)
+	w := httptest.NewRecorder()
+	server.handleHistory(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
+
+func TestHandleAddArtist_Source(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   types.SourceKind
+	}{
+		{name: "web interface", header: "web-ui", want: types.SourceUI},
+		{name: "API client", header: "", want: types.SourceAPI},
+		{name: "unknown client", header: "curl", want: types.SourceAPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mockPlaylist := createTestServer()
+			mockPlaylist.addResult = &types.AddResult{Success: true}
+
+			body := `{"artist_name":"Test Artist","playlist_id":"playlist1"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/add-artist", strings.NewReader(body))
+			if tt.header != "" {
+				req.Header.Set(webUIClientHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			server.handleAddArtist(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if mockPlaylist.lastSource.Kind != tt.want {
+				t.Errorf("AddArtistToPlaylist() source = %q, want %q", mockPlaylist.lastSource.Kind, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/toozej/go-listen/internal/middleware"
+	"github.com/toozej/go-listen/internal/services/spotify"
 	"github.com/toozej/go-listen/internal/types"
 	"github.com/toozej/go-listen/pkg/config"
 	"github.com/toozej/go-listen/pkg/logging"
@@ -158,7 +160,7 @@ type enhancedMockPlaylistManager struct {
 	mu         sync.Mutex
 }
 
-func (m *enhancedMockPlaylistManager) AddArtistToPlaylist(artistName, playlistID string, force bool) (*types.AddResult, error) {
+func (m *enhancedMockPlaylistManager) AddArtistToPlaylist(ctx context.Context, artistName, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.callCount++
@@ -168,9 +170,9 @@ func (m *enhancedMockPlaylistManager) AddArtistToPlaylist(artistName, playlistID
 	case "error artist":
 		return nil, fmt.Errorf("spotify API error")
 	case "rate limit artist":
-		return nil, fmt.Errorf("HTTP 429: Rate limit exceeded")
+		return nil, fmt.Errorf("failed to add tracks to playlist: %w", spotify.ErrRateLimited)
 	case "duplicate artist":
-		if force {
+		if mode == types.AddModeForce {
 			return m.addResults["success"], nil
 		}
 		return m.addResults["duplicate"], nil
@@ -179,14 +181,36 @@ func (m *enhancedMockPlaylistManager) AddArtistToPlaylist(artistName, playlistID
 	}
 }
 
-func (m *enhancedMockPlaylistManager) GetIncomingPlaylists() ([]types.Playlist, error) {
+func (m *enhancedMockPlaylistManager) AddArtistByID(ctx context.Context, artistID, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callCount++
+	return m.addResults["success"], nil
+}
+
+func (m *enhancedMockPlaylistManager) GetIncomingPlaylists(ctx context.Context) ([]types.Playlist, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.callCount++
 	return m.playlists, nil
 }
 
-func (m *enhancedMockPlaylistManager) GetTop5Tracks(artistID string) ([]types.Track, error) {
+func (m *enhancedMockPlaylistManager) ListPlaylists(ctx context.Context) ([]types.Playlist, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callCount++
+	return m.playlists, nil
+}
+
+func (m *enhancedMockPlaylistManager) MarkIncoming(ctx context.Context, playlistID string) error {
+	return nil
+}
+
+func (m *enhancedMockPlaylistManager) UnmarkIncoming(ctx context.Context, playlistID string) error {
+	return nil
+}
+
+func (m *enhancedMockPlaylistManager) GetArtistTracks(ctx context.Context, artistID string, selection types.TrackSelection) ([]types.Track, error) {
 	return nil, nil
 }
 
@@ -205,14 +229,14 @@ func (m *enhancedMockPlaylistManager) FilterPlaylistsBySearch(playlists []types.
 	return filtered
 }
 
-func (m *enhancedMockPlaylistManager) AddTracksToPlaylist(playlistID string, trackIDs []string) error {
+func (m *enhancedMockPlaylistManager) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.callCount++
 	return nil
 }
 
-func (m *enhancedMockPlaylistManager) CheckForDuplicates(playlistID string, trackIDs []string) (*types.DuplicateResult, error) {
+func (m *enhancedMockPlaylistManager) CheckForDuplicates(ctx context.Context, playlistID string, trackIDs []string) (*types.DuplicateResult, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.callCount++
@@ -221,6 +245,10 @@ func (m *enhancedMockPlaylistManager) CheckForDuplicates(playlistID string, trac
 	}, nil
 }
 
+func (m *enhancedMockPlaylistManager) FilterMissingTracks(ctx context.Context, playlistID string, tracks []types.Track) ([]types.Track, []types.Track, error) {
+	return tracks, nil, nil
+}
+
 func (m *enhancedMockPlaylistManager) GetCallCount() int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -16,6 +16,7 @@ type Track = types.Track
 type Playlist = types.Playlist
 type AddResult = types.AddResult
 type DuplicateResult = types.DuplicateResult
+type TrackSelection = types.TrackSelection
 
 type AddArtistRequest = types.AddArtistRequest
 type APIResponse = types.APIResponse
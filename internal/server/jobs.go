@@ -0,0 +1,213 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/jobs"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// Kinds of background jobs
+const (
+	// jobKindScrape identifies scrape-and-add jobs
+	jobKindScrape = "scrape"
+	// jobKindScrapePreview identifies dry-run scrapes, which add nothing
+	jobKindScrapePreview = "scrape_preview"
+	// jobKindScrapeCommit identifies jobs adding the artists approved from a preview
+	jobKindScrapeCommit = "scrape_commit"
+	// jobKindWatchRun identifies scrapes of a watched source started through the API
+	jobKindWatchRun = "watch_run"
+)
+
+// Server-sent event names used by the job event stream
+const (
+	sseEventProgress = "progress"
+	sseEventEnd      = "end"
+)
+
+// jobEventsKeepAlive is how often an idle job event stream sends a comment, so
+// proxies don't close the connection while a slow step runs
+const jobEventsKeepAlive = 15 * time.Second
+
+// handleJob reports a background job's progress (GET) or cancels it (DELETE)
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	jobID := r.PathValue("id")
+	if !isValidJobID(jobID) {
+		s.writeJSONError(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		job *jobs.Job
+		err error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		job, err = s.jobs.Get(jobID)
+	case http.MethodDelete:
+		job, err = s.jobs.Cancel(jobID)
+		if err == nil {
+			s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+				"component": "server",
+				"job_id":    jobID,
+			}).Info("Cancelled job")
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		s.writeJSONError(w, err.Error(), jobErrorStatus(err))
+		return
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    job,
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// handleJobEvents streams a background job's events as server-sent events. Each
+// progress event carries its sequence number as the event ID, so a reconnecting
+// client resumes after the Last-Event-ID it last saw. Once the job has finished the
+// stream sends an "end" event with the job's final snapshot and closes.
+func (s *Server) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jobID := r.PathValue("id")
+	if !isValidJobID(jobID) {
+		s.writeJSONError(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	after := 0
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		seq, err := strconv.Atoi(lastID)
+		if err != nil || seq < 0 {
+			s.writeJSONError(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		after = seq
+	}
+
+	events, job, changed, err := s.jobs.Events(jobID, after)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), jobErrorStatus(err))
+		return
+	}
+
+	logger := s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component": "server",
+		"operation": "job_events",
+		"job_id":    jobID,
+	})
+
+	// The stream lasts as long as the job, so lift the server's write timeout for it
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logger.WithError(err).Warn("Failed to clear write deadline for event stream")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(jobEventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		for _, event := range events {
+			if err := writeServerSentEvent(w, strconv.Itoa(event.Seq), sseEventProgress, event.Data); err != nil {
+				logger.WithError(err).Debug("Event stream closed")
+				return
+			}
+			after = event.Seq
+		}
+
+		if job.Status.Finished() {
+			if err := writeServerSentEvent(w, "", sseEventEnd, job); err != nil {
+				logger.WithError(err).Debug("Event stream closed")
+				return
+			}
+			_ = rc.Flush()
+			return
+		}
+
+		if err := rc.Flush(); err != nil {
+			logger.WithError(err).Debug("Event stream closed")
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-changed:
+		}
+
+		events, job, changed, err = s.jobs.Events(jobID, after)
+		if err != nil {
+			// The job expired while the client was following it
+			logger.WithError(err).Debug("Job no longer available for event stream")
+			return
+		}
+	}
+}
+
+// writeServerSentEvent writes data as a single JSON encoded server-sent event,
+// leaving out the ID line when id is empty
+func writeServerSentEvent(w io.Writer, id, name string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+	return err
+}
+
+// jobErrorStatus maps job manager errors to an HTTP status
+func jobErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, jobs.ErrFinished):
+		return http.StatusConflict
+	case errors.Is(err, jobs.ErrQueueFull), errors.Is(err, jobs.ErrShutdown):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// isValidJobID reports whether id looks like a job ID issued by the job manager
+func isValidJobID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,508 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/jobs"
+	"github.com/toozej/go-listen/internal/services/scraper"
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/logging"
+)
+
+// mockScraper reports progress and an event for each artist and blocks on release, if set,
+// before finishing, so tests can observe running jobs
+type mockScraper struct {
+	artists []string
+	release chan struct{}
+	// correlationIDs, if set, receives the correlation ID each scrape's context carries
+	correlationIDs chan string
+	// committed records the artists passed to CommitScrape
+	committed []types.ApprovedArtist
+}
+
+func (m *mockScraper) ScrapeArtists(ctx context.Context, url, cssSelector string) ([]string, error) {
+	return m.artists, nil
+}
+
+func (m *mockScraper) ScrapeAndAddToPlaylist(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*scraper.ScrapeResult, error) {
+	return m.ScrapeAndAddToPlaylistWithProgress(ctx, url, cssSelector, playlistID, mode, selection, source, nil)
+}
+
+func (m *mockScraper) ScrapeAndAddToPlaylistWithProgress(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, progress scraper.ProgressFunc) (*scraper.ScrapeResult, error) {
+	if m.correlationIDs != nil {
+		m.correlationIDs <- logging.CorrelationID(ctx)
+	}
+	result := &scraper.ScrapeResult{URL: url, ArtistsFound: m.artists}
+	for i, name := range m.artists {
+		result.MatchResults = append(result.MatchResults, scraper.ArtistMatchResult{Query: name, Matched: true, TracksAdded: 1})
+		result.SuccessCount++
+		if progress != nil {
+			progress(scraper.ScrapeProgress{
+				Phase:            scraper.PhaseAdding,
+				ArtistsTotal:     len(m.artists),
+				ArtistsProcessed: i + 1,
+				Event:            &types.ProgressEvent{Type: types.EventTracksAdded, Query: name, TracksAdded: 1},
+			})
+		}
+	}
+
+	if m.release != nil {
+		select {
+		case <-m.release:
+		case <-ctx.Done():
+			result.Message = "Scraping cancelled"
+			return result, ctx.Err()
+		}
+	}
+	result.Message = "Scraping complete"
+	return result, nil
+}
+
+func (m *mockScraper) PreviewScrape(ctx context.Context, url, cssSelector, playlistID string, progress scraper.ProgressFunc) (*scraper.ScrapePreview, error) {
+	preview := &scraper.ScrapePreview{URL: url, PlaylistID: playlistID, ArtistsFound: m.artists, Message: "Preview complete"}
+	for _, name := range m.artists {
+		preview.Artists = append(preview.Artists, scraper.ArtistMatchResult{Query: name, Matched: true, Artist: &types.Artist{ID: "id" + strconv.Itoa(len(preview.Artists)), Name: name}})
+		preview.MatchedCount++
+	}
+	return preview, nil
+}
+
+func (m *mockScraper) CommitScrape(ctx context.Context, url, playlistID string, artists []types.ApprovedArtist, mode types.AddMode, selection types.TrackSelection, source types.AddSource, progress scraper.ProgressFunc) (*scraper.ScrapeResult, error) {
+	m.committed = artists
+	result := &scraper.ScrapeResult{URL: url, Message: "Added approved artists"}
+	for _, artist := range artists {
+		result.ArtistsFound = append(result.ArtistsFound, artist.Query)
+		result.MatchResults = append(result.MatchResults, scraper.ArtistMatchResult{Query: artist.Query, Matched: true, TracksAdded: 1})
+		result.SuccessCount++
+	}
+	return result, nil
+}
+
+// createJobTestServer creates a test server running scrapes on a single worker
+func createJobTestServer(t *testing.T, mock *mockScraper) *Server {
+	t.Helper()
+
+	server, _ := createTestServer()
+	server.SetScraperService(mock)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	server.jobs = jobs.NewManager(jobs.Config{Workers: 1, QueueSize: 1}, logger)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.jobs.Shutdown(ctx)
+	})
+	return server
+}
+
+// submitScrape posts a scrape request and returns the queued job
+func submitScrape(t *testing.T, server *Server) jobs.Job {
+	t.Helper()
+
+	body := `{"url":"https://example.com/lineup","playlist_id":"playlist1"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/scrape-artists", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleScrapeArtists(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Success bool     `json:"success"`
+		Data    jobs.Job `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.ID == "" {
+		t.Fatal("Expected a job ID in the response")
+	}
+	if got := w.Header().Get("Location"); got != "/api/jobs/"+response.Data.ID {
+		t.Errorf("Expected Location header for the job, got %q", got)
+	}
+	return response.Data
+}
+
+// jobResponse is the job endpoint's response with a scrape job's progress and result
+type jobResponse struct {
+	Success bool `json:"success"`
+	Data    struct {
+		Status   jobs.Status             `json:"status"`
+		Progress *scraper.ScrapeProgress `json:"progress"`
+		Result   *scraper.ScrapeResult   `json:"result"`
+	} `json:"data"`
+	Error string `json:"error"`
+}
+
+// doJobRequest performs a request against the job endpoint and decodes the response
+func doJobRequest(t *testing.T, server *Server, method, id string) (int, jobResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/api/jobs/"+id, nil)
+	req.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	server.handleJob(w, req)
+
+	var response jobResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return w.Code, response
+}
+
+// pollJob polls a job until it reaches want or the test times out
+func pollJob(t *testing.T, server *Server, id string, want jobs.Status) jobResponse {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		code, response := doJobRequest(t, server, http.MethodGet, id)
+		if code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, code, response.Error)
+		}
+		if response.Data.Status == want {
+			return response
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job status = %s, want %s", response.Data.Status, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandleScrapeArtists_RunsJob(t *testing.T) {
+	server := createJobTestServer(t, &mockScraper{artists: []string{"Artist A", "Artist B"}})
+
+	job := submitScrape(t, server)
+	response := pollJob(t, server, job.ID, jobs.StatusSucceeded)
+
+	if response.Data.Result == nil || response.Data.Result.SuccessCount != 2 {
+		t.Fatalf("Expected a result with 2 successes, got %+v", response.Data.Result)
+	}
+	if response.Data.Progress == nil || response.Data.Progress.ArtistsProcessed != 2 {
+		t.Errorf("Expected progress for 2 artists, got %+v", response.Data.Progress)
+	}
+}
+
+func TestHandleScrapeArtists_DryRun(t *testing.T) {
+	server := createJobTestServer(t, &mockScraper{artists: []string{"Artist A", "Artist B"}})
+
+	body := `{"url":"https://example.com/lineup","playlist_id":"playlist1","dry_run":true}`
+	req := httptest.NewRequest(http.MethodPost, "/api/scrape-artists", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleScrapeArtists(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+
+	var queued struct {
+		Data jobs.Job `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&queued); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if queued.Data.Kind != jobKindScrapePreview {
+		t.Errorf("Job kind = %q, want %q", queued.Data.Kind, jobKindScrapePreview)
+	}
+	pollJob(t, server, queued.Data.ID, jobs.StatusSucceeded)
+
+	// The job's result is the preview rather than a scrape result
+	job, err := server.jobs.Get(queued.Data.ID)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	preview, ok := job.Result.(*scraper.ScrapePreview)
+	if !ok || preview.MatchedCount != 2 || len(preview.Artists) != 2 {
+		t.Errorf("Expected a preview of 2 matched artists, got %+v", job.Result)
+	}
+}
+
+func TestHandleCommitScrape(t *testing.T) {
+	mock := &mockScraper{}
+	server := createJobTestServer(t, mock)
+
+	body := `{"url":"https://example.com/lineup","playlist_id":"playlist1","artists":[{"query":"Artist A","artist_id":"4Z8W4fKeB5YxbusRsdQVPb"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/scrape-artists/commit", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleCommitScrape(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+
+	var queued struct {
+		Data jobs.Job `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&queued); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if queued.Data.Kind != jobKindScrapeCommit {
+		t.Errorf("Job kind = %q, want %q", queued.Data.Kind, jobKindScrapeCommit)
+	}
+	response := pollJob(t, server, queued.Data.ID, jobs.StatusSucceeded)
+
+	if response.Data.Result == nil || response.Data.Result.SuccessCount != 1 {
+		t.Fatalf("Expected a result with 1 success, got %+v", response.Data.Result)
+	}
+	want := []types.ApprovedArtist{{Query: "Artist A", ArtistID: "4Z8W4fKeB5YxbusRsdQVPb"}}
+	if !reflect.DeepEqual(mock.committed, want) {
+		t.Errorf("Committed artists = %+v, want %+v", mock.committed, want)
+	}
+}
+
+func TestHandleCommitScrape_Invalid(t *testing.T) {
+	tooMany := make([]string, maxApprovedArtists+1)
+	for i := range tooMany {
+		tooMany[i] = `{"artist_id":"abc"}`
+	}
+
+	tests := map[string]string{
+		"no artists":        `{"playlist_id":"playlist1","artists":[]}`,
+		"too many artists":  `{"playlist_id":"playlist1","artists":[` + strings.Join(tooMany, ",") + `]}`,
+		"invalid artist ID": `{"playlist_id":"playlist1","artists":[{"query":"Artist A","artist_id":"not-an-id"}]}`,
+		"missing playlist":  `{"artists":[{"artist_id":"abc"}]}`,
+		"auto playlist":     `{"playlist_id":"auto","artists":[{"artist_id":"abc"}]}`,
+		"invalid URL":       `{"url":"ftp://example.com","playlist_id":"playlist1","artists":[{"artist_id":"abc"}]}`,
+		"invalid mode":      `{"playlist_id":"playlist1","mode":"replace","artists":[{"artist_id":"abc"}]}`,
+		"unknown field":     `{"playlist_id":"playlist1","artists":[{"artist_id":"abc","confidence":0.9}]}`,
+	}
+
+	server := createJobTestServer(t, &mockScraper{})
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/scrape-artists/commit", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			server.handleCommitScrape(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleScrapeArtists_KeepsCorrelationID(t *testing.T) {
+	mock := &mockScraper{artists: []string{"Artist A"}, correlationIDs: make(chan string, 1)}
+	server := createJobTestServer(t, mock)
+
+	body := `{"url":"https://example.com/lineup","playlist_id":"playlist1"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/scrape-artists", strings.NewReader(body))
+	req = req.WithContext(logging.ContextWithCorrelationID(req.Context(), "req-123"))
+	w := httptest.NewRecorder()
+	server.handleScrapeArtists(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+
+	select {
+	case got := <-mock.correlationIDs:
+		if got != "req-123" {
+			t.Errorf("Scrape correlation ID = %q, want %q", got, "req-123")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Scrape job did not run")
+	}
+}
+
+func TestHandleJob_Cancel(t *testing.T) {
+	mock := &mockScraper{artists: []string{"Artist A"}, release: make(chan struct{})}
+	server := createJobTestServer(t, mock)
+
+	job := submitScrape(t, server)
+	running := pollJob(t, server, job.ID, jobs.StatusRunning)
+	if running.Data.Progress == nil || running.Data.Progress.ArtistsTotal != 1 {
+		t.Errorf("Expected progress while running, got %+v", running.Data.Progress)
+	}
+
+	code, _ := doJobRequest(t, server, http.MethodDelete, job.ID)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d cancelling job, got %d", http.StatusOK, code)
+	}
+
+	cancelled := pollJob(t, server, job.ID, jobs.StatusCancelled)
+	if cancelled.Data.Result == nil || cancelled.Data.Result.Message != "Scraping cancelled" {
+		t.Errorf("Expected the partial result of the cancelled scrape, got %+v", cancelled.Data.Result)
+	}
+
+	code, _ = doJobRequest(t, server, http.MethodDelete, job.ID)
+	if code != http.StatusConflict {
+		t.Errorf("Expected status %d cancelling a finished job, got %d", http.StatusConflict, code)
+	}
+}
+
+func TestHandleJob_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		id             string
+		expectedStatus int
+	}{
+		{name: "unknown job", method: http.MethodGet, id: "0123456789abcdef", expectedStatus: http.StatusNotFound},
+		{name: "cancel unknown job", method: http.MethodDelete, id: "0123456789abcdef", expectedStatus: http.StatusNotFound},
+		{name: "invalid job ID", method: http.MethodGet, id: "not-a-job", expectedStatus: http.StatusBadRequest},
+	}
+
+	server := createJobTestServer(t, &mockScraper{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, response := doJobRequest(t, server, tt.method, tt.id)
+			if code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, code)
+			}
+			if response.Success {
+				t.Error("Expected an unsuccessful response")
+			}
+		})
+	}
+
+	t.Run("method not allowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/0123456789abcdef", nil)
+		req.SetPathValue("id", "0123456789abcdef")
+		w := httptest.NewRecorder()
+		server.handleJob(w, req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+		}
+	})
+}
+
+// streamedEvent is a server-sent event read back from a job event stream
+type streamedEvent struct {
+	id   string
+	name string
+	data string
+}
+
+// parseEventStream splits a text/event-stream body into its events, skipping comments
+func parseEventStream(t *testing.T, body string) []streamedEvent {
+	t.Helper()
+
+	var events []streamedEvent
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var event streamedEvent
+		for _, line := range strings.Split(block, "\n") {
+			field, value, _ := strings.Cut(line, ": ")
+			switch field {
+			case "id":
+				event.id = value
+			case "event":
+				event.name = value
+			case "data":
+				event.data = value
+			}
+		}
+		if event.name != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// streamJobEvents reads a job's event stream until the handler returns
+func streamJobEvents(server *Server, id, lastEventID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/jobs/"+id+"/events", nil)
+	req.SetPathValue("id", id)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	w := httptest.NewRecorder()
+	server.handleJobEvents(w, req)
+	return w
+}
+
+func TestHandleJobEvents_Streams(t *testing.T) {
+	mock := &mockScraper{artists: []string{"Artist A", "Artist B"}, release: make(chan struct{})}
+	server := createJobTestServer(t, mock)
+
+	job := submitScrape(t, server)
+	pollJob(t, server, job.ID, jobs.StatusRunning)
+
+	// Follow the job while it runs, then let it finish
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- streamJobEvents(server, job.ID, "") }()
+	close(mock.release)
+
+	var w *httptest.ResponseRecorder
+	select {
+	case w = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the event stream to end")
+	}
+
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Expected an event stream, got content type %q", got)
+	}
+
+	events := parseEventStream(t, w.Body.String())
+	if len(events) != 3 {
+		t.Fatalf("Expected 2 progress events and an end event, got %+v", events)
+	}
+	for i, name := range []string{"Artist A", "Artist B"} {
+		var event types.ProgressEvent
+		if err := json.Unmarshal([]byte(events[i].data), &event); err != nil {
+			t.Fatalf("Failed to decode event: %v", err)
+		}
+		if events[i].name != sseEventProgress || events[i].id != strconv.Itoa(i+1) {
+			t.Errorf("Event %d = %s with ID %q, want a progress event with ID %d", i, events[i].name, events[i].id, i+1)
+		}
+		if event.Type != types.EventTracksAdded || event.Query != name {
+			t.Errorf("Event %d = %+v, want tracks added for %s", i, event, name)
+		}
+	}
+
+	end := events[2]
+	var finished jobs.Job
+	if err := json.Unmarshal([]byte(end.data), &finished); err != nil {
+		t.Fatalf("Failed to decode end event: %v", err)
+	}
+	if end.name != sseEventEnd || finished.Status != jobs.StatusSucceeded {
+		t.Errorf("Expected an end event for the succeeded job, got %s with %+v", end.name, finished)
+	}
+}
+
+func TestHandleJobEvents_Resume(t *testing.T) {
+	server := createJobTestServer(t, &mockScraper{artists: []string{"Artist A", "Artist B"}})
+
+	job := submitScrape(t, server)
+	pollJob(t, server, job.ID, jobs.StatusSucceeded)
+
+	events := parseEventStream(t, streamJobEvents(server, job.ID, "1").Body.String())
+	if len(events) != 2 || events[0].id != "2" || events[1].name != sseEventEnd {
+		t.Errorf("Expected only the event after ID 1 and the end event, got %+v", events)
+	}
+}
+
+func TestHandleJobEvents_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		lastEventID    string
+		expectedStatus int
+	}{
+		{name: "unknown job", id: "0123456789abcdef", expectedStatus: http.StatusNotFound},
+		{name: "invalid job ID", id: "not-a-job", expectedStatus: http.StatusBadRequest},
+		{name: "invalid last event ID", id: "0123456789abcdef", lastEventID: "abc", expectedStatus: http.StatusBadRequest},
+	}
+
+	server := createJobTestServer(t, &mockScraper{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := streamJobEvents(server, tt.id, tt.lastEventID)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/review"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// handleReview lists the items of the review queue, oldest first
+func (s *Server) handleReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.review == nil {
+		s.writeJSONError(w, "Review queue is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	filter, err := parseReviewFilter(r.URL.Query())
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, err := s.review.Store().List(filter)
+	if err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to query review queue")
+		s.writeJSONError(w, "Failed to query review queue", http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component":   "server",
+		"operation":   "get_review",
+		"status":      filter.Status,
+		"playlist_id": filter.PlaylistID,
+		"item_count":  len(items),
+	}).Debug("Returning review queue items")
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    items,
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// parseReviewFilter builds a review queue filter from query parameters
+func parseReviewFilter(query url.Values) (types.ReviewFilter, error) {
+	filter := types.ReviewFilter{
+		Status:     types.ReviewStatus(query.Get("status")),
+		PlaylistID: query.Get("playlist_id"),
+	}
+
+	switch filter.Status {
+	case "", types.ReviewPending, types.ReviewAccepted, types.ReviewRemapped, types.ReviewRejected:
+	default:
+		return filter, fmt.Errorf("status must be one of pending, accepted, remapped, rejected")
+	}
+
+	var err error
+	if filter.Limit, err = parseCountParam(query, "limit"); err != nil {
+		return filter, err
+	}
+	if filter.Offset, err = parseCountParam(query, "offset"); err != nil {
+		return filter, err
+	}
+	if filter.Limit > types.MaxReviewLimit {
+		return filter, fmt.Errorf("limit must be at most %d", types.MaxReviewLimit)
+	}
+
+	return filter, nil
+}
+
+// handleReviewItem returns a single item of the review queue with its candidates
+func (s *Server) handleReviewItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := s.reviewItemID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := s.review.Store().Get(id)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), reviewErrorStatus(err))
+		return
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    item,
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// handleAcceptReview adds the artist of a pending item, its best candidate or the
+// artist_id given in the optional request body, which remaps the item
+func (s *Server) handleAcceptReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := s.reviewItemID(w, r)
+	if !ok {
+		return
+	}
+
+	var decision types.ReviewDecision
+	if err := s.parseJSONRequest(r, &decision); err != nil && !errors.Is(err, io.EOF) {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Warn("Invalid JSON request")
+		s.writeJSONError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if decision.ArtistID != "" && !types.IsSpotifyID(decision.ArtistID) {
+		s.writeJSONError(w, "Invalid artist ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	item, result, err := s.review.Accept(ctx, id, decision.ArtistID, s.requestSource(ctx, r))
+	if err != nil {
+		message := err.Error()
+		if result != nil && result.Message != "" {
+			message = result.Message
+		}
+		s.writeJSONError(w, message, reviewErrorStatus(err))
+		return
+	}
+
+	response := types.WebUIResponse{
+		Success:     result.Success,
+		Message:     result.Message,
+		IsDuplicate: result.WasDuplicate,
+		LastAdded:   result.LastAdded,
+		Data:        types.ReviewResolution{Item: *item, Result: result},
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// handleRejectReview dismisses a pending item without adding anything
+func (s *Server) handleRejectReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := s.reviewItemID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := s.review.Reject(r.Context(), id)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), reviewErrorStatus(err))
+		return
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    types.ReviewResolution{Item: *item},
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// reviewItemID reads the item ID from the request path, writing the error response
+// when the review queue is disabled or the ID is invalid
+func (s *Server) reviewItemID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	if s.review == nil {
+		s.writeJSONError(w, "Review queue is not enabled", http.StatusServiceUnavailable)
+		return 0, false
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		s.writeJSONError(w, "Invalid review item ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// reviewErrorStatus maps review queue errors to an HTTP status
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, review.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, review.ErrResolved), errors.Is(err, review.ErrDeciding):
+		return http.StatusConflict
+	case errors.Is(err, review.ErrNotAdded):
+		return http.StatusUnprocessableEntity
+	default:
+		return requestErrorStatus(err)
+	}
+}
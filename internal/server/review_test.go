@@ -0,0 +1,310 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/services/review"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// createReviewTestServer creates a test server with a review queue holding items for the given names
+func createReviewTestServer(t *testing.T, queries ...string) (*Server, *mockPlaylistManager) {
+	t.Helper()
+	server, mockPlaylist := createTestServer()
+
+	store, err := review.Open(filepath.Join(t.TempDir(), "review.db"))
+	if err != nil {
+		t.Fatalf("review.Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	server.review = review.NewService(store, mockPlaylist, server.logger.Logger)
+
+	for _, query := range queries {
+		item := &types.ReviewItem{
+			Query:      query,
+			Artist:     types.Artist{ID: "best" + query, Name: query},
+			Confidence: 0.6,
+			PlaylistID: "playlist1",
+			Source:     types.SourceCLI,
+			SourceURL:  "https://example.com/lineup",
+		}
+		if err := store.Enqueue(item); err != nil {
+			t.Fatalf("Enqueue() unexpected error: %v", err)
+		}
+	}
+	return server, mockPlaylist
+}
+
+func TestParseReviewFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    types.ReviewFilter
+		wantErr bool
+	}{
+		{name: "empty", query: "", want: types.ReviewFilter{}},
+		{
+			name:  "all parameters",
+			query: "status=pending&playlist_id=p1&limit=10&offset=20",
+			want:  types.ReviewFilter{Status: types.ReviewPending, PlaylistID: "p1", Limit: 10, Offset: 20},
+		},
+		{name: "unknown status", query: "status=maybe", wantErr: true},
+		{name: "limit too large", query: "limit=501", wantErr: true},
+		{name: "non-numeric offset", query: "offset=ten", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseReviewFilter(query)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseReviewFilter() expected error but got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseReviewFilter() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseReviewFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleReview(t *testing.T) {
+	server, _ := createReviewTestServer(t, "First", "Second")
+	if _, err := server.review.Reject(t.Context(), 1); err != nil {
+		t.Fatalf("Reject() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		query        string
+		expectedCode int
+		wantQueries  []string
+	}{
+		{name: "all", method: http.MethodGet, expectedCode: http.StatusOK, wantQueries: []string{"First", "Second"}},
+		{name: "pending", method: http.MethodGet, query: "?status=pending", expectedCode: http.StatusOK, wantQueries: []string{"Second"}},
+		{name: "bad filter", method: http.MethodGet, query: "?status=maybe", expectedCode: http.StatusBadRequest},
+		{name: "method not allowed", method: http.MethodPost, expectedCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/review"+tt.query, nil)
+			w := httptest.NewRecorder()
+			server.handleReview(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data []types.ReviewItem `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Data) != len(tt.wantQueries) {
+				t.Fatalf("Expected %d items, got %+v", len(tt.wantQueries), response.Data)
+			}
+			for i, query := range tt.wantQueries {
+				if response.Data[i].Query != query {
+					t.Errorf("Item %d query = %q, want %q", i, response.Data[i].Query, query)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleReview_Disabled(t *testing.T) {
+	server, _ := createTestServer()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/review", nil)
+	w := httptest.NewRecorder()
+	server.handleReview(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/review/1/accept", nil)
+	req.SetPathValue("id", "1")
+	w = httptest.NewRecorder()
+	server.handleAcceptReview(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Accept expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
+
+func TestHandleReviewItem(t *testing.T) {
+	server, _ := createReviewTestServer(t, "First")
+
+	tests := []struct {
+		name         string
+		id           string
+		expectedCode int
+	}{
+		{name: "found", id: "1", expectedCode: http.StatusOK},
+		{name: "unknown", id: "9", expectedCode: http.StatusNotFound},
+		{name: "invalid", id: "abc", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/review/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			server.handleReviewItem(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleAcceptReview(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		wantArtistID string
+		wantStatus   types.ReviewStatus
+	}{
+		{name: "best candidate", expectedCode: http.StatusOK, wantArtistID: "bestFirst", wantStatus: types.ReviewAccepted},
+		{name: "empty decision", body: `{}`, expectedCode: http.StatusOK, wantArtistID: "bestFirst", wantStatus: types.ReviewAccepted},
+		{name: "remapped", body: `{"artist_id":"4Z8W4fKeB5YxbusRsdQVPb"}`, expectedCode: http.StatusOK, wantArtistID: "4Z8W4fKeB5YxbusRsdQVPb", wantStatus: types.ReviewRemapped},
+		{name: "invalid artist", body: `{"artist_id":"not-an-id"}`, expectedCode: http.StatusBadRequest},
+		{name: "unknown field", body: `{"artist":"x"}`, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mockPlaylist := createReviewTestServer(t, "First")
+			mockPlaylist.addResult = &types.AddResult{Success: true, Message: "Added"}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/review/1/accept", strings.NewReader(tt.body))
+			req.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			server.handleAcceptReview(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != http.StatusOK {
+				if mockPlaylist.lastArtistID != "" {
+					t.Errorf("Added %s for a rejected request", mockPlaylist.lastArtistID)
+				}
+				return
+			}
+
+			var response struct {
+				Success bool                   `json:"success"`
+				Data    types.ReviewResolution `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if mockPlaylist.lastArtistID != tt.wantArtistID || mockPlaylist.lastSource.URL != "https://example.com/lineup" {
+				t.Errorf("Added %s from %+v, want %s from the scraped page", mockPlaylist.lastArtistID, mockPlaylist.lastSource, tt.wantArtistID)
+			}
+			if !response.Success || response.Data.Item.Status != tt.wantStatus || response.Data.Result == nil {
+				t.Errorf("Response = %+v, want a %s item with its addition", response, tt.wantStatus)
+			}
+
+			// The item can only be decided on once
+			req = httptest.NewRequest(http.MethodPost, "/api/review/1/reject", nil)
+			req.SetPathValue("id", "1")
+			w = httptest.NewRecorder()
+			server.handleRejectReview(w, req)
+			if w.Code != http.StatusConflict {
+				t.Errorf("Reject after accept expected status %d, got %d", http.StatusConflict, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleAcceptReview_Failed(t *testing.T) {
+	server, mockPlaylist := createReviewTestServer(t, "First")
+	mockPlaylist.addError = errors.New("spotify unavailable")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/review/1/accept", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	server.handleAcceptReview(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusInternalServerError, w.Code, w.Body.String())
+	}
+	item, err := server.review.Store().Get(1)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if item.Status != types.ReviewPending {
+		t.Errorf("Status after a failed accept = %s, want pending", item.Status)
+	}
+}
+
+func TestHandleAcceptReview_NotAdded(t *testing.T) {
+	server, mockPlaylist := createReviewTestServer(t, "First")
+	mockPlaylist.addResult = &types.AddResult{Message: "Artist has no tracks available"}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/review/1/accept", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	server.handleAcceptReview(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "no tracks available") {
+		t.Errorf("Expected the reason nothing was added, got %s", w.Body.String())
+	}
+	item, err := server.review.Store().Get(1)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if item.Status != types.ReviewPending {
+		t.Errorf("Status after an accept adding nothing = %s, want pending", item.Status)
+	}
+}
+
+func TestHandleRejectReview(t *testing.T) {
+	server, mockPlaylist := createReviewTestServer(t, "First")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/review/1/reject", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	server.handleRejectReview(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if mockPlaylist.lastArtistID != "" {
+		t.Errorf("Reject added %s, want nothing added", mockPlaylist.lastArtistID)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/review/9/reject", nil)
+	req.SetPathValue("id", "9")
+	w = httptest.NewRecorder()
+	server.handleRejectReview(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Reject unknown item expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -4,16 +4,25 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/toozej/go-listen/internal/middleware"
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/services/blocklist"
+	"github.com/toozej/go-listen/internal/services/duplicate"
+	"github.com/toozej/go-listen/internal/services/history"
+	"github.com/toozej/go-listen/internal/services/jobs"
 	"github.com/toozej/go-listen/internal/services/playlist"
+	"github.com/toozej/go-listen/internal/services/review"
 	"github.com/toozej/go-listen/internal/services/scraper"
 	"github.com/toozej/go-listen/internal/services/spotify"
+	"github.com/toozej/go-listen/internal/services/watch"
 	"github.com/toozej/go-listen/internal/types"
 	"github.com/toozej/go-listen/pkg/config"
 	"github.com/toozej/go-listen/pkg/logging"
@@ -24,8 +33,11 @@ var staticFiles embed.FS
 
 // ScraperService defines the interface for web scraping operations
 type ScraperService interface {
-	ScrapeArtists(url, cssSelector string) ([]string, error)
-	ScrapeAndAddToPlaylist(url, cssSelector, playlistID string, force bool) (*scraper.ScrapeResult, error)
+	ScrapeArtists(ctx context.Context, url, cssSelector string) ([]string, error)
+	ScrapeAndAddToPlaylist(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*scraper.ScrapeResult, error)
+	ScrapeAndAddToPlaylistWithProgress(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, progress scraper.ProgressFunc) (*scraper.ScrapeResult, error)
+	PreviewScrape(ctx context.Context, url, cssSelector, playlistID string, progress scraper.ProgressFunc) (*scraper.ScrapePreview, error)
+	CommitScrape(ctx context.Context, url, playlistID string, artists []types.ApprovedArtist, mode types.AddMode, selection types.TrackSelection, source types.AddSource, progress scraper.ProgressFunc) (*scraper.ScrapeResult, error)
 }
 
 // Server represents the HTTP server
@@ -33,12 +45,22 @@ type Server struct {
 	router             *http.ServeMux
 	spotify            types.SpotifyService
 	playlist           types.PlaylistManager
+	duplicate          types.DuplicateDetector
 	scraper            ScraperService
+	searcher           types.ArtistSearcher
+	history            *history.SQLiteStore
+	aliases            *alias.Dictionary
+	blocklist          *blocklist.Blocklist
+	playlistRouter     *playlist.Router
+	review             *review.Service
+	watch              *watch.Scheduler
+	jobs               *jobs.Manager
 	config             *config.Config
 	logger             *logging.Logger
 	rateLimiter        *middleware.RateLimiter
 	securityMiddleware *middleware.SecurityMiddleware
 	loggingMiddleware  *middleware.LoggingMiddleware
+	authState          *authStateManager
 	server             *http.Server
 }
 
@@ -68,8 +90,88 @@ func NewServer(cfg *config.Config) *Server {
 	// Initialize Spotify service (core business logic)
 	spotifyService := spotify.NewService(cfg.Spotify, logger.Logger)
 
-	// Initialize playlist manager (depends on Spotify service)
-	playlistManager := playlist.NewService(spotifyService, logger.Logger)
+	// Initialize the addition history (optional, persists across restarts)
+	var historyStore *history.SQLiteStore
+	if cfg.History.Enabled {
+		store, err := history.Open(cfg.History.File)
+		if err != nil {
+			logger.WithComponent("server").WithError(err).Error("Failed to open history database, additions will not be recorded")
+		} else {
+			historyStore = store
+		}
+	}
+
+	// Initialize duplicate detection and playlist manager (depend on Spotify service)
+	duplicateDetector := duplicate.NewDuplicateService(spotifyService, logger.Logger)
+	duplicateDetector.SetSiblingPlaylists(cfg.Duplicates.SiblingPlaylists)
+	duplicateDetector.SetIndexTTL(time.Duration(cfg.Duplicates.IndexTTLSeconds) * time.Second)
+	playlistManager := playlist.NewPlaylistService(spotifyService, duplicateDetector, logger.Logger)
+	if historyStore != nil {
+		duplicateDetector.SetHistory(historyStore)
+		playlistManager.SetHistory(historyStore)
+	}
+
+	// Initialize the rules deciding which playlists are incoming playlists
+	if rules, err := newIncomingRules(cfg.Playlists); err != nil {
+		logger.WithComponent("server").WithError(err).Error("Failed to initialize incoming playlist rules")
+	} else {
+		playlistManager.SetIncomingRules(rules)
+	}
+
+	// Initialize the blocklist of artists never added to playlists
+	blocked, err := blocklist.New(cfg.Blocklist)
+	if err != nil {
+		logger.WithComponent("server").WithError(err).Error("Failed to initialize artist blocklist, no artists will be blocked")
+	} else {
+		playlistManager.SetBlocklist(blocked)
+	}
+
+	// Initialize the genre rules routing artists added to the auto playlist
+	var playlistRouter *playlist.Router
+	if cfg.Routing.Enabled() {
+		if playlistRouter, err = playlist.NewRouter(cfg.Routing, spotifyService); err != nil {
+			logger.WithComponent("server").WithError(err).Error("Failed to initialize playlist routing, automatic routing is disabled")
+		} else {
+			playlistManager.SetRouter(playlistRouter)
+		}
+	}
+
+	// Initialize the dictionary of artist names resolved without searching
+	aliases, err := alias.Open(cfg.Aliases)
+	if err != nil {
+		logger.WithComponent("server").WithError(err).Error("Failed to load artist aliases, aliases are disabled")
+	}
+
+	// Initialize the queue of scraped artists waiting for their match to be approved
+	var reviewService *review.Service
+	if cfg.Review.Enabled {
+		if err := cfg.Review.Validate(); err != nil {
+			logger.WithComponent("server").WithError(err).Error("Invalid review queue settings, the review queue is disabled")
+		} else if store, err := review.Open(cfg.Review.File); err != nil {
+			logger.WithComponent("server").WithError(err).Error("Failed to open review database, the review queue is disabled")
+		} else {
+			reviewService = review.NewService(store, playlistManager, logger.Logger)
+		}
+	}
+
+	// Initialize the pages re-scraped on their schedules; they are scraped once a scraper is set
+	var watchScheduler *watch.Scheduler
+	if cfg.Watch.Enabled {
+		if store, err := watch.Open(cfg.Watch.File); err != nil {
+			logger.WithComponent("server").WithError(err).Error("Failed to open watch database, watched sources are disabled")
+		} else {
+			watchScheduler = watch.NewScheduler(store, logger.Logger)
+			watchScheduler.SetCheckInterval(time.Duration(cfg.Watch.CheckIntervalSeconds) * time.Second)
+			watchScheduler.SetRunRetention(cfg.Watch.RunRetention)
+		}
+	}
+
+	// Initialize the worker pool running scrapes in the background
+	jobManager := jobs.NewManager(jobs.Config{
+		Workers:   cfg.Jobs.Workers,
+		QueueSize: cfg.Jobs.QueueSize,
+		Retention: time.Duration(cfg.Jobs.RetentionSeconds) * time.Second,
+	}, logger.Logger)
 
 	// Initialize rate limiter with default values if not configured
 	requestsPerSecond := cfg.Security.RateLimit.RequestsPerSecond
@@ -96,18 +198,45 @@ func NewServer(cfg *config.Config) *Server {
 		"rate_limit_burst":   burst,
 		"logging_level":      loggingCfg.Level,
 		"http_logging":       loggingCfg.EnableHTTP,
+		"oauth_pkce":         cfg.Spotify.UsePKCE,
+		"history_enabled":    historyStore != nil,
+		"aliases_enabled":    aliases != nil,
+		"blocklist_enabled":  blocked != nil,
+		"routing_enabled":    playlistRouter != nil,
+		"review_enabled":     reviewService != nil,
+		"watch_enabled":      watchScheduler != nil,
+		"sibling_playlists":  len(cfg.Duplicates.SiblingPlaylists),
+		"job_workers":        cfg.Jobs.Workers,
 	}).Info("Server components initialized successfully")
 
 	return &Server{
 		router:             http.NewServeMux(),
 		spotify:            spotifyService,
 		playlist:           playlistManager,
+		duplicate:          duplicateDetector,
+		history:            historyStore,
+		aliases:            aliases,
+		blocklist:          blocked,
+		playlistRouter:     playlistRouter,
+		review:             reviewService,
+		watch:              watchScheduler,
+		jobs:               jobManager,
 		config:             cfg,
 		logger:             logger,
 		rateLimiter:        rateLimiter,
 		securityMiddleware: securityMiddleware,
 		loggingMiddleware:  loggingMiddleware,
+		authState:          newAuthStateManager(cfg.Spotify.StateSecret, cfg.Spotify.UsePKCE),
+	}
+}
+
+// newIncomingRules creates the incoming playlist rules and their mark store from configuration
+func newIncomingRules(cfg config.PlaylistsConfig) (*playlist.IncomingRules, error) {
+	store, err := playlist.NewMarkStore(cfg)
+	if err != nil {
+		return nil, err
 	}
+	return playlist.NewIncomingRules(cfg, store)
 }
 
 // Start starts the HTTP server
@@ -128,13 +257,49 @@ func (s *Server) Start() error {
 		"write_timeout": s.config.Server.WriteTimeout,
 		"idle_timeout":  s.config.Server.IdleTimeout,
 	}).Info("Starting HTTP server")
+
+	// Scrape the watched sources on their schedules while the server runs
+	if s.watch != nil {
+		s.watch.Start()
+	}
 	return s.server.ListenAndServe()
 }
 
 // Stop gracefully stops the HTTP server
 func (s *Server) Stop(ctx context.Context) error {
 	s.logger.WithComponent("server").Info("Shutting down HTTP server")
-	return s.server.Shutdown(ctx)
+	err := s.server.Shutdown(ctx)
+
+	if s.watch != nil {
+		if stopErr := s.watch.Stop(ctx); stopErr != nil {
+			s.logger.WithComponent("server").WithError(stopErr).Error("Failed to stop watched source scheduler")
+		}
+	}
+
+	if s.jobs != nil {
+		if jobsErr := s.jobs.Shutdown(ctx); jobsErr != nil {
+			s.logger.WithComponent("server").WithError(jobsErr).Error("Failed to stop background jobs")
+		}
+	}
+
+	if s.history != nil {
+		if closeErr := s.history.Close(); closeErr != nil {
+			s.logger.WithComponent("server").WithError(closeErr).Error("Failed to close history database")
+		}
+	}
+
+	if s.review != nil {
+		if closeErr := s.review.Store().Close(); closeErr != nil {
+			s.logger.WithComponent("server").WithError(closeErr).Error("Failed to close review database")
+		}
+	}
+
+	if s.watch != nil {
+		if closeErr := s.watch.Store().Close(); closeErr != nil {
+			s.logger.WithComponent("server").WithError(closeErr).Error("Failed to close watch database")
+		}
+	}
+	return err
 }
 
 // SetScraperService sets the scraper service for the server
@@ -142,11 +307,72 @@ func (s *Server) SetScraperService(scraper ScraperService) {
 	s.scraper = scraper
 }
 
+// SetArtistSearcher sets the searcher scoring artist candidates. The playlist
+// manager uses it too, so ambiguous artist names are sent back for disambiguation.
+func (s *Server) SetArtistSearcher(searcher types.ArtistSearcher) {
+	s.searcher = searcher
+	if p, ok := s.playlist.(interface {
+		SetArtistSearcher(types.ArtistSearcher)
+	}); ok {
+		p.SetArtistSearcher(searcher)
+	}
+}
+
 // GetSpotifyService returns the server's Spotify service for reuse by other components
 func (s *Server) GetSpotifyService() types.SpotifyService {
 	return s.spotify
 }
 
+// GetHistoryStore returns the server's history store for reuse by other components, or nil if history is disabled
+func (s *Server) GetHistoryStore() types.HistoryStore {
+	if s.history == nil {
+		return nil
+	}
+	return s.history
+}
+
+// GetAliases returns the server's artist aliases for reuse by other components, or nil if they failed to load
+func (s *Server) GetAliases() types.AliasResolver {
+	if s.aliases == nil {
+		return nil
+	}
+	return s.aliases
+}
+
+// GetBlocklist returns the server's artist blocklist for reuse by other components, or nil if it failed to load
+func (s *Server) GetBlocklist() types.ArtistBlocklist {
+	if s.blocklist == nil {
+		return nil
+	}
+	return s.blocklist
+}
+
+// GetRouter returns the server's playlist router for reuse by other components, or nil if routing is disabled
+func (s *Server) GetRouter() types.PlaylistRouter {
+	if s.playlistRouter == nil {
+		return nil
+	}
+	return s.playlistRouter
+}
+
+// GetReviewQueue returns the server's review queue for reuse by other components, or nil if it is disabled
+func (s *Server) GetReviewQueue() types.ReviewQueue {
+	if s.review == nil {
+		return nil
+	}
+	return s.review.Store()
+}
+
+// GetWatchScheduler returns the server's scheduler of watched sources, or nil if they are disabled
+func (s *Server) GetWatchScheduler() *watch.Scheduler {
+	return s.watch
+}
+
+// GetDuplicateDetector returns the server's duplicate detector for reuse by other components
+func (s *Server) GetDuplicateDetector() types.DuplicateDetector {
+	return s.duplicate
+}
+
 // GetPlaylistManager returns the server's playlist manager for reuse by other components
 func (s *Server) GetPlaylistManager() types.PlaylistManager {
 	return s.playlist
@@ -170,9 +396,29 @@ func (s *Server) setupRoutes() {
 
 	// API routes
 	protectedMux.HandleFunc("/api/add-artist", s.handleAddArtist)
+	protectedMux.HandleFunc("/api/artists/search", s.handleSearchArtists)
 	protectedMux.HandleFunc("/api/playlists", s.handleGetPlaylists)
 	protectedMux.HandleFunc("/api/auth-status", s.handleAuthStatus)
 	protectedMux.HandleFunc("/api/scrape-artists", s.handleScrapeArtists)
+	protectedMux.HandleFunc("/api/scrape-artists/commit", s.handleCommitScrape)
+	protectedMux.HandleFunc("/api/history", s.handleHistory)
+	protectedMux.HandleFunc("/api/jobs/{id}", s.handleJob)
+	protectedMux.HandleFunc("/api/jobs/{id}/events", s.handleJobEvents)
+	protectedMux.HandleFunc("/api/aliases", s.handleAliases)
+	protectedMux.HandleFunc("/api/aliases/export", s.handleExportAliases)
+	protectedMux.HandleFunc("/api/aliases/import", s.handleImportAliases)
+	protectedMux.HandleFunc("/api/review", s.handleReview)
+	protectedMux.HandleFunc("/api/review/{id}", s.handleReviewItem)
+	protectedMux.HandleFunc("/api/review/{id}/accept", s.handleAcceptReview)
+	protectedMux.HandleFunc("/api/review/{id}/reject", s.handleRejectReview)
+	protectedMux.HandleFunc("/api/watch", s.handleWatch)
+	protectedMux.HandleFunc("/api/watch/{id}", s.handleWatchSource)
+	protectedMux.HandleFunc("/api/watch/{id}/run", s.handleRunWatchSource)
+	protectedMux.HandleFunc("/api/watch/{id}/runs", s.handleWatchRuns)
+
+	// Admin routes
+	protectedMux.HandleFunc("/api/admin/playlists", s.handleAdminPlaylists)
+	protectedMux.HandleFunc("/api/admin/playlists/{id}/incoming", s.handleAdminPlaylistIncoming)
 
 	// Apply middleware chain: logging -> security
 	var handler http.Handler = protectedMux
@@ -247,7 +493,7 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleGetPlaylists retrieves and filters playlists from the "Incoming" folder
+// handleGetPlaylists retrieves and filters the incoming playlists
 func (s *Server) handleGetPlaylists(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -259,11 +505,14 @@ func (s *Server) handleGetPlaylists(w http.ResponseWriter, r *http.Request) {
 		"operation": "get_playlists",
 	}).Debug("Handling playlist retrieval request")
 
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
 	// Get playlists from the playlist manager
-	playlists, err := s.playlist.GetIncomingPlaylists()
+	playlists, err := s.playlist.GetIncomingPlaylists(ctx)
 	if err != nil {
-		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to retrieve playlists")
-		s.writeJSONError(w, "Failed to retrieve playlists: "+err.Error(), http.StatusInternalServerError)
+		s.logger.WithContext(ctx).WithField("component", "server").WithError(err).Error("Failed to retrieve playlists")
+		s.writeJSONError(w, "Failed to retrieve playlists: "+err.Error(), requestErrorStatus(err))
 		return
 	}
 
@@ -321,15 +570,27 @@ func (s *Server) handleAddArtist(w http.ResponseWriter, r *http.Request) {
 	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
 		"component":   "server",
 		"artist_name": req.ArtistName,
+		"artist_id":   req.ArtistID,
 		"playlist_id": req.PlaylistID,
-		"force":       req.Force,
+		"mode":        req.AddMode(),
+		"track_count": req.TrackSelection().Count,
+		"strategy":    req.TrackSelection().Strategy,
 	}).Info("Processing add artist request")
 
-	// Add artist to playlist
-	result, err := s.playlist.AddArtistToPlaylist(req.ArtistName, req.PlaylistID, req.Force)
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	// Add artist to playlist, by ID when one was picked from the candidates of an ambiguous name
+	var result *types.AddResult
+	var err error
+	if req.ArtistID != "" {
+		result, err = s.playlist.AddArtistByID(ctx, req.ArtistID, req.PlaylistID, req.AddMode(), req.TrackSelection(), s.requestSource(ctx, r))
+	} else {
+		result, err = s.playlist.AddArtistToPlaylist(ctx, req.ArtistName, req.PlaylistID, req.AddMode(), req.TrackSelection(), s.requestSource(ctx, r))
+	}
 	if err != nil {
-		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to add artist to playlist")
-		s.writeJSONError(w, "Failed to add artist: "+err.Error(), http.StatusInternalServerError)
+		s.logger.WithContext(ctx).WithField("component", "server").WithError(err).Error("Failed to add artist to playlist")
+		s.writeJSONError(w, "Failed to add artist: "+err.Error(), requestErrorStatus(err))
 		return
 	}
 
@@ -347,14 +608,94 @@ func (s *Server) handleAddArtist(w http.ResponseWriter, r *http.Request) {
 			Success:     false,
 			Message:     result.Message,
 			IsDuplicate: true,
+			LastAdded:   result.LastAdded,
 			Data:        result,
 		}
 		s.writeJSONResponse(w, response, http.StatusOK)
+	case result.Blocked:
+		response := types.WebUIResponse{
+			Success: false,
+			Message: result.Message,
+			Data:    result,
+		}
+		s.writeJSONResponse(w, response, http.StatusOK)
+	case result.NeedsDisambiguation:
+		response := types.WebUIResponse{
+			Success:             false,
+			Message:             result.Message,
+			NeedsDisambiguation: true,
+			Data:                result,
+		}
+		s.writeJSONResponse(w, response, http.StatusOK)
 	default:
 		s.writeJSONError(w, result.Message, http.StatusBadRequest)
 	}
 }
 
+// handleSearchArtists returns the scored Spotify artists matching a name, best
+// match first, so a client can let the user pick between them
+func (s *Server) handleSearchArtists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.searcher == nil {
+		s.writeJSONError(w, "Artist search is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		s.writeJSONError(w, "query parameter q is required", http.StatusBadRequest)
+		return
+	}
+	if len(query) > 100 {
+		s.writeJSONError(w, "query too long (max 100 characters)", http.StatusBadRequest)
+		return
+	}
+
+	limit := types.DefaultCandidateLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > types.MaxCandidateLimit {
+			s.writeJSONError(w, fmt.Sprintf("limit must be between 1 and %d", types.MaxCandidateLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	candidates, err := s.searcher.FindCandidates(ctx, query, limit)
+	if err != nil {
+		if errors.Is(err, spotify.ErrNotFound) {
+			candidates = []types.ArtistCandidate{}
+		} else {
+			s.logger.WithContext(ctx).WithField("component", "server").WithError(err).Error("Failed to search for artists")
+			s.writeJSONError(w, "Failed to search for artists: "+err.Error(), requestErrorStatus(err))
+			return
+		}
+	}
+
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":       "server",
+		"operation":       "search_artists",
+		"query":           query,
+		"candidate_count": len(candidates),
+	}).Debug("Returning artist candidates")
+
+	response := types.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"candidates":           candidates,
+			"needs_disambiguation": types.NeedsDisambiguation(candidates),
+		},
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
 // handleScrapeArtists handles scraping artists from a URL and adding them to a playlist
 func (s *Server) handleScrapeArtists(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -393,28 +734,132 @@ func (s *Server) handleScrapeArtists(w http.ResponseWriter, r *http.Request) {
 		"url":          req.URL,
 		"css_selector": req.CSSSelector,
 		"playlist_id":  req.PlaylistID,
-		"force":        req.Force,
+		"mode":         req.AddMode(),
+		"track_count":  req.TrackSelection().Count,
+		"strategy":     req.TrackSelection().Strategy,
+		"dry_run":      req.DryRun,
 	}).Info("Processing scrape artists request")
 
-	// Perform scraping operation
-	result, err := s.scraper.ScrapeAndAddToPlaylist(req.URL, req.CSSSelector, req.PlaylistID, req.Force)
+	// Run the scrape in the background so it isn't bound by the request's write timeout
+	// and keeps going if the client disconnects; its logs keep the request's correlation ID
+	source := s.requestSource(r.Context(), r)
+	correlationID := logging.CorrelationID(r.Context())
+	kind, run := jobKindScrape, func(ctx context.Context, report jobs.Reporter) (any, error) {
+		ctx = logging.ContextWithCorrelationID(ctx, correlationID)
+		return s.scraper.ScrapeAndAddToPlaylistWithProgress(ctx, req.URL, req.CSSSelector, req.PlaylistID, req.AddMode(), req.TrackSelection(), source, scrapeReporter(report))
+	}
+	if req.DryRun {
+		kind, run = jobKindScrapePreview, func(ctx context.Context, report jobs.Reporter) (any, error) {
+			ctx = logging.ContextWithCorrelationID(ctx, correlationID)
+			return s.scraper.PreviewScrape(ctx, req.URL, req.CSSSelector, req.PlaylistID, scrapeReporter(report))
+		}
+	}
+	s.submitScrapeJob(w, r, kind, req.URL, run)
+}
+
+// handleCommitScrape adds the artists approved from a scrape preview to a playlist
+func (s *Server) handleCommitScrape(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.scraper == nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").Error("Scraper service not initialized")
+		s.writeJSONError(w, "Scraper service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.ScrapeCommitRequest
+	if err := s.parseJSONRequest(r, &req); err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Warn("Invalid JSON request")
+		s.writeJSONError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.validateScrapeCommitRequest(&req); err != nil {
+		s.logger.WithContext(r.Context()).WithError(err).WithFields(logrus.Fields{
+			"component":   "server",
+			"url":         req.URL,
+			"playlist_id": req.PlaylistID,
+			"artists":     len(req.Artists),
+		}).Warn("Invalid scrape commit request")
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component":   "server",
+		"url":         req.URL,
+		"playlist_id": req.PlaylistID,
+		"artists":     len(req.Artists),
+		"mode":        req.AddMode(),
+	}).Info("Processing scrape commit request")
+
+	source := s.requestSource(r.Context(), r)
+	correlationID := logging.CorrelationID(r.Context())
+	s.submitScrapeJob(w, r, jobKindScrapeCommit, req.URL, func(ctx context.Context, report jobs.Reporter) (any, error) {
+		ctx = logging.ContextWithCorrelationID(ctx, correlationID)
+		return s.scraper.CommitScrape(ctx, req.URL, req.PlaylistID, req.Artists, req.AddMode(), req.TrackSelection(), source, scrapeReporter(report))
+	})
+}
+
+// submitScrapeJob queues a scrape job and responds with the job, pointing to it with
+// the Location header
+func (s *Server) submitScrapeJob(w http.ResponseWriter, r *http.Request, kind, url string, run func(ctx context.Context, report jobs.Reporter) (any, error)) {
+	job, err := s.jobs.Submit(kind, run)
 	if err != nil {
-		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to scrape artists")
-		s.writeJSONError(w, "Failed to scrape artists: "+err.Error(), http.StatusInternalServerError)
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to queue scrape job")
+		s.writeJSONError(w, "Failed to start scrape: "+err.Error(), jobErrorStatus(err))
 		return
 	}
 
-	// Return successful response
-	response := types.ScrapeArtistsResponse{
+	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component": "server",
+		"job_id":    job.ID,
+		"job_kind":  kind,
+		"url":       url,
+	}).Info("Queued scrape job")
+
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	response := types.APIResponse{
 		Success: true,
-		Data:    result,
+		Data:    job,
 	}
+	s.writeJSONResponse(w, response, http.StatusAccepted)
+}
 
-	s.writeJSONResponse(w, response, http.StatusOK)
+// scrapeReporter reports a scrape's progress snapshots and events through its job
+func scrapeReporter(report jobs.Reporter) scraper.ProgressFunc {
+	return func(progress scraper.ScrapeProgress) {
+		report.Update(progress)
+		if progress.Event != nil {
+			report.Publish(*progress.Event)
+		}
+	}
 }
 
 // Helper methods
 
+// requestContext returns the context for the Spotify calls made while handling r.
+// It is cancelled when the client goes away, and its deadline falls shortly before
+// the server's write timeout so a slow call still leaves time to report the error.
+func (s *Server) requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	timeout := time.Duration(s.config.Server.WriteTimeout) * time.Second
+	if timeout <= 0 {
+		return context.WithCancel(r.Context())
+	}
+	return context.WithTimeout(r.Context(), timeout*9/10)
+}
+
+// requestErrorStatus maps an error from handling a request to an HTTP status
+func requestErrorStatus(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
 // parseJSONRequest parses JSON request body into the provided struct
 func (s *Server) parseJSONRequest(r *http.Request, v interface{}) error {
 	decoder := json.NewDecoder(r.Body)
@@ -424,7 +869,11 @@ func (s *Server) parseJSONRequest(r *http.Request, v interface{}) error {
 
 // validateAddArtistRequest validates the add artist request
 func (s *Server) validateAddArtistRequest(req *types.AddArtistRequest) error {
-	if strings.TrimSpace(req.ArtistName) == "" {
+	if req.ArtistID != "" {
+		if !types.IsSpotifyID(req.ArtistID) {
+			return fmt.Errorf("invalid artist ID")
+		}
+	} else if strings.TrimSpace(req.ArtistName) == "" {
 		return fmt.Errorf("artist name is required")
 	}
 	if len(req.ArtistName) > 100 {
@@ -433,7 +882,13 @@ func (s *Server) validateAddArtistRequest(req *types.AddArtistRequest) error {
 	if strings.TrimSpace(req.PlaylistID) == "" {
 		return fmt.Errorf("playlist ID is required")
 	}
-	return nil
+	if err := s.validateAutoPlaylist(req.PlaylistID); err != nil {
+		return err
+	}
+	if err := req.AddMode().Validate(); err != nil {
+		return err
+	}
+	return req.TrackSelection().Validate()
 }
 
 // validateScrapeArtistsRequest validates the scrape artists request
@@ -457,7 +912,61 @@ func (s *Server) validateScrapeArtistsRequest(req *types.ScrapeArtistsRequest) e
 	if strings.TrimSpace(req.PlaylistID) == "" {
 		return fmt.Errorf("playlist ID is required")
 	}
+	if err := s.validateAutoPlaylist(req.PlaylistID); err != nil {
+		return err
+	}
+
+	if err := req.AddMode().Validate(); err != nil {
+		return err
+	}
+
+	// Validate track count and strategy
+	return req.TrackSelection().Validate()
+}
+
+// maxApprovedArtists caps how many artists a single scrape commit adds
+const maxApprovedArtists = 200
+
+// validateScrapeCommitRequest validates the request adding the artists approved from a scrape preview
+func (s *Server) validateScrapeCommitRequest(req *types.ScrapeCommitRequest) error {
+	// The URL is only recorded as the source, but must still be a web page
+	if req.URL != "" && !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+		return fmt.Errorf("URL must start with http:// or https://")
+	}
+
+	if len(req.Artists) == 0 {
+		return fmt.Errorf("at least one artist is required")
+	}
+	if len(req.Artists) > maxApprovedArtists {
+		return fmt.Errorf("too many artists (max %d)", maxApprovedArtists)
+	}
+	for _, artist := range req.Artists {
+		if !types.IsSpotifyID(artist.ArtistID) {
+			return fmt.Errorf("invalid artist ID %q", artist.ArtistID)
+		}
+		if len(artist.Query) > 100 {
+			return fmt.Errorf("artist name too long (max 100 characters)")
+		}
+	}
+
+	if strings.TrimSpace(req.PlaylistID) == "" {
+		return fmt.Errorf("playlist ID is required")
+	}
+	if err := s.validateAutoPlaylist(req.PlaylistID); err != nil {
+		return err
+	}
+
+	if err := req.AddMode().Validate(); err != nil {
+		return err
+	}
+	return req.TrackSelection().Validate()
+}
 
+// validateAutoPlaylist rejects requests targeting the auto playlist when routing is disabled
+func (s *Server) validateAutoPlaylist(playlistID string) error {
+	if playlistID == types.AutoPlaylistID && s.playlistRouter == nil {
+		return fmt.Errorf("automatic playlist routing is not configured")
+	}
 	return nil
 }
 
@@ -491,14 +1000,21 @@ func (s *Server) writeJSONError(w http.ResponseWriter, message string, statusCod
 	s.writeJSONResponse(w, response, statusCode)
 }
 
-// handleAuth redirects to Spotify authorization
+// handleAuth starts a new login session and redirects to Spotify authorization
 func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	authURL := s.spotify.GetAuthURL()
+	session, err := s.authState.issue(w, r)
+	if err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to create login session")
+		http.Error(w, "Authentication not available", http.StatusInternalServerError)
+		return
+	}
+
+	authURL := s.spotify.GetAuthURL(session.State, session.CodeVerifier)
 	if authURL == "" {
 		http.Error(w, "Authentication not available", http.StatusInternalServerError)
 		return
@@ -507,7 +1023,7 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
 		"component": "server",
 		"operation": "auth_redirect",
-		"auth_url":  authURL,
+		"pkce":      session.CodeVerifier != "",
 	}).Info("Redirecting to Spotify authentication")
 
 	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
@@ -524,6 +1040,10 @@ func (s *Server) handleCallback(w http.ResponseWriter, r *http.Request) {
 	state := r.URL.Query().Get("state")
 	errorParam := r.URL.Query().Get("error")
 
+	// Each login session is single use, whatever the outcome of the callback
+	session, stateErr := s.authState.verify(r, state)
+	s.authState.clear(w, r)
+
 	if errorParam != "" {
 		s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
 			"component": "server",
@@ -540,13 +1060,22 @@ func (s *Server) handleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if stateErr != nil {
+		s.logger.LogSecurityEvent(r.Context(), "oauth_state_rejected", r.RemoteAddr, r.UserAgent(), stateErr.Error())
+		http.Error(w, "Authentication failed: "+stateErr.Error(), http.StatusBadRequest)
+		return
+	}
+
 	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
 		"component": "server",
 		"operation": "auth_callback",
-		"state":     state,
+		"pkce":      session.CodeVerifier != "",
 	}).Info("Processing Spotify authentication callback")
 
-	err := s.spotify.CompleteAuth(code, state)
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+
+	err := s.spotify.CompleteAuth(ctx, code, session.CodeVerifier)
 	if err != nil {
 		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to complete authentication")
 		http.Error(w, "Authentication failed: "+err.Error(), http.StatusInternalServerError)
@@ -590,7 +1119,8 @@ func (s *Server) handleAuthStatus(w http.ResponseWriter, r *http.Request) {
 	isAuthenticated := s.spotify.IsAuthenticated()
 	authURL := ""
 	if !isAuthenticated {
-		authURL = s.spotify.GetAuthURL()
+		// The login must start at /auth so a state cookie is issued for this browser
+		authURL = "/auth"
 	}
 
 	response := types.APIResponse{
@@ -6,7 +6,9 @@ class GoListenApp {
         this.playlistSelect = document.getElementById('playlist-select');
         this.addButton = document.getElementById('add-button');
         this.overrideButton = document.getElementById('override-button');
+        this.fillGapsButton = document.getElementById('fill-gaps-button');
         this.messageArea = document.getElementById('message-area');
+        this.artistCandidates = document.getElementById('artist-candidates');
         this.playerArea = document.getElementById('spotify-player');
 
         // Scraping form elements
@@ -14,16 +16,21 @@ class GoListenApp {
         this.scrapeUrlInput = document.getElementById('scrape-url');
         this.cssSelectorInput = document.getElementById('css-selector');
         this.scrapePlaylistSelect = document.getElementById('scrape-playlist-select');
+        this.scrapeModeSelect = document.getElementById('scrape-mode-select');
         this.scrapeButton = document.getElementById('scrape-button');
+        this.scrapeCancelButton = document.getElementById('scrape-cancel-button');
         this.scrapeMessageArea = document.getElementById('scrape-message-area');
         this.scrapeResults = document.getElementById('scrape-results');
+        this.scrapeLog = document.getElementById('scrape-log');
 
         // State management
         this.playlists = [];
         this.isLoading = false;
         this.isScraping = false;
+        this.scrapeJobId = null;
         this.isUpdatingDropdown = false; // Flag to prevent unwanted player updates
         this.csrfToken = null;
+        this.pickedArtist = null; // Candidate picked for an ambiguous artist name
 
         this.init();
     }
@@ -99,8 +106,16 @@ class GoListenApp {
         // Override button
         this.overrideButton.addEventListener('click', () => this.handleOverride());
 
+        // Fill gaps button
+        this.fillGapsButton.addEventListener('click', () => this.handleFillGaps());
+        this.scrapeCancelButton.addEventListener('click', () => this.cancelScrapeJob());
+
         // Artist input validation
-        this.artistInput.addEventListener('input', () => this.validateArtistInput());
+        this.artistInput.addEventListener('input', () => {
+            this.pickedArtist = null;
+            this.hideArtistCandidates();
+            this.validateArtistInput();
+        });
         this.artistInput.addEventListener('blur', () => this.validateArtistInput());
 
         // Keyboard navigation
@@ -171,6 +186,14 @@ class GoListenApp {
             }
         });
 
+        // Handle Enter key on fill gaps button
+        this.fillGapsButton.addEventListener('keydown', (e) => {
+            if (e.key === 'Enter' || e.key === ' ') {
+                e.preventDefault();
+                this.handleFillGaps();
+            }
+        });
+
         // Handle Escape key to close messages
         document.addEventListener('keydown', (e) => {
             if (e.key === 'Escape' && this.messageArea.style.display !== 'none') {
@@ -348,7 +371,7 @@ class GoListenApp {
                 this.populatePlaylistSelect(this.playlists);
 
                 if (this.playlists.length === 0) {
-                    this.showMessage('No playlists found in your "Incoming" folder. Please create some playlists first.', 'warning');
+                    this.showMessage('No incoming playlists found. Name a playlist "Incoming ...", add #incoming to its description or mark it through the admin API.', 'warning');
                 }
             } else {
                 throw new Error(data.error || 'Invalid response format');
@@ -480,7 +503,7 @@ class GoListenApp {
         const artistName = this.artistInput.value.trim();
         const playlistId = this.playlistSelect.value;
 
-        await this.addArtist(artistName, playlistId, false);
+        await this.addArtist(artistName, playlistId, 'skip');
     }
 
     async handleOverride() {
@@ -491,7 +514,30 @@ class GoListenApp {
         const artistName = this.artistInput.value.trim();
         const playlistId = this.playlistSelect.value;
 
-        await this.addArtist(artistName, playlistId, true);
+        await this.addArtist(artistName, playlistId, 'force', this.pickedArtistId(artistName));
+    }
+
+    async handleFillGaps() {
+        if (!this.validateForm()) {
+            return;
+        }
+
+        const artistName = this.artistInput.value.trim();
+        const playlistId = this.playlistSelect.value;
+
+        await this.addArtist(artistName, playlistId, 'fill_gaps', this.pickedArtistId(artistName));
+    }
+
+    // pickedArtistId returns the ID of the candidate picked for an ambiguous name, so
+    // overriding a duplicate adds that artist rather than asking again
+    pickedArtistId(artistName) {
+        return this.pickedArtist && this.pickedArtist.name === artistName ? this.pickedArtist.id : null;
+    }
+
+    showDuplicateActions(visible) {
+        const display = visible ? 'inline-block' : 'none';
+        this.overrideButton.style.display = display;
+        this.fillGapsButton.style.display = display;
     }
 
     validateForm() {
@@ -566,13 +612,15 @@ class GoListenApp {
         this.clearFieldError(this.artistInput);
         this.clearFieldError(this.playlistSelect);
         this.hideMessage();
-        this.overrideButton.style.display = 'none';
+        this.showDuplicateActions(false);
+        this.hideArtistCandidates();
         this.updateButtonState();
     }
 
-    async addArtist(artistName, playlistId, force = false) {
+    async addArtist(artistName, playlistId, mode = 'skip', artistId = null) {
         this.setLoading(true);
         this.hideMessage();
+        this.hideArtistCandidates();
 
         try {
             // Get selected playlist name for better user feedback
@@ -582,6 +630,7 @@ class GoListenApp {
             const headers = {
                 'Content-Type': 'application/json',
                 'Accept': 'application/json',
+                'X-Go-Listen-Client': 'web-ui',
             };
 
             // Add CSRF token if available
@@ -592,10 +641,14 @@ class GoListenApp {
             const response = await fetch('/api/add-artist', {
                 method: 'POST',
                 headers: headers,
-                body: JSON.stringify({
+                body: JSON.stringify(artistId ? {
+                    artist_id: artistId,
+                    playlist_id: playlistId,
+                    mode: mode
+                } : {
                     artist_name: artistName,
                     playlist_id: playlistId,
-                    force: force
+                    mode: mode
                 })
             });
 
@@ -620,10 +673,11 @@ class GoListenApp {
             if (data.success) {
                 const message = data.message || `Successfully added ${artistName} to ${playlistName}`;
                 this.showMessage(message, 'success', 5000);
-                this.overrideButton.style.display = 'none';
+                this.showDuplicateActions(false);
 
                 // Clear form on success - but keep playlist selection
                 this.artistInput.value = '';
+                this.pickedArtist = null;
                 this.updateButtonState();
 
                 // Ensure playlist dropdown remains enabled and selected
@@ -637,16 +691,32 @@ class GoListenApp {
                 // Log success for debugging
                 console.log('Artist added successfully:', data.data);
 
-            } else if (data.is_duplicate && !force) {
+            } else if (data.needs_disambiguation) {
+                const message = data.message || `Several artists match ${artistName}, choose the one to add`;
+                this.showMessage(message, 'warning');
+                this.showDuplicateActions(false);
+                this.showArtistCandidates(data.data ? data.data.candidates : [], playlistId, mode);
+
+            } else if (data.data && data.data.blocked) {
+                const message = data.message || `${artistName} is blocklisted`;
+                this.showMessage(message, 'warning');
+                this.showDuplicateActions(false);
+
+            } else if (data.is_duplicate && mode === 'skip') {
                 const message = data.message || `${artistName} may already be in ${playlistName}`;
                 this.showMessage(message, 'warning');
-                this.overrideButton.style.display = 'inline-block';
-                this.overrideButton.focus(); // Focus override button for accessibility
+                this.showDuplicateActions(true);
+                this.fillGapsButton.focus(); // Focus the least destructive option for accessibility
+
+            } else if (data.is_duplicate) {
+                const message = data.message || `${artistName}'s tracks are already in ${playlistName}`;
+                this.showMessage(message, 'warning');
+                this.fillGapsButton.style.display = 'none';
 
             } else {
                 const message = data.error || data.message || 'Failed to add artist';
                 this.showMessage(message, 'error');
-                this.overrideButton.style.display = 'none';
+                this.showDuplicateActions(false);
             }
 
         } catch (error) {
@@ -664,13 +734,95 @@ class GoListenApp {
             }
 
             this.showMessage(userMessage, 'error');
-            this.overrideButton.style.display = 'none';
+            this.showDuplicateActions(false);
 
         } finally {
             this.setLoading(false);
         }
     }
 
+    showArtistCandidates(candidates, playlistId, mode) {
+        if (!this.artistCandidates || !candidates || candidates.length === 0) {
+            return;
+        }
+
+        this.artistCandidates.innerHTML = '';
+        candidates.forEach(candidate => {
+            const element = this.createCandidateElement(candidate);
+            element.addEventListener('click', () => {
+                this.pickedArtist = candidate.artist;
+                this.addArtist(candidate.artist.name, playlistId, mode, candidate.artist.id);
+            });
+            this.artistCandidates.appendChild(element);
+        });
+        this.artistCandidates.style.display = 'block';
+
+        const first = this.artistCandidates.querySelector('button');
+        if (first) {
+            first.focus();
+        }
+    }
+
+    hideArtistCandidates() {
+        if (this.artistCandidates) {
+            this.artistCandidates.innerHTML = '';
+            this.artistCandidates.style.display = 'none';
+        }
+    }
+
+    createCandidateElement(candidate) {
+        const artist = candidate.artist;
+        const button = document.createElement('button');
+        button.type = 'button';
+        button.className = 'artist-candidate';
+
+        if (artist.image_url) {
+            const image = document.createElement('img');
+            image.className = 'artist-candidate-image';
+            image.src = artist.image_url;
+            image.alt = '';
+            image.loading = 'lazy';
+            button.appendChild(image);
+        } else {
+            const placeholder = document.createElement('div');
+            placeholder.className = 'artist-candidate-image placeholder';
+            placeholder.setAttribute('aria-hidden', 'true');
+            button.appendChild(placeholder);
+        }
+
+        const content = document.createElement('div');
+        content.className = 'artist-candidate-content';
+
+        const name = document.createElement('div');
+        name.className = 'artist-candidate-name';
+        name.textContent = artist.name;
+        content.appendChild(name);
+
+        const details = document.createElement('div');
+        details.className = 'artist-candidate-details';
+        const parts = [`${this.formatFollowers(artist.followers || 0)} followers`];
+        if (artist.genres && artist.genres.length > 0) {
+            parts.push(artist.genres.slice(0, 3).join(', '));
+        }
+        details.textContent = parts.join(' · ');
+        content.appendChild(details);
+
+        button.appendChild(content);
+        button.setAttribute('aria-label', `Add ${artist.name} (${details.textContent})`);
+
+        return button;
+    }
+
+    formatFollowers(count) {
+        if (count >= 1000000) {
+            return `${(count / 1000000).toFixed(1)}M`;
+        }
+        if (count >= 1000) {
+            return `${(count / 1000).toFixed(1)}K`;
+        }
+        return String(count);
+    }
+
     updatePlayer() {
         const selectedOption = this.playlistSelect.selectedOptions[0];
 
@@ -835,7 +987,7 @@ class GoListenApp {
 
         // Create help text
         const helpText = document.createElement('small');
-        helpText.textContent = 'Choose from your "Incoming" folder playlists above';
+        helpText.textContent = 'Choose from your incoming playlists above';
 
         placeholderDiv.appendChild(spotifyIcon);
         placeholderDiv.appendChild(message);
@@ -941,8 +1093,9 @@ class GoListenApp {
         const url = this.scrapeUrlInput.value.trim();
         const cssSelector = this.cssSelectorInput.value.trim();
         const playlistId = this.scrapePlaylistSelect.value;
+        const mode = this.scrapeModeSelect.value;
 
-        await this.scrapeAndAddArtists(url, cssSelector, playlistId);
+        await this.scrapeAndAddArtists(url, cssSelector, playlistId, mode);
     }
 
     validateScrapeForm() {
@@ -991,10 +1144,11 @@ class GoListenApp {
         }
     }
 
-    async scrapeAndAddArtists(url, cssSelector, playlistId) {
+    async scrapeAndAddArtists(url, cssSelector, playlistId, mode = 'skip') {
         this.setScrapeLoading(true);
         this.hideScrapeMessage();
         this.hideScrapeResults();
+        this.hideScrapeLog();
 
         try {
             const selectedOption = this.scrapePlaylistSelect.selectedOptions[0];
@@ -1003,6 +1157,7 @@ class GoListenApp {
             const headers = {
                 'Content-Type': 'application/json',
                 'Accept': 'application/json',
+                'X-Go-Listen-Client': 'web-ui',
             };
 
             // Add CSRF token if available
@@ -1017,7 +1172,7 @@ class GoListenApp {
                     url: url,
                     css_selector: cssSelector,
                     playlist_id: playlistId,
-                    force: false
+                    mode: mode
                 })
             });
 
@@ -1039,10 +1194,21 @@ class GoListenApp {
 
             const data = await response.json();
 
-            if (data.success && data.data) {
-                const result = data.data;
+            if (!data.success || !data.data) {
+                const message = data.error || 'Failed to scrape artists';
+                this.showScrapeMessage(message, 'error');
+                return;
+            }
+
+            // The scrape runs as a background job; follow it until it finishes
+            this.scrapeJobId = data.data.id;
+            this.showScrapeMessage('Scrape queued...', 'info');
+            const job = await this.followScrapeJob(data.data.id);
+            const result = job.result;
+
+            if (job.status === 'succeeded' && result) {
                 this.displayScrapeResults(result, playlistName);
-                
+
                 // Show success message
                 const message = `Found ${result.artists_found.length} artists, successfully added ${result.success_count} to ${playlistName}`;
                 this.showScrapeMessage(message, 'success', 5000);
@@ -1054,8 +1220,14 @@ class GoListenApp {
                 // Refresh player to show new tracks
                 this.refreshPlayer();
 
+            } else if (job.status === 'cancelled') {
+                if (result) {
+                    this.displayScrapeResults(result, playlistName);
+                    this.refreshPlayer();
+                }
+                this.showScrapeMessage(result ? result.message : 'Scrape cancelled', 'warning');
             } else {
-                const message = data.error || 'Failed to scrape artists';
+                const message = job.error || 'Failed to scrape artists';
                 this.showScrapeMessage(message, 'error');
             }
 
@@ -1076,10 +1248,181 @@ class GoListenApp {
             this.showScrapeMessage(userMessage, 'error');
 
         } finally {
+            this.scrapeJobId = null;
             this.setScrapeLoading(false);
         }
     }
 
+    // followScrapeJob streams a job's events into the live log, falling back to
+    // polling when the browser or a proxy doesn't support server-sent events
+    async followScrapeJob(jobId) {
+        if (typeof EventSource === 'undefined') {
+            return this.waitForScrapeJob(jobId);
+        }
+
+        const job = await new Promise((resolve) => {
+            const source = new EventSource(`/api/jobs/${encodeURIComponent(jobId)}/events`);
+            let processed = 0;
+
+            source.addEventListener('progress', (e) => {
+                const event = JSON.parse(e.data);
+                this.appendScrapeLogEntry(event);
+                if (event.type === 'tracks_added' || event.type === 'duplicate_skipped' || event.type === 'artist_blocked' || (event.type === 'error' && event.artist)) {
+                    processed++;
+                }
+                this.showScrapeEventProgress(event, processed);
+            });
+
+            source.addEventListener('end', (e) => {
+                source.close();
+                resolve(JSON.parse(e.data));
+            });
+
+            // The browser reconnects by itself after a dropped connection; only give
+            // up on the stream once it has closed for good
+            source.onerror = () => {
+                if (source.readyState === EventSource.CLOSED) {
+                    resolve(null);
+                }
+            };
+        });
+
+        return job || this.waitForScrapeJob(jobId);
+    }
+
+    showScrapeEventProgress(event, processed) {
+        let message;
+        switch (event.type) {
+            case 'artist_found':
+                message = 'Found artists, matching on Spotify...';
+                break;
+            case 'artist_matched':
+            case 'artist_not_matched':
+                message = 'Matching artists on Spotify...';
+                break;
+            case 'done':
+                message = event.message;
+                break;
+            default:
+                message = `Adding artists: ${processed} processed`;
+        }
+        this.showScrapeMessage(message, 'info');
+    }
+
+    appendScrapeLogEntry(event) {
+        const name = event.artist ? event.artist.name : event.query;
+        let text;
+        let status = '';
+        switch (event.type) {
+            case 'artist_found':
+                text = `Found ${event.query}`;
+                if (event.source && event.source !== 'text') {
+                    text += ` (${event.source})`;
+                }
+                break;
+            case 'artist_matched':
+                text = `Matched ${event.query} → ${name} (${Math.round(event.confidence * 100)}%)`;
+                break;
+            case 'artist_not_matched':
+                text = `No match for ${event.query}${event.message ? ': ' + event.message : ''}`;
+                status = 'error';
+                break;
+            case 'artist_blocked':
+                text = `Blocked ${name}: ${event.message}`;
+                status = 'duplicate';
+                break;
+            case 'duplicate_skipped':
+                text = `Skipped ${name}: ${event.message}`;
+                status = 'duplicate';
+                break;
+            case 'tracks_added':
+                text = `Added ${event.tracks_added} tracks by ${name}`;
+                if (event.route) {
+                    text += ` to ${event.route.playlist_name || event.route.playlist_id}`;
+                }
+                if (event.tracks_skipped > 0) {
+                    text += ` (${event.tracks_skipped} already present)`;
+                }
+                status = 'success';
+                break;
+            case 'error':
+                text = name ? `Error for ${name}: ${event.message}` : `Error: ${event.message}`;
+                status = 'error';
+                break;
+            default:
+                text = event.message;
+        }
+
+        const entry = document.createElement('li');
+        entry.className = `scrape-log-entry ${status}`.trim();
+        entry.textContent = text;
+        this.scrapeLog.appendChild(entry);
+        this.scrapeLog.classList.add('visible');
+        this.scrapeLog.scrollTop = this.scrapeLog.scrollHeight;
+    }
+
+    hideScrapeLog() {
+        this.scrapeLog.classList.remove('visible');
+        this.scrapeLog.innerHTML = '';
+    }
+
+    async waitForScrapeJob(jobId) {
+        for (;;) {
+            await new Promise(resolve => setTimeout(resolve, 1000));
+
+            const response = await fetch(`/api/jobs/${encodeURIComponent(jobId)}`, {
+                headers: { 'Accept': 'application/json' }
+            });
+            const data = await response.json();
+            if (!response.ok || !data.success) {
+                throw new Error(data.error || `HTTP ${response.status}: ${response.statusText}`);
+            }
+
+            const job = data.data;
+            if (job.status === 'succeeded' || job.status === 'failed' || job.status === 'cancelled') {
+                return job;
+            }
+            this.showScrapeProgress(job);
+        }
+    }
+
+    showScrapeProgress(job) {
+        const progress = job.progress;
+        let message = 'Scrape queued...';
+        if (job.status === 'running') {
+            if (!progress || progress.phase === 'fetching') {
+                message = 'Fetching page...';
+            } else if (progress.phase === 'matching') {
+                message = `Matching ${progress.artists_total} artists on Spotify...`;
+            } else {
+                message = `Adding artists: ${progress.artists_processed} of ${progress.artists_total} processed`;
+            }
+        }
+        this.showScrapeMessage(message, 'info');
+    }
+
+    async cancelScrapeJob() {
+        if (!this.scrapeJobId) {
+            return;
+        }
+
+        const headers = { 'Accept': 'application/json' };
+        if (this.csrfToken) {
+            headers['X-CSRF-Token'] = this.csrfToken;
+        }
+
+        this.scrapeCancelButton.disabled = true;
+        try {
+            await fetch(`/api/jobs/${encodeURIComponent(this.scrapeJobId)}`, {
+                method: 'DELETE',
+                headers: headers
+            });
+            this.showScrapeMessage('Cancelling scrape...', 'warning');
+        } catch (error) {
+            console.error('Error cancelling scrape:', error);
+        }
+    }
+
     displayScrapeResults(result, playlistName) {
         // Clear previous results
         this.scrapeResults.innerHTML = '';
@@ -1111,10 +1454,22 @@ class GoListenApp {
             stats.appendChild(duplicateStat);
         }
 
+        // Blocklisted artists, left out without counting as failures
+        if (result.blocked_count > 0) {
+            const blockedStat = this.createStatElement('Blocked', result.blocked_count, 'duplicate');
+            stats.appendChild(blockedStat);
+        }
+
         // Total tracks added
         const tracksStat = this.createStatElement('Tracks Added', result.total_tracks_added, 'success');
         stats.appendChild(tracksStat);
 
+        // Tracks left out because they were already in the playlist
+        if (result.total_tracks_skipped > 0) {
+            const skippedStat = this.createStatElement('Tracks Already Present', result.total_tracks_skipped, 'duplicate');
+            stats.appendChild(skippedStat);
+        }
+
         summary.appendChild(stats);
         this.scrapeResults.appendChild(summary);
 
@@ -1160,7 +1515,7 @@ class GoListenApp {
         let status = 'error';
         let iconPath = 'M12 2C6.48 2 2 6.48 2 12s4.48 10 10 10 10-4.48 10-10S17.52 2 12 2zm1 15h-2v-2h2v2zm0-4h-2V7h2v6z'; // Error icon
         
-        if (match.was_duplicate) {
+        if (match.was_duplicate || match.blocked) {
             status = 'duplicate';
             iconPath = 'M12 2C6.48 2 2 6.48 2 12s4.48 10 10 10 10-4.48 10-10S17.52 2 12 2zm-2 15l-5-5 1.41-1.41L10 14.17l7.59-7.59L19 8l-9 9z'; // Check icon
         } else if (match.matched && match.tracks_added > 0) {
@@ -1194,8 +1549,12 @@ class GoListenApp {
         const statusText = document.createElement('div');
         statusText.className = 'artist-result-status';
         
-        if (match.was_duplicate) {
+        if (match.blocked) {
+            statusText.textContent = `${match.error || 'Blocklisted'} (skipped)`;
+        } else if (match.was_duplicate) {
             statusText.textContent = 'Already in playlist (skipped)';
+        } else if (match.matched && match.tracks_added > 0 && match.tracks_skipped > 0) {
+            statusText.textContent = `Successfully added ${match.tracks_added} tracks (${match.tracks_skipped} already present)`;
         } else if (match.matched && match.tracks_added > 0) {
             statusText.textContent = `Successfully added ${match.tracks_added} tracks`;
         } else if (match.error) {
@@ -1207,6 +1566,13 @@ class GoListenApp {
         content.appendChild(name);
         content.appendChild(statusText);
 
+        if (match.route) {
+            const route = document.createElement('div');
+            route.className = 'artist-result-tracks';
+            route.textContent = `Playlist: ${match.route.playlist_name || match.route.playlist_id}`;
+            content.appendChild(route);
+        }
+
         if (match.confidence > 0) {
             const confidence = document.createElement('div');
             confidence.className = 'artist-result-tracks';
@@ -1258,12 +1624,15 @@ class GoListenApp {
             this.scrapeUrlInput.disabled = true;
             this.cssSelectorInput.disabled = true;
             this.scrapePlaylistSelect.disabled = true;
+            this.scrapeCancelButton.disabled = false;
+            this.scrapeCancelButton.style.display = 'inline-block';
         } else {
             this.scrapeButton.classList.remove('loading');
             this.scrapeForm.classList.remove('loading');
             this.scrapeUrlInput.disabled = false;
             this.cssSelectorInput.disabled = false;
             this.scrapePlaylistSelect.disabled = this.playlists.length === 0;
+            this.scrapeCancelButton.style.display = 'none';
         }
     }
 
@@ -1276,6 +1645,7 @@ class GoListenApp {
             this.artistInput.disabled = true;
             // Don't disable playlist select during loading to prevent graying out
             this.overrideButton.disabled = true;
+            this.fillGapsButton.disabled = true;
         } else {
             this.addButton.classList.remove('loading');
             this.form.classList.remove('loading');
@@ -1283,6 +1653,7 @@ class GoListenApp {
             // Only disable playlist select if no playlists are available
             this.playlistSelect.disabled = this.playlists.length === 0;
             this.overrideButton.disabled = false;
+            this.fillGapsButton.disabled = false;
         }
 
         this.updateButtonState();
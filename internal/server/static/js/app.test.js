@@ -595,6 +595,26 @@ describe('Scraping Functionality', () => {
         expect(status.textContent).toBe('Successfully added 5 tracks');
     });
 
+    test('should create artist candidate element', () => {
+        const candidate = {
+            artist: {
+                id: 'artist1',
+                name: 'Low',
+                genres: ['slowcore', 'indie rock'],
+                followers: 412000,
+                image_url: 'https://i.scdn.co/image/low'
+            },
+            name_score: 1.0,
+            confidence: 1.0
+        };
+
+        const element = app.createCandidateElement(candidate);
+        expect(element.className).toBe('artist-candidate');
+        expect(element.querySelector('.artist-candidate-name').textContent).toBe('Low');
+        expect(element.querySelector('.artist-candidate-details').textContent).toBe('412.0K followers · slowcore, indie rock');
+        expect(element.querySelector('img').src).toBe('https://i.scdn.co/image/low');
+    });
+
     test('should create artist result element for duplicate', () => {
         const match = {
             query: 'Test Artist',
@@ -613,6 +633,43 @@ describe('Scraping Functionality', () => {
         expect(status.textContent).toBe('Already in playlist (skipped)');
     });
 
+    test('should create artist result element for blocked artist', () => {
+        const match = {
+            query: 'Various Artists',
+            matched: true,
+            artist: { name: 'Various Artists' },
+            confidence: 1,
+            tracks_added: 0,
+            was_duplicate: false,
+            blocked: true,
+            block_reason: 'name_pattern',
+            error: 'Artist name is blocklisted'
+        };
+
+        const result = app.createArtistResultElement(match);
+        expect(result.className).toContain('duplicate');
+
+        const status = result.querySelector('.artist-result-status');
+        expect(status.textContent).toBe('Artist name is blocklisted (skipped)');
+    });
+
+    test('should show the playlist a routed artist was added to', () => {
+        const match = {
+            query: 'Test Artist',
+            matched: true,
+            artist: { name: 'Test Artist' },
+            confidence: 0.95,
+            tracks_added: 5,
+            was_duplicate: false,
+            route: { playlist_id: 'heavy123', playlist_name: 'Incoming Heavy', genre: 'metal' },
+            error: null
+        };
+
+        const result = app.createArtistResultElement(match);
+        const details = Array.from(result.querySelectorAll('.artist-result-tracks')).map(el => el.textContent);
+        expect(details).toContain('Playlist: Incoming Heavy');
+    });
+
     test('should create artist result element for error', () => {
         const match = {
             query: 'Test Artist',
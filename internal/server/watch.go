@@ -0,0 +1,351 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/jobs"
+	"github.com/toozej/go-listen/internal/services/watch"
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/logging"
+)
+
+// handleWatch lists the watched sources, or watches a new one
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.watch == nil {
+		s.writeJSONError(w, "Watched sources are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		s.addWatchSource(w, r)
+		return
+	}
+
+	sources, err := s.watch.Store().List()
+	if err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to query watched sources")
+		s.writeJSONError(w, "Failed to query watched sources", http.StatusInternalServerError)
+		return
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    sources,
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// addWatchSource watches the page described by the request body; it is first
+// scraped when the scheduler next checks for due sources
+func (s *Server) addWatchSource(w http.ResponseWriter, r *http.Request) {
+	var req types.WatchSourceRequest
+	if err := s.parseJSONRequest(r, &req); err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Warn("Invalid JSON request")
+		s.writeJSONError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if _, err := s.validateWatchSourceRequest(&req); err != nil {
+		s.logger.WithContext(r.Context()).WithError(err).WithFields(logrus.Fields{
+			"component":   "server",
+			"url":         req.URL,
+			"playlist_id": req.PlaylistID,
+			"schedule":    req.Schedule,
+		}).Warn("Invalid watch source request")
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	source := watchedSource(&req)
+	source.User = s.requestSource(r.Context(), r).User
+	if err := s.watch.Store().Add(source); err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to add watched source")
+		s.writeJSONError(w, "Failed to add watched source", http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component":   "server",
+		"operation":   "add_watch_source",
+		"source_id":   source.ID,
+		"url":         source.URL,
+		"playlist_id": source.PlaylistID,
+		"schedule":    source.Schedule,
+	}).Info("Watching source")
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    source,
+	}
+	s.writeJSONResponse(w, response, http.StatusCreated)
+}
+
+// handleWatchSource returns, changes or stops watching a single watched source
+func (s *Server) handleWatchSource(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := s.watchSourceID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		s.updateWatchSource(w, r, id)
+		return
+	case http.MethodDelete:
+		if err := s.watch.Store().Delete(id); err != nil {
+			s.writeJSONError(w, err.Error(), watchErrorStatus(err))
+			return
+		}
+		s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+			"component": "server",
+			"operation": "delete_watch_source",
+			"source_id": id,
+		}).Info("Stopped watching source")
+		response := types.APIResponse{
+			Success: true,
+			Data:    map[string]any{"id": id, "deleted": true},
+		}
+		s.writeJSONResponse(w, response, http.StatusOK)
+		return
+	}
+
+	source, err := s.watch.Store().Get(id)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), watchErrorStatus(err))
+		return
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    source,
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// updateWatchSource replaces the settings of a watched source with the request body,
+// rescheduling its next run an interval after its last one
+func (s *Server) updateWatchSource(w http.ResponseWriter, r *http.Request, id int64) {
+	var req types.WatchSourceRequest
+	if err := s.parseJSONRequest(r, &req); err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Warn("Invalid JSON request")
+		s.writeJSONError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	interval, err := s.validateWatchSourceRequest(&req)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current, err := s.watch.Store().Get(id)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), watchErrorStatus(err))
+		return
+	}
+
+	source := watchedSource(&req)
+	source.ID = id
+	source.NextRunAt = watch.NextRun(current.LastRunAt, interval, time.Now())
+	if err := s.watch.Store().Update(source); err != nil {
+		if !errors.Is(err, watch.ErrNotFound) {
+			s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to update watched source")
+		}
+		s.writeJSONError(w, err.Error(), watchErrorStatus(err))
+		return
+	}
+
+	s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"component":   "server",
+		"operation":   "update_watch_source",
+		"source_id":   id,
+		"url":         source.URL,
+		"schedule":    source.Schedule,
+		"enabled":     source.Enabled,
+		"next_run_at": source.NextRunAt,
+	}).Info("Updated watched source")
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    source,
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// handleRunWatchSource scrapes a watched source now as a background job, processing
+// only the artists not seen on it before
+func (s *Server) handleRunWatchSource(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := s.watchSourceID(w, r)
+	if !ok {
+		return
+	}
+
+	if s.scraper == nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").Error("Scraper service not initialized")
+		s.writeJSONError(w, "Scraper service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	source, err := s.watch.Store().Get(id)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), watchErrorStatus(err))
+		return
+	}
+	if s.watch.Running(id) {
+		s.writeJSONError(w, watch.ErrRunning.Error(), http.StatusConflict)
+		return
+	}
+
+	correlationID := logging.CorrelationID(r.Context())
+	s.submitScrapeJob(w, r, jobKindWatchRun, source.URL, func(ctx context.Context, report jobs.Reporter) (any, error) {
+		ctx = logging.ContextWithCorrelationID(ctx, correlationID)
+		return s.watch.Run(ctx, id, scrapeReporter(report))
+	})
+}
+
+// handleWatchRuns lists the runs of a watched source, newest first
+func (s *Server) handleWatchRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := s.watchSourceID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := parseCountParam(query, "limit")
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := parseCountParam(query, "offset")
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit > types.MaxWatchRunLimit {
+		s.writeJSONError(w, fmt.Sprintf("limit must be at most %d", types.MaxWatchRunLimit), http.StatusBadRequest)
+		return
+	}
+	if limit == 0 {
+		limit = types.DefaultWatchRunLimit
+	}
+
+	if _, err := s.watch.Store().Get(id); err != nil {
+		s.writeJSONError(w, err.Error(), watchErrorStatus(err))
+		return
+	}
+	runs, err := s.watch.Store().Runs(id, limit, offset)
+	if err != nil {
+		s.logger.WithContext(r.Context()).WithField("component", "server").WithError(err).Error("Failed to query watch runs")
+		s.writeJSONError(w, "Failed to query watch runs", http.StatusInternalServerError)
+		return
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    runs,
+	}
+	s.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// validateWatchSourceRequest validates a request to watch a page, returning how
+// often it is scraped
+func (s *Server) validateWatchSourceRequest(req *types.WatchSourceRequest) (time.Duration, error) {
+	if strings.TrimSpace(req.URL) == "" {
+		return 0, fmt.Errorf("URL is required")
+	}
+	if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+		return 0, fmt.Errorf("URL must start with http:// or https://")
+	}
+	if len(req.CSSSelector) > 500 {
+		return 0, fmt.Errorf("CSS selector too long (max 500 characters)")
+	}
+
+	if strings.TrimSpace(req.PlaylistID) == "" {
+		return 0, fmt.Errorf("playlist ID is required")
+	}
+	if err := s.validateAutoPlaylist(req.PlaylistID); err != nil {
+		return 0, err
+	}
+
+	if err := req.AddMode().Validate(); err != nil {
+		return 0, err
+	}
+	if err := req.TrackSelection().Validate(); err != nil {
+		return 0, err
+	}
+
+	// Pages are not scraped more often than the configured minimum, to be polite to their sites
+	interval, err := watch.ParseSchedule(req.Schedule)
+	if err != nil {
+		return 0, err
+	}
+	if minInterval := time.Duration(s.config.Watch.MinIntervalSeconds) * time.Second; interval < minInterval {
+		return 0, fmt.Errorf("schedule must be at least %s apart", minInterval)
+	}
+	return interval, nil
+}
+
+// watchedSource builds a watched source from a validated request
+func watchedSource(req *types.WatchSourceRequest) *types.WatchedSource {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &types.WatchedSource{
+		URL:         req.URL,
+		CSSSelector: req.CSSSelector,
+		PlaylistID:  req.PlaylistID,
+		Mode:        req.AddMode(),
+		Selection:   req.TrackSelection(),
+		Schedule:    strings.ToLower(strings.TrimSpace(req.Schedule)),
+		Enabled:     enabled,
+	}
+}
+
+// watchSourceID reads the source ID from the request path, writing the error response
+// when watched sources are disabled or the ID is invalid
+func (s *Server) watchSourceID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	if s.watch == nil {
+		s.writeJSONError(w, "Watched sources are not enabled", http.StatusServiceUnavailable)
+		return 0, false
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		s.writeJSONError(w, "Invalid watched source ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// watchErrorStatus maps watched source errors to an HTTP status
+func watchErrorStatus(err error) int {
+	if errors.Is(err, watch.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return requestErrorStatus(err)
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-listen/internal/services/jobs"
+	"github.com/toozej/go-listen/internal/services/scraper"
+	"github.com/toozej/go-listen/internal/services/watch"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+func (m *mockScraper) ScrapeNewArtists(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, seen func(name string) bool, progress scraper.ProgressFunc) (*scraper.ScrapeResult, error) {
+	result := &scraper.ScrapeResult{URL: url, ArtistsFound: m.artists, Message: "Scraping complete"}
+	for _, name := range m.artists {
+		if seen(name) {
+			result.SeenCount++
+			continue
+		}
+		result.MatchResults = append(result.MatchResults, scraper.ArtistMatchResult{Query: name, Matched: true, TracksAdded: 1})
+		result.SuccessCount++
+	}
+	return result, nil
+}
+
+// createWatchTestServer creates a test server watching sources no more often than hourly
+func createWatchTestServer(t *testing.T, mock *mockScraper) *Server {
+	t.Helper()
+	server := createJobTestServer(t, mock)
+	server.config.Watch.MinIntervalSeconds = 3600
+
+	store, err := watch.Open(filepath.Join(t.TempDir(), "watch.db"))
+	if err != nil {
+		t.Fatalf("watch.Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	server.watch = watch.NewScheduler(store, server.logger.Logger)
+	server.watch.SetScraper(mock)
+	return server
+}
+
+// addWatchSource watches a page through the API and returns the source
+func addWatchSource(t *testing.T, server *Server, body string) types.WatchedSource {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/watch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleWatch(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data types.WatchedSource `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response.Data
+}
+
+func TestHandleWatch(t *testing.T) {
+	server := createWatchTestServer(t, &mockScraper{})
+
+	source := addWatchSource(t, server, `{"url":"https://example.com/lineup","playlist_id":"playlist1","schedule":"Weekly","track_count":2}`)
+	if source.ID == 0 || source.Schedule != "weekly" || !source.Enabled || source.Selection.Count != 2 ||
+		source.Mode != types.AddModeSkip {
+		t.Errorf("Added source = %+v, want an enabled weekly source adding 2 tracks", source)
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		expectedCode int
+	}{
+		{name: "list", method: http.MethodGet, expectedCode: http.StatusOK},
+		{name: "missing schedule", method: http.MethodPost, body: `{"url":"https://example.com/lineup","playlist_id":"playlist1"}`, expectedCode: http.StatusBadRequest},
+		{name: "unknown schedule", method: http.MethodPost, body: `{"url":"https://example.com/lineup","playlist_id":"playlist1","schedule":"monthly"}`, expectedCode: http.StatusBadRequest},
+		{name: "schedule below minimum", method: http.MethodPost, body: `{"url":"https://example.com/lineup","playlist_id":"playlist1","schedule":"30m"}`, expectedCode: http.StatusBadRequest},
+		{name: "invalid URL", method: http.MethodPost, body: `{"url":"ftp://example.com","playlist_id":"playlist1","schedule":"daily"}`, expectedCode: http.StatusBadRequest},
+		{name: "auto playlist without routing", method: http.MethodPost, body: `{"url":"https://example.com/lineup","playlist_id":"auto","schedule":"daily"}`, expectedCode: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPost, body: `{"url":"https://example.com/lineup","playlist_id":"playlist1","schedule":"daily","cron":"* * * * *"}`, expectedCode: http.StatusBadRequest},
+		{name: "method not allowed", method: http.MethodDelete, expectedCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/watch", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			server.handleWatch(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if tt.method != http.MethodGet {
+				return
+			}
+
+			var response struct {
+				Data []types.WatchedSource `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Data) != 1 || response.Data[0].ID != source.ID {
+				t.Errorf("Expected only the added source, got %+v", response.Data)
+			}
+		})
+	}
+}
+
+func TestHandleWatch_Disabled(t *testing.T) {
+	server, _ := createTestServer()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/watch", nil)
+	w := httptest.NewRecorder()
+	server.handleWatch(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/watch/1/run", nil)
+	req.SetPathValue("id", "1")
+	w = httptest.NewRecorder()
+	server.handleRunWatchSource(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Run expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
+
+func TestHandleWatchSource(t *testing.T) {
+	server := createWatchTestServer(t, &mockScraper{})
+	source := addWatchSource(t, server, `{"url":"https://example.com/lineup","playlist_id":"playlist1","schedule":"weekly"}`)
+	id := strconv.FormatInt(source.ID, 10)
+
+	tests := []struct {
+		name         string
+		method       string
+		id           string
+		body         string
+		expectedCode int
+	}{
+		{name: "get", method: http.MethodGet, id: id, expectedCode: http.StatusOK},
+		{name: "invalid ID", method: http.MethodGet, id: "abc", expectedCode: http.StatusBadRequest},
+		{name: "unknown source", method: http.MethodGet, id: "999", expectedCode: http.StatusNotFound},
+		{name: "update", method: http.MethodPut, id: id, body: `{"url":"https://example.com/lineup","playlist_id":"playlist2","schedule":"daily","enabled":false}`, expectedCode: http.StatusOK},
+		{name: "invalid update", method: http.MethodPut, id: id, body: `{"url":"https://example.com/lineup","playlist_id":"playlist2","schedule":"1m"}`, expectedCode: http.StatusBadRequest},
+		{name: "update unknown source", method: http.MethodPut, id: "999", body: `{"url":"https://example.com/lineup","playlist_id":"playlist2","schedule":"daily"}`, expectedCode: http.StatusNotFound},
+		{name: "method not allowed", method: http.MethodPost, id: id, expectedCode: http.StatusMethodNotAllowed},
+		{name: "delete", method: http.MethodDelete, id: id, expectedCode: http.StatusOK},
+		{name: "delete again", method: http.MethodDelete, id: id, expectedCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/watch/"+tt.id, strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			server.handleWatchSource(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if tt.name != "update" {
+				return
+			}
+
+			// The changed settings are stored
+			updated, err := server.watch.Store().Get(source.ID)
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			if updated.PlaylistID != "playlist2" || updated.Schedule != "daily" || updated.Enabled {
+				t.Errorf("Updated source = %+v, want a disabled daily source for playlist2", updated)
+			}
+		})
+	}
+}
+
+func TestHandleRunWatchSource(t *testing.T) {
+	server := createWatchTestServer(t, &mockScraper{artists: []string{"Artist A", "Artist B"}})
+	source := addWatchSource(t, server, `{"url":"https://example.com/lineup","playlist_id":"playlist1","schedule":"weekly"}`)
+	id := strconv.FormatInt(source.ID, 10)
+
+	// runWatch scrapes the source through the API and returns the finished run
+	runWatch := func() *types.WatchRun {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/watch/"+id+"/run", nil)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		server.handleRunWatchSource(w, req)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+		}
+
+		var queued struct {
+			Data jobs.Job `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&queued); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if queued.Data.Kind != jobKindWatchRun {
+			t.Errorf("Job kind = %q, want %q", queued.Data.Kind, jobKindWatchRun)
+		}
+
+		// The job's result is the run rather than a scrape result, so it is read from the manager
+		deadline := time.Now().Add(5 * time.Second)
+		job, err := server.jobs.Get(queued.Data.ID)
+		for err == nil && job.Status != jobs.StatusSucceeded && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+			job, err = server.jobs.Get(queued.Data.ID)
+		}
+		if err != nil || job.Status != jobs.StatusSucceeded {
+			t.Fatalf("Job = %+v, %v, want it succeeded", job, err)
+		}
+		run, ok := job.Result.(*types.WatchRun)
+		if !ok {
+			t.Fatalf("Expected a watch run result, got %+v", job.Result)
+		}
+		return run
+	}
+
+	if run := runWatch(); run.Status != types.WatchRunSucceeded || len(run.NewArtists) != 2 || run.SuccessCount != 2 {
+		t.Errorf("First run = %+v, want both artists added", run)
+	}
+	if run := runWatch(); len(run.NewArtists) != 0 || run.ArtistsFound != 2 {
+		t.Errorf("Second run = %+v, want both artists found but none new", run)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/watch/"+id+"/runs?limit=1", nil)
+	req.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	server.handleWatchRuns(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response struct {
+		Data []types.WatchRun `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 1 || len(response.Data[0].NewArtists) != 0 {
+		t.Errorf("Expected only the latest run, got %+v", response.Data)
+	}
+
+	for _, tt := range []struct {
+		name         string
+		id           string
+		query        string
+		expectedCode int
+	}{
+		{name: "limit too large", id: id, query: "?limit=101", expectedCode: http.StatusBadRequest},
+		{name: "unknown source", id: "999", expectedCode: http.StatusNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/watch/"+tt.id+"/runs"+tt.query, nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			server.handleWatchRuns(w, req)
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/watch/999/run", nil)
+	req.SetPathValue("id", "999")
+	w = httptest.NewRecorder()
+	server.handleRunWatchSource(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Run of unknown source expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,261 @@
+// Package alias maintains the dictionary of artist names that always resolve to
+// a given Spotify artist, overriding the fuzzy search for names it gets wrong.
+package alias
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/toozej/go-listen/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrNotFound is returned when a name has no alias
+	ErrNotFound = errors.New("alias not found")
+	// ErrInvalid is returned for an alias without a name or with an unrecognized artist
+	ErrInvalid = errors.New("invalid alias")
+)
+
+// MaxNameLength is the longest name an alias may have, the same as the longest
+// artist name that can be searched for
+const MaxNameLength = 100
+
+// Dictionary maps artist names to the Spotify artists they stand for. Names are
+// matched ignoring case and extra whitespace.
+type Dictionary struct {
+	store Store
+
+	mu      sync.RWMutex
+	aliases map[string]types.ArtistAlias
+}
+
+// NewDictionary creates an alias dictionary, loading the existing aliases from store
+func NewDictionary(store Store) (*Dictionary, error) {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	stored, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aliases: %w", err)
+	}
+
+	aliases := make(map[string]types.ArtistAlias, len(stored))
+	for _, alias := range stored {
+		alias, err := Normalize(alias)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load aliases: %w", err)
+		}
+		aliases[Key(alias.Name)] = alias
+	}
+
+	return &Dictionary{store: store, aliases: aliases}, nil
+}
+
+// Key returns the form of a name aliases are looked up by
+func Key(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// Resolve returns the alias of a name, if it has one
+func (d *Dictionary) Resolve(name string) (types.ArtistAlias, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	alias, ok := d.aliases[Key(name)]
+	return alias, ok
+}
+
+// List returns every alias, sorted by name
+func (d *Dictionary) List() []types.ArtistAlias {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return sortedAliases(d.aliases)
+}
+
+// Set adds an alias, or replaces the alias of the same name, and persists the
+// change. It returns the alias as stored and whether it replaced an existing one.
+func (d *Dictionary) Set(alias types.ArtistAlias) (types.ArtistAlias, bool, error) {
+	alias, err := Normalize(alias)
+	if err != nil {
+		return types.ArtistAlias{}, false, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := Key(alias.Name)
+	_, replaced := d.aliases[key]
+
+	aliases := maps.Clone(d.aliases)
+	aliases[key] = alias
+	if err := d.save(aliases); err != nil {
+		return types.ArtistAlias{}, false, err
+	}
+	return alias, replaced, nil
+}
+
+// Delete removes the alias of a name and persists the change
+func (d *Dictionary) Delete(name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := Key(name)
+	if _, ok := d.aliases[key]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, strings.TrimSpace(name))
+	}
+
+	aliases := maps.Clone(d.aliases)
+	delete(aliases, key)
+	return d.save(aliases)
+}
+
+// Import adds the given aliases, replacing those with the same names, and
+// persists the change. With replace, every existing alias is removed first. No
+// alias is imported unless all of them are valid.
+func (d *Dictionary) Import(imported []types.ArtistAlias, replace bool) (int, error) {
+	normalized := make([]types.ArtistAlias, len(imported))
+	for i, alias := range imported {
+		var err error
+		if normalized[i], err = Normalize(alias); err != nil {
+			return 0, fmt.Errorf("alias %d: %w", i+1, err)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	aliases := make(map[string]types.ArtistAlias, len(d.aliases)+len(normalized))
+	if !replace {
+		maps.Copy(aliases, d.aliases)
+	}
+	for _, alias := range normalized {
+		aliases[Key(alias.Name)] = alias
+	}
+
+	if err := d.save(aliases); err != nil {
+		return 0, err
+	}
+	return len(normalized), nil
+}
+
+// save persists aliases and makes them the dictionary's aliases. The caller must hold mu.
+func (d *Dictionary) save(aliases map[string]types.ArtistAlias) error {
+	if err := d.store.Save(sortedAliases(aliases)); err != nil {
+		return fmt.Errorf("failed to save aliases: %w", err)
+	}
+	d.aliases = aliases
+	return nil
+}
+
+// sortedAliases returns the aliases in a map sorted by name
+func sortedAliases(aliases map[string]types.ArtistAlias) []types.ArtistAlias {
+	keys := slices.Sorted(maps.Keys(aliases))
+	sorted := make([]types.ArtistAlias, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, aliases[key])
+	}
+	return sorted
+}
+
+// Normalize validates an alias, trimming its names and reducing its artist,
+// which may be given as an ID, URI or open.spotify.com URL, to an ID
+func Normalize(alias types.ArtistAlias) (types.ArtistAlias, error) {
+	alias.Name = strings.Join(strings.Fields(alias.Name), " ")
+	alias.ArtistName = strings.TrimSpace(alias.ArtistName)
+
+	if alias.Name == "" {
+		return types.ArtistAlias{}, fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	if len(alias.Name) > MaxNameLength {
+		return types.ArtistAlias{}, fmt.Errorf("%w: name too long (max %d characters)", ErrInvalid, MaxNameLength)
+	}
+
+	artistID, err := ParseArtistID(alias.ArtistID)
+	if err != nil {
+		return types.ArtistAlias{}, fmt.Errorf("%w for %q: %w", ErrInvalid, alias.Name, err)
+	}
+	alias.ArtistID = artistID
+	return alias, nil
+}
+
+// ParseArtistID returns the Spotify artist ID in ref, which may be an ID, a
+// spotify:artist: URI or an open.spotify.com artist URL
+func ParseArtistID(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	id := ref
+
+	switch {
+	case ref == "":
+		return "", fmt.Errorf("artist is required")
+	case strings.HasPrefix(ref, "spotify:"):
+		var ok bool
+		if id, ok = strings.CutPrefix(ref, "spotify:artist:"); !ok {
+			return "", fmt.Errorf("%q is not a Spotify artist URI", ref)
+		}
+	case strings.Contains(ref, "/"):
+		parsed, err := url.Parse(ref)
+		if err != nil || parsed.Host != "open.spotify.com" {
+			return "", fmt.Errorf("%q is not an open.spotify.com artist URL", ref)
+		}
+		// Localized links put the locale first: /intl-de/artist/{id}
+		segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+		index := slices.Index(segments, "artist")
+		if index < 0 || index != len(segments)-2 {
+			return "", fmt.Errorf("%q is not an open.spotify.com artist URL", ref)
+		}
+		id = segments[index+1]
+	}
+
+	if !types.IsSpotifyID(id) {
+		return "", fmt.Errorf("%q is not a Spotify artist ID", id)
+	}
+	return id, nil
+}
+
+// yamlFile is the layout of an alias file imported or exported as YAML
+type yamlFile struct {
+	Aliases []types.ArtistAlias `yaml:"aliases"`
+}
+
+// WriteYAML writes aliases to w as YAML, in the layout ReadYAML reads
+func WriteYAML(w io.Writer, aliases []types.ArtistAlias) error {
+	if aliases == nil {
+		aliases = []types.ArtistAlias{}
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(yamlFile{Aliases: aliases}); err != nil {
+		return fmt.Errorf("failed to encode aliases: %w", err)
+	}
+	return encoder.Close()
+}
+
+// ReadYAML reads aliases from YAML of the form:
+//
+//	aliases:
+//	  - name: Kanye
+//	    artist_id: 5K4W6rqBFWDnAN6FQUkS6x
+//	    artist_name: Ye
+//
+// The artist_id may also be a Spotify URI or open.spotify.com URL. Aliases are
+// returned as read; they are validated when imported.
+func ReadYAML(r io.Reader) ([]types.ArtistAlias, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file yamlFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode aliases: %w", err)
+	}
+	return file.Aliases, nil
+}
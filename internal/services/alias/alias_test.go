@@ -0,0 +1,239 @@
+package alias
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+const (
+	kanyeID = "5K4W6rqBFWDnAN6FQUkS6x"
+	lowID   = "0hPLuUR2bZKwRs5l0P6ZMh"
+)
+
+func TestParseArtistID(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{name: "ID", ref: kanyeID, want: kanyeID},
+		{name: "ID with whitespace", ref: "  " + kanyeID + "\n", want: kanyeID},
+		{name: "URI", ref: "spotify:artist:" + kanyeID, want: kanyeID},
+		{name: "URL", ref: "https://open.spotify.com/artist/" + kanyeID, want: kanyeID},
+		{name: "URL with query", ref: "https://open.spotify.com/artist/" + kanyeID + "?si=abc123", want: kanyeID},
+		{name: "localized URL", ref: "https://open.spotify.com/intl-de/artist/" + kanyeID, want: kanyeID},
+		{name: "empty", ref: "", wantErr: true},
+		{name: "track URI", ref: "spotify:track:" + kanyeID, wantErr: true},
+		{name: "track URL", ref: "https://open.spotify.com/track/" + kanyeID, wantErr: true},
+		{name: "other host", ref: "https://example.com/artist/" + kanyeID, wantErr: true},
+		{name: "short ID", ref: "abc123", wantErr: true},
+		{name: "invalid characters", ref: "5K4W6rqBFWDnAN6FQUkS6-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArtistID(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseArtistID(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseArtistID(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize(types.ArtistAlias{Name: "  Kanye   West ", ArtistID: "spotify:artist:" + kanyeID, ArtistName: " Ye "})
+	if err != nil {
+		t.Fatalf("Normalize() unexpected error: %v", err)
+	}
+	want := types.ArtistAlias{Name: "Kanye West", ArtistID: kanyeID, ArtistName: "Ye"}
+	if got != want {
+		t.Errorf("Normalize() = %+v, want %+v", got, want)
+	}
+
+	for _, invalid := range []types.ArtistAlias{
+		{Name: " ", ArtistID: kanyeID},
+		{Name: strings.Repeat("a", MaxNameLength+1), ArtistID: kanyeID},
+		{Name: "Kanye", ArtistID: "nope"},
+	} {
+		if _, err := Normalize(invalid); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Normalize(%+v) error = %v, want ErrInvalid", invalid, err)
+		}
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() unexpected error: %v", err)
+	}
+	dict, err := NewDictionary(store)
+	if err != nil {
+		t.Fatalf("NewDictionary() unexpected error: %v", err)
+	}
+
+	if _, ok := dict.Resolve("Kanye"); ok {
+		t.Error("Resolve() found an alias in an empty dictionary")
+	}
+
+	if _, replaced, err := dict.Set(types.ArtistAlias{Name: "Kanye", ArtistID: kanyeID}); err != nil || replaced {
+		t.Fatalf("Set() = replaced %v, error %v, want a new alias", replaced, err)
+	}
+	if _, replaced, err := dict.Set(types.ArtistAlias{Name: "LOW", ArtistID: lowID}); err != nil || replaced {
+		t.Fatalf("Set() = replaced %v, error %v, want a new alias", replaced, err)
+	}
+	if _, replaced, err := dict.Set(types.ArtistAlias{Name: "kanye", ArtistID: kanyeID, ArtistName: "Ye"}); err != nil || !replaced {
+		t.Fatalf("Set() = replaced %v, error %v, want the alias replaced", replaced, err)
+	}
+
+	if alias, ok := dict.Resolve("  KANYE "); !ok || alias.ArtistID != kanyeID || alias.ArtistName != "Ye" {
+		t.Errorf("Resolve() = %+v, %v, want the replaced alias", alias, ok)
+	}
+
+	// A new dictionary over the same file sees the saved aliases
+	reloaded, err := NewDictionary(store)
+	if err != nil {
+		t.Fatalf("NewDictionary() unexpected error: %v", err)
+	}
+	list := reloaded.List()
+	if len(list) != 2 || list[0].Name != "kanye" || list[1].Name != "LOW" {
+		t.Errorf("List() after reload = %+v, want kanye then LOW", list)
+	}
+
+	if err := reloaded.Delete("Low"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if err := reloaded.Delete("Low"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() of a missing alias error = %v, want ErrNotFound", err)
+	}
+	if _, ok := reloaded.Resolve("low"); ok {
+		t.Error("Resolve() found a deleted alias")
+	}
+}
+
+func TestDictionary_Import(t *testing.T) {
+	dict, err := NewDictionary(NewMemoryStore())
+	if err != nil {
+		t.Fatalf("NewDictionary() unexpected error: %v", err)
+	}
+	if _, _, err := dict.Set(types.ArtistAlias{Name: "Low", ArtistID: lowID}); err != nil {
+		t.Fatalf("Set() unexpected error: %v", err)
+	}
+
+	// An invalid alias stops the whole import
+	_, err = dict.Import([]types.ArtistAlias{
+		{Name: "Kanye", ArtistID: kanyeID},
+		{Name: "Broken", ArtistID: "nope"},
+	}, false)
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Import() error = %v, want ErrInvalid", err)
+	}
+	if _, ok := dict.Resolve("Kanye"); ok {
+		t.Error("Import() kept aliases from a failed import")
+	}
+
+	if count, err := dict.Import([]types.ArtistAlias{{Name: "Kanye", ArtistID: kanyeID}}, false); err != nil || count != 1 {
+		t.Fatalf("Import() = %d, %v, want 1 alias imported", count, err)
+	}
+	if len(dict.List()) != 2 {
+		t.Errorf("Import() without replace left %+v, want both aliases", dict.List())
+	}
+
+	if _, err := dict.Import([]types.ArtistAlias{{Name: "Ye", ArtistID: kanyeID}}, true); err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	if list := dict.List(); len(list) != 1 || list[0].Name != "Ye" {
+		t.Errorf("Import() with replace left %+v, want only Ye", list)
+	}
+}
+
+func TestNewDictionary_InvalidStoredAlias(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"Kanye","artist_id":"nope"}]`), 0o600); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() unexpected error: %v", err)
+	}
+
+	if _, err := NewDictionary(store); !errors.Is(err, ErrInvalid) {
+		t.Errorf("NewDictionary() error = %v, want ErrInvalid", err)
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	aliases := []types.ArtistAlias{
+		{Name: "Kanye", ArtistID: kanyeID, ArtistName: "Ye"},
+		{Name: "Low", ArtistID: lowID},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, aliases); err != nil {
+		t.Fatalf("WriteYAML() unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "aliases:\n  - name: Kanye\n") {
+		t.Errorf("WriteYAML() wrote unexpected layout:\n%s", buf.String())
+	}
+
+	read, err := ReadYAML(&buf)
+	if err != nil {
+		t.Fatalf("ReadYAML() unexpected error: %v", err)
+	}
+	if len(read) != 2 || read[0] != aliases[0] || read[1] != aliases[1] {
+		t.Errorf("ReadYAML() = %+v, want %+v", read, aliases)
+	}
+}
+
+func TestReadYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: 0},
+		{name: "no aliases", input: "aliases: []\n", want: 0},
+		{name: "URI artist", input: "aliases:\n  - name: Kanye\n    artist_id: spotify:artist:" + kanyeID + "\n", want: 1},
+		{name: "unknown field", input: "aliases:\n  - name: Kanye\n    artist: " + kanyeID + "\n", wantErr: true},
+		{name: "malformed", input: "aliases: [", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadYAML(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ReadYAML() returned %d aliases, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	if _, err := NewStore(config.AliasesConfig{Store: "memory"}); err != nil {
+		t.Errorf("NewStore(memory) unexpected error: %v", err)
+	}
+	if _, err := NewStore(config.AliasesConfig{Store: "file", File: filepath.Join(t.TempDir(), "aliases.json")}); err != nil {
+		t.Errorf("NewStore(file) unexpected error: %v", err)
+	}
+	if _, err := NewStore(config.AliasesConfig{Store: "file"}); err == nil {
+		t.Error("NewStore(file) expected error without a file path")
+	}
+	if _, err := NewStore(config.AliasesConfig{Store: "redis"}); err == nil {
+		t.Error("NewStore(redis) expected error for an unknown store")
+	}
+}
@@ -0,0 +1,128 @@
+package alias
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/toozej/go-listen/internal/fileutil"
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+// Store persists the alias dictionary
+type Store interface {
+	// Load returns all stored aliases, or none if none have been saved
+	Load() ([]types.ArtistAlias, error)
+	// Save replaces the stored aliases
+	Save(aliases []types.ArtistAlias) error
+}
+
+// NewStore creates the alias store selected by the aliases configuration.
+// An empty store type falls back to an in-memory store.
+func NewStore(cfg config.AliasesConfig) (Store, error) {
+	switch strings.ToLower(cfg.Store) {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "file":
+		return NewFileStore(cfg.File)
+	default:
+		return nil, fmt.Errorf("unknown alias store type: %s", cfg.Store)
+	}
+}
+
+// Open creates the alias dictionary persisted in the store selected by the aliases configuration
+func Open(cfg config.AliasesConfig) (*Dictionary, error) {
+	store, err := NewStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewDictionary(store)
+}
+
+// MemoryStore keeps aliases in process memory only
+type MemoryStore struct {
+	mu      sync.RWMutex
+	aliases []types.ArtistAlias
+}
+
+// NewMemoryStore creates an empty in-memory alias store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load returns a copy of the stored aliases
+func (m *MemoryStore) Load() ([]types.ArtistAlias, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Clone(m.aliases), nil
+}
+
+// Save stores a copy of the aliases
+func (m *MemoryStore) Save(aliases []types.ArtistAlias) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.aliases = slices.Clone(aliases)
+	return nil
+}
+
+// FileStore persists aliases to a JSON file
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore creates a file-backed alias store at path
+func NewFileStore(path string) (*FileStore, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("alias file path is required")
+	}
+	return &FileStore{path: filepath.Clean(path)}, nil
+}
+
+// Load reads the alias file, treating a missing file as no aliases
+func (f *FileStore) Load() ([]types.ArtistAlias, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read alias file: %w", err)
+	}
+
+	var aliases []types.ArtistAlias
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to decode alias file: %w", err)
+	}
+	return aliases, nil
+}
+
+// Save atomically replaces the alias file
+func (f *FileStore) Save(aliases []types.ArtistAlias) error {
+	if aliases == nil {
+		aliases = []types.ArtistAlias{}
+	}
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode aliases: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := fileutil.WriteAtomic(f.path, data); err != nil {
+		return fmt.Errorf("failed to save alias file: %w", err)
+	}
+
+	return nil
+}
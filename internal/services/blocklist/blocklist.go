@@ -0,0 +1,72 @@
+// Package blocklist decides which artists are never added to playlists, such as
+// "Various Artists" or sponsor names picked up by the scraper.
+package blocklist
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+// Blocklist blocks artists by Spotify ID, name pattern or genre
+type Blocklist struct {
+	ids         map[string]bool
+	namePattern *regexp.Regexp
+	genres      map[string]bool
+}
+
+// New creates a blocklist from configuration
+func New(cfg config.BlocklistConfig) (*Blocklist, error) {
+	b := &Blocklist{
+		ids:    make(map[string]bool),
+		genres: make(map[string]bool),
+	}
+
+	for _, id := range cfg.ArtistIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			b.ids[id] = true
+		}
+	}
+
+	for _, genre := range cfg.Genres {
+		if genre = normalizeGenre(genre); genre != "" {
+			b.genres[genre] = true
+		}
+	}
+
+	if cfg.NamePattern != "" {
+		pattern, err := regexp.Compile(cfg.NamePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blocklist name pattern: %w", err)
+		}
+		b.namePattern = pattern
+	}
+
+	return b, nil
+}
+
+// Blocked reports whether an artist is blocked and the rule that blocked it.
+// The artist ID is checked first, then the name pattern, then the genres.
+func (b *Blocklist) Blocked(artist types.Artist) (bool, types.BlockReason) {
+	switch {
+	case b.ids[artist.ID]:
+		return true, types.BlockReasonArtistID
+	case b.namePattern != nil && b.namePattern.MatchString(artist.Name):
+		return true, types.BlockReasonNamePattern
+	}
+
+	for _, genre := range artist.Genres {
+		if b.genres[normalizeGenre(genre)] {
+			return true, types.BlockReasonGenre
+		}
+	}
+	return false, ""
+}
+
+// normalizeGenre lowercases a genre and trims its surrounding whitespace
+func normalizeGenre(genre string) string {
+	return strings.ToLower(strings.TrimSpace(genre))
+}
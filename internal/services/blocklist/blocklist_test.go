@@ -0,0 +1,82 @@
+package blocklist
+
+import (
+	"testing"
+
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+func TestBlocklist_Blocked(t *testing.T) {
+	blocklist, err := New(config.BlocklistConfig{
+		ArtistIDs:   []string{"sponsor123", " "},
+		NamePattern: "(?i)^various artists$",
+		Genres:      []string{" Children's Music ", "sleep"},
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		artist      types.Artist
+		wantBlocked bool
+		wantReason  types.BlockReason
+	}{
+		{
+			name:        "blocked ID",
+			artist:      types.Artist{ID: "sponsor123", Name: "Energy Drink", Genres: []string{"sleep"}},
+			wantBlocked: true,
+			wantReason:  types.BlockReasonArtistID,
+		},
+		{
+			name:        "name pattern",
+			artist:      types.Artist{ID: "various", Name: "VARIOUS ARTISTS"},
+			wantBlocked: true,
+			wantReason:  types.BlockReasonNamePattern,
+		},
+		{
+			name:        "genre ignores case",
+			artist:      types.Artist{ID: "kids", Name: "Kids Band", Genres: []string{"pop", "children's music"}},
+			wantBlocked: true,
+			wantReason:  types.BlockReasonGenre,
+		},
+		{
+			name:   "partial name",
+			artist: types.Artist{ID: "tribute", Name: "Various Artists Tribute Band"},
+		},
+		{
+			name:   "partial genre",
+			artist: types.Artist{ID: "sleepy", Name: "Sleepy Sun", Genres: []string{"sleep rock"}},
+		},
+		{
+			name:   "allowed",
+			artist: types.Artist{ID: "radiohead", Name: "Radiohead", Genres: []string{"alternative rock"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked, reason := blocklist.Blocked(tt.artist)
+			if blocked != tt.wantBlocked || reason != tt.wantReason {
+				t.Errorf("Blocked(%+v) = %v, %q, want %v, %q", tt.artist, blocked, reason, tt.wantBlocked, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestNew_Empty(t *testing.T) {
+	blocklist, err := New(config.BlocklistConfig{})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if blocked, _ := blocklist.Blocked(types.Artist{ID: "any", Name: "Various Artists"}); blocked {
+		t.Error("Blocked() blocked an artist with an empty blocklist")
+	}
+}
+
+func TestNew_InvalidNamePattern(t *testing.T) {
+	if _, err := New(config.BlocklistConfig{NamePattern: "[unclosed"}); err == nil {
+		t.Error("New() expected error for an invalid name pattern")
+	}
+}
@@ -1,32 +1,70 @@
 package duplicate
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/logging"
 )
 
 // DuplicateService implements the DuplicateDetector interface
 type DuplicateService struct {
 	spotify types.SpotifyService
-	logger  *log.Logger
+	history types.HistoryStore
+	indexes *indexCache
+	logger  *logging.Logger
+
+	mu       sync.RWMutex
+	siblings []string
 }
 
 // NewDuplicateService creates a new duplicate detection service
 func NewDuplicateService(spotify types.SpotifyService, logger *log.Logger) *DuplicateService {
 	return &DuplicateService{
 		spotify: spotify,
-		logger:  logger,
+		indexes: newIndexCache(defaultIndexTTL),
+		logger:  logging.Wrap(logger),
+	}
+}
+
+// SetSiblingPlaylists sets the playlists that are also searched when checking
+// whether an artist is already present
+func (d *DuplicateService) SetSiblingPlaylists(playlistIDs []string) {
+	siblings := make([]string, 0, len(playlistIDs))
+	for _, id := range playlistIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			siblings = append(siblings, id)
+		}
 	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.siblings = siblings
+}
+
+// SetIndexTTL sets how long a playlist's index is reused; zero disables caching
+func (d *DuplicateService) SetIndexTTL(ttl time.Duration) {
+	d.indexes.setTTL(ttl)
 }
 
-// CheckDuplicates checks if any of the provided tracks already exist in the playlist
-func (d *DuplicateService) CheckDuplicates(playlistID string, tracks []types.Track) (*types.DuplicateResult, error) {
+// SetHistory sets the store used to report when an artist was last added to a playlist
+func (d *DuplicateService) SetHistory(history types.HistoryStore) {
+	d.history = history
+}
+
+// CheckDuplicates checks which of the provided tracks are already in the playlist.
+// A track counts as present when the playlist holds the same Spotify track, the same
+// recording (by ISRC) from another release, or a track with the same normalized title
+// and primary artist.
+func (d *DuplicateService) CheckDuplicates(ctx context.Context, playlistID string, tracks []types.Track) (*types.DuplicateResult, error) {
 	if len(tracks) == 0 {
-		d.logger.WithFields(log.Fields{
+		d.logger.WithContext(ctx).WithFields(log.Fields{
 			"component":   "duplicate_service",
 			"operation":   "check_duplicates",
 			"playlist_id": playlistID,
@@ -37,40 +75,46 @@ func (d *DuplicateService) CheckDuplicates(playlistID string, tracks []types.Tra
 		}, nil
 	}
 
-	d.logger.WithFields(log.Fields{
+	d.logger.WithContext(ctx).WithFields(log.Fields{
 		"component":   "duplicate_service",
 		"operation":   "check_duplicates",
 		"playlist_id": playlistID,
 		"track_count": len(tracks),
 	}).Debug("Checking for duplicate tracks in playlist")
 
-	// Extract track IDs for batch checking
-	trackIDs := make([]string, len(tracks))
-	trackNames := make([]string, len(tracks))
-	for i, track := range tracks {
-		trackIDs[i] = track.ID
-		trackNames[i] = track.Name
-	}
-
-	// Check which tracks exist in the playlist
-	existsResults, err := d.spotify.CheckTracksInPlaylist(playlistID, trackIDs)
+	index, err := d.indexFor(ctx, playlistID)
 	if err != nil {
-		d.logger.WithError(err).WithFields(log.Fields{
+		d.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
 			"component":   "duplicate_service",
 			"operation":   "check_duplicates",
 			"playlist_id": playlistID,
 			"track_count": len(tracks),
-		}).Error("Failed to check tracks in playlist")
-		return nil, fmt.Errorf("failed to check tracks in playlist: %w", err)
+		}).Error("Failed to get playlist tracks for duplicate check")
+		return nil, fmt.Errorf("failed to get playlist tracks: %w", err)
 	}
 
 	// Collect duplicate tracks
 	var duplicateTracks []types.Track
 	var duplicateTrackNames []string
-	for i, exists := range existsResults {
-		if exists && i < len(tracks) {
-			duplicateTracks = append(duplicateTracks, tracks[i])
-			duplicateTrackNames = append(duplicateTrackNames, tracks[i].Name)
+	matchedBy := make(map[string]matchKind)
+	for _, track := range tracks {
+		existing, kind := index.match(track)
+		if kind == matchNone {
+			continue
+		}
+		duplicateTracks = append(duplicateTracks, track)
+		duplicateTrackNames = append(duplicateTrackNames, track.Name)
+		matchedBy[track.Name] = kind
+
+		if kind != matchID {
+			d.logger.WithContext(ctx).WithFields(log.Fields{
+				"component":   "duplicate_service",
+				"operation":   "check_duplicates",
+				"playlist_id": playlistID,
+				"track_id":    track.ID,
+				"existing_id": existing.ID,
+				"matched_by":  kind,
+			}).Debug("Track matches a different release already in playlist")
 		}
 	}
 
@@ -79,24 +123,24 @@ func (d *DuplicateService) CheckDuplicates(playlistID string, tracks []types.Tra
 	result := &types.DuplicateResult{
 		HasDuplicates:   hasDuplicates,
 		DuplicateTracks: duplicateTracks,
-		LastAdded:       time.Now(), // This would ideally come from playlist metadata
 	}
 
 	if hasDuplicates {
 		result.Message = fmt.Sprintf("Found %d duplicate track(s): %s",
 			len(duplicateTracks), strings.Join(duplicateTrackNames, ", "))
 
-		d.logger.WithFields(log.Fields{
+		d.logger.WithContext(ctx).WithFields(log.Fields{
 			"component":            "duplicate_service",
 			"operation":            "check_duplicates",
 			"playlist_id":          playlistID,
 			"duplicate_count":      len(duplicateTracks),
 			"duplicate_tracks":     duplicateTrackNames,
+			"matched_by":           matchedBy,
 			"total_tracks_checked": len(tracks),
 		}).Info("Duplicate tracks detected")
 	} else {
 		result.Message = "No duplicate tracks found"
-		d.logger.WithFields(log.Fields{
+		d.logger.WithContext(ctx).WithFields(log.Fields{
 			"component":            "duplicate_service",
 			"operation":            "check_duplicates",
 			"playlist_id":          playlistID,
@@ -107,82 +151,174 @@ func (d *DuplicateService) CheckDuplicates(playlistID string, tracks []types.Tra
 	return result, nil
 }
 
-// CheckArtistInPlaylist checks if an artist's tracks already exist in the playlist
-func (d *DuplicateService) CheckArtistInPlaylist(playlistID, artistID string) (*types.DuplicateResult, error) {
-	d.logger.WithFields(log.Fields{
-		"component":   "duplicate_service",
-		"operation":   "check_artist_duplicates",
-		"playlist_id": playlistID,
-		"artist_id":   artistID,
-	}).Debug("Checking if artist tracks exist in playlist")
+// CheckArtistInPlaylist checks if any track in the playlist, or in one of the
+// sibling playlists, credits the artist. Every track is scanned rather than the
+// artist's current top tracks, so older additions are found after the top tracks change.
+func (d *DuplicateService) CheckArtistInPlaylist(ctx context.Context, playlistID, artistID string) (*types.DuplicateResult, error) {
+	playlistIDs := d.playlistsToCheck(playlistID)
 
-	// Get the artist's top tracks
-	tracks, err := d.spotify.GetArtistTopTracks(artistID)
-	if err != nil {
-		d.logger.WithError(err).WithFields(log.Fields{
-			"component":   "duplicate_service",
-			"operation":   "check_artist_duplicates",
-			"artist_id":   artistID,
-			"playlist_id": playlistID,
-		}).Error("Failed to get artist top tracks for duplicate check")
-		return nil, fmt.Errorf("failed to get artist top tracks: %w", err)
+	d.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":      "duplicate_service",
+		"operation":      "check_artist_duplicates",
+		"playlist_id":    playlistID,
+		"artist_id":      artistID,
+		"playlist_count": len(playlistIDs),
+	}).Debug("Checking if artist has tracks in playlists")
+
+	var (
+		duplicateTracks []types.Track
+		foundIn         []string
+		targetCount     int
+		artistName      string
+	)
+	for i, id := range playlistIDs {
+		index, err := d.indexFor(ctx, id)
+		if err != nil {
+			if i == 0 {
+				d.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
+					"component":   "duplicate_service",
+					"operation":   "check_artist_duplicates",
+					"artist_id":   artistID,
+					"playlist_id": playlistID,
+				}).Error("Failed to get playlist tracks for duplicate check")
+				return nil, fmt.Errorf("failed to get playlist tracks: %w", err)
+			}
+			// A missing sibling shouldn't block additions to the target playlist
+			d.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
+				"component":   "duplicate_service",
+				"operation":   "check_artist_duplicates",
+				"artist_id":   artistID,
+				"playlist_id": id,
+			}).Warn("Failed to get sibling playlist tracks, skipping it")
+			continue
+		}
+
+		tracks := index.artistTracks[artistID]
+		if len(tracks) == 0 {
+			continue
+		}
+		if i == 0 {
+			targetCount = len(tracks)
+		}
+		if artistName == "" {
+			artistName = index.artistNames[artistID]
+		}
+		duplicateTracks = append(duplicateTracks, tracks...)
+		foundIn = append(foundIn, id)
 	}
 
-	if len(tracks) == 0 {
-		d.logger.WithFields(log.Fields{
-			"component":   "duplicate_service",
-			"operation":   "check_artist_duplicates",
-			"artist_id":   artistID,
-			"playlist_id": playlistID,
-		}).Debug("Artist has no tracks to check for duplicates")
-		return &types.DuplicateResult{
-			HasDuplicates: false,
-			Message:       "Artist has no tracks",
-		}, nil
+	result := &types.DuplicateResult{
+		HasDuplicates:   len(duplicateTracks) > 0,
+		DuplicateTracks: duplicateTracks,
+		ArtistName:      artistName,
+		PlaylistIDs:     foundIn,
 	}
 
-	// Get artist name from the first track for better messaging
-	var artistName string
-	if len(tracks) > 0 && len(tracks[0].Artists) > 0 {
-		artistName = tracks[0].Artists[0].Name
+	if !result.HasDuplicates {
+		result.Message = "No tracks by this artist found in the playlist, safe to add"
+		d.logger.WithContext(ctx).WithFields(log.Fields{
+			"component":      "duplicate_service",
+			"operation":      "check_artist_duplicates",
+			"artist_id":      artistID,
+			"playlist_id":    playlistID,
+			"has_duplicates": false,
+		}).Debug("Artist not found in playlists")
+		return result, nil
 	}
 
-	// Use CheckDuplicates to do the actual checking
-	result, err := d.CheckDuplicates(playlistID, tracks)
+	result.LastAdded = d.lastAdded(ctx, playlistID, artistID)
+	switch {
+	case targetCount > 0 && result.LastAdded != nil:
+		result.Message = fmt.Sprintf("Artist '%s' already has %d track(s) in this playlist (last added: %s). Use 'Add Anyway' to override.",
+			artistName, targetCount, result.LastAdded.Local().Format("2006-01-02 15:04:05"))
+	case targetCount > 0:
+		result.Message = fmt.Sprintf("Artist '%s' already has %d track(s) in this playlist. Use 'Add Anyway' to override.",
+			artistName, targetCount)
+	default:
+		result.Message = fmt.Sprintf("Artist '%s' already has %d track(s) in %d sibling playlist(s). Use 'Add Anyway' to override.",
+			artistName, len(duplicateTracks), len(foundIn))
+	}
+
+	d.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":       "duplicate_service",
+		"operation":       "check_artist_duplicates",
+		"artist_name":     artistName,
+		"artist_id":       artistID,
+		"playlist_id":     playlistID,
+		"found_in":        foundIn,
+		"duplicate_count": len(duplicateTracks),
+		"last_added":      result.LastAdded,
+		"has_duplicates":  true,
+	}).Info("Artist tracks already exist in playlist")
+
+	return result, nil
+}
+
+// InvalidatePlaylist drops the cached index of a playlist, e.g. after tracks were added to it
+func (d *DuplicateService) InvalidatePlaylist(playlistID string) {
+	d.indexes.invalidate(playlistID)
+}
+
+// playlistsToCheck returns the target playlist followed by its siblings, without repeats
+func (d *DuplicateService) playlistsToCheck(playlistID string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	playlistIDs := []string{playlistID}
+	for _, sibling := range d.siblings {
+		if !slices.Contains(playlistIDs, sibling) {
+			playlistIDs = append(playlistIDs, sibling)
+		}
+	}
+	return playlistIDs
+}
+
+// indexFor returns the index of a playlist, building it from the playlist's tracks
+// when it isn't cached
+func (d *DuplicateService) indexFor(ctx context.Context, playlistID string) (*playlistIndex, error) {
+	if index := d.indexes.get(playlistID); index != nil {
+		return index, nil
+	}
+
+	tracks, err := d.spotify.GetPlaylistTracks(ctx, playlistID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Enhance the result with artist-specific information
-	if result != nil {
-		result.ArtistName = artistName
-
-		if result.HasDuplicates {
-			result.Message = fmt.Sprintf("Artist '%s' already has %d track(s) in this playlist (last added: %s). Use 'Add Anyway' to override.",
-				artistName, len(result.DuplicateTracks), result.LastAdded.Format("2006-01-02 15:04:05"))
-
-			d.logger.WithFields(log.Fields{
-				"component":       "duplicate_service",
-				"operation":       "check_artist_duplicates",
-				"artist_name":     artistName,
-				"artist_id":       artistID,
-				"playlist_id":     playlistID,
-				"duplicate_count": len(result.DuplicateTracks),
-				"last_added":      result.LastAdded,
-				"has_duplicates":  true,
-			}).Info("Artist tracks already exist in playlist")
-		} else {
-			result.Message = fmt.Sprintf("Artist '%s' tracks not found in playlist, safe to add", artistName)
-			d.logger.WithFields(log.Fields{
-				"component":      "duplicate_service",
-				"operation":      "check_artist_duplicates",
-				"artist_name":    artistName,
-				"artist_id":      artistID,
-				"playlist_id":    playlistID,
-				"has_duplicates": false,
-			}).Debug("Artist tracks not found in playlist")
-		}
+	index := newPlaylistIndex(tracks, d.indexes.now())
+	d.indexes.put(playlistID, index)
+
+	d.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":    "duplicate_service",
+		"operation":    "index_playlist",
+		"playlist_id":  playlistID,
+		"track_count":  len(tracks),
+		"artist_count": len(index.artistTracks),
+		"isrc_count":   len(index.isrcs),
+	}).Debug("Indexed playlist tracks")
+
+	return index, nil
+}
+
+// lastAdded returns when the artist was last added to the playlist according to
+// history, or nil if there is no history or the artist was added outside go-listen
+func (d *DuplicateService) lastAdded(ctx context.Context, playlistID, artistID string) *time.Time {
+	if d.history == nil {
+		return nil
 	}
 
-	return result, nil
+	entry, err := d.history.LastAdded(playlistID, artistID)
+	if err != nil {
+		d.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
+			"component":   "duplicate_service",
+			"operation":   "last_added",
+			"artist_id":   artistID,
+			"playlist_id": playlistID,
+		}).Warn("Failed to look up artist history")
+		return nil
+	}
+	if entry == nil {
+		return nil
+	}
+	return &entry.AddedAt
 }
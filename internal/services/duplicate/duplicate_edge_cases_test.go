@@ -1,6 +1,7 @@
 package duplicate
 
 import (
+	"context"
 	"errors"
 	"runtime"
 	"strings"
@@ -56,21 +57,20 @@ func testLargeTrackLists(t *testing.T) {
 	// Create a large number of tracks to test performance and memory usage
 	trackCount := 1000
 	tracks := make([]types.Track, trackCount)
-	trackIDs := make([]string, trackCount)
-	checkResults := make([]bool, trackCount)
+	present := make([]bool, trackCount)
 
 	for i := 0; i < trackCount; i++ {
 		tracks[i] = types.Track{
 			ID:   "track" + string(rune(i)),
 			Name: "Song " + string(rune(i)),
 		}
-		trackIDs[i] = tracks[i].ID
-		checkResults[i] = i%2 == 0 // Every other track is a duplicate
+		present[i] = i%2 == 0 // Every other track is a duplicate
 	}
 
 	mockSpotify := &MockSpotifyService{
-		checkResults:     checkResults,
-		expectedTrackIDs: trackIDs,
+		playlistTracks: map[string][]types.Track{
+			"playlist123": presentTracks(tracks, present),
+		},
 	}
 
 	logger := log.New()
@@ -79,7 +79,7 @@ func testLargeTrackLists(t *testing.T) {
 	service := NewDuplicateService(mockSpotify, logger)
 
 	start := time.Now()
-	result, err := service.CheckDuplicates("playlist123", tracks)
+	result, err := service.CheckDuplicates(context.Background(), "playlist123", tracks)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -105,11 +105,9 @@ func testLargeTrackLists(t *testing.T) {
 
 func testConcurrentAccess(t *testing.T) {
 	mockSpotify := &MockSpotifyService{
-		tracks: []types.Track{
-			{ID: "track1", Name: "Song 1"},
-			{ID: "track2", Name: "Song 2"},
+		playlistTracks: map[string][]types.Track{
+			"playlist" + string(rune(1)): {{ID: "track" + string(rune(1)), Name: "Song 1"}},
 		},
-		checkResults: []bool{true, false},
 	}
 
 	logger := log.New()
@@ -129,7 +127,7 @@ func testConcurrentAccess(t *testing.T) {
 				{ID: "track" + string(rune(id)), Name: "Song " + string(rune(id))},
 			}
 
-			_, err := service.CheckDuplicates("playlist"+string(rune(id)), tracks)
+			_, err := service.CheckDuplicates(context.Background(), "playlist"+string(rune(id)), tracks)
 			if err != nil {
 				errs <- err
 			}
@@ -151,10 +149,9 @@ func testConcurrentAccess(t *testing.T) {
 func testMemoryEfficiency(t *testing.T) {
 	// Test that the service doesn't leak memory with repeated operations
 	mockSpotify := &MockSpotifyService{
-		tracks: []types.Track{
-			{ID: "track1", Name: "Song 1"},
+		playlistTracks: map[string][]types.Track{
+			"playlist": {{ID: "track1", Name: "Song 1"}},
 		},
-		checkResults: []bool{true},
 	}
 
 	logger := log.New()
@@ -168,7 +165,7 @@ func testMemoryEfficiency(t *testing.T) {
 			{ID: "track" + string(rune(i)), Name: "Song " + string(rune(i))},
 		}
 
-		result, err := service.CheckDuplicates("playlist", tracks)
+		result, err := service.CheckDuplicates(context.Background(), "playlist", tracks)
 		if err != nil {
 			t.Errorf("Error in iteration %d: %v", i, err)
 		}
@@ -193,8 +190,9 @@ func testUnicodeHandling(t *testing.T) {
 	}
 
 	mockSpotify := &MockSpotifyService{
-		checkResults:     []bool{true, false, true, false},
-		expectedTrackIDs: []string{"track1", "track2", "track3", "track4"},
+		playlistTracks: map[string][]types.Track{
+			"playlist123": presentTracks(unicodeTracks, []bool{true, false, true, false}),
+		},
 	}
 
 	logger := log.New()
@@ -202,7 +200,7 @@ func testUnicodeHandling(t *testing.T) {
 
 	service := NewDuplicateService(mockSpotify, logger)
 
-	result, err := service.CheckDuplicates("playlist123", unicodeTracks)
+	result, err := service.CheckDuplicates(context.Background(), "playlist123", unicodeTracks)
 	if err != nil {
 		t.Errorf("Unexpected error with unicode tracks: %v", err)
 	}
@@ -223,10 +221,9 @@ func testUnicodeHandling(t *testing.T) {
 
 func testTimestampAccuracy(t *testing.T) {
 	mockSpotify := &MockSpotifyService{
-		tracks: []types.Track{
-			{ID: "track1", Name: "Song 1"},
+		playlistTracks: map[string][]types.Track{
+			"playlist123": artistTracks("artist1", "Artist", "track1"),
 		},
-		checkResults: []bool{true},
 	}
 
 	logger := log.New()
@@ -234,28 +231,62 @@ func testTimestampAccuracy(t *testing.T) {
 
 	service := NewDuplicateService(mockSpotify, logger)
 
-	beforeCall := time.Now()
-	result, err := service.CheckDuplicates("playlist123", mockSpotify.tracks)
-	afterCall := time.Now()
-
+	// Without history the time of the last addition is unknown
+	result, err := service.CheckArtistInPlaylist(context.Background(), "playlist123", "artist1")
 	if err != nil {
-		t.Errorf("Unexpected error: %v", err)
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.LastAdded != nil {
+		t.Errorf("Expected no LastAdded without history, got %v", result.LastAdded)
 	}
 
-	if result == nil {
-		t.Fatal("Expected result but got nil")
+	// With history the time comes from the recorded addition, not the check
+	addedAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	service.SetHistory(&fakeHistory{entries: map[string]*types.HistoryEntry{
+		"playlist123/artist1": {ArtistID: "artist1", PlaylistID: "playlist123", AddedAt: addedAt},
+	}})
+
+	result, err = service.CheckArtistInPlaylist(context.Background(), "playlist123", "artist1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.LastAdded == nil || !result.LastAdded.Equal(addedAt) {
+		t.Errorf("Expected LastAdded %v, got %v", addedAt, result.LastAdded)
+	}
+	if !strings.Contains(result.Message, "last added: "+addedAt.Local().Format("2006-01-02 15:04:05")) {
+		t.Errorf("Expected last added time in message, got '%s'", result.Message)
 	}
 
-	// Check that timestamp is within reasonable bounds
-	if result.LastAdded.Before(beforeCall) || result.LastAdded.After(afterCall) {
-		t.Errorf("Timestamp %v is not within expected range %v - %v",
-			result.LastAdded, beforeCall, afterCall)
+	// A history lookup failure degrades to an unknown time
+	service.SetHistory(&fakeHistory{err: errors.New("database locked")})
+	result, err = service.CheckArtistInPlaylist(context.Background(), "playlist123", "artist1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
 	}
+	if result.LastAdded != nil {
+		t.Errorf("Expected no LastAdded when history fails, got %v", result.LastAdded)
+	}
+}
+
+// fakeHistory is an in-memory types.HistoryStore keyed by "playlistID/artistID"
+type fakeHistory struct {
+	entries map[string]*types.HistoryEntry
+	err     error
+}
+
+func (f *fakeHistory) Record(entry *types.HistoryEntry) error {
+	return f.err
+}
 
-	// Check timestamp precision (should be within 1 second)
-	if time.Since(result.LastAdded) > time.Second {
-		t.Errorf("Timestamp is too old: %v", result.LastAdded)
+func (f *fakeHistory) LastAdded(playlistID, artistID string) (*types.HistoryEntry, error) {
+	if f.err != nil {
+		return nil, f.err
 	}
+	return f.entries[playlistID+"/"+artistID], nil
+}
+
+func (f *fakeHistory) List(filter types.HistoryFilter) ([]types.HistoryEntry, error) {
+	return nil, f.err
 }
 
 // TestDuplicateService_ErrorRecovery tests error recovery scenarios
@@ -295,8 +326,8 @@ func TestDuplicateService_ErrorRecovery(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockSpotify := &MockSpotifyService{
-				tracks:     []types.Track{{ID: "track1", Name: "Song 1"}},
-				checkError: tt.mockError,
+				tracks:         []types.Track{{ID: "track1", Name: "Song 1"}},
+				playlistErrors: map[string]error{"playlist123": tt.mockError},
 			}
 
 			logger := log.New()
@@ -304,7 +335,7 @@ func TestDuplicateService_ErrorRecovery(t *testing.T) {
 
 			service := NewDuplicateService(mockSpotify, logger)
 
-			result, err := service.CheckDuplicates("playlist123", mockSpotify.tracks)
+			result, err := service.CheckDuplicates(context.Background(), "playlist123", mockSpotify.tracks)
 
 			if tt.expectError {
 				if err == nil {
@@ -333,7 +364,7 @@ func TestDuplicateService_MessageFormatting(t *testing.T) {
 	tests := []struct {
 		name            string
 		tracks          []types.Track
-		checkResults    []bool
+		present         []bool
 		expectedMessage string
 		messageContains []string
 	}{
@@ -342,7 +373,7 @@ func TestDuplicateService_MessageFormatting(t *testing.T) {
 			tracks: []types.Track{
 				{ID: "track1", Name: "Song 1"},
 			},
-			checkResults:    []bool{true},
+			present:         []bool{true},
 			expectedMessage: "Found 1 duplicate track(s): Song 1",
 		},
 		{
@@ -352,7 +383,7 @@ func TestDuplicateService_MessageFormatting(t *testing.T) {
 				{ID: "track2", Name: "Song 2"},
 				{ID: "track3", Name: "Song 3"},
 			},
-			checkResults:    []bool{true, false, true},
+			present:         []bool{true, false, true},
 			messageContains: []string{"Found 2 duplicate track(s)", "Song 1", "Song 3"},
 		},
 		{
@@ -360,7 +391,7 @@ func TestDuplicateService_MessageFormatting(t *testing.T) {
 			tracks: []types.Track{
 				{ID: "track1", Name: "This is a very long song name that might cause formatting issues"},
 			},
-			checkResults:    []bool{true},
+			present:         []bool{true},
 			messageContains: []string{"Found 1 duplicate track(s)", "This is a very long song name"},
 		},
 		{
@@ -369,21 +400,17 @@ func TestDuplicateService_MessageFormatting(t *testing.T) {
 				{ID: "track1", Name: "Song with \"quotes\" and 'apostrophes'"},
 				{ID: "track2", Name: "Song with & ampersand"},
 			},
-			checkResults:    []bool{true, true},
+			present:         []bool{true, true},
 			messageContains: []string{"Found 2 duplicate track(s)", "quotes", "ampersand"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			trackIDs := make([]string, len(tt.tracks))
-			for i, track := range tt.tracks {
-				trackIDs[i] = track.ID
-			}
-
 			mockSpotify := &MockSpotifyService{
-				checkResults:     tt.checkResults,
-				expectedTrackIDs: trackIDs,
+				playlistTracks: map[string][]types.Track{
+					"playlist123": presentTracks(tt.tracks, tt.present),
+				},
 			}
 
 			logger := log.New()
@@ -391,7 +418,7 @@ func TestDuplicateService_MessageFormatting(t *testing.T) {
 
 			service := NewDuplicateService(mockSpotify, logger)
 
-			result, err := service.CheckDuplicates("playlist123", tt.tracks)
+			result, err := service.CheckDuplicates(context.Background(), "playlist123", tt.tracks)
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
@@ -423,21 +450,20 @@ func TestDuplicateService_PerformanceMetrics(t *testing.T) {
 	for _, size := range sizes {
 		t.Run("size_"+string(rune(size)), func(t *testing.T) {
 			tracks := make([]types.Track, size)
-			trackIDs := make([]string, size)
-			checkResults := make([]bool, size)
+			present := make([]bool, size)
 
 			for i := 0; i < size; i++ {
 				tracks[i] = types.Track{
 					ID:   "track" + string(rune(i)),
 					Name: "Song " + string(rune(i)),
 				}
-				trackIDs[i] = tracks[i].ID
-				checkResults[i] = i%3 == 0 // Every third track is a duplicate
+				present[i] = i%3 == 0 // Every third track is a duplicate
 			}
 
 			mockSpotify := &MockSpotifyService{
-				checkResults:     checkResults,
-				expectedTrackIDs: trackIDs,
+				playlistTracks: map[string][]types.Track{
+					"playlist123": presentTracks(tracks, present),
+				},
 			}
 
 			logger := log.New()
@@ -446,7 +472,7 @@ func TestDuplicateService_PerformanceMetrics(t *testing.T) {
 			service := NewDuplicateService(mockSpotify, logger)
 
 			start := time.Now()
-			result, err := service.CheckDuplicates("playlist123", tracks)
+			result, err := service.CheckDuplicates(context.Background(), "playlist123", tracks)
 			duration := time.Since(start)
 
 			if err != nil {
@@ -473,3 +499,166 @@ func TestDuplicateService_PerformanceMetrics(t *testing.T) {
 		})
 	}
 }
+
+// TestDuplicateService_CrossReleaseMatching tests that the same recording is recognised
+// across singles, albums and reissues that carry different Spotify track IDs
+func TestDuplicateService_CrossReleaseMatching(t *testing.T) {
+	artist := []types.Artist{{ID: "artist1", Name: "The Band"}}
+	otherArtist := []types.Artist{{ID: "artist2", Name: "Someone Else"}}
+
+	tests := []struct {
+		name           string
+		playlist       []types.Track
+		candidate      types.Track
+		expectedMatch  bool
+		expectedReason matchKind
+	}{
+		{
+			name:           "same track ID",
+			playlist:       []types.Track{{ID: "single1", Name: "Song", Artists: artist}},
+			candidate:      types.Track{ID: "single1", Name: "Song", Artists: artist},
+			expectedMatch:  true,
+			expectedReason: matchID,
+		},
+		{
+			name:           "album version of a single by ISRC",
+			playlist:       []types.Track{{ID: "single1", Name: "Song", ISRC: "USABC1234567", Artists: artist}},
+			candidate:      types.Track{ID: "album1", Name: "Song", ISRC: "USABC1234567", Artists: artist},
+			expectedMatch:  true,
+			expectedReason: matchISRC,
+		},
+		{
+			name:           "deluxe edition with a renamed title by ISRC",
+			playlist:       []types.Track{{ID: "album1", Name: "Song", ISRC: "USABC1234567", Artists: artist}},
+			candidate:      types.Track{ID: "deluxe1", Name: "Song - Bonus Version", ISRC: "USABC1234567", Artists: artist},
+			expectedMatch:  true,
+			expectedReason: matchISRC,
+		},
+		{
+			name:           "hyphenated lowercase ISRC",
+			playlist:       []types.Track{{ID: "single1", Name: "Song", ISRC: "US-ABC-12-34567", Artists: artist}},
+			candidate:      types.Track{ID: "album1", Name: "Song", ISRC: "usabc1234567", Artists: artist},
+			expectedMatch:  true,
+			expectedReason: matchISRC,
+		},
+		{
+			name:           "remaster suffix without ISRC",
+			playlist:       []types.Track{{ID: "album1", Name: "Song", Artists: artist}},
+			candidate:      types.Track{ID: "remaster1", Name: "Song - 2011 Remaster", Artists: artist},
+			expectedMatch:  true,
+			expectedReason: matchTitle,
+		},
+		{
+			name:           "deluxe edition label without ISRC",
+			playlist:       []types.Track{{ID: "album1", Name: "Song", Artists: artist}},
+			candidate:      types.Track{ID: "deluxe1", Name: "Song (Deluxe Edition)", Artists: artist},
+			expectedMatch:  true,
+			expectedReason: matchTitle,
+		},
+		{
+			name:           "featured artist credit and punctuation without ISRC",
+			playlist:       []types.Track{{ID: "album1", Name: "Don't Stop", Artists: artist}},
+			candidate:      types.Track{ID: "single1", Name: "DON'T STOP (feat. Guest)", Artists: artist},
+			expectedMatch:  true,
+			expectedReason: matchTitle,
+		},
+		{
+			name:           "title fallback by artist name when IDs are missing",
+			playlist:       []types.Track{{ID: "album1", Name: "Song", Artists: []types.Artist{{Name: "The Band"}}}},
+			candidate:      types.Track{ID: "single1", Name: "Song", Artists: []types.Artist{{Name: "the band"}}},
+			expectedMatch:  true,
+			expectedReason: matchTitle,
+		},
+		{
+			name:      "live version with its own ISRC",
+			playlist:  []types.Track{{ID: "album1", Name: "Song", ISRC: "USABC1234567", Artists: artist}},
+			candidate: types.Track{ID: "live1", Name: "Song - Live", ISRC: "USABC7654321", Artists: artist},
+		},
+		{
+			name:      "same title with a different ISRC",
+			playlist:  []types.Track{{ID: "album1", Name: "Intro", ISRC: "USABC1234567", Artists: artist}},
+			candidate: types.Track{ID: "album2", Name: "Intro", ISRC: "USABC7654321", Artists: artist},
+		},
+		{
+			name:      "re-recording with its own ISRC",
+			playlist:  []types.Track{{ID: "album1", Name: "Song", ISRC: "USABC1234567", Artists: artist}},
+			candidate: types.Track{ID: "rerecord1", Name: "Song (Taylor's Version)", ISRC: "USXYZ2100001", Artists: artist},
+		},
+		{
+			name:           "same title where only one track has an ISRC",
+			playlist:       []types.Track{{ID: "album1", Name: "Song", ISRC: "USABC1234567", Artists: artist}},
+			candidate:      types.Track{ID: "single1", Name: "Song - 2011 Remaster", Artists: artist},
+			expectedMatch:  true,
+			expectedReason: matchTitle,
+		},
+		{
+			name: "same title where another playlist track lacks an ISRC",
+			playlist: []types.Track{
+				{ID: "album1", Name: "Intro", ISRC: "USABC1234567", Artists: artist},
+				{ID: "album2", Name: "Intro", Artists: artist},
+			},
+			candidate:      types.Track{ID: "album3", Name: "Intro", ISRC: "USABC7654321", Artists: artist},
+			expectedMatch:  true,
+			expectedReason: matchTitle,
+		},
+		{
+			name:      "live version is a different recording",
+			playlist:  []types.Track{{ID: "album1", Name: "Song", Artists: artist}},
+			candidate: types.Track{ID: "live1", Name: "Song (Live at Wembley)", Artists: artist},
+		},
+		{
+			name:      "remix is a different recording",
+			playlist:  []types.Track{{ID: "album1", Name: "Song", Artists: artist}},
+			candidate: types.Track{ID: "remix1", Name: "Song - Club Remix", Artists: artist},
+		},
+		{
+			name:      "acoustic version is a different recording",
+			playlist:  []types.Track{{ID: "album1", Name: "Song", Artists: artist}},
+			candidate: types.Track{ID: "acoustic1", Name: "Song (Acoustic)", Artists: artist},
+		},
+		{
+			name:      "same title by a different artist",
+			playlist:  []types.Track{{ID: "album1", Name: "Song", Artists: artist}},
+			candidate: types.Track{ID: "cover1", Name: "Song", Artists: otherArtist},
+		},
+		{
+			name:      "candidate without artists",
+			playlist:  []types.Track{{ID: "album1", Name: "Song", Artists: artist}},
+			candidate: types.Track{ID: "single1", Name: "Song"},
+		},
+		{
+			name:      "playlist track without artists",
+			playlist:  []types.Track{{ID: "album1", Name: "Song"}},
+			candidate: types.Track{ID: "single1", Name: "Song", Artists: artist},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSpotify := &MockSpotifyService{
+				playlistTracks: map[string][]types.Track{"playlist123": tt.playlist},
+			}
+
+			logger := log.New()
+			logger.SetLevel(log.FatalLevel)
+
+			service := NewDuplicateService(mockSpotify, logger)
+
+			result, err := service.CheckDuplicates(context.Background(), "playlist123", []types.Track{tt.candidate})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.HasDuplicates != tt.expectedMatch {
+				t.Errorf("Expected HasDuplicates %v, got %v (%s)", tt.expectedMatch, result.HasDuplicates, result.Message)
+			}
+
+			index, err := service.indexFor(context.Background(), "playlist123")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, kind := index.match(tt.candidate); kind != tt.expectedReason {
+				t.Errorf("Expected match by %q, got %q", tt.expectedReason, kind)
+			}
+		})
+	}
+}
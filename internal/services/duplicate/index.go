@@ -0,0 +1,140 @@
+package duplicate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// defaultIndexTTL is how long a playlist's index is reused when no TTL is configured
+const defaultIndexTTL = 5 * time.Minute
+
+// playlistIndex indexes a single playlist's tracks by the artists they credit and
+// by every key a candidate track can be matched on
+type playlistIndex struct {
+	artistTracks map[string][]types.Track
+	artistNames  map[string]string
+	ids          map[string]types.Track
+	isrcs        map[string]types.Track
+	titles       map[string][]types.Track
+	builtAt      time.Time
+}
+
+// newPlaylistIndex indexes the playlist's tracks
+func newPlaylistIndex(tracks []types.Track, builtAt time.Time) *playlistIndex {
+	index := &playlistIndex{
+		artistTracks: make(map[string][]types.Track),
+		artistNames:  make(map[string]string),
+		ids:          make(map[string]types.Track, len(tracks)),
+		isrcs:        make(map[string]types.Track, len(tracks)),
+		titles:       make(map[string][]types.Track, len(tracks)),
+		builtAt:      builtAt,
+	}
+	for _, track := range tracks {
+		for _, artist := range track.Artists {
+			if artist.ID == "" {
+				continue
+			}
+			index.artistTracks[artist.ID] = append(index.artistTracks[artist.ID], track)
+			if _, ok := index.artistNames[artist.ID]; !ok {
+				index.artistNames[artist.ID] = artist.Name
+			}
+		}
+		if track.ID != "" {
+			index.ids[track.ID] = track
+		}
+		if isrc := normalizeISRC(track.ISRC); isrc != "" {
+			index.isrcs[isrc] = track
+		}
+		if key := titleKey(track); key != "" {
+			index.titles[key] = append(index.titles[key], track)
+		}
+	}
+	return index
+}
+
+// match returns the playlist track a candidate duplicates and how it was matched.
+// Track IDs are compared first, then ISRCs, then normalized titles and artists. Titles
+// only match when either track lacks an ISRC: two different ISRCs are two recordings,
+// such as an "Intro" on two albums or a re-recording, however alike their titles.
+func (i *playlistIndex) match(track types.Track) (types.Track, matchKind) {
+	if existing, ok := i.ids[track.ID]; ok && track.ID != "" {
+		return existing, matchID
+	}
+	if isrc := normalizeISRC(track.ISRC); isrc != "" {
+		if existing, ok := i.isrcs[isrc]; ok {
+			return existing, matchISRC
+		}
+	}
+	if key := titleKey(track); key != "" {
+		isrc := normalizeISRC(track.ISRC)
+		for _, existing := range i.titles[key] {
+			if isrc == "" || normalizeISRC(existing.ISRC) == "" {
+				return existing, matchTitle
+			}
+		}
+	}
+	return types.Track{}, matchNone
+}
+
+// indexCache holds the index of each playlist until it expires or is invalidated
+type indexCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	indexes map[string]*playlistIndex
+	now     func() time.Time
+}
+
+// newIndexCache creates an empty cache; a ttl of zero or less disables caching
+func newIndexCache(ttl time.Duration) *indexCache {
+	return &indexCache{
+		ttl:     ttl,
+		indexes: make(map[string]*playlistIndex),
+		now:     time.Now,
+	}
+}
+
+// get returns the cached index for a playlist, or nil if there is none or it has expired
+func (c *indexCache) get(playlistID string) *playlistIndex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index, ok := c.indexes[playlistID]
+	if !ok {
+		return nil
+	}
+	if c.ttl <= 0 || c.now().Sub(index.builtAt) >= c.ttl {
+		delete(c.indexes, playlistID)
+		return nil
+	}
+	return index
+}
+
+// put stores a playlist's index
+func (c *indexCache) put(playlistID string, index *playlistIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+	c.indexes[playlistID] = index
+}
+
+// invalidate drops a playlist's index so the next check rebuilds it
+func (c *indexCache) invalidate(playlistID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.indexes, playlistID)
+}
+
+// setTTL changes the cache lifetime and drops every cached index
+func (c *indexCache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttl = ttl
+	c.indexes = make(map[string]*playlistIndex)
+}
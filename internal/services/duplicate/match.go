@@ -0,0 +1,96 @@
+package duplicate
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// matchKind records how a track was recognised as already being in a playlist
+type matchKind string
+
+const (
+	matchNone matchKind = ""
+	// matchID means the playlist holds the same Spotify track
+	matchID matchKind = "id"
+	// matchISRC means the playlist holds the same recording from another release
+	matchISRC matchKind = "isrc"
+	// matchTitle means the playlist holds a track with the same normalized title and primary artist
+	matchTitle matchKind = "title"
+)
+
+var (
+	// bracketedPattern matches a parenthesised or bracketed part of a title
+	bracketedPattern = regexp.MustCompile(`[(\[]([^)\]]*)[)\]]`)
+	// dashSuffixPattern matches a " - ..." suffix of a title
+	dashSuffixPattern = regexp.MustCompile(`\s+-\s+(.*)$`)
+	// featuringPattern matches a featured artist credit written into a title
+	featuringPattern = regexp.MustCompile(`(?i)^\s*(feat\.?|ft\.?|featuring|with)\s`)
+	// trailingFeaturingPattern matches an unbracketed featured artist credit at the end of a title
+	trailingFeaturingPattern = regexp.MustCompile(`(?i)\s(feat\.?|ft\.?|featuring)\s.*$`)
+	// releaseVersionPattern matches version labels that name a release rather than a
+	// different recording. Live, acoustic, remix and edit versions are deliberately
+	// left out since they are different recordings.
+	releaseVersionPattern = regexp.MustCompile(`(?i)remaster|deluxe|edition|bonus track|album version|explicit`)
+)
+
+// normalizeISRC uppercases an ISRC and drops the hyphens some sources format it with
+func normalizeISRC(isrc string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(isrc), "-", ""))
+}
+
+// titleKey returns the fallback key a track is matched on: its normalized title and
+// its primary artist. Tracks without a title or artist have no key.
+func titleKey(track types.Track) string {
+	if len(track.Artists) == 0 {
+		return ""
+	}
+	title := normalizeTitle(track.Name)
+	if title == "" {
+		return ""
+	}
+
+	artist := track.Artists[0].ID
+	if artist == "" {
+		artist = normalizeText(track.Artists[0].Name)
+	}
+	if artist == "" {
+		return ""
+	}
+	return title + "\x00" + artist
+}
+
+// normalizeTitle strips featured artist credits and release labels such as
+// "(Remastered 2011)" or "- Deluxe Edition" from a title, then normalizes case,
+// punctuation and whitespace
+func normalizeTitle(title string) string {
+	title = bracketedPattern.ReplaceAllStringFunc(title, func(part string) string {
+		inner := bracketedPattern.FindStringSubmatch(part)[1]
+		if featuringPattern.MatchString(inner) || releaseVersionPattern.MatchString(inner) {
+			return " "
+		}
+		return part
+	})
+
+	if suffix := dashSuffixPattern.FindStringSubmatch(title); suffix != nil {
+		if featuringPattern.MatchString(suffix[1]) || releaseVersionPattern.MatchString(suffix[1]) {
+			title = title[:len(title)-len(suffix[0])]
+		}
+	}
+
+	title = trailingFeaturingPattern.ReplaceAllString(title, "")
+	return normalizeText(title)
+}
+
+// normalizeText lowercases text, replaces punctuation with spaces and collapses whitespace
+func normalizeText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}
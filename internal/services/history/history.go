@@ -0,0 +1,243 @@
+// Package history records every addition of an artist's tracks to a playlist
+// in a local SQLite database, using a pure-Go driver so builds need no cgo.
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/toozej/go-listen/internal/types"
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// migrations upgrade the schema in order; the index of the last applied
+// migration plus one is stored in the database's user_version
+var migrations = []string{
+	`CREATE TABLE additions (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		artist_id   TEXT    NOT NULL,
+		artist_name TEXT    NOT NULL,
+		playlist_id TEXT    NOT NULL,
+		tracks      TEXT    NOT NULL,
+		source      TEXT    NOT NULL,
+		source_url  TEXT    NOT NULL DEFAULT '',
+		user_id     TEXT    NOT NULL DEFAULT '',
+		added_at    INTEGER NOT NULL
+	);
+	CREATE INDEX additions_playlist_artist ON additions (playlist_id, artist_id, added_at);
+	CREATE INDEX additions_added_at ON additions (added_at);`,
+}
+
+// SQLiteStore implements types.HistoryStore on a SQLite database
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// Open opens or creates the history database at path and migrates it to the latest schema
+func Open(path string) (*SQLiteStore, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("history database path is required")
+	}
+
+	path = filepath.Clean(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// The busy timeout lets the CLI and server share the database
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids lock contention within the process
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteStore{db: db}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// migrate applies any migrations newer than the database's schema version
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read history schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("history database schema version %d is newer than supported version %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to migrate history database: %w", err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply history migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply history migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to apply history migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Record stores an addition, setting its ID and defaulting AddedAt to now
+func (s *SQLiteStore) Record(entry *types.HistoryEntry) error {
+	if entry == nil {
+		return fmt.Errorf("cannot record nil history entry")
+	}
+	if entry.AddedAt.IsZero() {
+		entry.AddedAt = time.Now()
+	}
+
+	tracks := entry.Tracks
+	if tracks == nil {
+		tracks = []types.Track{}
+	}
+	tracksJSON, err := json.Marshal(tracks)
+	if err != nil {
+		return fmt.Errorf("failed to encode history tracks: %w", err)
+	}
+
+	result, err := s.db.Exec(`INSERT INTO additions
+		(artist_id, artist_name, playlist_id, tracks, source, source_url, user_id, added_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ArtistID, entry.ArtistName, entry.PlaylistID, string(tracksJSON),
+		string(entry.Source), entry.SourceURL, entry.User, entry.AddedAt.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("failed to record history entry: %w", err)
+	}
+
+	if entry.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read history entry ID: %w", err)
+	}
+	return nil
+}
+
+// LastAdded returns the most recent addition of the artist to the playlist, or nil if there is none
+func (s *SQLiteStore) LastAdded(playlistID, artistID string) (*types.HistoryEntry, error) {
+	row := s.db.QueryRow(`SELECT `+entryColumns+` FROM additions
+		WHERE playlist_id = ? AND artist_id = ?
+		ORDER BY added_at DESC, id DESC LIMIT 1`, playlistID, artistID)
+
+	entry, err := scanEntry(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query last addition: %w", err)
+	}
+	return entry, nil
+}
+
+// List returns additions matching the filter, newest first
+func (s *SQLiteStore) List(filter types.HistoryFilter) ([]types.HistoryEntry, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if filter.ArtistID != "" {
+		conditions = append(conditions, "artist_id = ?")
+		args = append(args, filter.ArtistID)
+	}
+	if filter.PlaylistID != "" {
+		conditions = append(conditions, "playlist_id = ?")
+		args = append(args, filter.PlaylistID)
+	}
+	if filter.Source != "" {
+		conditions = append(conditions, "source = ?")
+		args = append(args, string(filter.Source))
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "added_at >= ?")
+		args = append(args, filter.Since.UnixMilli())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "added_at < ?")
+		args = append(args, filter.Until.UnixMilli())
+	}
+
+	query := "SELECT " + entryColumns + " FROM additions"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY added_at DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, clampLimit(filter.Limit), max(filter.Offset, 0))
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []types.HistoryEntry{}
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// entryColumns are the columns read by scanEntry, in order
+const entryColumns = "id, artist_id, artist_name, playlist_id, tracks, source, source_url, user_id, added_at"
+
+// scanEntry reads a history entry from a row selected with entryColumns
+func scanEntry(row interface{ Scan(dest ...any) error }) (*types.HistoryEntry, error) {
+	var (
+		entry      types.HistoryEntry
+		tracksJSON string
+		source     string
+		addedAt    int64
+	)
+	if err := row.Scan(&entry.ID, &entry.ArtistID, &entry.ArtistName, &entry.PlaylistID,
+		&tracksJSON, &source, &entry.SourceURL, &entry.User, &addedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(tracksJSON), &entry.Tracks); err != nil {
+		return nil, fmt.Errorf("failed to decode history tracks: %w", err)
+	}
+	entry.Source = types.SourceKind(source)
+	entry.AddedAt = time.UnixMilli(addedAt).UTC()
+	return &entry, nil
+}
+
+// clampLimit applies the default and maximum history page size
+func clampLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return types.DefaultHistoryLimit
+	case limit > types.MaxHistoryLimit:
+		return types.MaxHistoryLimit
+	default:
+		return limit
+	}
+}
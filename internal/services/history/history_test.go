@@ -0,0 +1,209 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-listen/internal/types"
+)
+
+func openTestStore(t *testing.T) (*SQLiteStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data", "history.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, path
+}
+
+func TestOpen(t *testing.T) {
+	store, path := openTestStore(t)
+
+	entry := &types.HistoryEntry{ArtistID: "artist1", ArtistName: "Artist", PlaylistID: "playlist1", Source: types.SourceCLI}
+	if err := store.Record(entry); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+
+	// Reopening an existing database must not reapply migrations or lose data
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() on existing database unexpected error: %v", err)
+	}
+	defer reopened.Close()
+
+	entries, err := reopened.List(types.HistoryFilter{})
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ArtistID != "artist1" {
+		t.Errorf("List() after reopen = %+v, want the recorded entry", entries)
+	}
+}
+
+func TestOpen_InvalidPath(t *testing.T) {
+	if _, err := Open(""); err == nil {
+		t.Error("Open(\"\") expected error but got none")
+	}
+
+	// A regular file where the parent directory should be
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(filepath.Join(parent, "history.db")); err == nil {
+		t.Error("Open() expected error for unusable directory but got none")
+	}
+}
+
+func TestSQLiteStore_RecordAndLastAdded(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tracks := []types.Track{{ID: "track1", Name: "Song", URI: "spotify:track:track1"}}
+
+	first := &types.HistoryEntry{ArtistID: "artist1", PlaylistID: "playlist1", Tracks: tracks, Source: types.SourceUI, AddedAt: base}
+	second := &types.HistoryEntry{
+		ArtistID:   "artist1",
+		ArtistName: "Artist",
+		PlaylistID: "playlist1",
+		Tracks:     tracks,
+		Source:     types.SourceCLI,
+		SourceURL:  "https://example.com/lineup",
+		User:       "user1",
+		AddedAt:    base.Add(time.Hour),
+	}
+	other := &types.HistoryEntry{ArtistID: "artist1", PlaylistID: "playlist2", Source: types.SourceAPI, AddedAt: base.Add(2 * time.Hour)}
+
+	for _, entry := range []*types.HistoryEntry{first, second, other} {
+		if err := store.Record(entry); err != nil {
+			t.Fatalf("Record() unexpected error: %v", err)
+		}
+		if entry.ID == 0 {
+			t.Error("Record() did not set the entry ID")
+		}
+	}
+
+	last, err := store.LastAdded("playlist1", "artist1")
+	if err != nil {
+		t.Fatalf("LastAdded() unexpected error: %v", err)
+	}
+	if last == nil {
+		t.Fatal("LastAdded() = nil, want the most recent entry")
+	}
+	if last.ID != second.ID || !last.AddedAt.Equal(second.AddedAt) {
+		t.Errorf("LastAdded() = %+v, want entry %d at %v", last, second.ID, second.AddedAt)
+	}
+	if last.SourceURL != second.SourceURL || last.User != second.User || last.Source != types.SourceCLI {
+		t.Errorf("LastAdded() lost source details: %+v", last)
+	}
+	if len(last.Tracks) != 1 || last.Tracks[0].URI != "spotify:track:track1" {
+		t.Errorf("LastAdded() tracks = %+v, want the recorded tracks", last.Tracks)
+	}
+
+	none, err := store.LastAdded("playlist1", "unknown")
+	if err != nil || none != nil {
+		t.Errorf("LastAdded() for unknown artist = (%v, %v), want (nil, nil)", none, err)
+	}
+}
+
+func TestSQLiteStore_RecordDefaultsAddedAt(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	before := time.Now().Truncate(time.Millisecond)
+	entry := &types.HistoryEntry{ArtistID: "artist1", PlaylistID: "playlist1", Source: types.SourceAPI}
+	if err := store.Record(entry); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+	if entry.AddedAt.Before(before) || entry.AddedAt.After(time.Now()) {
+		t.Errorf("Record() AddedAt = %v, want the current time", entry.AddedAt)
+	}
+	if err := store.Record(nil); err == nil {
+		t.Error("Record(nil) expected error but got none")
+	}
+}
+
+func TestSQLiteStore_List(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	seed := []types.HistoryEntry{
+		{ArtistID: "a1", PlaylistID: "p1", Source: types.SourceUI, AddedAt: base},
+		{ArtistID: "a2", PlaylistID: "p1", Source: types.SourceAPI, AddedAt: base.Add(time.Hour)},
+		{ArtistID: "a1", PlaylistID: "p2", Source: types.SourceCLI, AddedAt: base.Add(2 * time.Hour)},
+		{ArtistID: "a3", PlaylistID: "p2", Source: types.SourceCLI, AddedAt: base.Add(3 * time.Hour)},
+	}
+	for i := range seed {
+		if err := store.Record(&seed[i]); err != nil {
+			t.Fatalf("Record() unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filter  types.HistoryFilter
+		wantIDs []string
+	}{
+		{name: "all newest first", filter: types.HistoryFilter{}, wantIDs: []string{"a3", "a1", "a2", "a1"}},
+		{name: "by artist", filter: types.HistoryFilter{ArtistID: "a1"}, wantIDs: []string{"a1", "a1"}},
+		{name: "by playlist", filter: types.HistoryFilter{PlaylistID: "p1"}, wantIDs: []string{"a2", "a1"}},
+		{name: "by source", filter: types.HistoryFilter{Source: types.SourceCLI}, wantIDs: []string{"a3", "a1"}},
+		{
+			name:    "time range is half-open",
+			filter:  types.HistoryFilter{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour)},
+			wantIDs: []string{"a1", "a2"},
+		},
+		{name: "limit", filter: types.HistoryFilter{Limit: 2}, wantIDs: []string{"a3", "a1"}},
+		{name: "offset", filter: types.HistoryFilter{Limit: 2, Offset: 3}, wantIDs: []string{"a1"}},
+		{name: "no matches", filter: types.HistoryFilter{ArtistID: "missing"}, wantIDs: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := store.List(tt.filter)
+			if err != nil {
+				t.Fatalf("List() unexpected error: %v", err)
+			}
+			if entries == nil {
+				t.Fatal("List() = nil, want an empty slice")
+			}
+			got := make([]string, len(entries))
+			for i, entry := range entries {
+				got[i] = entry.ArtistID
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("List() artists = %v, want %v", got, tt.wantIDs)
+			}
+			for i := range got {
+				if got[i] != tt.wantIDs[i] {
+					t.Errorf("List() artists = %v, want %v", got, tt.wantIDs)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestClampLimit(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{limit: 0, want: types.DefaultHistoryLimit},
+		{limit: -1, want: types.DefaultHistoryLimit},
+		{limit: 10, want: 10},
+		{limit: types.MaxHistoryLimit + 1, want: types.MaxHistoryLimit},
+	}
+
+	for _, tt := range tests {
+		if got := clampLimit(tt.limit); got != tt.want {
+			t.Errorf("clampLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+// Package jobs runs long operations such as scrapes in the background on a bounded
+// pool of workers, so HTTP requests can return immediately and poll for progress or
+// follow the events a job publishes.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Finished reports whether the job has stopped and will not change again
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+var (
+	// ErrNotFound is returned for unknown or expired job IDs
+	ErrNotFound = errors.New("job not found")
+	// ErrQueueFull is returned when every worker is busy and the queue has no room
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrFinished is returned when cancelling a job that has already stopped
+	ErrFinished = errors.New("job has already finished")
+	// ErrShutdown is returned when submitting to a manager that is shutting down
+	ErrShutdown = errors.New("job manager is shutting down")
+)
+
+// Job is a snapshot of a background job
+type Job struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     Status     `json:"status"`
+	Progress   any        `json:"progress,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Event is a value published by a running job. Seq numbers a job's events from 1
+// in the order they were published.
+type Event struct {
+	Seq  int `json:"seq"`
+	Data any `json:"data"`
+}
+
+// Reporter lets a running task describe its progress. Values passed to it must
+// not be modified afterwards.
+type Reporter interface {
+	// Update replaces the job's progress snapshot
+	Update(progress any)
+	// Publish appends an event to the job's event log
+	Publish(event any)
+}
+
+// Task is the work a job performs. It should stop promptly once ctx is cancelled
+// and report its progress through report.
+type Task func(ctx context.Context, report Reporter) (any, error)
+
+// Config controls the size of a Manager's worker pool
+type Config struct {
+	// Workers is the number of jobs run at the same time
+	Workers int
+	// QueueSize is the number of jobs that may wait for a free worker
+	QueueSize int
+	// Retention is how long finished jobs can still be looked up; zero keeps them
+	// until the manager shuts down
+	Retention time.Duration
+}
+
+// job is a Manager's record of a job; its fields are guarded by the Manager's mutex
+type job struct {
+	Job
+	task   Task
+	ctx    context.Context
+	cancel context.CancelFunc
+	events []Event
+	// changed is closed, and replaced, whenever an event is published; it stays
+	// closed once the job has finished
+	changed chan struct{}
+}
+
+// Manager queues jobs and runs them on a fixed pool of workers. Jobs run on the
+// manager's own context, so they outlive the request that submitted them.
+type Manager struct {
+	mu        sync.Mutex
+	jobs      map[string]*job
+	queue     chan *job
+	retention time.Duration
+	closed    bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+// NewManager creates a manager and starts its workers
+func NewManager(cfg Config, logger *logrus.Logger) *Manager {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		jobs:      make(map[string]*job),
+		queue:     make(chan *job, cfg.QueueSize),
+		retention: cfg.Retention,
+		ctx:       ctx,
+		cancel:    cancel,
+		logger:    logger,
+		now:       time.Now,
+	}
+
+	m.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go m.worker()
+	}
+	return m
+}
+
+// Submit queues a task and returns a snapshot of the new job
+func (m *Manager) Submit(kind string, task Task) (*Job, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, ErrShutdown
+	}
+	m.pruneLocked()
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	j := &job{
+		Job: Job{
+			ID:        id,
+			Kind:      kind,
+			Status:    StatusQueued,
+			CreatedAt: m.now(),
+		},
+		task:    task,
+		ctx:     ctx,
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+
+	select {
+	case m.queue <- j:
+	default:
+		cancel()
+		return nil, ErrQueueFull
+	}
+	m.jobs[id] = j
+
+	m.logger.WithFields(logrus.Fields{
+		"component": "jobs",
+		"operation": "submit",
+		"job_id":    id,
+		"kind":      kind,
+	}).Info("Job queued")
+
+	snapshot := j.Job
+	return &snapshot, nil
+}
+
+// Get returns a snapshot of a job
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	snapshot := j.Job
+	return &snapshot, nil
+}
+
+// Events returns a snapshot of a job and the events it published after the first
+// after, along with a channel that is closed once it publishes another event or
+// finishes. Callers follow a job by calling Events again with the last Seq they saw
+// each time the channel is closed, until the job has finished.
+func (m *Manager) Events(id string, after int) ([]Event, *Job, <-chan struct{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, nil, nil, ErrNotFound
+	}
+
+	var events []Event
+	if after < 0 {
+		after = 0
+	}
+	if after < len(j.events) {
+		events = append(events, j.events[after:]...)
+	}
+	snapshot := j.Job
+	return events, &snapshot, j.changed, nil
+}
+
+// Cancel stops a queued or running job and returns its snapshot. A running job
+// is marked cancelled once its task returns.
+func (m *Manager) Cancel(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if j.Status.Finished() {
+		snapshot := j.Job
+		return &snapshot, ErrFinished
+	}
+
+	j.cancel()
+	if j.Status == StatusQueued {
+		// The worker that dequeues it will skip it
+		m.finishLocked(j, StatusCancelled, nil, context.Canceled)
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"component": "jobs",
+		"operation": "cancel",
+		"job_id":    id,
+		"kind":      j.Kind,
+	}).Info("Job cancellation requested")
+
+	snapshot := j.Job
+	return &snapshot, nil
+}
+
+// Shutdown cancels every job and waits for the workers to stop or ctx to expire
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for jobs to stop: %w", ctx.Err())
+	}
+}
+
+// worker runs queued jobs until the queue is closed
+func (m *Manager) worker() {
+	defer m.wg.Done()
+	for j := range m.queue {
+		m.run(j)
+	}
+}
+
+// run executes a single job and records its outcome
+func (m *Manager) run(j *job) {
+	m.mu.Lock()
+	if j.Status != StatusQueued || j.ctx.Err() != nil {
+		m.finishLocked(j, StatusCancelled, nil, context.Canceled)
+		m.mu.Unlock()
+		return
+	}
+	startedAt := m.now()
+	j.Status = StatusRunning
+	j.StartedAt = &startedAt
+	m.mu.Unlock()
+
+	logger := m.logger.WithFields(logrus.Fields{
+		"component": "jobs",
+		"operation": "run",
+		"job_id":    j.ID,
+		"kind":      j.Kind,
+	})
+	logger.Info("Job started")
+
+	result, err := m.execute(j)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case j.ctx.Err() != nil:
+		m.finishLocked(j, StatusCancelled, result, j.ctx.Err())
+	case err != nil:
+		m.finishLocked(j, StatusFailed, result, err)
+	default:
+		m.finishLocked(j, StatusSucceeded, result, nil)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"status":      j.Status,
+		"duration_ms": j.FinishedAt.Sub(startedAt).Milliseconds(),
+	}).Info("Job finished")
+}
+
+// execute runs the job's task, turning a panic into an error so one bad job
+// cannot take down the worker pool
+func (m *Manager) execute(j *job) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return j.task(j.ctx, &reporter{manager: m, job: j})
+}
+
+// reporter records a running job's progress and events
+type reporter struct {
+	manager *Manager
+	job     *job
+}
+
+// Update replaces the job's progress snapshot
+func (r *reporter) Update(progress any) {
+	r.manager.mu.Lock()
+	defer r.manager.mu.Unlock()
+	r.job.Progress = progress
+}
+
+// Publish appends an event to the job's log and wakes anyone waiting for it.
+// Events published after the job has finished are dropped.
+func (r *reporter) Publish(event any) {
+	r.manager.mu.Lock()
+	defer r.manager.mu.Unlock()
+
+	j := r.job
+	if j.Status.Finished() {
+		return
+	}
+	j.events = append(j.events, Event{Seq: len(j.events) + 1, Data: event})
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// finishLocked records a job's final state and releases its context
+func (m *Manager) finishLocked(j *job, status Status, result any, err error) {
+	if j.Status.Finished() {
+		return
+	}
+	finishedAt := m.now()
+	j.Status = status
+	j.Result = result
+	j.FinishedAt = &finishedAt
+	if err != nil {
+		j.Error = err.Error()
+	}
+	j.cancel()
+	close(j.changed)
+}
+
+// pruneLocked forgets jobs that finished longer ago than the retention period
+func (m *Manager) pruneLocked() {
+	if m.retention <= 0 {
+		return
+	}
+	cutoff := m.now().Add(-m.retention)
+	for id, j := range m.jobs {
+		if j.FinishedAt != nil && j.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// newID returns a random job ID
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,380 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestManager(t *testing.T, cfg Config) *Manager {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	m := NewManager(cfg, logger)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = m.Shutdown(ctx)
+	})
+	return m
+}
+
+// waitForStatus polls a job until it reaches want or the test times out
+func waitForStatus(t *testing.T, m *Manager, id string, want Status) *Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%s) unexpected error: %v", id, err)
+		}
+		if job.Status == want {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s status = %s, want %s", id, job.Status, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// blockingTask reports started once running and waits for release or cancellation
+func blockingTask(started chan<- struct{}, release <-chan struct{}) Task {
+	return func(ctx context.Context, report Reporter) (any, error) {
+		report.Update("started")
+		close(started)
+		select {
+		case <-release:
+			return "released", nil
+		case <-ctx.Done():
+			return "partial", ctx.Err()
+		}
+	}
+}
+
+func TestManager_Outcomes(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name           string
+		task           Task
+		expectedStatus Status
+		expectedResult any
+		expectedError  string
+	}{
+		{
+			name: "succeeds",
+			task: func(ctx context.Context, report Reporter) (any, error) {
+				report.Update(1)
+				report.Update(2)
+				return "done", nil
+			},
+			expectedStatus: StatusSucceeded,
+			expectedResult: "done",
+		},
+		{
+			name: "fails with partial result",
+			task: func(ctx context.Context, report Reporter) (any, error) {
+				return "partial", errBoom
+			},
+			expectedStatus: StatusFailed,
+			expectedResult: "partial",
+			expectedError:  "boom",
+		},
+		{
+			name: "panics",
+			task: func(ctx context.Context, report Reporter) (any, error) {
+				panic("bad task")
+			},
+			expectedStatus: StatusFailed,
+			expectedError:  "job panicked: bad task",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t, Config{Workers: 1, QueueSize: 1})
+
+			job, err := m.Submit("test", tt.task)
+			if err != nil {
+				t.Fatalf("Submit() unexpected error: %v", err)
+			}
+			if job.Status != StatusQueued || job.Kind != "test" || job.ID == "" {
+				t.Errorf("Submit() = %+v, want a queued test job with an ID", job)
+			}
+
+			job = waitForStatus(t, m, job.ID, tt.expectedStatus)
+			if job.Result != tt.expectedResult {
+				t.Errorf("Result = %v, want %v", job.Result, tt.expectedResult)
+			}
+			if job.Error != tt.expectedError {
+				t.Errorf("Error = %q, want %q", job.Error, tt.expectedError)
+			}
+			if job.StartedAt == nil || job.FinishedAt == nil {
+				t.Errorf("expected start and finish times, got %v and %v", job.StartedAt, job.FinishedAt)
+			}
+		})
+	}
+}
+
+func TestManager_Progress(t *testing.T) {
+	m := newTestManager(t, Config{Workers: 1, QueueSize: 1})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	job, err := m.Submit("test", blockingTask(started, release))
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	<-started
+
+	running := waitForStatus(t, m, job.ID, StatusRunning)
+	if running.Progress != "started" {
+		t.Errorf("Progress = %v, want started", running.Progress)
+	}
+
+	close(release)
+	finished := waitForStatus(t, m, job.ID, StatusSucceeded)
+	if finished.Result != "released" {
+		t.Errorf("Result = %v, want released", finished.Result)
+	}
+}
+
+func TestManager_Events(t *testing.T) {
+	m := newTestManager(t, Config{Workers: 1, QueueSize: 1})
+
+	published := make(chan struct{})
+	release := make(chan struct{})
+	job, err := m.Submit("test", func(ctx context.Context, report Reporter) (any, error) {
+		report.Publish("first")
+		report.Publish("second")
+		close(published)
+		<-release
+		report.Publish("third")
+		return "done", nil
+	})
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	<-published
+
+	events, snapshot, changed, err := m.Events(job.ID, 0)
+	if err != nil {
+		t.Fatalf("Events() unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0] != (Event{Seq: 1, Data: "first"}) || events[1] != (Event{Seq: 2, Data: "second"}) {
+		t.Fatalf("Events() = %+v, want the first two events", events)
+	}
+	if snapshot.Status.Finished() {
+		t.Errorf("Status = %s, want an unfinished job", snapshot.Status)
+	}
+
+	// Resuming after the last event seen only returns newer events
+	if events, _, _, _ := m.Events(job.ID, 2); len(events) != 0 {
+		t.Errorf("Events() after 2 = %+v, want none", events)
+	}
+
+	close(release)
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the next event")
+	}
+
+	finished := waitForStatus(t, m, job.ID, StatusSucceeded)
+	events, _, changed, err = m.Events(job.ID, 2)
+	if err != nil {
+		t.Fatalf("Events() unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0] != (Event{Seq: 3, Data: "third"}) {
+		t.Errorf("Events() after 2 = %+v, want the third event", events)
+	}
+	select {
+	case <-changed:
+	default:
+		t.Error("expected a closed channel once the job finished")
+	}
+	if finished.Result != "done" {
+		t.Errorf("Result = %v, want done", finished.Result)
+	}
+
+	if _, _, _, err := m.Events("missing", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Events() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestManager_Cancel(t *testing.T) {
+	t.Run("running job", func(t *testing.T) {
+		m := newTestManager(t, Config{Workers: 1, QueueSize: 1})
+
+		started := make(chan struct{})
+		job, err := m.Submit("test", blockingTask(started, make(chan struct{})))
+		if err != nil {
+			t.Fatalf("Submit() unexpected error: %v", err)
+		}
+		<-started
+
+		if _, err := m.Cancel(job.ID); err != nil {
+			t.Fatalf("Cancel() unexpected error: %v", err)
+		}
+		cancelled := waitForStatus(t, m, job.ID, StatusCancelled)
+		if cancelled.Result != "partial" {
+			t.Errorf("Result = %v, want the partial result", cancelled.Result)
+		}
+
+		if _, err := m.Cancel(job.ID); !errors.Is(err, ErrFinished) {
+			t.Errorf("second Cancel() error = %v, want %v", err, ErrFinished)
+		}
+	})
+
+	t.Run("queued job never runs", func(t *testing.T) {
+		m := newTestManager(t, Config{Workers: 1, QueueSize: 1})
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		if _, err := m.Submit("test", blockingTask(started, release)); err != nil {
+			t.Fatalf("Submit() unexpected error: %v", err)
+		}
+		<-started
+
+		ran := false
+		queued, err := m.Submit("test", func(ctx context.Context, report Reporter) (any, error) {
+			ran = true
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("Submit() unexpected error: %v", err)
+		}
+
+		cancelled, err := m.Cancel(queued.ID)
+		if err != nil {
+			t.Fatalf("Cancel() unexpected error: %v", err)
+		}
+		if cancelled.Status != StatusCancelled {
+			t.Errorf("Status = %s, want %s", cancelled.Status, StatusCancelled)
+		}
+
+		close(release)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := m.Shutdown(ctx); err != nil {
+			t.Fatalf("Shutdown() unexpected error: %v", err)
+		}
+		if ran {
+			t.Error("cancelled queued job ran")
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		m := newTestManager(t, Config{Workers: 1})
+
+		if _, err := m.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Cancel() error = %v, want %v", err, ErrNotFound)
+		}
+	})
+}
+
+func TestManager_BoundedPool(t *testing.T) {
+	const workers = 2
+	m := newTestManager(t, Config{Workers: workers, QueueSize: 1})
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	task := func(ctx context.Context, report Reporter) (any, error) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, nil
+	}
+
+	var ids []string
+	for i := 0; i < workers; i++ {
+		job, err := m.Submit("test", task)
+		if err != nil {
+			t.Fatalf("Submit() unexpected error: %v", err)
+		}
+		ids = append(ids, job.ID)
+		waitForStatus(t, m, job.ID, StatusRunning)
+	}
+
+	// Both workers are busy, so one more job fits in the queue and the next is refused
+	queued, err := m.Submit("test", task)
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	ids = append(ids, queued.ID)
+	if _, err := m.Submit("test", task); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Submit() error = %v, want %v", err, ErrQueueFull)
+	}
+
+	close(release)
+	for _, id := range ids {
+		waitForStatus(t, m, id, StatusSucceeded)
+	}
+	if maxRunning > workers {
+		t.Errorf("%d jobs ran at once, want at most %d", maxRunning, workers)
+	}
+}
+
+func TestManager_Retention(t *testing.T) {
+	m := newTestManager(t, Config{Workers: 1, QueueSize: 1, Retention: time.Minute})
+
+	job, err := m.Submit("test", func(ctx context.Context, report Reporter) (any, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	waitForStatus(t, m, job.ID, StatusSucceeded)
+
+	m.mu.Lock()
+	m.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	m.mu.Unlock()
+
+	if _, err := m.Get(job.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v once the job expired", err, ErrNotFound)
+	}
+}
+
+func TestManager_Shutdown(t *testing.T) {
+	m := newTestManager(t, Config{Workers: 1, QueueSize: 1})
+
+	started := make(chan struct{})
+	job, err := m.Submit("test", blockingTask(started, make(chan struct{})))
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() unexpected error: %v", err)
+	}
+
+	stopped, err := m.Get(job.ID)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if stopped.Status != StatusCancelled {
+		t.Errorf("Status = %s, want %s after shutdown", stopped.Status, StatusCancelled)
+	}
+	if _, err := m.Submit("test", blockingTask(make(chan struct{}), nil)); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Submit() error = %v, want %v", err, ErrShutdown)
+	}
+}
@@ -0,0 +1,100 @@
+package playlist
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+// IncomingRules decides which playlists are incoming playlists. Explicit marks
+// take precedence over the configured ID allowlist, name pattern and description marker.
+type IncomingRules struct {
+	ids               map[string]bool
+	namePattern       *regexp.Regexp
+	descriptionMarker string
+
+	store MarkStore
+	mu    sync.RWMutex
+	marks map[string]bool
+}
+
+// NewIncomingRules creates incoming playlist rules from configuration, loading existing marks from store
+func NewIncomingRules(cfg config.PlaylistsConfig, store MarkStore) (*IncomingRules, error) {
+	if store == nil {
+		store = NewMemoryMarkStore()
+	}
+
+	rules := &IncomingRules{
+		ids:               make(map[string]bool),
+		descriptionMarker: strings.ToLower(strings.TrimSpace(cfg.IncomingDescriptionMarker)),
+		store:             store,
+	}
+
+	for _, id := range cfg.IncomingIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			rules.ids[id] = true
+		}
+	}
+
+	if cfg.IncomingNamePattern != "" {
+		pattern, err := regexp.Compile(cfg.IncomingNamePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid incoming playlist name pattern: %w", err)
+		}
+		rules.namePattern = pattern
+	}
+
+	marks, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load playlist marks: %w", err)
+	}
+	rules.marks = marks
+
+	return rules, nil
+}
+
+// Classify reports whether a playlist is incoming and the rule that decided it.
+// The reason is empty for playlists that match no rule.
+func (r *IncomingRules) Classify(playlist types.Playlist) (bool, types.IncomingReason) {
+	r.mu.RLock()
+	marked, hasMark := r.marks[playlist.ID]
+	r.mu.RUnlock()
+
+	switch {
+	case hasMark && marked:
+		return true, types.IncomingReasonMarked
+	case hasMark:
+		return false, types.IncomingReasonUnmarked
+	case r.ids[playlist.ID]:
+		return true, types.IncomingReasonConfiguredID
+	case r.namePattern != nil && r.namePattern.MatchString(playlist.Name):
+		return true, types.IncomingReasonNamePattern
+	case r.descriptionMarker != "" && strings.Contains(strings.ToLower(playlist.Description), r.descriptionMarker):
+		return true, types.IncomingReasonDescriptionMarker
+	default:
+		return false, ""
+	}
+}
+
+// SetMark explicitly marks a playlist as incoming or excluded and persists the change
+func (r *IncomingRules) SetMark(playlistID string, incoming bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	marks := maps.Clone(r.marks)
+	if marks == nil {
+		marks = make(map[string]bool)
+	}
+	marks[playlistID] = incoming
+
+	if err := r.store.Save(marks); err != nil {
+		return fmt.Errorf("failed to save playlist marks: %w", err)
+	}
+	r.marks = marks
+	return nil
+}
@@ -0,0 +1,166 @@
+package playlist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+func TestIncomingRules_Classify(t *testing.T) {
+	cfg := config.PlaylistsConfig{
+		IncomingIDs:               []string{"configured"},
+		IncomingNamePattern:       `(?i)^incoming\b`,
+		IncomingDescriptionMarker: "#Incoming",
+	}
+	store := NewMemoryMarkStore()
+	if err := store.Save(map[string]bool{"marked": true, "unmarked": false}); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	rules, err := NewIncomingRules(cfg, store)
+	if err != nil {
+		t.Fatalf("NewIncomingRules() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		playlist     types.Playlist
+		wantIncoming bool
+		wantReason   types.IncomingReason
+	}{
+		{
+			name:         "explicit mark",
+			playlist:     types.Playlist{ID: "marked", Name: "Road Trip"},
+			wantIncoming: true,
+			wantReason:   types.IncomingReasonMarked,
+		},
+		{
+			name:       "explicit unmark overrides name pattern",
+			playlist:   types.Playlist{ID: "unmarked", Name: "Incoming Rock"},
+			wantReason: types.IncomingReasonUnmarked,
+		},
+		{
+			name:         "configured ID",
+			playlist:     types.Playlist{ID: "configured", Name: "Road Trip"},
+			wantIncoming: true,
+			wantReason:   types.IncomingReasonConfiguredID,
+		},
+		{
+			name:         "name pattern",
+			playlist:     types.Playlist{ID: "p1", Name: "INCOMING jazz"},
+			wantIncoming: true,
+			wantReason:   types.IncomingReasonNamePattern,
+		},
+		{
+			name:         "description marker is case-insensitive",
+			playlist:     types.Playlist{ID: "p2", Name: "New Finds", Description: "stuff to check #incoming"},
+			wantIncoming: true,
+			wantReason:   types.IncomingReasonDescriptionMarker,
+		},
+		{
+			name:     "name merely starting with i",
+			playlist: types.Playlist{ID: "p3", Name: "Indie Favourites"},
+		},
+		{
+			name:     "name containing the word elsewhere",
+			playlist: types.Playlist{ID: "p4", Name: "Not Incoming"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incoming, reason := rules.Classify(tt.playlist)
+			if incoming != tt.wantIncoming || reason != tt.wantReason {
+				t.Errorf("Classify() = (%v, %q), want (%v, %q)", incoming, reason, tt.wantIncoming, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestNewIncomingRules_InvalidPattern(t *testing.T) {
+	if _, err := NewIncomingRules(config.PlaylistsConfig{IncomingNamePattern: "(incoming"}, nil); err == nil {
+		t.Error("NewIncomingRules() expected error for invalid pattern but got none")
+	}
+}
+
+func TestIncomingRules_SetMarkPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "marks.json")
+	store, err := NewFileMarkStore(path)
+	if err != nil {
+		t.Fatalf("NewFileMarkStore() unexpected error: %v", err)
+	}
+
+	rules, err := NewIncomingRules(config.PlaylistsConfig{}, store)
+	if err != nil {
+		t.Fatalf("NewIncomingRules() unexpected error: %v", err)
+	}
+	if err := rules.SetMark("playlist1", true); err != nil {
+		t.Fatalf("SetMark() unexpected error: %v", err)
+	}
+	if err := rules.SetMark("playlist2", false); err != nil {
+		t.Fatalf("SetMark() unexpected error: %v", err)
+	}
+
+	// A new process loads the marks from disk
+	reloaded, err := NewIncomingRules(config.PlaylistsConfig{}, store)
+	if err != nil {
+		t.Fatalf("NewIncomingRules() unexpected error: %v", err)
+	}
+	if incoming, reason := reloaded.Classify(types.Playlist{ID: "playlist1"}); !incoming || reason != types.IncomingReasonMarked {
+		t.Errorf("Classify(playlist1) = (%v, %q), want marked", incoming, reason)
+	}
+	if incoming, reason := reloaded.Classify(types.Playlist{ID: "playlist2"}); incoming || reason != types.IncomingReasonUnmarked {
+		t.Errorf("Classify(playlist2) = (%v, %q), want unmarked", incoming, reason)
+	}
+}
+
+func TestNewMarkStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.PlaylistsConfig
+		wantErr bool
+	}{
+		{name: "default memory", cfg: config.PlaylistsConfig{}},
+		{name: "memory", cfg: config.PlaylistsConfig{MarkStore: "memory"}},
+		{name: "file", cfg: config.PlaylistsConfig{MarkStore: "file", MarkFile: filepath.Join(t.TempDir(), "marks.json")}},
+		{name: "file without path", cfg: config.PlaylistsConfig{MarkStore: "file"}, wantErr: true},
+		{name: "unknown", cfg: config.PlaylistsConfig{MarkStore: "redis"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewMarkStore(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("NewMarkStore() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewMarkStore() unexpected error: %v", err)
+			}
+			marks, err := store.Load()
+			if err != nil || len(marks) != 0 {
+				t.Errorf("Load() on a new store = (%v, %v), want no marks", marks, err)
+			}
+		})
+	}
+}
+
+func TestFileMarkStore_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "marks.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileMarkStore(path)
+	if err != nil {
+		t.Fatalf("NewFileMarkStore() unexpected error: %v", err)
+	}
+	if _, err := store.Load(); err == nil {
+		t.Error("Load() expected error for corrupt file but got none")
+	}
+}
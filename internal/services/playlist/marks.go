@@ -0,0 +1,119 @@
+package playlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/toozej/go-listen/internal/fileutil"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+// MarkStore persists playlists explicitly marked or unmarked as incoming.
+// Marks map a playlist ID to true (incoming) or false (excluded).
+type MarkStore interface {
+	// Load returns all stored marks, or an empty map if none have been saved
+	Load() (map[string]bool, error)
+	// Save replaces the stored marks
+	Save(marks map[string]bool) error
+}
+
+// NewMarkStore creates the mark store selected by the playlists configuration.
+// An empty store type falls back to an in-memory store.
+func NewMarkStore(cfg config.PlaylistsConfig) (MarkStore, error) {
+	switch strings.ToLower(cfg.MarkStore) {
+	case "", "memory":
+		return NewMemoryMarkStore(), nil
+	case "file":
+		return NewFileMarkStore(cfg.MarkFile)
+	default:
+		return nil, fmt.Errorf("unknown playlist mark store type: %s", cfg.MarkStore)
+	}
+}
+
+// MemoryMarkStore keeps marks in process memory only
+type MemoryMarkStore struct {
+	mu    sync.RWMutex
+	marks map[string]bool
+}
+
+// NewMemoryMarkStore creates an empty in-memory mark store
+func NewMemoryMarkStore() *MemoryMarkStore {
+	return &MemoryMarkStore{marks: make(map[string]bool)}
+}
+
+// Load returns a copy of the stored marks
+func (m *MemoryMarkStore) Load() (map[string]bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return maps.Clone(m.marks), nil
+}
+
+// Save stores a copy of the marks
+func (m *MemoryMarkStore) Save(marks map[string]bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.marks = maps.Clone(marks)
+	if m.marks == nil {
+		m.marks = make(map[string]bool)
+	}
+	return nil
+}
+
+// FileMarkStore persists marks to a JSON file
+type FileMarkStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileMarkStore creates a file-backed mark store at path
+func NewFileMarkStore(path string) (*FileMarkStore, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("playlist mark file path is required")
+	}
+	return &FileMarkStore{path: filepath.Clean(path)}, nil
+}
+
+// Load reads the mark file, treating a missing file as no marks
+func (f *FileMarkStore) Load() (map[string]bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	marks := make(map[string]bool)
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return marks, nil
+		}
+		return nil, fmt.Errorf("failed to read playlist mark file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &marks); err != nil {
+		return nil, fmt.Errorf("failed to decode playlist mark file: %w", err)
+	}
+	return marks, nil
+}
+
+// Save atomically replaces the mark file
+func (f *FileMarkStore) Save(marks map[string]bool) error {
+	data, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode playlist marks: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := fileutil.WriteAtomic(f.path, data); err != nil {
+		return fmt.Errorf("failed to save playlist mark file: %w", err)
+	}
+
+	return nil
+}
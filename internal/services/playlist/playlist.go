@@ -1,25 +1,38 @@
 package playlist
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/spotify"
 	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/logging"
 )
 
 // PlaylistService implements the PlaylistManager interface
 type PlaylistService struct {
 	spotify   types.SpotifyService
 	duplicate types.DuplicateDetector
-	logger    *log.Logger
+	incoming  *IncomingRules
+	history   types.HistoryStore
+	searcher  types.ArtistSearcher
+	blocklist types.ArtistBlocklist
+	router    types.PlaylistRouter
+	logger    *logging.Logger
 }
 
+// ErrNoIncomingRules is returned when incoming playlists are requested before rules are set
+var ErrNoIncomingRules = errors.New("incoming playlist rules are not configured")
+
 // NewPlaylistService creates a new playlist service
 func NewPlaylistService(spotify types.SpotifyService, duplicate types.DuplicateDetector, logger *log.Logger) *PlaylistService {
 	return &PlaylistService{
 		spotify:   spotify,
 		duplicate: duplicate,
-		logger:    logger,
+		logger:    logging.Wrap(logger),
 	}
 }
 
@@ -27,24 +40,59 @@ func NewPlaylistService(spotify types.SpotifyService, duplicate types.DuplicateD
 func NewService(spotify types.SpotifyService, logger *log.Logger) *PlaylistService {
 	return &PlaylistService{
 		spotify: spotify,
-		logger:  logger,
+		logger:  logging.Wrap(logger),
 	}
 }
 
-// AddArtistToPlaylist adds an artist's top tracks to a playlist
-func (p *PlaylistService) AddArtistToPlaylist(artistName, playlistID string, force bool) (*types.AddResult, error) {
-	p.logger.WithFields(log.Fields{
+// SetIncomingRules sets the rules deciding which playlists are incoming playlists
+func (p *PlaylistService) SetIncomingRules(rules *IncomingRules) {
+	p.incoming = rules
+}
+
+// SetHistory sets the store successful additions are recorded in
+func (p *PlaylistService) SetHistory(history types.HistoryStore) {
+	p.history = history
+}
+
+// SetArtistSearcher sets the searcher used to resolve artist names. With a searcher
+// set, a name several artists plausibly match is not added; the candidates are
+// returned instead so the caller can pick one with AddArtistByID.
+func (p *PlaylistService) SetArtistSearcher(searcher types.ArtistSearcher) {
+	p.searcher = searcher
+}
+
+// SetBlocklist sets the blocklist of artists that are never added
+func (p *PlaylistService) SetBlocklist(blocklist types.ArtistBlocklist) {
+	p.blocklist = blocklist
+}
+
+// SetRouter sets the router picking the playlist of artists added to types.AutoPlaylistID
+func (p *PlaylistService) SetRouter(router types.PlaylistRouter) {
+	p.router = router
+}
+
+// AddArtistToPlaylist adds an artist's tracks, chosen by the track selection, to a playlist
+func (p *PlaylistService) AddArtistToPlaylist(ctx context.Context, artistName, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	mode, selection = addDefaults(mode, selection)
+
+	p.logger.WithContext(ctx).WithFields(log.Fields{
 		"component":   "playlist_service",
 		"operation":   "add_artist",
 		"artist_name": artistName,
 		"playlist_id": playlistID,
-		"force":       force,
+		"mode":        mode,
+		"track_count": selection.Count,
+		"strategy":    selection.Strategy,
 	}).Info("Starting to add artist to playlist")
 
+	if result, err := validateAdd(mode, selection); err != nil {
+		return result, err
+	}
+
 	// Search for the artist
-	artist, err := p.spotify.SearchArtist(artistName)
+	artist, candidates, err := p.findArtist(ctx, artistName)
 	if err != nil {
-		p.logger.WithError(err).WithFields(log.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
 			"component":   "playlist_service",
 			"operation":   "add_artist",
 			"artist_name": artistName,
@@ -54,25 +102,214 @@ func (p *PlaylistService) AddArtistToPlaylist(artistName, playlistID string, for
 			Message: "Failed to find artist: " + err.Error(),
 		}, err
 	}
+	if artist == nil {
+		p.logger.WithContext(ctx).WithFields(log.Fields{
+			"component":       "playlist_service",
+			"operation":       "add_artist",
+			"artist_name":     artistName,
+			"candidate_count": len(candidates),
+		}).Info("Artist name is ambiguous, asking for disambiguation")
+		return &types.AddResult{
+			Success:             false,
+			NeedsDisambiguation: true,
+			Candidates:          candidates,
+			Message:             fmt.Sprintf("Several artists match '%s', choose the one to add", artistName),
+		}, nil
+	}
+
+	return p.addArtist(ctx, artist, playlistID, mode, selection, source)
+}
+
+// AddArtistByID adds the tracks of the artist with the given Spotify ID to a
+// playlist, as picked from the candidates of an ambiguous name
+func (p *PlaylistService) AddArtistByID(ctx context.Context, artistID, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	mode, selection = addDefaults(mode, selection)
+
+	p.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":   "playlist_service",
+		"operation":   "add_artist",
+		"artist_id":   artistID,
+		"playlist_id": playlistID,
+		"mode":        mode,
+		"track_count": selection.Count,
+		"strategy":    selection.Strategy,
+	}).Info("Starting to add artist to playlist by ID")
+
+	if result, err := validateAdd(mode, selection); err != nil {
+		return result, err
+	}
+
+	artist, err := p.spotify.GetArtist(ctx, artistID)
+	if err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
+			"component": "playlist_service",
+			"operation": "add_artist",
+			"artist_id": artistID,
+		}).Error("Failed to get artist")
+		return &types.AddResult{
+			Success: false,
+			Message: "Failed to find artist: " + err.Error(),
+		}, err
+	}
+
+	return p.addArtist(ctx, artist, playlistID, mode, selection, source)
+}
+
+// addDefaults fills in the add mode and track selection a request left empty
+func addDefaults(mode types.AddMode, selection types.TrackSelection) (types.AddMode, types.TrackSelection) {
+	if mode == "" {
+		mode = types.AddModeSkip
+	}
+	return mode, selection.WithDefaults()
+}
+
+// validateAdd checks the add mode and track selection of a request
+func validateAdd(mode types.AddMode, selection types.TrackSelection) (*types.AddResult, error) {
+	if err := mode.Validate(); err != nil {
+		return &types.AddResult{
+			Success: false,
+			Message: "Invalid add mode: " + err.Error(),
+		}, err
+	}
+	if err := selection.Validate(); err != nil {
+		return &types.AddResult{
+			Success: false,
+			Message: "Invalid track selection: " + err.Error(),
+		}, err
+	}
+	return nil, nil
+}
+
+// findArtist resolves an artist name. Without an artist searcher Spotify's top
+// result is used; with one, no artist is returned when the name is ambiguous,
+// only the scored candidates.
+func (p *PlaylistService) findArtist(ctx context.Context, artistName string) (*types.Artist, []types.ArtistCandidate, error) {
+	if p.searcher == nil {
+		artist, err := p.spotify.SearchArtist(ctx, artistName)
+		return artist, nil, err
+	}
+
+	candidates, err := p.searcher.FindCandidates(ctx, artistName, types.DefaultCandidateLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if types.NeedsDisambiguation(candidates) {
+		return nil, candidates, nil
+	}
+	return &candidates[0].Artist, candidates, nil
+}
+
+// addArtist adds a resolved artist's tracks to a playlist, unless the artist is
+// blocklisted, first routing the artist by genre when the playlist is types.AutoPlaylistID
+func (p *PlaylistService) addArtist(ctx context.Context, artist *types.Artist, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	// Blocked artists are never added, whatever the add mode
+	if p.blocklist != nil {
+		if blocked, reason := p.blocklist.Blocked(*artist); blocked {
+			p.logger.WithContext(ctx).WithFields(log.Fields{
+				"component":    "playlist_service",
+				"operation":    "add_artist",
+				"artist_id":    artist.ID,
+				"artist_name":  artist.Name,
+				"playlist_id":  playlistID,
+				"block_reason": reason,
+			}).Info("Artist is blocklisted, not adding")
+			return &types.AddResult{
+				Success:     false,
+				Artist:      *artist,
+				Blocked:     true,
+				BlockReason: reason,
+				Message:     reason.Message(),
+			}, nil
+		}
+	}
 
-	// Get the artist's top 5 tracks
-	tracks, err := p.spotify.GetArtistTopTracks(artist.ID)
+	if playlistID != types.AutoPlaylistID {
+		return p.addArtistTo(ctx, artist, playlistID, mode, selection, source)
+	}
+
+	route, result, err := p.route(ctx, artist)
+	if route == nil {
+		return result, err
+	}
+	result, err = p.addArtistTo(ctx, artist, route.PlaylistID, mode, selection, source)
+	if result != nil {
+		result.Route = route
+	}
+	return result, err
+}
+
+// route picks the playlist of an artist added to types.AutoPlaylistID. When the
+// artist can't be routed the route is nil and the failed result is returned instead.
+func (p *PlaylistService) route(ctx context.Context, artist *types.Artist) (*types.PlaylistRoute, *types.AddResult, error) {
+	if p.router == nil {
+		return nil, &types.AddResult{
+			Success: false,
+			Artist:  *artist,
+			Message: "Failed to route artist: " + ErrNoRouter.Error(),
+		}, ErrNoRouter
+	}
+
+	route, err := p.router.Route(ctx, *artist)
 	if err != nil {
-		p.logger.WithError(err).WithFields(log.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
+			"component":   "playlist_service",
+			"operation":   "route_artist",
+			"artist_id":   artist.ID,
+			"artist_name": artist.Name,
+		}).Error("Failed to route artist to a playlist")
+		return nil, &types.AddResult{
+			Success: false,
+			Artist:  *artist,
+			Message: "Failed to route artist: " + err.Error(),
+		}, err
+	}
+	if route == nil {
+		p.logger.WithContext(ctx).WithFields(log.Fields{
+			"component":   "playlist_service",
+			"operation":   "route_artist",
+			"artist_id":   artist.ID,
+			"artist_name": artist.Name,
+			"genres":      artist.Genres,
+		}).Warn("No routing rule matches artist")
+		return nil, &types.AddResult{
+			Success: false,
+			Artist:  *artist,
+			Message: "No routing rule matches " + artist.Name + "'s genres and no default playlist is configured",
+		}, nil
+	}
+
+	p.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":   "playlist_service",
+		"operation":   "route_artist",
+		"artist_id":   artist.ID,
+		"artist_name": artist.Name,
+		"playlist_id": route.PlaylistID,
+		"genre":       route.Genre,
+	}).Info("Routed artist to playlist")
+	return route, nil, nil
+}
+
+// addArtistTo adds a resolved artist's tracks to a given playlist
+func (p *PlaylistService) addArtistTo(ctx context.Context, artist *types.Artist, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	// Get the artist's tracks using the requested strategy
+	tracks, err := p.spotify.GetArtistTracks(ctx, artist.ID, selection)
+	if err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
 			"component":   "playlist_service",
 			"operation":   "add_artist",
 			"artist_id":   artist.ID,
 			"artist_name": artist.Name,
-		}).Error("Failed to get artist top tracks")
+			"strategy":    selection.Strategy,
+		}).Error("Failed to get artist tracks")
 		return &types.AddResult{
 			Success: false,
 			Artist:  *artist,
-			Message: "Failed to get artist's top tracks: " + err.Error(),
+			Message: "Failed to get artist's " + describeSelection(selection) + ": " + err.Error(),
 		}, err
 	}
 
 	if len(tracks) == 0 {
-		p.logger.WithFields(log.Fields{
+		p.logger.WithContext(ctx).WithFields(log.Fields{
 			"component":   "playlist_service",
 			"operation":   "add_artist",
 			"artist_id":   artist.ID,
@@ -85,19 +322,19 @@ func (p *PlaylistService) AddArtistToPlaylist(artistName, playlistID string, for
 		}, nil
 	}
 
-	// Check for duplicates if not forced
+	// Check for duplicates unless forced or only filling gaps
 	var wasDuplicate bool
-	if !force && p.duplicate != nil {
-		duplicateResult, err := p.duplicate.CheckArtistInPlaylist(playlistID, artist.ID)
+	if mode == types.AddModeSkip && p.duplicate != nil {
+		duplicateResult, err := p.duplicate.CheckArtistInPlaylist(ctx, playlistID, artist.ID)
 		if err != nil {
-			p.logger.WithError(err).WithFields(log.Fields{
+			p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
 				"component":   "playlist_service",
 				"operation":   "duplicate_check",
 				"artist_id":   artist.ID,
 				"playlist_id": playlistID,
 			}).Warn("Failed to check for duplicates, proceeding anyway")
 		} else if duplicateResult != nil && duplicateResult.HasDuplicates {
-			p.logger.WithFields(log.Fields{
+			p.logger.WithContext(ctx).WithFields(log.Fields{
 				"component":      "playlist_service",
 				"operation":      "duplicate_check",
 				"artist_id":      artist.ID,
@@ -111,11 +348,43 @@ func (p *PlaylistService) AddArtistToPlaylist(artistName, playlistID string, for
 				Success:      false,
 				Artist:       *artist,
 				WasDuplicate: true,
+				LastAdded:    duplicateResult.LastAdded,
 				Message:      duplicateResult.Message,
 			}, nil
 		}
 	}
 
+	// In fill-gaps mode, leave out the tracks that are already in the playlist
+	var skipped []types.Track
+	if mode == types.AddModeFillGaps {
+		missing, present, err := p.FilterMissingTracks(ctx, playlistID, tracks)
+		if err != nil {
+			return &types.AddResult{
+				Success: false,
+				Artist:  *artist,
+				Message: "Failed to check which tracks are already in the playlist: " + err.Error(),
+			}, err
+		}
+		if len(missing) == 0 {
+			p.logger.WithContext(ctx).WithFields(log.Fields{
+				"component":   "playlist_service",
+				"operation":   "fill_gaps",
+				"artist_id":   artist.ID,
+				"artist_name": artist.Name,
+				"playlist_id": playlistID,
+			}).Info("All selected artist tracks already exist in playlist")
+
+			return &types.AddResult{
+				Success:       false,
+				Artist:        *artist,
+				WasDuplicate:  true,
+				TracksSkipped: present,
+				Message:       "All of " + artist.Name + "'s " + describeSelection(selection) + " are already in the playlist",
+			}, nil
+		}
+		tracks, skipped = missing, present
+	}
+
 	// Extract track IDs and names for logging
 	trackIDs := make([]string, len(tracks))
 	trackNames := make([]string, len(tracks))
@@ -125,9 +394,9 @@ func (p *PlaylistService) AddArtistToPlaylist(artistName, playlistID string, for
 	}
 
 	// Add tracks to playlist in batch with error handling
-	err = p.spotify.AddTracksToPlaylist(playlistID, trackIDs)
+	err = p.spotify.AddTracksToPlaylist(ctx, playlistID, trackIDs)
 	if err != nil {
-		p.logger.WithError(err).WithFields(log.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
 			"component":   "playlist_service",
 			"operation":   "add_tracks",
 			"playlist_id": playlistID,
@@ -135,12 +404,11 @@ func (p *PlaylistService) AddArtistToPlaylist(artistName, playlistID string, for
 			"artist_name": artist.Name,
 		}).Error("Failed to add tracks to playlist")
 
-		// Check if it's a rate limiting error and provide appropriate message
+		// The Spotify client has already retried, so a rate limit here means Spotify wants a longer break
 		errorMessage := "Failed to add tracks to playlist: " + err.Error()
-		if strings.Contains(strings.ToLower(err.Error()), "rate limit") ||
-			strings.Contains(strings.ToLower(err.Error()), "429") {
+		if errors.Is(err, spotify.ErrRateLimited) {
 			errorMessage = "Rate limited by Spotify API. Please try again later."
-			p.logger.WithFields(log.Fields{
+			p.logger.WithContext(ctx).WithFields(log.Fields{
 				"component": "playlist_service",
 				"operation": "add_tracks",
 				"event":     "rate_limit_hit",
@@ -148,105 +416,224 @@ func (p *PlaylistService) AddArtistToPlaylist(artistName, playlistID string, for
 		}
 
 		return &types.AddResult{
-			Success:      false,
-			Artist:       *artist,
-			TracksAdded:  tracks,
-			WasDuplicate: wasDuplicate,
-			Message:      errorMessage,
+			Success:       false,
+			Artist:        *artist,
+			TracksAdded:   tracks,
+			TracksSkipped: skipped,
+			WasDuplicate:  wasDuplicate,
+			Message:       errorMessage,
 		}, err
 	}
 
-	p.logger.WithFields(log.Fields{
-		"component":   "playlist_service",
-		"operation":   "add_tracks",
-		"artist_name": artist.Name,
-		"playlist_id": playlistID,
-		"track_count": len(tracks),
-		"track_names": trackNames,
+	p.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":     "playlist_service",
+		"operation":     "add_tracks",
+		"artist_name":   artist.Name,
+		"playlist_id":   playlistID,
+		"track_count":   len(tracks),
+		"skipped_count": len(skipped),
+		"track_names":   trackNames,
 	}).Info("Successfully added artist tracks to playlist")
 
+	p.invalidateDuplicates(playlistID)
+	p.recordHistory(ctx, artist, playlistID, tracks, source)
+
+	message := "Successfully added " + artist.Name + "'s " + describeSelection(selection) + " to playlist"
+	if len(skipped) > 0 {
+		message = fmt.Sprintf("Successfully added %d of %s's %s to playlist (%d already present)",
+			len(tracks), artist.Name, describeSelection(selection), len(skipped))
+	}
+
 	return &types.AddResult{
-		Success:      true,
-		Artist:       *artist,
-		TracksAdded:  tracks,
-		WasDuplicate: wasDuplicate,
-		Message:      "Successfully added " + artist.Name + "'s top tracks to playlist",
+		Success:       true,
+		Artist:        *artist,
+		TracksAdded:   tracks,
+		TracksSkipped: skipped,
+		WasDuplicate:  wasDuplicate,
+		Message:       message,
 	}, nil
 }
 
-// GetIncomingPlaylists gets playlists from the "Incoming" folder
-func (p *PlaylistService) GetIncomingPlaylists() ([]types.Playlist, error) {
-	p.logger.WithFields(log.Fields{
-		"component":   "playlist_service",
-		"operation":   "get_incoming_playlists",
-		"folder_name": "Incoming",
-	}).Debug("Fetching playlists from 'Incoming' folder")
+// invalidateDuplicates drops the duplicate detector's cached view of a playlist after tracks were added to it
+func (p *PlaylistService) invalidateDuplicates(playlistID string) {
+	if p.duplicate != nil {
+		p.duplicate.InvalidatePlaylist(playlistID)
+	}
+}
 
-	playlists, err := p.spotify.GetUserPlaylists("Incoming")
-	if err != nil {
-		p.logger.WithError(err).WithFields(log.Fields{
+// recordHistory records a successful addition. Failures are logged rather than
+// returned since the tracks have already been added to the playlist.
+func (p *PlaylistService) recordHistory(ctx context.Context, artist *types.Artist, playlistID string, tracks []types.Track, source types.AddSource) {
+	if p.history == nil {
+		return
+	}
+
+	entry := &types.HistoryEntry{
+		ArtistID:   artist.ID,
+		ArtistName: artist.Name,
+		PlaylistID: playlistID,
+		Tracks:     tracks,
+		Source:     source.Kind,
+		SourceURL:  source.URL,
+		User:       source.User,
+	}
+	if err := p.history.Record(entry); err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
 			"component":   "playlist_service",
-			"operation":   "get_incoming_playlists",
-			"folder_name": "Incoming",
-		}).Error("Failed to fetch playlists from Incoming folder")
+			"operation":   "record_history",
+			"artist_id":   artist.ID,
+			"playlist_id": playlistID,
+		}).Error("Failed to record artist addition in history")
+	}
+}
+
+// GetIncomingPlaylists gets the user's playlists matching the incoming playlist rules
+func (p *PlaylistService) GetIncomingPlaylists(ctx context.Context) ([]types.Playlist, error) {
+	p.logger.WithContext(ctx).WithFields(log.Fields{
+		"component": "playlist_service",
+		"operation": "get_incoming_playlists",
+	}).Debug("Fetching incoming playlists")
+
+	playlists, err := p.ListPlaylists(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	playlistNames := make([]string, len(playlists))
+	incoming := make([]types.Playlist, 0, len(playlists))
+	playlistNames := make([]string, 0, len(playlists))
+	for _, playlist := range playlists {
+		if playlist.IsIncoming {
+			incoming = append(incoming, playlist)
+			playlistNames = append(playlistNames, playlist.Name)
+		}
+	}
+
+	p.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":       "playlist_service",
+		"operation":       "get_incoming_playlists",
+		"playlist_count":  len(incoming),
+		"total_playlists": len(playlists),
+		"playlist_names":  playlistNames,
+	}).Info("Successfully fetched incoming playlists")
+	return incoming, nil
+}
+
+// ListPlaylists gets all of the user's playlists, each classified by the incoming playlist rules
+func (p *PlaylistService) ListPlaylists(ctx context.Context) ([]types.Playlist, error) {
+	if p.incoming == nil {
+		return nil, ErrNoIncomingRules
+	}
+
+	playlists, err := p.spotify.GetUserPlaylists(ctx)
+	if err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
+			"component": "playlist_service",
+			"operation": "list_playlists",
+		}).Error("Failed to fetch user playlists")
+		return nil, err
+	}
+
+	classified := make([]types.Playlist, len(playlists))
 	for i, playlist := range playlists {
-		playlistNames[i] = playlist.Name
+		playlist.IsIncoming, playlist.IncomingReason = p.incoming.Classify(playlist)
+		classified[i] = playlist
 	}
+	return classified, nil
+}
 
-	p.logger.WithFields(log.Fields{
-		"component":      "playlist_service",
-		"operation":      "get_incoming_playlists",
-		"folder_name":    "Incoming",
-		"playlist_count": len(playlists),
-		"playlist_names": playlistNames,
-	}).Info("Successfully fetched Incoming playlists")
-	return playlists, nil
+// MarkIncoming explicitly marks a playlist as incoming, regardless of the configured rules
+func (p *PlaylistService) MarkIncoming(ctx context.Context, playlistID string) error {
+	return p.setIncomingMark(ctx, playlistID, true)
 }
 
-// GetTop5Tracks gets the top 5 tracks for an artist
-func (p *PlaylistService) GetTop5Tracks(artistID string) ([]types.Track, error) {
-	p.logger.WithFields(log.Fields{
-		"component": "playlist_service",
-		"operation": "get_top5_tracks",
-		"artist_id": artistID,
-	}).Debug("Getting top 5 tracks for artist")
+// UnmarkIncoming explicitly excludes a playlist from the incoming playlists, regardless of the configured rules
+func (p *PlaylistService) UnmarkIncoming(ctx context.Context, playlistID string) error {
+	return p.setIncomingMark(ctx, playlistID, false)
+}
+
+// setIncomingMark records an explicit incoming mark for a playlist
+func (p *PlaylistService) setIncomingMark(ctx context.Context, playlistID string, incoming bool) error {
+	if p.incoming == nil {
+		return ErrNoIncomingRules
+	}
 
-	tracks, err := p.spotify.GetArtistTopTracks(artistID)
+	if err := p.incoming.SetMark(playlistID, incoming); err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
+			"component":   "playlist_service",
+			"operation":   "set_incoming_mark",
+			"playlist_id": playlistID,
+			"incoming":    incoming,
+		}).Error("Failed to mark playlist")
+		return err
+	}
+
+	p.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":   "playlist_service",
+		"operation":   "set_incoming_mark",
+		"playlist_id": playlistID,
+		"incoming":    incoming,
+	}).Info("Marked playlist")
+	return nil
+}
+
+// GetArtistTracks gets an artist's tracks according to the track selection
+func (p *PlaylistService) GetArtistTracks(ctx context.Context, artistID string, selection types.TrackSelection) ([]types.Track, error) {
+	selection = selection.WithDefaults()
+
+	p.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":   "playlist_service",
+		"operation":   "get_artist_tracks",
+		"artist_id":   artistID,
+		"track_count": selection.Count,
+		"strategy":    selection.Strategy,
+	}).Debug("Getting tracks for artist")
+
+	tracks, err := p.spotify.GetArtistTracks(ctx, artistID, selection)
 	if err != nil {
-		p.logger.WithError(err).WithFields(log.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
 			"component": "playlist_service",
-			"operation": "get_top5_tracks",
+			"operation": "get_artist_tracks",
 			"artist_id": artistID,
-		}).Error("Failed to get artist top tracks")
+			"strategy":  selection.Strategy,
+		}).Error("Failed to get artist tracks")
 		return nil, err
 	}
 
-	p.logger.WithFields(log.Fields{
+	p.logger.WithContext(ctx).WithFields(log.Fields{
 		"component":   "playlist_service",
-		"operation":   "get_top5_tracks",
+		"operation":   "get_artist_tracks",
 		"artist_id":   artistID,
+		"strategy":    selection.Strategy,
 		"track_count": len(tracks),
-	}).Info("Successfully retrieved artist top tracks")
+	}).Info("Successfully retrieved artist tracks")
 
 	return tracks, nil
 }
 
+// describeSelection returns a human readable description of the tracks a selection picks
+func describeSelection(selection types.TrackSelection) string {
+	switch selection.Strategy {
+	case types.TrackStrategyLatest:
+		return "latest tracks"
+	case types.TrackStrategyRandom:
+		return "randomly selected tracks"
+	default:
+		return "top tracks"
+	}
+}
+
 // AddTracksToPlaylist adds tracks to a playlist
-func (p *PlaylistService) AddTracksToPlaylist(playlistID string, trackIDs []string) error {
-	p.logger.WithFields(log.Fields{
+func (p *PlaylistService) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
+	p.logger.WithContext(ctx).WithFields(log.Fields{
 		"component":   "playlist_service",
 		"operation":   "add_tracks_to_playlist",
 		"playlist_id": playlistID,
 		"track_count": len(trackIDs),
 	}).Debug("Adding tracks to playlist")
 
-	err := p.spotify.AddTracksToPlaylist(playlistID, trackIDs)
+	err := p.spotify.AddTracksToPlaylist(ctx, playlistID, trackIDs)
 	if err != nil {
-		p.logger.WithError(err).WithFields(log.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
 			"component":   "playlist_service",
 			"operation":   "add_tracks_to_playlist",
 			"playlist_id": playlistID,
@@ -255,7 +642,9 @@ func (p *PlaylistService) AddTracksToPlaylist(playlistID string, trackIDs []stri
 		return err
 	}
 
-	p.logger.WithFields(log.Fields{
+	p.invalidateDuplicates(playlistID)
+
+	p.logger.WithContext(ctx).WithFields(log.Fields{
 		"component":   "playlist_service",
 		"operation":   "add_tracks_to_playlist",
 		"playlist_id": playlistID,
@@ -266,17 +655,17 @@ func (p *PlaylistService) AddTracksToPlaylist(playlistID string, trackIDs []stri
 }
 
 // CheckForDuplicates checks if tracks already exist in a playlist
-func (p *PlaylistService) CheckForDuplicates(playlistID string, trackIDs []string) (*types.DuplicateResult, error) {
-	p.logger.WithFields(log.Fields{
+func (p *PlaylistService) CheckForDuplicates(ctx context.Context, playlistID string, trackIDs []string) (*types.DuplicateResult, error) {
+	p.logger.WithContext(ctx).WithFields(log.Fields{
 		"component":   "playlist_service",
 		"operation":   "check_duplicates",
 		"playlist_id": playlistID,
 		"track_count": len(trackIDs),
 	}).Debug("Checking for duplicate tracks in playlist")
 
-	duplicateFlags, err := p.spotify.CheckTracksInPlaylist(playlistID, trackIDs)
+	duplicateFlags, err := p.spotify.CheckTracksInPlaylist(ctx, playlistID, trackIDs)
 	if err != nil {
-		p.logger.WithError(err).WithFields(log.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
 			"component":   "playlist_service",
 			"operation":   "check_duplicates",
 			"playlist_id": playlistID,
@@ -304,7 +693,7 @@ func (p *PlaylistService) CheckForDuplicates(playlistID string, trackIDs []strin
 		Message:       message,
 	}
 
-	p.logger.WithFields(log.Fields{
+	p.logger.WithContext(ctx).WithFields(log.Fields{
 		"component":       "playlist_service",
 		"operation":       "check_duplicates",
 		"playlist_id":     playlistID,
@@ -316,6 +705,72 @@ func (p *PlaylistService) CheckForDuplicates(playlistID string, trackIDs []strin
 	return result, nil
 }
 
+// FilterMissingTracks splits tracks into those not yet in the playlist and those already present, keeping their order.
+// With a duplicate detector configured, other releases of the same recording count as present too.
+func (p *PlaylistService) FilterMissingTracks(ctx context.Context, playlistID string, tracks []types.Track) (missing, present []types.Track, err error) {
+	if len(tracks) == 0 {
+		return nil, nil, nil
+	}
+
+	inPlaylist, err := p.tracksInPlaylist(ctx, playlistID, tracks)
+	if err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
+			"component":   "playlist_service",
+			"operation":   "filter_missing_tracks",
+			"playlist_id": playlistID,
+			"track_count": len(tracks),
+		}).Error("Failed to check which tracks are in playlist")
+		return nil, nil, fmt.Errorf("failed to check tracks in playlist: %w", err)
+	}
+	if len(inPlaylist) != len(tracks) {
+		return nil, nil, fmt.Errorf("playlist check returned %d results for %d tracks", len(inPlaylist), len(tracks))
+	}
+
+	for i, track := range tracks {
+		if inPlaylist[i] {
+			present = append(present, track)
+		} else {
+			missing = append(missing, track)
+		}
+	}
+
+	p.logger.WithContext(ctx).WithFields(log.Fields{
+		"component":     "playlist_service",
+		"operation":     "filter_missing_tracks",
+		"playlist_id":   playlistID,
+		"missing_count": len(missing),
+		"present_count": len(present),
+	}).Debug("Filtered tracks already in playlist")
+
+	return missing, present, nil
+}
+
+// tracksInPlaylist reports for each track whether the playlist already holds it
+func (p *PlaylistService) tracksInPlaylist(ctx context.Context, playlistID string, tracks []types.Track) ([]bool, error) {
+	if p.duplicate == nil {
+		trackIDs := make([]string, len(tracks))
+		for i, track := range tracks {
+			trackIDs[i] = track.ID
+		}
+		return p.spotify.CheckTracksInPlaylist(ctx, playlistID, trackIDs)
+	}
+
+	result, err := p.duplicate.CheckDuplicates(ctx, playlistID, tracks)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicateIDs := make(map[string]bool, len(result.DuplicateTracks))
+	for _, track := range result.DuplicateTracks {
+		duplicateIDs[track.ID] = true
+	}
+	inPlaylist := make([]bool, len(tracks))
+	for i, track := range tracks {
+		inPlaylist[i] = duplicateIDs[track.ID]
+	}
+	return inPlaylist, nil
+}
+
 // FilterPlaylistsBySearch filters playlists by search term
 func (p *PlaylistService) FilterPlaylistsBySearch(playlists []types.Playlist, searchTerm string) []types.Playlist {
 	if searchTerm == "" {
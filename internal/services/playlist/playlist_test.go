@@ -1,12 +1,18 @@
 package playlist
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/spotify"
 	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
 )
 
 // MockSpotifyService is a mock implementation of SpotifyService
@@ -15,30 +21,42 @@ type MockSpotifyService struct {
 	err       error
 }
 
-func (m *MockSpotifyService) SearchArtist(query string) (*types.Artist, error) {
+func (m *MockSpotifyService) SearchArtist(ctx context.Context, query string) (*types.Artist, error) {
 	return nil, errors.New("not implemented in mock")
 }
 
-func (m *MockSpotifyService) GetArtistTopTracks(artistID string) ([]types.Track, error) {
+func (m *MockSpotifyService) SearchArtists(ctx context.Context, query string, limit int) ([]types.Artist, error) {
 	return nil, errors.New("not implemented in mock")
 }
 
-func (m *MockSpotifyService) GetUserPlaylists(folderName string) ([]types.Playlist, error) {
+func (m *MockSpotifyService) GetArtist(ctx context.Context, artistID string) (*types.Artist, error) {
+	return nil, errors.New("not implemented in mock")
+}
+
+func (m *MockSpotifyService) GetArtistTracks(ctx context.Context, artistID string, selection types.TrackSelection) ([]types.Track, error) {
+	return nil, errors.New("not implemented in mock")
+}
+
+func (m *MockSpotifyService) GetUserPlaylists(ctx context.Context) ([]types.Playlist, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
 	return m.playlists, nil
 }
 
-func (m *MockSpotifyService) AddTracksToPlaylist(playlistID string, trackIDs []string) error {
+func (m *MockSpotifyService) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
 	return errors.New("not implemented in mock")
 }
 
-func (m *MockSpotifyService) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]bool, error) {
+func (m *MockSpotifyService) CheckTracksInPlaylist(ctx context.Context, playlistID string, trackIDs []string) ([]bool, error) {
 	return nil, errors.New("not implemented in mock")
 }
 
-func (m *MockSpotifyService) GetAuthURL() string {
+func (m *MockSpotifyService) GetPlaylistTracks(ctx context.Context, playlistID string) ([]types.Track, error) {
+	return nil, errors.New("not implemented in mock")
+}
+
+func (m *MockSpotifyService) GetAuthURL(state, codeVerifier string) string {
 	return "mock-auth-url"
 }
 
@@ -46,83 +64,72 @@ func (m *MockSpotifyService) IsAuthenticated() bool {
 	return true
 }
 
-func (m *MockSpotifyService) CompleteAuth(code, state string) error {
+func (m *MockSpotifyService) CompleteAuth(ctx context.Context, code, codeVerifier string) error {
 	return nil
 }
 
+func (m *MockSpotifyService) CurrentUserID(ctx context.Context) (string, error) {
+	return "test-user", nil
+}
+
 // MockDuplicateDetector is a mock implementation of DuplicateDetector
 type MockDuplicateDetector struct{}
 
-func (m *MockDuplicateDetector) CheckDuplicates(playlistID string, tracks []types.Track) (*types.DuplicateResult, error) {
+func (m *MockDuplicateDetector) CheckDuplicates(ctx context.Context, playlistID string, tracks []types.Track) (*types.DuplicateResult, error) {
 	return nil, errors.New("not implemented in mock")
 }
 
-func (m *MockDuplicateDetector) CheckArtistInPlaylist(playlistID, artistID string) (*types.DuplicateResult, error) {
+func (m *MockDuplicateDetector) CheckArtistInPlaylist(ctx context.Context, playlistID, artistID string) (*types.DuplicateResult, error) {
 	return nil, errors.New("not implemented in mock")
 }
 
+func (m *MockDuplicateDetector) InvalidatePlaylist(playlistID string) {}
+
 func TestPlaylistService_GetIncomingPlaylists(t *testing.T) {
+	userPlaylists := []types.Playlist{
+		{ID: "playlist1", Name: "My Incoming Playlist", URI: "spotify:playlist:playlist1", TrackCount: 10},
+		{ID: "playlist2", Name: "Indie Favourites", URI: "spotify:playlist:playlist2", TrackCount: 5},
+		{ID: "playlist3", Name: "New Finds", Description: "Fresh stuff #Incoming", URI: "spotify:playlist:playlist3"},
+		{ID: "playlist4", Name: "Workout", URI: "spotify:playlist:playlist4"},
+	}
+
 	tests := []struct {
-		name           string
-		mockPlaylists  []types.Playlist
-		mockError      error
-		expectedResult []types.Playlist
-		expectedError  bool
+		name          string
+		mockPlaylists []types.Playlist
+		mockError     error
+		config        config.PlaylistsConfig
+		expectedIDs   []string
+		expectedError bool
 	}{
 		{
-			name: "successful retrieval of incoming playlists",
-			mockPlaylists: []types.Playlist{
-				{
-					ID:         "playlist1",
-					Name:       "My Incoming Playlist",
-					URI:        "spotify:playlist:playlist1",
-					TrackCount: 10,
-					EmbedURL:   "https://open.spotify.com/embed/playlist/playlist1",
-					IsIncoming: true,
-				},
-				{
-					ID:         "playlist2",
-					Name:       "Another Incoming",
-					URI:        "spotify:playlist:playlist2",
-					TrackCount: 5,
-					EmbedURL:   "https://open.spotify.com/embed/playlist/playlist2",
-					IsIncoming: true,
-				},
-			},
-			mockError: nil,
-			expectedResult: []types.Playlist{
-				{
-					ID:         "playlist1",
-					Name:       "My Incoming Playlist",
-					URI:        "spotify:playlist:playlist1",
-					TrackCount: 10,
-					EmbedURL:   "https://open.spotify.com/embed/playlist/playlist1",
-					IsIncoming: true,
-				},
-				{
-					ID:         "playlist2",
-					Name:       "Another Incoming",
-					URI:        "spotify:playlist:playlist2",
-					TrackCount: 5,
-					EmbedURL:   "https://open.spotify.com/embed/playlist/playlist2",
-					IsIncoming: true,
-				},
-			},
-			expectedError: false,
+			name:          "name pattern and description marker",
+			mockPlaylists: userPlaylists,
+			config:        config.PlaylistsConfig{IncomingNamePattern: "(?i)incoming", IncomingDescriptionMarker: "#incoming"},
+			expectedIDs:   []string{"playlist1", "playlist3"},
 		},
 		{
-			name:           "empty incoming folder",
-			mockPlaylists:  []types.Playlist{},
-			mockError:      nil,
-			expectedResult: []types.Playlist{},
-			expectedError:  false,
+			name:          "configured IDs",
+			mockPlaylists: userPlaylists,
+			config:        config.PlaylistsConfig{IncomingIDs: []string{"playlist4", " playlist2 "}},
+			expectedIDs:   []string{"playlist2", "playlist4"},
+		},
+		{
+			name:          "no matches does not fall back to all playlists",
+			mockPlaylists: userPlaylists,
+			config:        config.PlaylistsConfig{IncomingNamePattern: "^Inbox$"},
+			expectedIDs:   []string{},
 		},
 		{
-			name:           "spotify service error",
-			mockPlaylists:  nil,
-			mockError:      errors.New("spotify API error"),
-			expectedResult: nil,
-			expectedError:  true,
+			name:          "no user playlists",
+			mockPlaylists: []types.Playlist{},
+			config:        config.PlaylistsConfig{IncomingNamePattern: "(?i)incoming"},
+			expectedIDs:   []string{},
+		},
+		{
+			name:          "spotify service error",
+			mockError:     errors.New("spotify API error"),
+			config:        config.PlaylistsConfig{IncomingNamePattern: "(?i)incoming"},
+			expectedError: true,
 		},
 	}
 
@@ -139,9 +146,14 @@ func TestPlaylistService_GetIncomingPlaylists(t *testing.T) {
 
 			// Create service
 			service := NewPlaylistService(mockSpotify, mockDuplicate, logger)
+			rules, err := NewIncomingRules(tt.config, NewMemoryMarkStore())
+			if err != nil {
+				t.Fatalf("NewIncomingRules() unexpected error: %v", err)
+			}
+			service.SetIncomingRules(rules)
 
 			// Execute
-			result, err := service.GetIncomingPlaylists()
+			result, err := service.GetIncomingPlaylists(context.Background())
 
 			// Assert
 			if tt.expectedError {
@@ -151,18 +163,82 @@ func TestPlaylistService_GetIncomingPlaylists(t *testing.T) {
 				if result != nil {
 					t.Errorf("Expected nil result but got %v", result)
 				}
-			} else {
-				if err != nil {
-					t.Errorf("Unexpected error: %v", err)
-				}
-				if !reflect.DeepEqual(result, tt.expectedResult) {
-					t.Errorf("Expected result %v, got %v", tt.expectedResult, result)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			ids := make([]string, len(result))
+			for i, playlist := range result {
+				ids[i] = playlist.ID
+				if !playlist.IsIncoming || playlist.IncomingReason == "" {
+					t.Errorf("Playlist %s returned without incoming classification: %+v", playlist.ID, playlist)
 				}
 			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("Expected playlists %v, got %v", tt.expectedIDs, ids)
+			}
 		})
 	}
 }
 
+func TestPlaylistService_MarkIncoming(t *testing.T) {
+	mockSpotify := &MockSpotifyService{
+		playlists: []types.Playlist{
+			{ID: "playlist1", Name: "Incoming"},
+			{ID: "playlist2", Name: "Road Trip"},
+		},
+	}
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	service := NewPlaylistService(mockSpotify, &MockDuplicateDetector{}, logger)
+	rules, err := NewIncomingRules(config.PlaylistsConfig{IncomingNamePattern: "(?i)incoming"}, NewMemoryMarkStore())
+	if err != nil {
+		t.Fatalf("NewIncomingRules() unexpected error: %v", err)
+	}
+	service.SetIncomingRules(rules)
+
+	if err := service.MarkIncoming(context.Background(), "playlist2"); err != nil {
+		t.Fatalf("MarkIncoming() unexpected error: %v", err)
+	}
+	if err := service.UnmarkIncoming(context.Background(), "playlist1"); err != nil {
+		t.Fatalf("UnmarkIncoming() unexpected error: %v", err)
+	}
+
+	playlists, err := service.ListPlaylists(context.Background())
+	if err != nil {
+		t.Fatalf("ListPlaylists() unexpected error: %v", err)
+	}
+
+	want := map[string]types.IncomingReason{
+		"playlist1": types.IncomingReasonUnmarked,
+		"playlist2": types.IncomingReasonMarked,
+	}
+	for _, playlist := range playlists {
+		if playlist.IncomingReason != want[playlist.ID] {
+			t.Errorf("Playlist %s reason = %q, want %q", playlist.ID, playlist.IncomingReason, want[playlist.ID])
+		}
+		if playlist.IsIncoming != (playlist.ID == "playlist2") {
+			t.Errorf("Playlist %s IsIncoming = %v after marking", playlist.ID, playlist.IsIncoming)
+		}
+	}
+}
+
+func TestPlaylistService_IncomingWithoutRules(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	service := NewService(&MockSpotifyService{}, logger)
+
+	if _, err := service.GetIncomingPlaylists(context.Background()); !errors.Is(err, ErrNoIncomingRules) {
+		t.Errorf("GetIncomingPlaylists() error = %v, want %v", err, ErrNoIncomingRules)
+	}
+	if err := service.MarkIncoming(context.Background(), "playlist1"); !errors.Is(err, ErrNoIncomingRules) {
+		t.Errorf("MarkIncoming() error = %v, want %v", err, ErrNoIncomingRules)
+	}
+}
+
 func TestPlaylistService_FilterPlaylistsBySearch(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -263,7 +339,7 @@ func TestPlaylistService_AddArtistToPlaylist(t *testing.T) {
 		name                string
 		artistName          string
 		playlistID          string
-		force               bool
+		mode                types.AddMode
 		mockArtist          *types.Artist
 		mockArtistError     error
 		mockTracks          []types.Track
@@ -279,7 +355,7 @@ func TestPlaylistService_AddArtistToPlaylist(t *testing.T) {
 			name:       "successful artist addition",
 			artistName: "Test Artist",
 			playlistID: "playlist123",
-			force:      false,
+			mode:       types.AddModeSkip,
 			mockArtist: &types.Artist{
 				ID:   "artist123",
 				Name: "Test Artist",
@@ -302,7 +378,7 @@ func TestPlaylistService_AddArtistToPlaylist(t *testing.T) {
 			name:            "artist not found",
 			artistName:      "Unknown Artist",
 			playlistID:      "playlist123",
-			force:           false,
+			mode:            types.AddModeSkip,
 			mockArtist:      nil,
 			mockArtistError: errors.New("artist not found"),
 			expectedSuccess: false,
@@ -312,7 +388,7 @@ func TestPlaylistService_AddArtistToPlaylist(t *testing.T) {
 			name:       "artist has no tracks",
 			artistName: "Test Artist",
 			playlistID: "playlist123",
-			force:      false,
+			mode:       types.AddModeSkip,
 			mockArtist: &types.Artist{
 				ID:   "artist123",
 				Name: "Test Artist",
@@ -329,7 +405,7 @@ func TestPlaylistService_AddArtistToPlaylist(t *testing.T) {
 			name:       "duplicate detected without force",
 			artistName: "Test Artist",
 			playlistID: "playlist123",
-			force:      false,
+			mode:       types.AddModeSkip,
 			mockArtist: &types.Artist{
 				ID:   "artist123",
 				Name: "Test Artist",
@@ -353,7 +429,7 @@ func TestPlaylistService_AddArtistToPlaylist(t *testing.T) {
 			name:       "force addition with duplicates",
 			artistName: "Test Artist",
 			playlistID: "playlist123",
-			force:      true,
+			mode:       types.AddModeForce,
 			mockArtist: &types.Artist{
 				ID:   "artist123",
 				Name: "Test Artist",
@@ -373,7 +449,7 @@ func TestPlaylistService_AddArtistToPlaylist(t *testing.T) {
 			name:       "failed to add tracks to playlist",
 			artistName: "Test Artist",
 			playlistID: "playlist123",
-			force:      false,
+			mode:       types.AddModeSkip,
 			mockArtist: &types.Artist{
 				ID:   "artist123",
 				Name: "Test Artist",
@@ -415,7 +491,7 @@ func TestPlaylistService_AddArtistToPlaylist(t *testing.T) {
 			service := NewPlaylistService(mockSpotify, mockDuplicate, logger)
 
 			// Execute
-			result, err := service.AddArtistToPlaylist(tt.artistName, tt.playlistID, tt.force)
+			result, err := service.AddArtistToPlaylist(context.Background(), tt.artistName, tt.playlistID, tt.mode, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
 
 			// Assert
 			if result == nil {
@@ -456,29 +532,57 @@ type EnhancedMockSpotifyService struct {
 	tracks      []types.Track
 	tracksError error
 	addError    error
+	inPlaylist  []bool
+	checkError  error
+	addedIDs    []string
 }
 
-func (m *EnhancedMockSpotifyService) SearchArtist(query string) (*types.Artist, error) {
+func (m *EnhancedMockSpotifyService) SearchArtist(ctx context.Context, query string) (*types.Artist, error) {
 	return m.artist, m.artistError
 }
 
-func (m *EnhancedMockSpotifyService) GetArtistTopTracks(artistID string) ([]types.Track, error) {
+func (m *EnhancedMockSpotifyService) SearchArtists(ctx context.Context, query string, limit int) ([]types.Artist, error) {
+	if m.artistError != nil {
+		return nil, m.artistError
+	}
+	if m.artist == nil {
+		return nil, spotify.ErrNotFound
+	}
+	return []types.Artist{*m.artist}, nil
+}
+
+func (m *EnhancedMockSpotifyService) GetArtist(ctx context.Context, artistID string) (*types.Artist, error) {
+	if m.artist != nil && m.artist.ID != artistID {
+		return nil, spotify.ErrNotFound
+	}
+	return m.artist, m.artistError
+}
+
+func (m *EnhancedMockSpotifyService) GetArtistTracks(ctx context.Context, artistID string, selection types.TrackSelection) ([]types.Track, error) {
 	return m.tracks, m.tracksError
 }
 
-func (m *EnhancedMockSpotifyService) GetUserPlaylists(folderName string) ([]types.Playlist, error) {
+func (m *EnhancedMockSpotifyService) GetUserPlaylists(ctx context.Context) ([]types.Playlist, error) {
 	return nil, errors.New("not implemented in enhanced mock")
 }
 
-func (m *EnhancedMockSpotifyService) AddTracksToPlaylist(playlistID string, trackIDs []string) error {
-	return m.addError
+func (m *EnhancedMockSpotifyService) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
+	if m.addError != nil {
+		return m.addError
+	}
+	m.addedIDs = append(m.addedIDs, trackIDs...)
+	return nil
+}
+
+func (m *EnhancedMockSpotifyService) CheckTracksInPlaylist(ctx context.Context, playlistID string, trackIDs []string) ([]bool, error) {
+	return m.inPlaylist, m.checkError
 }
 
-func (m *EnhancedMockSpotifyService) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]bool, error) {
+func (m *EnhancedMockSpotifyService) GetPlaylistTracks(ctx context.Context, playlistID string) ([]types.Track, error) {
 	return nil, errors.New("not implemented in enhanced mock")
 }
 
-func (m *EnhancedMockSpotifyService) GetAuthURL() string {
+func (m *EnhancedMockSpotifyService) GetAuthURL(state, codeVerifier string) string {
 	return "mock-auth-url"
 }
 
@@ -486,23 +590,40 @@ func (m *EnhancedMockSpotifyService) IsAuthenticated() bool {
 	return true
 }
 
-func (m *EnhancedMockSpotifyService) CompleteAuth(code, state string) error {
+func (m *EnhancedMockSpotifyService) CompleteAuth(ctx context.Context, code, codeVerifier string) error {
 	return nil
 }
 
+func (m *EnhancedMockSpotifyService) CurrentUserID(ctx context.Context) (string, error) {
+	return "test-user", nil
+}
+
 // EnhancedMockDuplicateDetector provides more control over duplicate detection
 type EnhancedMockDuplicateDetector struct {
-	result *types.DuplicateResult
-	err    error
+	result       *types.DuplicateResult
+	err          error
+	tracksResult *types.DuplicateResult
+	tracksErr    error
+	invalidated  []string
 }
 
-func (m *EnhancedMockDuplicateDetector) CheckDuplicates(playlistID string, tracks []types.Track) (*types.DuplicateResult, error) {
-	return m.result, m.err
+func (m *EnhancedMockDuplicateDetector) CheckDuplicates(ctx context.Context, playlistID string, tracks []types.Track) (*types.DuplicateResult, error) {
+	if m.tracksErr != nil {
+		return nil, m.tracksErr
+	}
+	if m.tracksResult != nil {
+		return m.tracksResult, nil
+	}
+	return &types.DuplicateResult{}, nil
 }
 
-func (m *EnhancedMockDuplicateDetector) CheckArtistInPlaylist(playlistID, artistID string) (*types.DuplicateResult, error) {
+func (m *EnhancedMockDuplicateDetector) CheckArtistInPlaylist(ctx context.Context, playlistID, artistID string) (*types.DuplicateResult, error) {
 	return m.result, m.err
 }
+
+func (m *EnhancedMockDuplicateDetector) InvalidatePlaylist(playlistID string) {
+	m.invalidated = append(m.invalidated, playlistID)
+}
 func TestPlaylistService_AddArtistToPlaylist_RateLimiting(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -510,14 +631,14 @@ func TestPlaylistService_AddArtistToPlaylist_RateLimiting(t *testing.T) {
 		expectedMessage string
 	}{
 		{
-			name:            "rate limit error with 429",
-			addTracksError:  errors.New("HTTP 429: Rate limit exceeded"),
+			name:            "rate limit error",
+			addTracksError:  fmt.Errorf("failed to add tracks to playlist playlist123: %w", spotify.ErrRateLimited),
 			expectedMessage: "Rate limited by Spotify API. Please try again later.",
 		},
 		{
-			name:            "rate limit error with text",
-			addTracksError:  errors.New("Rate limit exceeded, please try again"),
-			expectedMessage: "Rate limited by Spotify API. Please try again later.",
+			name:            "rate limit mentioned by another error",
+			addTracksError:  errors.New("HTTP 429: Rate limit exceeded"),
+			expectedMessage: "Failed to add tracks to playlist: HTTP 429: Rate limit exceeded",
 		},
 		{
 			name:            "other error",
@@ -552,7 +673,7 @@ func TestPlaylistService_AddArtistToPlaylist_RateLimiting(t *testing.T) {
 
 			service := NewPlaylistService(mockSpotify, mockDuplicate, logger)
 
-			result, err := service.AddArtistToPlaylist("Test Artist", "playlist123", false)
+			result, err := service.AddArtistToPlaylist(context.Background(), "Test Artist", "playlist123", types.AddModeSkip, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
 
 			if result == nil {
 				t.Fatal("Expected result but got nil")
@@ -573,11 +694,120 @@ func TestPlaylistService_AddArtistToPlaylist_RateLimiting(t *testing.T) {
 	}
 }
 
+// recordingHistory is a types.HistoryStore that keeps recorded entries in memory
+type recordingHistory struct {
+	entries []types.HistoryEntry
+}
+
+func (h *recordingHistory) Record(entry *types.HistoryEntry) error {
+	h.entries = append(h.entries, *entry)
+	return nil
+}
+
+func (h *recordingHistory) LastAdded(playlistID, artistID string) (*types.HistoryEntry, error) {
+	return nil, nil
+}
+
+func (h *recordingHistory) List(filter types.HistoryFilter) ([]types.HistoryEntry, error) {
+	return h.entries, nil
+}
+
+// TestPlaylistService_AddArtistToPlaylist_RecordsHistory tests that only successful additions are recorded
+func TestPlaylistService_AddArtistToPlaylist_RecordsHistory(t *testing.T) {
+	tests := []struct {
+		name        string
+		duplicate   bool
+		addError    error
+		wantRecords int
+	}{
+		{name: "successful addition", wantRecords: 1},
+		{name: "duplicate", duplicate: true},
+		{name: "add failure", addError: errors.New("playlist not found")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSpotify := &EnhancedMockSpotifyService{
+				artist: &types.Artist{ID: "artist123", Name: "Test Artist"},
+				tracks: []types.Track{
+					{ID: "track1", Name: "Song 1", URI: "spotify:track:track1"},
+				},
+				addError: tt.addError,
+			}
+			mockDuplicate := &EnhancedMockDuplicateDetector{
+				result: &types.DuplicateResult{HasDuplicates: tt.duplicate},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			history := &recordingHistory{}
+			service := NewPlaylistService(mockSpotify, mockDuplicate, logger)
+			service.SetHistory(history)
+
+			source := types.AddSource{Kind: types.SourceUI, User: "test-user"}
+			_, _ = service.AddArtistToPlaylist(context.Background(), "Test Artist", "playlist123", types.AddModeSkip, types.DefaultTrackSelection(), source)
+
+			if len(history.entries) != tt.wantRecords {
+				t.Fatalf("Expected %d history entries, got %d", tt.wantRecords, len(history.entries))
+			}
+			if tt.wantRecords == 0 {
+				return
+			}
+			entry := history.entries[0]
+			if entry.ArtistID != "artist123" || entry.PlaylistID != "playlist123" || entry.Source != types.SourceUI || entry.User != "test-user" {
+				t.Errorf("Unexpected history entry: %+v", entry)
+			}
+			if len(entry.Tracks) != 1 || entry.Tracks[0].ID != "track1" {
+				t.Errorf("Expected the added tracks in history, got %+v", entry.Tracks)
+			}
+		})
+	}
+}
+
+// TestPlaylistService_AddArtistToPlaylist_InvalidatesDuplicateIndex tests that the duplicate
+// detector forgets its cached view of a playlist only once tracks were actually added
+func TestPlaylistService_AddArtistToPlaylist_InvalidatesDuplicateIndex(t *testing.T) {
+	tests := []struct {
+		name            string
+		duplicate       bool
+		addError        error
+		wantInvalidated []string
+	}{
+		{name: "successful addition", wantInvalidated: []string{"playlist123"}},
+		{name: "duplicate", duplicate: true},
+		{name: "add failure", addError: errors.New("playlist not found")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSpotify := &EnhancedMockSpotifyService{
+				artist:   &types.Artist{ID: "artist123", Name: "Test Artist"},
+				tracks:   []types.Track{{ID: "track1", Name: "Song 1"}},
+				addError: tt.addError,
+			}
+			mockDuplicate := &EnhancedMockDuplicateDetector{
+				result: &types.DuplicateResult{HasDuplicates: tt.duplicate},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			service := NewPlaylistService(mockSpotify, mockDuplicate, logger)
+			_, _ = service.AddArtistToPlaylist(context.Background(), "Test Artist", "playlist123", types.AddModeSkip, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
+
+			if !reflect.DeepEqual(mockDuplicate.invalidated, tt.wantInvalidated) {
+				t.Errorf("Expected invalidated playlists %v, got %v", tt.wantInvalidated, mockDuplicate.invalidated)
+			}
+		})
+	}
+}
+
 // TestPlaylistService_AddArtistToPlaylist_OverrideScenarios tests override functionality
 func TestPlaylistService_AddArtistToPlaylist_OverrideScenarios(t *testing.T) {
 	tests := []struct {
 		name                string
-		force               bool
+		mode                types.AddMode
 		mockDuplicateResult *types.DuplicateResult
 		mockDuplicateError  error
 		expectedSuccess     bool
@@ -585,8 +815,8 @@ func TestPlaylistService_AddArtistToPlaylist_OverrideScenarios(t *testing.T) {
 		shouldCheckDups     bool
 	}{
 		{
-			name:  "force bypass with duplicates",
-			force: true,
+			name: "force bypass with duplicates",
+			mode: types.AddModeForce,
 			mockDuplicateResult: &types.DuplicateResult{
 				HasDuplicates: true,
 				Message:       "Artist tracks already exist",
@@ -597,8 +827,8 @@ func TestPlaylistService_AddArtistToPlaylist_OverrideScenarios(t *testing.T) {
 			shouldCheckDups:    false, // Force should bypass duplicate check
 		},
 		{
-			name:  "no force with duplicates blocks addition",
-			force: false,
+			name: "no force with duplicates blocks addition",
+			mode: types.AddModeSkip,
 			mockDuplicateResult: &types.DuplicateResult{
 				HasDuplicates: true,
 				Message:       "Artist 'Test Artist' already has tracks in playlist. Use 'Add Anyway' to override.",
@@ -609,8 +839,8 @@ func TestPlaylistService_AddArtistToPlaylist_OverrideScenarios(t *testing.T) {
 			shouldCheckDups:    true,
 		},
 		{
-			name:  "no force with no duplicates proceeds",
-			force: false,
+			name: "no force with no duplicates proceeds",
+			mode: types.AddModeSkip,
 			mockDuplicateResult: &types.DuplicateResult{
 				HasDuplicates: false,
 				Message:       "No duplicates found",
@@ -622,7 +852,7 @@ func TestPlaylistService_AddArtistToPlaylist_OverrideScenarios(t *testing.T) {
 		},
 		{
 			name:                "force with duplicate check error proceeds anyway",
-			force:               true,
+			mode:                types.AddModeForce,
 			mockDuplicateResult: nil,
 			mockDuplicateError:  errors.New("duplicate check failed"),
 			expectedSuccess:     true,
@@ -631,7 +861,7 @@ func TestPlaylistService_AddArtistToPlaylist_OverrideScenarios(t *testing.T) {
 		},
 		{
 			name:                "no force with duplicate check error proceeds with warning",
-			force:               false,
+			mode:                types.AddModeSkip,
 			mockDuplicateResult: nil,
 			mockDuplicateError:  errors.New("duplicate check failed"),
 			expectedSuccess:     true,
@@ -667,7 +897,7 @@ func TestPlaylistService_AddArtistToPlaylist_OverrideScenarios(t *testing.T) {
 
 			service := NewPlaylistService(mockSpotify, mockDuplicate, logger)
 
-			result, err := service.AddArtistToPlaylist("Test Artist", "playlist123", tt.force)
+			result, err := service.AddArtistToPlaylist(context.Background(), "Test Artist", "playlist123", tt.mode, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
 
 			if err != nil && tt.expectedSuccess {
 				t.Errorf("Unexpected error: %v", err)
@@ -685,7 +915,7 @@ func TestPlaylistService_AddArtistToPlaylist_OverrideScenarios(t *testing.T) {
 			}
 
 			// Verify that WasDuplicate is set correctly
-			if !tt.force && tt.mockDuplicateResult != nil && tt.mockDuplicateResult.HasDuplicates && tt.mockDuplicateError == nil {
+			if tt.mode == types.AddModeSkip && tt.mockDuplicateResult != nil && tt.mockDuplicateResult.HasDuplicates && tt.mockDuplicateError == nil {
 				if !result.WasDuplicate {
 					t.Error("Expected WasDuplicate to be true when duplicates are detected without force")
 				}
@@ -736,7 +966,7 @@ func TestPlaylistService_OverrideButtonFunctionality(t *testing.T) {
 	service := NewPlaylistService(mockSpotify, mockDuplicate, logger)
 
 	// First call without force - should detect duplicates and fail
-	result1, err1 := service.AddArtistToPlaylist("Test Artist", "playlist123", false)
+	result1, err1 := service.AddArtistToPlaylist(context.Background(), "Test Artist", "playlist123", types.AddModeSkip, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
 
 	if err1 != nil {
 		t.Errorf("Unexpected error on first call: %v", err1)
@@ -752,7 +982,7 @@ func TestPlaylistService_OverrideButtonFunctionality(t *testing.T) {
 	}
 
 	// Second call with force - should succeed despite duplicates
-	result2, err2 := service.AddArtistToPlaylist("Test Artist", "playlist123", true)
+	result2, err2 := service.AddArtistToPlaylist(context.Background(), "Test Artist", "playlist123", types.AddModeForce, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
 
 	if err2 != nil {
 		t.Errorf("Unexpected error on second call: %v", err2)
@@ -816,7 +1046,8 @@ func TestPlaylistService_APIOverrideParameter(t *testing.T) {
 
 			service := NewPlaylistService(mockSpotify, mockDuplicate, logger)
 
-			result, err := service.AddArtistToPlaylist("API Test Artist", "playlist123", tt.forceParam)
+			req := types.AddArtistRequest{Force: tt.forceParam}
+			result, err := service.AddArtistToPlaylist(context.Background(), "API Test Artist", "playlist123", req.AddMode(), types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
 
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
@@ -845,3 +1076,425 @@ func TestPlaylistService_APIOverrideParameter(t *testing.T) {
 		})
 	}
 }
+
+func TestPlaylistService_AddArtistToPlaylist_FillGaps(t *testing.T) {
+	tracks := []types.Track{
+		{ID: "track1", Name: "Song 1"},
+		{ID: "track2", Name: "Song 2"},
+		{ID: "track3", Name: "Song 3"},
+	}
+
+	tests := []struct {
+		name            string
+		inPlaylist      []bool
+		checkError      error
+		expectedSuccess bool
+		expectedDup     bool
+		expectedError   bool
+		expectedAdded   []string
+		expectedSkipped []string
+		expectedMessage string
+	}{
+		{
+			name:            "adds only missing tracks",
+			inPlaylist:      []bool{true, false, true},
+			expectedSuccess: true,
+			expectedAdded:   []string{"track2"},
+			expectedSkipped: []string{"track1", "track3"},
+			expectedMessage: "Successfully added 1 of Test Artist's top tracks to playlist (2 already present)",
+		},
+		{
+			name:            "adds every track when none are present",
+			inPlaylist:      []bool{false, false, false},
+			expectedSuccess: true,
+			expectedAdded:   []string{"track1", "track2", "track3"},
+			expectedMessage: "Successfully added Test Artist's top tracks to playlist",
+		},
+		{
+			name:            "all tracks already present",
+			inPlaylist:      []bool{true, true, true},
+			expectedDup:     true,
+			expectedSkipped: []string{"track1", "track2", "track3"},
+			expectedMessage: "All of Test Artist's top tracks are already in the playlist",
+		},
+		{
+			name:            "playlist check fails",
+			checkError:      errors.New("spotify api error"),
+			expectedError:   true,
+			expectedMessage: "Failed to check which tracks are already in the playlist: failed to check tracks in playlist: spotify api error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSpotify := &EnhancedMockSpotifyService{
+				artist: &types.Artist{ID: "artist123", Name: "Test Artist"},
+				tracks: tracks,
+			}
+			tracksResult := &types.DuplicateResult{}
+			for i, present := range tt.inPlaylist {
+				if present {
+					tracksResult.DuplicateTracks = append(tracksResult.DuplicateTracks, tracks[i])
+				}
+			}
+			// The artist-level check would refuse the artist; fill-gaps mode must not consult it
+			mockDuplicate := &EnhancedMockDuplicateDetector{
+				result:       &types.DuplicateResult{HasDuplicates: true, Message: "duplicate"},
+				tracksResult: tracksResult,
+				tracksErr:    tt.checkError,
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			service := NewPlaylistService(mockSpotify, mockDuplicate, logger)
+			result, err := service.AddArtistToPlaylist(context.Background(), "Test Artist", "playlist123", types.AddModeFillGaps, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
+
+			if tt.expectedError != (err != nil) {
+				t.Fatalf("Expected error %v, got %v", tt.expectedError, err)
+			}
+			if result.Success != tt.expectedSuccess {
+				t.Errorf("Expected success %v, got %v", tt.expectedSuccess, result.Success)
+			}
+			if result.WasDuplicate != tt.expectedDup {
+				t.Errorf("Expected WasDuplicate %v, got %v", tt.expectedDup, result.WasDuplicate)
+			}
+			if result.Message != tt.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tt.expectedMessage, result.Message)
+			}
+			if !reflect.DeepEqual(mockSpotify.addedIDs, tt.expectedAdded) {
+				t.Errorf("Expected added tracks %v, got %v", tt.expectedAdded, mockSpotify.addedIDs)
+			}
+
+			var skipped []string
+			for _, track := range result.TracksSkipped {
+				skipped = append(skipped, track.ID)
+			}
+			if !reflect.DeepEqual(skipped, tt.expectedSkipped) {
+				t.Errorf("Expected skipped tracks %v, got %v", tt.expectedSkipped, skipped)
+			}
+		})
+	}
+}
+
+func TestPlaylistService_FilterMissingTracks_WithoutDetector(t *testing.T) {
+	tracks := []types.Track{
+		{ID: "track1", Name: "Song 1"},
+		{ID: "track2", Name: "Song 2"},
+	}
+	mockSpotify := &EnhancedMockSpotifyService{inPlaylist: []bool{false, true}}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	service := NewPlaylistService(mockSpotify, nil, logger)
+	missing, present, err := service.FilterMissingTracks(context.Background(), "playlist123", tracks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(missing) != 1 || missing[0].ID != "track1" {
+		t.Errorf("Expected track1 missing, got %v", missing)
+	}
+	if len(present) != 1 || present[0].ID != "track2" {
+		t.Errorf("Expected track2 present, got %v", present)
+	}
+}
+
+func TestPlaylistService_AddArtistToPlaylist_InvalidMode(t *testing.T) {
+	mockSpotify := &EnhancedMockSpotifyService{
+		artist: &types.Artist{ID: "artist123", Name: "Test Artist"},
+		tracks: []types.Track{{ID: "track1", Name: "Song 1"}},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	service := NewPlaylistService(mockSpotify, nil, logger)
+	result, err := service.AddArtistToPlaylist(context.Background(), "Test Artist", "playlist123", types.AddMode("replace"), types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
+
+	if err == nil {
+		t.Fatal("Expected error for unknown add mode")
+	}
+	if result.Success {
+		t.Error("Expected failure for unknown add mode")
+	}
+	if len(mockSpotify.addedIDs) != 0 {
+		t.Errorf("Expected no tracks added, got %v", mockSpotify.addedIDs)
+	}
+}
+
+// mockArtistSearcher is a types.ArtistSearcher returning fixed candidates
+type mockArtistSearcher struct {
+	candidates []types.ArtistCandidate
+	err        error
+}
+
+func (m *mockArtistSearcher) FindBestMatch(ctx context.Context, query string) (*types.Artist, float64, error) {
+	if m.err != nil {
+		return nil, 0, m.err
+	}
+	return &m.candidates[0].Artist, m.candidates[0].Confidence, nil
+}
+
+func (m *mockArtistSearcher) FindCandidates(ctx context.Context, query string, limit int) ([]types.ArtistCandidate, error) {
+	return m.candidates, m.err
+}
+
+func TestPlaylistService_AddArtistToPlaylist_Disambiguation(t *testing.T) {
+	tests := []struct {
+		name            string
+		candidates      []types.ArtistCandidate
+		wantAmbiguous   bool
+		wantAddedArtist string
+	}{
+		{
+			name: "clear best match is added",
+			candidates: []types.ArtistCandidate{
+				{Artist: types.Artist{ID: "artist1", Name: "Nirvana"}, NameScore: 1.0, Confidence: 1.0},
+				{Artist: types.Artist{ID: "artist2", Name: "Nirvana"}, NameScore: 1.0, Confidence: 0.72},
+			},
+			wantAddedArtist: "artist1",
+		},
+		{
+			name: "equally prominent namesakes need disambiguation",
+			candidates: []types.ArtistCandidate{
+				{Artist: types.Artist{ID: "artist1", Name: "Low"}, NameScore: 1.0, Confidence: 1.0},
+				{Artist: types.Artist{ID: "artist2", Name: "Low"}, NameScore: 1.0, Confidence: 0.95},
+			},
+			wantAmbiguous: true,
+		},
+		{
+			name: "no confident candidate needs disambiguation",
+			candidates: []types.ArtistCandidate{
+				{Artist: types.Artist{ID: "artist1", Name: "The Lows"}, NameScore: 0.45, Confidence: 0.45},
+				{Artist: types.Artist{ID: "artist2", Name: "Lowly"}, NameScore: 0.4, Confidence: 0.3},
+			},
+			wantAmbiguous: true,
+		},
+		{
+			name: "single candidate is added",
+			candidates: []types.ArtistCandidate{
+				{Artist: types.Artist{ID: "artist1", Name: "The Lows"}, NameScore: 0.45, Confidence: 0.45},
+			},
+			wantAddedArtist: "artist1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSpotify := &EnhancedMockSpotifyService{
+				tracks: []types.Track{{ID: "track1", Name: "Song 1"}},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			service := NewPlaylistService(mockSpotify, nil, logger)
+			service.SetArtistSearcher(&mockArtistSearcher{candidates: tt.candidates})
+
+			result, err := service.AddArtistToPlaylist(context.Background(), "Low", "playlist123", types.AddModeSkip, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result.NeedsDisambiguation != tt.wantAmbiguous {
+				t.Errorf("Expected NeedsDisambiguation %v, got %v", tt.wantAmbiguous, result.NeedsDisambiguation)
+			}
+			if tt.wantAmbiguous {
+				if result.Success {
+					t.Error("Expected an ambiguous name not to be added")
+				}
+				if len(result.Candidates) != len(tt.candidates) {
+					t.Errorf("Expected %d candidates, got %d", len(tt.candidates), len(result.Candidates))
+				}
+				if len(mockSpotify.addedIDs) != 0 {
+					t.Errorf("Expected no tracks added, got %v", mockSpotify.addedIDs)
+				}
+				return
+			}
+
+			if !result.Success {
+				t.Errorf("Expected success, got: %s", result.Message)
+			}
+			if result.Artist.ID != tt.wantAddedArtist {
+				t.Errorf("Expected artist %s added, got %s", tt.wantAddedArtist, result.Artist.ID)
+			}
+		})
+	}
+}
+
+func TestPlaylistService_AddArtistByID(t *testing.T) {
+	mockSpotify := &EnhancedMockSpotifyService{
+		artist: &types.Artist{ID: "artist123", Name: "Test Artist"},
+		tracks: []types.Track{{ID: "track1", Name: "Song 1"}},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	service := NewPlaylistService(mockSpotify, nil, logger)
+
+	result, err := service.AddArtistByID(context.Background(), "artist123", "playlist123", types.AddModeSkip, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceUI})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Success || result.Artist.ID != "artist123" {
+		t.Errorf("Expected artist123 to be added, got %+v", result)
+	}
+	if len(mockSpotify.addedIDs) != 1 || mockSpotify.addedIDs[0] != "track1" {
+		t.Errorf("Expected track1 added, got %v", mockSpotify.addedIDs)
+	}
+
+	result, err = service.AddArtistByID(context.Background(), "unknown", "playlist123", types.AddModeSkip, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceUI})
+	if !errors.Is(err, spotify.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown artist, got %v", err)
+	}
+	if result == nil || result.Success {
+		t.Error("Expected a failed result for an unknown artist")
+	}
+}
+
+// blocklistFunc adapts a function to the ArtistBlocklist interface
+type blocklistFunc func(artist types.Artist) (bool, types.BlockReason)
+
+func (f blocklistFunc) Blocked(artist types.Artist) (bool, types.BlockReason) {
+	return f(artist)
+}
+
+func TestPlaylistService_AddArtistToPlaylist_Blocklist(t *testing.T) {
+	blockGenre := blocklistFunc(func(artist types.Artist) (bool, types.BlockReason) {
+		if slices.Contains(artist.Genres, "sleep") {
+			return true, types.BlockReasonGenre
+		}
+		return false, ""
+	})
+
+	tests := []struct {
+		name        string
+		artist      *types.Artist
+		mode        types.AddMode
+		wantBlocked bool
+	}{
+		{name: "blocked", artist: &types.Artist{ID: "artist123", Name: "Rain Sounds", Genres: []string{"sleep"}}, mode: types.AddModeSkip, wantBlocked: true},
+		{name: "blocked when forced", artist: &types.Artist{ID: "artist123", Name: "Rain Sounds", Genres: []string{"sleep"}}, mode: types.AddModeForce, wantBlocked: true},
+		{name: "allowed", artist: &types.Artist{ID: "artist456", Name: "Test Artist", Genres: []string{"rock"}}, mode: types.AddModeSkip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSpotify := &EnhancedMockSpotifyService{
+				artist: tt.artist,
+				tracks: []types.Track{{ID: "track1", Name: "Song 1"}},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			history := &recordingHistory{}
+			service := NewPlaylistService(mockSpotify, nil, logger)
+			service.SetHistory(history)
+			service.SetBlocklist(blockGenre)
+
+			result, err := service.AddArtistToPlaylist(context.Background(), tt.artist.Name, "playlist123", tt.mode, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result.Blocked != tt.wantBlocked {
+				t.Fatalf("Expected blocked %v, got %+v", tt.wantBlocked, result)
+			}
+			if !tt.wantBlocked {
+				if !result.Success {
+					t.Errorf("Expected the allowed artist to be added, got %+v", result)
+				}
+				return
+			}
+			if result.Success || result.BlockReason != types.BlockReasonGenre || result.Artist.ID != tt.artist.ID {
+				t.Errorf("Expected a genre block of %s, got %+v", tt.artist.ID, result)
+			}
+			if len(mockSpotify.addedIDs) != 0 || len(history.entries) != 0 {
+				t.Errorf("Expected nothing added for a blocked artist, added %v and recorded %d entries", mockSpotify.addedIDs, len(history.entries))
+			}
+		})
+	}
+}
+
+// routerFunc adapts a function to the PlaylistRouter interface
+type routerFunc func(artist types.Artist) (*types.PlaylistRoute, error)
+
+func (f routerFunc) Route(ctx context.Context, artist types.Artist) (*types.PlaylistRoute, error) {
+	return f(artist)
+}
+
+func TestPlaylistService_AddArtistToPlaylist_AutoRouting(t *testing.T) {
+	heavy := &types.PlaylistRoute{PlaylistID: "heavy123", PlaylistName: "Incoming Heavy", Genre: "metal"}
+	byGenre := routerFunc(func(artist types.Artist) (*types.PlaylistRoute, error) {
+		switch {
+		case slices.Contains(artist.Genres, "black metal"):
+			return heavy, nil
+		case slices.Contains(artist.Genres, "broken"):
+			return nil, errors.New("routing playlist \"Incoming Broken\" not found")
+		default:
+			return nil, nil
+		}
+	})
+
+	tests := []struct {
+		name            string
+		genres          []string
+		router          types.PlaylistRouter
+		wantSuccess     bool
+		wantErr         bool
+		wantPlaylistID  string
+		wantRoute       *types.PlaylistRoute
+		wantMessagePart string
+	}{
+		{name: "routed", genres: []string{"black metal"}, router: byGenre, wantSuccess: true, wantPlaylistID: "heavy123", wantRoute: heavy},
+		{name: "no matching rule", genres: []string{"folk"}, router: byGenre, wantMessagePart: "No routing rule matches"},
+		{name: "routing fails", genres: []string{"broken"}, router: byGenre, wantErr: true, wantMessagePart: "Failed to route artist"},
+		{name: "no router", genres: []string{"black metal"}, wantErr: true, wantMessagePart: "not configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSpotify := &EnhancedMockSpotifyService{
+				artist: &types.Artist{ID: "artist123", Name: "Test Artist", Genres: tt.genres},
+				tracks: []types.Track{{ID: "track1", Name: "Song 1"}},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			history := &recordingHistory{}
+			service := NewPlaylistService(mockSpotify, nil, logger)
+			service.SetHistory(history)
+			if tt.router != nil {
+				service.SetRouter(tt.router)
+			}
+
+			result, err := service.AddArtistToPlaylist(context.Background(), "Test Artist", types.AutoPlaylistID, types.AddModeSkip, types.DefaultTrackSelection(), types.AddSource{Kind: types.SourceAPI})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddArtistToPlaylist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("Expected success %v, got %+v", tt.wantSuccess, result)
+			}
+			if !reflect.DeepEqual(result.Route, tt.wantRoute) {
+				t.Errorf("Expected route %+v, got %+v", tt.wantRoute, result.Route)
+			}
+			if tt.wantMessagePart != "" && !strings.Contains(result.Message, tt.wantMessagePart) {
+				t.Errorf("Expected message containing %q, got %q", tt.wantMessagePart, result.Message)
+			}
+
+			if tt.wantPlaylistID == "" {
+				if len(mockSpotify.addedIDs) != 0 {
+					t.Errorf("Expected nothing added, got %v", mockSpotify.addedIDs)
+				}
+				return
+			}
+			if len(history.entries) != 1 || history.entries[0].PlaylistID != tt.wantPlaylistID {
+				t.Errorf("Expected the addition recorded against %s, got %+v", tt.wantPlaylistID, history.entries)
+			}
+		})
+	}
+}
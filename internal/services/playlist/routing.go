@@ -0,0 +1,144 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+// ErrNoRouter is returned when an artist is added to the auto playlist before routing rules are set
+var ErrNoRouter = errors.New("automatic playlist routing is not configured")
+
+// routerPlaylistTTL is how long the user's playlists are cached for resolving routing targets by name
+const routerPlaylistTTL = 5 * time.Minute
+
+// RoutingRule sends artists with a genre containing Genre to Playlist
+type RoutingRule struct {
+	Genre    string
+	Playlist string
+}
+
+// Router routes artists to playlists by genre. Rules are tried in order and the
+// first whose genre is part of one of the artist's genres wins; artists no rule
+// matches go to the default playlist. Playlists are given by ID or name.
+type Router struct {
+	spotify         types.SpotifyService
+	rules           []RoutingRule
+	defaultPlaylist string
+
+	mu        sync.Mutex // guards the cached playlists, never held across Spotify calls
+	playlists []types.Playlist
+	fetched   time.Time
+}
+
+// NewRouter creates a router from configuration, resolving playlist names through spotify
+func NewRouter(cfg config.RoutingConfig, spotify types.SpotifyService) (*Router, error) {
+	router := &Router{
+		spotify:         spotify,
+		defaultPlaylist: strings.TrimSpace(cfg.DefaultPlaylist),
+	}
+
+	for _, rule := range cfg.Rules {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		genre, playlist, ok := strings.Cut(rule, "=")
+		genre, playlist = strings.ToLower(strings.TrimSpace(genre)), strings.TrimSpace(playlist)
+		if !ok || genre == "" || playlist == "" {
+			return nil, fmt.Errorf("invalid routing rule %q: want genre=playlist", rule)
+		}
+		router.rules = append(router.rules, RoutingRule{Genre: genre, Playlist: playlist})
+	}
+
+	return router, nil
+}
+
+// Route returns the playlist an artist is routed to, or nil if no rule matches
+// the artist's genres and no default playlist is configured
+func (r *Router) Route(ctx context.Context, artist types.Artist) (*types.PlaylistRoute, error) {
+	target, genre := r.match(artist.Genres)
+	if target == "" {
+		return nil, nil
+	}
+
+	playlist, err := r.resolve(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return &types.PlaylistRoute{
+		PlaylistID:   playlist.ID,
+		PlaylistName: playlist.Name,
+		Genre:        genre,
+	}, nil
+}
+
+// match returns the playlist of the first rule matching one of the genres, with
+// the rule's genre, or the default playlist if none does
+func (r *Router) match(genres []string) (playlist, genre string) {
+	for _, rule := range r.rules {
+		for _, g := range genres {
+			if strings.Contains(strings.ToLower(g), rule.Genre) {
+				return rule.Playlist, rule.Genre
+			}
+		}
+	}
+	return r.defaultPlaylist, ""
+}
+
+// resolve finds the playlist a routing target names, by ID or else by name. A
+// target shaped like a Spotify ID is used as is when it isn't among the user's
+// playlists, since Spotify only lists playlists the user owns or follows.
+func (r *Router) resolve(ctx context.Context, target string) (types.Playlist, error) {
+	target = strings.TrimPrefix(target, "spotify:playlist:")
+
+	playlists, err := r.userPlaylists(ctx)
+	if err != nil {
+		if types.IsSpotifyID(target) {
+			return types.Playlist{ID: target}, nil
+		}
+		return types.Playlist{}, fmt.Errorf("failed to resolve routing playlist %q: %w", target, err)
+	}
+
+	for _, playlist := range playlists {
+		if playlist.ID == target {
+			return playlist, nil
+		}
+	}
+	for _, playlist := range playlists {
+		if strings.EqualFold(playlist.Name, target) {
+			return playlist, nil
+		}
+	}
+	if types.IsSpotifyID(target) {
+		return types.Playlist{ID: target}, nil
+	}
+	return types.Playlist{}, fmt.Errorf("routing playlist %q not found", target)
+}
+
+// userPlaylists returns the user's playlists, cached for routerPlaylistTTL. They are
+// listed without holding the lock so a slow Spotify call doesn't stall other routes.
+func (r *Router) userPlaylists(ctx context.Context) ([]types.Playlist, error) {
+	r.mu.Lock()
+	playlists, fetched := r.playlists, r.fetched
+	r.mu.Unlock()
+	if playlists != nil && time.Since(fetched) < routerPlaylistTTL {
+		return playlists, nil
+	}
+
+	playlists, err := r.spotify.GetUserPlaylists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.playlists = playlists
+	r.fetched = time.Now()
+	r.mu.Unlock()
+	return playlists, nil
+}
@@ -0,0 +1,192 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+const (
+	heavyPlaylistID = "37i9dQZF1DX9qNs32fujYe"
+	jazzPlaylistID  = "37i9dQZF1DXbITWG1ZJKYt"
+	otherPlaylistID = "37i9dQZF1DX0XUsuxWHRQd"
+)
+
+// countingPlaylistLister counts how often the user's playlists are listed
+type countingPlaylistLister struct {
+	MockSpotifyService
+	calls int
+}
+
+func (c *countingPlaylistLister) GetUserPlaylists(ctx context.Context) ([]types.Playlist, error) {
+	c.calls++
+	return c.MockSpotifyService.GetUserPlaylists(ctx)
+}
+
+func TestRouter_Route(t *testing.T) {
+	spotify := &countingPlaylistLister{MockSpotifyService: MockSpotifyService{playlists: []types.Playlist{
+		{ID: heavyPlaylistID, Name: "Incoming Heavy"},
+		{ID: jazzPlaylistID, Name: "Incoming Jazz"},
+	}}}
+	router, err := NewRouter(config.RoutingConfig{
+		Rules:           []string{"Metal=incoming heavy", " jazz = " + jazzPlaylistID, "punk=spotify:playlist:" + otherPlaylistID},
+		DefaultPlaylist: "Incoming Jazz",
+	}, spotify)
+	if err != nil {
+		t.Fatalf("NewRouter() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		genres []string
+		want   types.PlaylistRoute
+	}{
+		{
+			name:   "genre contains rule",
+			genres: []string{"indie", "Black Metal"},
+			want:   types.PlaylistRoute{PlaylistID: heavyPlaylistID, PlaylistName: "Incoming Heavy", Genre: "metal"},
+		},
+		{
+			name:   "first rule wins",
+			genres: []string{"jazz metal"},
+			want:   types.PlaylistRoute{PlaylistID: heavyPlaylistID, PlaylistName: "Incoming Heavy", Genre: "metal"},
+		},
+		{
+			name:   "rule by ID",
+			genres: []string{"jazz fusion"},
+			want:   types.PlaylistRoute{PlaylistID: jazzPlaylistID, PlaylistName: "Incoming Jazz", Genre: "jazz"},
+		},
+		{
+			name:   "unlisted playlist URI",
+			genres: []string{"pop punk"},
+			want:   types.PlaylistRoute{PlaylistID: otherPlaylistID, Genre: "punk"},
+		},
+		{
+			name:   "default playlist",
+			genres: []string{"folk"},
+			want:   types.PlaylistRoute{PlaylistID: jazzPlaylistID, PlaylistName: "Incoming Jazz"},
+		},
+		{
+			name: "no genres",
+			want: types.PlaylistRoute{PlaylistID: jazzPlaylistID, PlaylistName: "Incoming Jazz"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := router.Route(context.Background(), types.Artist{ID: "artist1", Genres: tt.genres})
+			if err != nil {
+				t.Fatalf("Route() unexpected error: %v", err)
+			}
+			if route == nil || *route != tt.want {
+				t.Errorf("Route() = %+v, want %+v", route, tt.want)
+			}
+		})
+	}
+
+	if spotify.calls != 1 {
+		t.Errorf("Expected the playlists to be listed once, got %d calls", spotify.calls)
+	}
+}
+
+// blockingPlaylistLister holds its first listing until release is closed
+type blockingPlaylistLister struct {
+	MockSpotifyService
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (b *blockingPlaylistLister) GetUserPlaylists(ctx context.Context) ([]types.Playlist, error) {
+	if b.calls.Add(1) == 1 {
+		close(b.started)
+		<-b.release
+	}
+	return b.MockSpotifyService.GetUserPlaylists(ctx)
+}
+
+func TestRouter_SlowListingDoesNotBlock(t *testing.T) {
+	spotify := &blockingPlaylistLister{
+		MockSpotifyService: MockSpotifyService{playlists: []types.Playlist{{ID: heavyPlaylistID, Name: "Incoming Heavy"}}},
+		started:            make(chan struct{}),
+		release:            make(chan struct{}),
+	}
+	router, err := NewRouter(config.RoutingConfig{DefaultPlaylist: "Incoming Heavy"}, spotify)
+	if err != nil {
+		t.Fatalf("NewRouter() unexpected error: %v", err)
+	}
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := router.Route(context.Background(), types.Artist{ID: "artist1"})
+		slow <- err
+	}()
+	<-spotify.started
+
+	// Another route lists the playlists itself rather than waiting on the stuck listing
+	done := make(chan error, 1)
+	go func() {
+		_, err := router.Route(context.Background(), types.Artist{ID: "artist2"})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Route() unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Route() blocked behind a slow playlist listing")
+	}
+
+	close(spotify.release)
+	if err := <-slow; err != nil {
+		t.Errorf("Route() unexpected error after the slow listing: %v", err)
+	}
+}
+
+func TestRouter_NoMatch(t *testing.T) {
+	router, err := NewRouter(config.RoutingConfig{Rules: []string{"metal=" + heavyPlaylistID}}, &MockSpotifyService{})
+	if err != nil {
+		t.Fatalf("NewRouter() unexpected error: %v", err)
+	}
+
+	route, err := router.Route(context.Background(), types.Artist{ID: "artist1", Genres: []string{"jazz"}})
+	if err != nil || route != nil {
+		t.Errorf("Route() = %+v, %v, want no route without a default playlist", route, err)
+	}
+}
+
+func TestRouter_UnknownPlaylistName(t *testing.T) {
+	tests := []struct {
+		name    string
+		spotify *MockSpotifyService
+	}{
+		{name: "not among playlists", spotify: &MockSpotifyService{playlists: []types.Playlist{{ID: heavyPlaylistID, Name: "Incoming Heavy"}}}},
+		{name: "listing fails", spotify: &MockSpotifyService{err: errors.New("unauthorized")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := NewRouter(config.RoutingConfig{DefaultPlaylist: "Incoming Folk"}, tt.spotify)
+			if err != nil {
+				t.Fatalf("NewRouter() unexpected error: %v", err)
+			}
+			if _, err := router.Route(context.Background(), types.Artist{ID: "artist1"}); err == nil {
+				t.Error("Route() expected error for a playlist name that can't be resolved")
+			}
+		})
+	}
+}
+
+func TestNewRouter_InvalidRule(t *testing.T) {
+	for _, rule := range []string{"metal", "=Incoming Heavy", "metal="} {
+		if _, err := NewRouter(config.RoutingConfig{Rules: []string{rule}}, &MockSpotifyService{}); err == nil {
+			t.Errorf("NewRouter() expected error for rule %q", rule)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/logging"
+)
+
+// Service decides on the items of the review queue, adding the approved artists
+// through the playlist manager so the blocklist, routing and add mode still apply
+type Service struct {
+	store    *SQLiteStore
+	playlist types.PlaylistManager
+	logger   *logging.Logger
+
+	mu sync.Mutex
+	// deciding holds the IDs of the items being accepted or rejected
+	deciding map[int64]bool
+}
+
+// NewService creates a review service deciding on the items of store
+func NewService(store *SQLiteStore, playlist types.PlaylistManager, logger *logrus.Logger) *Service {
+	return &Service{store: store, playlist: playlist, logger: logging.Wrap(logger), deciding: make(map[int64]bool)}
+}
+
+// Store returns the queue the service decides on
+func (s *Service) Store() *SQLiteStore {
+	return s.store
+}
+
+// Accept adds the artist of a pending item to the playlist it was scraped for, with
+// the add mode and track selection of the scrape. artistID remaps the item to another
+// artist than its best candidate; empty accepts the best candidate. An artist already
+// in the playlist counts as added. The item stays pending when the artist could not
+// be added, so it can be retried: with the add's error, or with ErrNotAdded and the
+// result saying why when nothing was added. An item already decided on is returned
+// with ErrResolved, and ErrDeciding is returned while another decision is in progress.
+func (s *Service) Accept(ctx context.Context, id int64, artistID string, source types.AddSource) (*types.ReviewItem, *types.AddResult, error) {
+	if !s.claim(id) {
+		return nil, nil, ErrDeciding
+	}
+	defer s.release(id)
+
+	item, err := s.pending(id)
+	if err != nil {
+		return item, nil, err
+	}
+
+	status := types.ReviewAccepted
+	if artistID == "" {
+		artistID = item.Artist.ID
+	} else if artistID != item.Artist.ID {
+		status = types.ReviewRemapped
+	}
+	if artistID == "" {
+		return item, nil, errors.New("review item has no artist to accept")
+	}
+
+	// The addition is credited to the scraped page the artist was found on
+	source.URL = item.SourceURL
+	result, err := s.playlist.AddArtistByID(ctx, artistID, item.PlaylistID, item.Mode, item.Selection, source)
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"component": "review",
+			"operation": "accept",
+			"review_id": id,
+			"artist_id": artistID,
+		}).Error("Failed to add reviewed artist, leaving it pending")
+		return item, result, fmt.Errorf("failed to add artist: %w", err)
+	}
+	if !result.Success && !result.WasDuplicate {
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"component": "review",
+			"operation": "accept",
+			"review_id": id,
+			"artist_id": artistID,
+			"message":   result.Message,
+		}).Warn("Reviewed artist was not added, leaving it pending")
+		return item, result, fmt.Errorf("%w: %s", ErrNotAdded, result.Message)
+	}
+
+	if err := s.resolve(ctx, item, status, artistID); err != nil {
+		return item, result, err
+	}
+	return item, result, nil
+}
+
+// Reject dismisses a pending item without adding anything
+func (s *Service) Reject(ctx context.Context, id int64) (*types.ReviewItem, error) {
+	if !s.claim(id) {
+		return nil, ErrDeciding
+	}
+	defer s.release(id)
+
+	item, err := s.pending(id)
+	if err != nil {
+		return item, err
+	}
+	if err := s.resolve(ctx, item, types.ReviewRejected, ""); err != nil {
+		return item, err
+	}
+	return item, nil
+}
+
+// claim marks an item as being decided on, reporting false if it already was
+func (s *Service) claim(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.deciding[id] {
+		return false
+	}
+	s.deciding[id] = true
+	return true
+}
+
+// release marks an item as no longer being decided on
+func (s *Service) release(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.deciding, id)
+}
+
+// pending returns an item that has not been decided on yet, or the item with
+// ErrResolved when it has
+func (s *Service) pending(id int64) (*types.ReviewItem, error) {
+	item, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != types.ReviewPending {
+		return item, ErrResolved
+	}
+	return item, nil
+}
+
+// resolve records the decision on an item, updating it to match
+func (s *Service) resolve(ctx context.Context, item *types.ReviewItem, status types.ReviewStatus, artistID string) error {
+	if err := s.store.Resolve(item.ID, status, artistID); err != nil {
+		return err
+	}
+	resolved, err := s.store.Get(item.ID)
+	if err != nil {
+		return err
+	}
+	*item = *resolved
+
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component": "review",
+		"operation": "resolve",
+		"review_id": item.ID,
+		"query":     item.Query,
+		"status":    status,
+		"artist_id": artistID,
+	}).Info("Resolved review item")
+	return nil
+}
@@ -0,0 +1,418 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+func openTestStore(t *testing.T) (*SQLiteStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data", "review.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, path
+}
+
+func newItem(query, playlistID string) *types.ReviewItem {
+	return &types.ReviewItem{
+		Query:      query,
+		Artist:     types.Artist{ID: "best", Name: query},
+		Confidence: 0.6,
+		Candidates: []types.ArtistCandidate{
+			{Artist: types.Artist{ID: "best", Name: query}, Confidence: 0.6},
+			{Artist: types.Artist{ID: "other", Name: query + " Band"}, Confidence: 0.4},
+		},
+		PlaylistID: playlistID,
+		Mode:       types.AddModeSkip,
+		Selection:  types.TrackSelection{Count: 3, Strategy: types.TrackStrategyTop},
+		Source:     types.SourceCLI,
+		SourceURL:  "https://example.com/lineup",
+		FoundBy:    "text",
+	}
+}
+
+func TestOpen(t *testing.T) {
+	store, path := openTestStore(t)
+
+	if err := store.Enqueue(newItem("Artist", "playlist1")); err != nil {
+		t.Fatalf("Enqueue() unexpected error: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+
+	// Reopening an existing database must not reapply migrations or lose data
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() on existing database unexpected error: %v", err)
+	}
+	defer reopened.Close()
+
+	items, err := reopened.List(types.ReviewFilter{})
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(items) != 1 || items[0].Query != "Artist" {
+		t.Errorf("List() after reopen = %+v, want the queued item", items)
+	}
+
+	if _, err := Open(""); err == nil {
+		t.Error("Open(\"\") expected error but got none")
+	}
+}
+
+func TestSQLiteStore_Enqueue(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	first := newItem("Big Thief", "playlist1")
+	if err := store.Enqueue(first); err != nil {
+		t.Fatalf("Enqueue() unexpected error: %v", err)
+	}
+	if first.ID == 0 || first.Status != types.ReviewPending {
+		t.Fatalf("Enqueue() = %+v, want a pending item with an ID", first)
+	}
+
+	got, err := store.Get(first.ID)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if got.Artist.ID != "best" || len(got.Candidates) != 2 || got.Selection.Count != 3 ||
+		got.SourceURL != first.SourceURL || got.FoundBy != "text" || got.ResolvedAt != nil {
+		t.Errorf("Get() = %+v, want the queued item", got)
+	}
+
+	// The same name for the same playlist waits for review once
+	again := newItem("  big   THIEF ", "playlist1")
+	if err := store.Enqueue(again); err != nil {
+		t.Fatalf("Enqueue() again unexpected error: %v", err)
+	}
+	if again.ID != first.ID {
+		t.Errorf("Enqueue() again ID = %d, want the pending item's %d", again.ID, first.ID)
+	}
+
+	// but is queued separately for another playlist, or once the first was resolved
+	other := newItem("Big Thief", "playlist2")
+	if err := store.Enqueue(other); err != nil {
+		t.Fatalf("Enqueue() other playlist unexpected error: %v", err)
+	}
+	if other.ID == first.ID {
+		t.Error("Enqueue() for another playlist reused the pending item")
+	}
+	if err := store.Resolve(first.ID, types.ReviewRejected, ""); err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	requeued := newItem("Big Thief", "playlist1")
+	if err := store.Enqueue(requeued); err != nil {
+		t.Fatalf("Enqueue() after resolving unexpected error: %v", err)
+	}
+	if requeued.ID == first.ID {
+		t.Error("Enqueue() after resolving reused the resolved item")
+	}
+}
+
+func TestSQLiteStore_List(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	for _, item := range []*types.ReviewItem{
+		newItem("One", "playlist1"),
+		newItem("Two", "playlist2"),
+		newItem("Three", "playlist1"),
+	} {
+		if err := store.Enqueue(item); err != nil {
+			t.Fatalf("Enqueue() unexpected error: %v", err)
+		}
+	}
+	if err := store.Resolve(1, types.ReviewAccepted, "best"); err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter types.ReviewFilter
+		want   []string
+	}{
+		{name: "all, oldest first", filter: types.ReviewFilter{}, want: []string{"One", "Two", "Three"}},
+		{name: "pending", filter: types.ReviewFilter{Status: types.ReviewPending}, want: []string{"Two", "Three"}},
+		{name: "accepted", filter: types.ReviewFilter{Status: types.ReviewAccepted}, want: []string{"One"}},
+		{name: "playlist", filter: types.ReviewFilter{PlaylistID: "playlist1"}, want: []string{"One", "Three"}},
+		{name: "paged", filter: types.ReviewFilter{Limit: 1, Offset: 1}, want: []string{"Two"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := store.List(tt.filter)
+			if err != nil {
+				t.Fatalf("List() unexpected error: %v", err)
+			}
+			var got []string
+			for _, item := range items {
+				got = append(got, item.Query)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("List() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("List() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteStore_Resolve(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	item := newItem("Artist", "playlist1")
+	if err := store.Enqueue(item); err != nil {
+		t.Fatalf("Enqueue() unexpected error: %v", err)
+	}
+
+	if err := store.Resolve(item.ID, types.ReviewPending, ""); err == nil {
+		t.Error("Resolve() as pending expected error but got none")
+	}
+	if err := store.Resolve(item.ID, types.ReviewRemapped, "other"); err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+
+	got, err := store.Get(item.ID)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if got.Status != types.ReviewRemapped || got.ResolvedArtistID != "other" || got.ResolvedAt == nil {
+		t.Errorf("Get() after Resolve() = %+v, want it remapped to other", got)
+	}
+
+	if err := store.Resolve(item.ID, types.ReviewRejected, ""); !errors.Is(err, ErrResolved) {
+		t.Errorf("Resolve() twice error = %v, want ErrResolved", err)
+	}
+	if err := store.Resolve(999, types.ReviewRejected, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve() unknown item error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() unknown item error = %v, want ErrNotFound", err)
+	}
+}
+
+// mockPlaylistManager records the artists added by ID, failing for unknown ones and
+// returning result, when set, instead of adding
+type mockPlaylistManager struct {
+	types.PlaylistManager
+	known  map[string]bool
+	result *types.AddResult
+	// block, when set, holds every addition until it is closed
+	block chan struct{}
+
+	mu     sync.Mutex
+	added  []string
+	source types.AddSource
+}
+
+func (m *mockPlaylistManager) AddArtistByID(ctx context.Context, artistID, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	m.mu.Lock()
+	m.added = append(m.added, artistID)
+	m.source = source
+	m.mu.Unlock()
+	if m.block != nil {
+		<-m.block
+	}
+	if m.result != nil {
+		return m.result, nil
+	}
+	if !m.known[artistID] {
+		return &types.AddResult{Message: "Failed to find artist: not found"}, errors.New("not found")
+	}
+	return &types.AddResult{Success: true, Artist: types.Artist{ID: artistID}}, nil
+}
+
+func newTestService(t *testing.T, known ...string) (*Service, *mockPlaylistManager) {
+	t.Helper()
+	store, _ := openTestStore(t)
+	playlist := &mockPlaylistManager{known: map[string]bool{}}
+	for _, id := range known {
+		playlist.known[id] = true
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewService(store, playlist, logger), playlist
+}
+
+func TestService_Accept(t *testing.T) {
+	tests := []struct {
+		name       string
+		artistID   string
+		wantAdded  string
+		wantStatus types.ReviewStatus
+	}{
+		{name: "best candidate", wantAdded: "best", wantStatus: types.ReviewAccepted},
+		{name: "best candidate by ID", artistID: "best", wantAdded: "best", wantStatus: types.ReviewAccepted},
+		{name: "remapped", artistID: "other", wantAdded: "other", wantStatus: types.ReviewRemapped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, playlist := newTestService(t, "best", "other")
+			queued := newItem("Artist", "playlist1")
+			if err := service.Store().Enqueue(queued); err != nil {
+				t.Fatalf("Enqueue() unexpected error: %v", err)
+			}
+
+			item, result, err := service.Accept(context.Background(), queued.ID, tt.artistID, types.AddSource{Kind: types.SourceAPI})
+			if err != nil {
+				t.Fatalf("Accept() unexpected error: %v", err)
+			}
+			if !result.Success || len(playlist.added) != 1 || playlist.added[0] != tt.wantAdded {
+				t.Errorf("Accept() added %v with %+v, want %s", playlist.added, result, tt.wantAdded)
+			}
+			if playlist.source.URL != queued.SourceURL || playlist.source.Kind != types.SourceAPI {
+				t.Errorf("Accept() source = %+v, want the API crediting the scraped page", playlist.source)
+			}
+			if item.Status != tt.wantStatus || item.ResolvedArtistID != tt.wantAdded || item.ResolvedAt == nil {
+				t.Errorf("Accept() item = %+v, want it %s with %s", item, tt.wantStatus, tt.wantAdded)
+			}
+
+			if _, _, err := service.Accept(context.Background(), queued.ID, "", types.AddSource{}); !errors.Is(err, ErrResolved) {
+				t.Errorf("Accept() twice error = %v, want ErrResolved", err)
+			}
+		})
+	}
+}
+
+func TestService_Accept_Failed(t *testing.T) {
+	service, _ := newTestService(t)
+	queued := newItem("Artist", "playlist1")
+	if err := service.Store().Enqueue(queued); err != nil {
+		t.Fatalf("Enqueue() unexpected error: %v", err)
+	}
+
+	if _, _, err := service.Accept(context.Background(), queued.ID, "", types.AddSource{}); err == nil {
+		t.Fatal("Accept() expected error but got none")
+	}
+	// A failed addition leaves the item to be retried
+	item, err := service.Store().Get(queued.ID)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if item.Status != types.ReviewPending {
+		t.Errorf("Status after a failed Accept() = %s, want pending", item.Status)
+	}
+
+	if _, _, err := service.Accept(context.Background(), 999, "", types.AddSource{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Accept() unknown item error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestService_Accept_NotAdded(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     types.AddResult
+		wantStatus types.ReviewStatus
+		wantErr    error
+	}{
+		{name: "no tracks", result: types.AddResult{Message: "Artist has no tracks available"}, wantStatus: types.ReviewPending, wantErr: ErrNotAdded},
+		{name: "no route", result: types.AddResult{Message: "No routing rule matches Artist"}, wantStatus: types.ReviewPending, wantErr: ErrNotAdded},
+		{name: "blocked", result: types.AddResult{Blocked: true, Message: "Artist is blocklisted"}, wantStatus: types.ReviewPending, wantErr: ErrNotAdded},
+		// An artist already in the playlist needs no addition
+		{name: "duplicate", result: types.AddResult{WasDuplicate: true, Message: "Artist already in playlist"}, wantStatus: types.ReviewAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, playlist := newTestService(t, "best")
+			playlist.result = &tt.result
+			queued := newItem("Artist", "playlist1")
+			if err := service.Store().Enqueue(queued); err != nil {
+				t.Fatalf("Enqueue() unexpected error: %v", err)
+			}
+
+			_, result, err := service.Accept(context.Background(), queued.ID, "", types.AddSource{})
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Errorf("Accept() error = %v, want %v", err, tt.wantErr)
+			}
+			if result == nil || result.Message != tt.result.Message {
+				t.Errorf("Accept() result = %+v, want the addition's result", result)
+			}
+			item, err := service.Store().Get(queued.ID)
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			if item.Status != tt.wantStatus {
+				t.Errorf("Status after Accept() = %s, want %s", item.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestService_Accept_Concurrent(t *testing.T) {
+	service, playlist := newTestService(t, "best")
+	playlist.block = make(chan struct{})
+	queued := newItem("Artist", "playlist1")
+	if err := service.Store().Enqueue(queued); err != nil {
+		t.Fatalf("Enqueue() unexpected error: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, _, err := service.Accept(context.Background(), queued.ID, "", types.AddSource{})
+		done <- err
+	}()
+
+	// Wait for the first decision to start adding the artist
+	for {
+		playlist.mu.Lock()
+		adding := len(playlist.added) == 1
+		playlist.mu.Unlock()
+		if adding {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, _, err := service.Accept(context.Background(), queued.ID, "", types.AddSource{}); !errors.Is(err, ErrDeciding) {
+		t.Errorf("Accept() while deciding error = %v, want ErrDeciding", err)
+	}
+	if _, err := service.Reject(context.Background(), queued.ID); !errors.Is(err, ErrDeciding) {
+		t.Errorf("Reject() while deciding error = %v, want ErrDeciding", err)
+	}
+
+	close(playlist.block)
+	if err := <-done; err != nil {
+		t.Fatalf("Accept() unexpected error: %v", err)
+	}
+	if len(playlist.added) != 1 {
+		t.Errorf("Added %v, want the artist added once", playlist.added)
+	}
+	if _, _, err := service.Accept(context.Background(), queued.ID, "", types.AddSource{}); !errors.Is(err, ErrResolved) {
+		t.Errorf("Accept() after deciding error = %v, want ErrResolved", err)
+	}
+}
+
+func TestService_Reject(t *testing.T) {
+	service, playlist := newTestService(t, "best")
+	queued := newItem("Artist", "playlist1")
+	if err := service.Store().Enqueue(queued); err != nil {
+		t.Fatalf("Enqueue() unexpected error: %v", err)
+	}
+
+	item, err := service.Reject(context.Background(), queued.ID)
+	if err != nil {
+		t.Fatalf("Reject() unexpected error: %v", err)
+	}
+	if item.Status != types.ReviewRejected || item.ResolvedArtistID != "" || len(playlist.added) != 0 {
+		t.Errorf("Reject() = %+v after adding %v, want it rejected with nothing added", item, playlist.added)
+	}
+	if _, err := service.Reject(context.Background(), queued.ID); !errors.Is(err, ErrResolved) {
+		t.Errorf("Reject() twice error = %v, want ErrResolved", err)
+	}
+}
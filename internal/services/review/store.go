@@ -0,0 +1,313 @@
+// Package review keeps the queue of scraped artists whose match was not confident
+// enough to add them straight away, until someone accepts, remaps or rejects each.
+package review
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/types"
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+var (
+	// ErrNotFound is returned for an item that is not in the queue
+	ErrNotFound = errors.New("review item not found")
+	// ErrResolved is returned when deciding on an item that was already decided on
+	ErrResolved = errors.New("review item already resolved")
+	// ErrDeciding is returned when deciding on an item while another decision on it is in progress
+	ErrDeciding = errors.New("review item is already being decided on")
+	// ErrNotAdded is returned when accepting an item whose artist was not added to the playlist
+	ErrNotAdded = errors.New("artist was not added")
+)
+
+// migrations upgrade the schema in order; the index of the last applied
+// migration plus one is stored in the database's user_version
+var migrations = []string{
+	`CREATE TABLE items (
+		id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+		query              TEXT    NOT NULL,
+		query_key          TEXT    NOT NULL,
+		artist             TEXT    NOT NULL,
+		confidence         REAL    NOT NULL,
+		candidates         TEXT    NOT NULL,
+		playlist_id        TEXT    NOT NULL,
+		mode               TEXT    NOT NULL,
+		selection          TEXT    NOT NULL,
+		source             TEXT    NOT NULL,
+		source_url         TEXT    NOT NULL DEFAULT '',
+		found_by           TEXT    NOT NULL DEFAULT '',
+		user_id            TEXT    NOT NULL DEFAULT '',
+		status             TEXT    NOT NULL,
+		resolved_artist_id TEXT    NOT NULL DEFAULT '',
+		created_at         INTEGER NOT NULL,
+		resolved_at        INTEGER
+	);
+	CREATE INDEX items_status_created_at ON items (status, created_at);
+	CREATE INDEX items_pending_query ON items (query_key, playlist_id) WHERE status = 'pending';`,
+}
+
+// SQLiteStore implements types.ReviewQueue on a SQLite database
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// Open opens or creates the review database at path and migrates it to the latest schema
+func Open(path string) (*SQLiteStore, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("review database path is required")
+	}
+
+	path = filepath.Clean(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create review directory: %w", err)
+	}
+
+	// The busy timeout lets the CLI and server share the database
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open review database: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids lock contention within the process
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteStore{db: db}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// migrate applies any migrations newer than the database's schema version
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read review schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("review database schema version %d is newer than supported version %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to migrate review database: %w", err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply review migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply review migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to apply review migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Enqueue queues an item as pending, setting its ID and defaulting CreatedAt to now.
+// When the same name, ignoring case and extra whitespace, already waits for review
+// for the same playlist, nothing is queued and the item gets the waiting one's ID.
+func (s *SQLiteStore) Enqueue(item *types.ReviewItem) error {
+	if item == nil {
+		return fmt.Errorf("cannot queue nil review item")
+	}
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	item.Status = types.ReviewPending
+	key := alias.Key(item.Query)
+
+	err := s.db.QueryRow(`SELECT id FROM items WHERE status = ? AND query_key = ? AND playlist_id = ?
+		ORDER BY id LIMIT 1`, string(types.ReviewPending), key, item.PlaylistID).Scan(&item.ID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to look up pending review item: %w", err)
+	}
+
+	candidates := item.Candidates
+	if candidates == nil {
+		candidates = []types.ArtistCandidate{}
+	}
+	artistJSON, err := json.Marshal(item.Artist)
+	if err != nil {
+		return fmt.Errorf("failed to encode review artist: %w", err)
+	}
+	candidatesJSON, err := json.Marshal(candidates)
+	if err != nil {
+		return fmt.Errorf("failed to encode review candidates: %w", err)
+	}
+	selectionJSON, err := json.Marshal(item.Selection)
+	if err != nil {
+		return fmt.Errorf("failed to encode review track selection: %w", err)
+	}
+
+	result, err := s.db.Exec(`INSERT INTO items
+		(query, query_key, artist, confidence, candidates, playlist_id, mode, selection,
+		 source, source_url, found_by, user_id, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		item.Query, key, string(artistJSON), item.Confidence, string(candidatesJSON), item.PlaylistID,
+		string(item.Mode), string(selectionJSON), string(item.Source), item.SourceURL, item.FoundBy,
+		item.User, string(item.Status), item.CreatedAt.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("failed to queue review item: %w", err)
+	}
+
+	if item.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read review item ID: %w", err)
+	}
+	return nil
+}
+
+// Get returns the item with the given ID, or ErrNotFound
+func (s *SQLiteStore) Get(id int64) (*types.ReviewItem, error) {
+	item, err := scanItem(s.db.QueryRow(`SELECT `+itemColumns+` FROM items WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review item: %w", err)
+	}
+	return item, nil
+}
+
+// List returns items matching the filter, oldest first so the queue is worked through in order
+func (s *SQLiteStore) List(filter types.ReviewFilter) ([]types.ReviewItem, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, string(filter.Status))
+	}
+	if filter.PlaylistID != "" {
+		conditions = append(conditions, "playlist_id = ?")
+		args = append(args, filter.PlaylistID)
+	}
+
+	query := "SELECT " + itemColumns + " FROM items"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at, id LIMIT ? OFFSET ?"
+	args = append(args, clampLimit(filter.Limit), max(filter.Offset, 0))
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review queue: %w", err)
+	}
+	defer rows.Close()
+
+	items := []types.ReviewItem{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read review item: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read review queue: %w", err)
+	}
+	return items, nil
+}
+
+// Resolve records the decision on a pending item: the status it leaves pending
+// with and, unless rejected, the artist added. It returns ErrNotFound for an
+// unknown item and ErrResolved for one already decided on.
+func (s *SQLiteStore) Resolve(id int64, status types.ReviewStatus, artistID string) error {
+	if status == types.ReviewPending {
+		return fmt.Errorf("cannot resolve review item as %s", status)
+	}
+
+	result, err := s.db.Exec(`UPDATE items SET status = ?, resolved_artist_id = ?, resolved_at = ?
+		WHERE id = ? AND status = ?`,
+		string(status), artistID, time.Now().UnixMilli(), id, string(types.ReviewPending))
+	if err != nil {
+		return fmt.Errorf("failed to resolve review item: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to resolve review item: %w", err)
+	}
+	if updated > 0 {
+		return nil
+	}
+
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	return ErrResolved
+}
+
+// itemColumns are the columns read by scanItem, in order
+const itemColumns = "id, query, artist, confidence, candidates, playlist_id, mode, selection, " +
+	"source, source_url, found_by, user_id, status, resolved_artist_id, created_at, resolved_at"
+
+// scanItem reads a review item from a row selected with itemColumns
+func scanItem(row interface{ Scan(dest ...any) error }) (*types.ReviewItem, error) {
+	var (
+		item                                types.ReviewItem
+		artistJSON, candidatesJSON, selJSON string
+		mode, source, status                string
+		createdAt                           int64
+		resolvedAt                          sql.NullInt64
+	)
+	if err := row.Scan(&item.ID, &item.Query, &artistJSON, &item.Confidence, &candidatesJSON,
+		&item.PlaylistID, &mode, &selJSON, &source, &item.SourceURL, &item.FoundBy, &item.User,
+		&status, &item.ResolvedArtistID, &createdAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(artistJSON), &item.Artist); err != nil {
+		return nil, fmt.Errorf("failed to decode review artist: %w", err)
+	}
+	if err := json.Unmarshal([]byte(candidatesJSON), &item.Candidates); err != nil {
+		return nil, fmt.Errorf("failed to decode review candidates: %w", err)
+	}
+	if err := json.Unmarshal([]byte(selJSON), &item.Selection); err != nil {
+		return nil, fmt.Errorf("failed to decode review track selection: %w", err)
+	}
+	item.Mode = types.AddMode(mode)
+	item.Source = types.SourceKind(source)
+	item.Status = types.ReviewStatus(status)
+	item.CreatedAt = time.UnixMilli(createdAt).UTC()
+	if resolvedAt.Valid {
+		resolved := time.UnixMilli(resolvedAt.Int64).UTC()
+		item.ResolvedAt = &resolved
+	}
+	return &item, nil
+}
+
+// clampLimit applies the default and maximum review page size
+func clampLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return types.DefaultReviewLimit
+	case limit > types.MaxReviewLimit:
+		return types.MaxReviewLimit
+	default:
+		return limit
+	}
+}
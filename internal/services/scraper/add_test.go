@@ -0,0 +1,69 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// playlistContents adds each artist by ID once, reporting it as a duplicate afterwards
+// like the playlist service's duplicate check
+type playlistContents struct {
+	mockPlaylistManager
+	artists map[string]bool
+}
+
+func (p *playlistContents) AddArtistByID(ctx context.Context, artistID, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	p.added = append(p.added, artistID)
+	artist := types.Artist{ID: artistID}
+	if p.artists[artistID] {
+		return &types.AddResult{Artist: artist, WasDuplicate: true, Message: "Artist already in playlist"}, nil
+	}
+	p.artists[artistID] = true
+	return &types.AddResult{Success: true, Artist: artist, TracksAdded: []types.Track{{ID: "track-" + artistID}}}, nil
+}
+
+func TestWebScraper_ScrapeAndAdd_RepeatedArtist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><ul><li>Big Thief</li><li>Squid</li><li>Big Thief (Live)</li></ul></body></html>`))
+	}))
+	defer server.Close()
+
+	bigThief := types.ArtistCandidate{Artist: types.Artist{ID: "bigthief", Name: "Big Thief"}, NameScore: 1, Confidence: 1}
+	searcher := &mockSearcher{candidates: map[string][]types.ArtistCandidate{
+		"Big Thief":        {bigThief},
+		"Big Thief (Live)": {bigThief},
+		"Squid":            {{Artist: types.Artist{ID: "squid", Name: "Squid"}, NameScore: 1, Confidence: 1}},
+	}}
+	playlist := &playlistContents{artists: map[string]bool{}}
+
+	logger := quietLogger()
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), searcher, playlist, logger)
+
+	result, err := scraper.ScrapeAndAddToPlaylist(context.Background(), server.URL, "li", "playlist1", types.AddModeSkip, types.TrackSelection{}, types.AddSource{Kind: types.SourceCLI})
+	if err != nil {
+		t.Fatalf("ScrapeAndAddToPlaylist() unexpected error: %v", err)
+	}
+
+	// Every match goes through the playlist manager, which sees the artist added earlier in the scrape
+	slices.Sort(playlist.added)
+	if !slices.Equal(playlist.added, []string{"bigthief", "bigthief", "squid"}) {
+		t.Errorf("Added artists %v, want every match added through the playlist manager", playlist.added)
+	}
+	if result.SuccessCount != 2 || result.DuplicateCount != 1 || result.TotalTracksAdded != 2 {
+		t.Errorf("ScrapeAndAddToPlaylist() = %+v, want 2 added and the repeated artist skipped as a duplicate", result)
+	}
+	var repeats int
+	for _, match := range result.MatchResults {
+		if match.Artist != nil && match.Artist.ID == "bigthief" && match.WasDuplicate {
+			repeats++
+		}
+	}
+	if repeats != 1 {
+		t.Errorf("Big Thief skipped as a duplicate %d times, want once for the second name", repeats)
+	}
+}
@@ -0,0 +1,471 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+)
+
+// SiteExtractor extracts artist names from the pages of a particular site, such as
+// a Reddit thread or a Bandcamp label, where the generic text strategies pick up
+// navigation, usernames and other noise.
+type SiteExtractor interface {
+	// Name identifies the extractor in logs
+	Name() string
+	// Matches reports whether the extractor handles the page at u
+	Matches(u *url.URL) bool
+	// Extract returns the artist names on the page in page order, or none when the
+	// page isn't laid out the way the extractor expects
+	Extract(doc *ParsedDocument) []string
+}
+
+// ExtractorRegistry picks the site extractor for a page. Extractors are tried in the
+// order they were registered and the first that matches the page's URL is used.
+type ExtractorRegistry struct {
+	extractors []SiteExtractor
+}
+
+// NewExtractorRegistry creates a registry of the given extractors
+func NewExtractorRegistry(extractors ...SiteExtractor) *ExtractorRegistry {
+	return &ExtractorRegistry{extractors: extractors}
+}
+
+// DefaultExtractorRegistry creates a registry of the built-in extractors
+func DefaultExtractorRegistry() *ExtractorRegistry {
+	return NewExtractorRegistry(BuiltinExtractors()...)
+}
+
+// BuiltinExtractors returns the extractors for Reddit, Bandcamp, Wikipedia and lineup pages
+func BuiltinExtractors() []SiteExtractor {
+	return []SiteExtractor{
+		&RedditExtractor{},
+		&BandcampExtractor{},
+		&WikipediaExtractor{},
+		&LineupExtractor{},
+	}
+}
+
+// LoadExtractorRegistry creates a registry of the selector extractors declared in the
+// YAML file at path followed by the built-in extractors, so declared extractors take
+// precedence. An empty path gives the built-in extractors only.
+func LoadExtractorRegistry(path string) (*ExtractorRegistry, error) {
+	if path == "" {
+		return DefaultExtractorRegistry(), nil
+	}
+
+	file, err := os.Open(path) // #nosec G304 -- path is from config
+	if err != nil {
+		return nil, fmt.Errorf("failed to open extractors file: %w", err)
+	}
+	defer file.Close()
+
+	declared, err := ReadSelectorExtractors(file)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := NewExtractorRegistry()
+	for _, extractor := range declared {
+		registry.Register(extractor)
+	}
+	for _, extractor := range BuiltinExtractors() {
+		registry.Register(extractor)
+	}
+	return registry, nil
+}
+
+// Register adds an extractor, tried after the ones already registered
+func (r *ExtractorRegistry) Register(extractor SiteExtractor) {
+	r.extractors = append(r.extractors, extractor)
+}
+
+// Lookup returns the first extractor matching rawURL, or nil if none does
+func (r *ExtractorRegistry) Lookup(rawURL string) SiteExtractor {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	for _, extractor := range r.extractors {
+		if extractor.Matches(u) {
+			return extractor
+		}
+	}
+	return nil
+}
+
+// SelectorExtractor reads one artist name from each element matching a CSS selector,
+// on pages whose host and URL match. It lets extractors be declared in configuration.
+type SelectorExtractor struct {
+	name     string
+	hosts    []string
+	pattern  *regexp.Regexp
+	selector string
+}
+
+// NewSelectorExtractor creates an extractor for pages on one of hosts, or any of
+// their subdomains, whose URL matches urlPattern. Either may be empty, but not both.
+func NewSelectorExtractor(name string, hosts []string, urlPattern, selector string) (*SelectorExtractor, error) {
+	e := &SelectorExtractor{
+		name:     strings.TrimSpace(name),
+		selector: strings.TrimSpace(selector),
+	}
+	for _, host := range hosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			e.hosts = append(e.hosts, host)
+		}
+	}
+
+	switch {
+	case e.name == "":
+		return nil, errors.New("extractor name is required")
+	case e.selector == "":
+		return nil, fmt.Errorf("extractor %q: selector is required", e.name)
+	case len(e.hosts) == 0 && urlPattern == "":
+		return nil, fmt.Errorf("extractor %q: hosts or url_pattern is required", e.name)
+	}
+
+	if urlPattern != "" {
+		pattern, err := regexp.Compile(urlPattern)
+		if err != nil {
+			return nil, fmt.Errorf("extractor %q: invalid url_pattern: %w", e.name, err)
+		}
+		e.pattern = pattern
+	}
+	return e, nil
+}
+
+// Name implements the SiteExtractor interface
+func (e *SelectorExtractor) Name() string {
+	return e.name
+}
+
+// Matches implements the SiteExtractor interface
+func (e *SelectorExtractor) Matches(u *url.URL) bool {
+	if len(e.hosts) > 0 && !matchesHost(u, e.hosts...) {
+		return false
+	}
+	return e.pattern == nil || e.pattern.MatchString(u.String())
+}
+
+// Extract implements the SiteExtractor interface
+func (e *SelectorExtractor) Extract(doc *ParsedDocument) []string {
+	var names []string
+	doc.Document.Find(e.selector).Each(func(_ int, s *goquery.Selection) {
+		names = append(names, elementText(s))
+	})
+	return uniqueNames(names)
+}
+
+// extractorsFile is the layout of the YAML file declaring selector extractors
+type extractorsFile struct {
+	Extractors []struct {
+		Name       string   `yaml:"name"`
+		Hosts      []string `yaml:"hosts"`
+		URLPattern string   `yaml:"url_pattern"`
+		Selector   string   `yaml:"selector"`
+	} `yaml:"extractors"`
+}
+
+// ReadSelectorExtractors reads selector extractors from YAML of the form:
+//
+//	extractors:
+//	  - name: my-venue
+//	    hosts: [myvenue.example.com]
+//	    url_pattern: /events/
+//	    selector: .event .performer
+//
+// url_pattern is a regular expression matched against the whole URL.
+func ReadSelectorExtractors(r io.Reader) ([]*SelectorExtractor, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file extractorsFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode extractors: %w", err)
+	}
+
+	extractors := make([]*SelectorExtractor, 0, len(file.Extractors))
+	for _, declared := range file.Extractors {
+		extractor, err := NewSelectorExtractor(declared.Name, declared.Hosts, declared.URLPattern, declared.Selector)
+		if err != nil {
+			return nil, err
+		}
+		extractors = append(extractors, extractor)
+	}
+	return extractors, nil
+}
+
+// redditBodySelector matches post and comment bodies on new and old Reddit, leaving
+// out the sidebar, usernames and vote counts
+const redditBodySelector = `[slot="text-body"], [slot="comment"], #siteTable .usertext-body .md, .commentarea .usertext-body .md`
+
+// RedditExtractor reads artist names from the post and comments of a Reddit thread,
+// one per list item, or one per line or comma-separated entry of a paragraph.
+type RedditExtractor struct{}
+
+// Name implements the SiteExtractor interface
+func (r *RedditExtractor) Name() string {
+	return "reddit"
+}
+
+// Matches implements the SiteExtractor interface
+func (r *RedditExtractor) Matches(u *url.URL) bool {
+	return matchesHost(u, "reddit.com") && strings.Contains(u.Path, "/comments/")
+}
+
+// Extract implements the SiteExtractor interface
+func (r *RedditExtractor) Extract(doc *ParsedDocument) []string {
+	var names []string
+	doc.Document.Find(redditBodySelector).Each(func(_ int, body *goquery.Selection) {
+		if items := body.Find("li"); items.Length() > 0 {
+			items.Each(func(_ int, item *goquery.Selection) {
+				names = append(names, elementText(item))
+			})
+			return
+		}
+
+		paragraphs := body.Find("p")
+		if paragraphs.Length() == 0 {
+			paragraphs = body
+		}
+		paragraphs.Each(func(_ int, p *goquery.Selection) {
+			for _, entry := range splitList(p.Text()) {
+				if looksLikeName(entry) {
+					names = append(names, entry)
+				}
+			}
+		})
+	})
+	return uniqueNames(names)
+}
+
+// BandcampExtractor reads the artists of a Bandcamp label's artist list, the artist
+// of an album or track page, or of each track on a various artists compilation.
+type BandcampExtractor struct{}
+
+// Name implements the SiteExtractor interface
+func (b *BandcampExtractor) Name() string {
+	return "bandcamp"
+}
+
+// Matches implements the SiteExtractor interface
+func (b *BandcampExtractor) Matches(u *url.URL) bool {
+	return matchesHost(u, "bandcamp.com")
+}
+
+// Extract implements the SiteExtractor interface
+func (b *BandcampExtractor) Extract(doc *ParsedDocument) []string {
+	var names []string
+	doc.Document.Find(".artists-grid-name").Each(func(_ int, s *goquery.Selection) {
+		names = append(names, elementText(s))
+	})
+	if len(names) > 0 {
+		return uniqueNames(names)
+	}
+
+	artist := elementText(doc.Document.Find("#name-section h3 span a").First())
+	if artist == "" || isPlaceholderArtist(artist) {
+		// Compilation tracks are titled "Artist - Title"
+		doc.Document.Find(".track_list .track-title").Each(func(_ int, s *goquery.Selection) {
+			if name, _, ok := strings.Cut(elementText(s), " - "); ok {
+				names = append(names, name)
+			}
+		})
+		if len(names) > 0 {
+			return uniqueNames(names)
+		}
+	}
+	if artist == "" {
+		artist = elementText(doc.Document.Find("#band-name-location .title").First())
+	}
+	return uniqueNames([]string{artist})
+}
+
+// wikipediaNoiseSelector matches the parts of an article that link to things other
+// than its subject: references, navigation boxes, infoboxes and the like
+const wikipediaNoiseSelector = ".navbox, .reflist, .references, .infobox, .hatnote, .mw-editsection, .toc, .thumb, .sidebar, .metadata, sup"
+
+// wikipediaEndSections are the headings after which an article lists no more of its subjects
+var wikipediaEndSections = []string{"see also", "references", "notes", "external links", "further reading"}
+
+// WikipediaExtractor reads the artist of a discography, or the articles linked from
+// the list items and tables of other articles, such as festival lineups.
+type WikipediaExtractor struct{}
+
+// Name implements the SiteExtractor interface
+func (w *WikipediaExtractor) Name() string {
+	return "wikipedia"
+}
+
+// Matches implements the SiteExtractor interface
+func (w *WikipediaExtractor) Matches(u *url.URL) bool {
+	return matchesHost(u, "wikipedia.org") && strings.HasPrefix(u.Path, "/wiki/")
+}
+
+// Extract implements the SiteExtractor interface
+func (w *WikipediaExtractor) Extract(doc *ParsedDocument) []string {
+	title := elementText(doc.Document.Find("#firstHeading").First())
+	if subject, ok := strings.CutSuffix(title, " discography"); ok {
+		return uniqueNames([]string{subject})
+	}
+
+	// Work on a copy so the page can still be read as text if nothing is found here
+	content := doc.Document.Find("#mw-content-text .mw-parser-output").First().Clone()
+	content.Find(wikipediaNoiseSelector).Remove()
+
+	var names []string
+	content.Children().EachWithBreak(func(_ int, section *goquery.Selection) bool {
+		if isWikipediaEndSection(section) {
+			return false
+		}
+		section.Find("li, td, th[scope='row']").Each(func(_ int, item *goquery.Selection) {
+			if link := item.Find("a[href^='/wiki/']").First(); isArticleLink(link) {
+				names = append(names, elementText(link))
+			}
+		})
+		return true
+	})
+	return uniqueNames(names)
+}
+
+// isWikipediaEndSection reports whether s is the heading of a section such as "References"
+func isWikipediaEndSection(s *goquery.Selection) bool {
+	if !s.Is("h2") && !s.Is(".mw-heading2") {
+		return false
+	}
+	heading := strings.ToLower(elementText(s))
+	for _, end := range wikipediaEndSections {
+		if strings.HasPrefix(heading, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// isArticleLink reports whether link points to an article rather than a file,
+// category or other namespaced page, and isn't a bare year
+func isArticleLink(link *goquery.Selection) bool {
+	href, ok := link.Attr("href")
+	if !ok || strings.Contains(strings.TrimPrefix(href, "/wiki/"), ":") {
+		return false
+	}
+	text := elementText(link)
+	return text != "" && strings.Trim(text, "0123456789") != ""
+}
+
+// lineupPathPattern recognizes lineup pages by their path
+var lineupPathPattern = regexp.MustCompile(`(?i)line-?up`)
+
+// lineupNameSelector matches elements holding a single artist's name on lineup pages
+const lineupNameSelector = `[class*="artist-name"], [class*="artist__name"], [class*="artistName"], [class*="performer-name"]`
+
+// lineupContainerSelector matches the elements listing a lineup's artists
+const lineupContainerSelector = `[class*="lineup"], [id*="lineup"]`
+
+// LineupExtractor reads festival and venue lineups from pages with "lineup" in their
+// path, from elements named after artists or else the items of lineup lists.
+type LineupExtractor struct{}
+
+// Name implements the SiteExtractor interface
+func (l *LineupExtractor) Name() string {
+	return "lineup"
+}
+
+// Matches implements the SiteExtractor interface
+func (l *LineupExtractor) Matches(u *url.URL) bool {
+	return lineupPathPattern.MatchString(u.Path)
+}
+
+// Extract implements the SiteExtractor interface
+func (l *LineupExtractor) Extract(doc *ParsedDocument) []string {
+	var names []string
+	doc.Document.Find(lineupNameSelector).Each(func(_ int, s *goquery.Selection) {
+		names = append(names, elementText(s))
+	})
+	if len(names) > 0 {
+		return uniqueNames(names)
+	}
+
+	doc.Document.Find(lineupContainerSelector).Each(func(_ int, container *goquery.Selection) {
+		items := container.Find("li")
+		if items.Length() == 0 {
+			items = container.Find("a")
+		}
+		items.Each(func(_ int, item *goquery.Selection) {
+			// Prefer the link in an item, which leaves out days and stages
+			if link := item.Find("a").First(); link.Length() > 0 {
+				item = link
+			}
+			names = append(names, elementText(item))
+		})
+	})
+	return uniqueNames(names)
+}
+
+// matchesHost reports whether u is on one of the domains or their subdomains
+func matchesHost(u *url.URL, domains ...string) bool {
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// elementText returns the text of s with its whitespace collapsed
+func elementText(s *goquery.Selection) string {
+	return strings.Join(strings.Fields(s.Text()), " ")
+}
+
+// splitList splits free text into entries at line breaks, commas and bullets
+func splitList(text string) []string {
+	var entries []string
+	for _, entry := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == '\n' || r == ',' || r == '•' || r == '·' || r == '|'
+	}) {
+		entry = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(entry), "-*"))
+		entry = strings.TrimRight(entry, "!?:;")
+		// A lone trailing period ends a sentence; initialisms such as "D.C." keep theirs
+		if strings.Count(entry, ".") == 1 {
+			entry = strings.TrimSuffix(entry, ".")
+		}
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// maxNameWords is the most words an entry of free text can have and still be read
+// as an artist name rather than a sentence
+const maxNameWords = 6
+
+// looksLikeName reports whether an entry of free text is short enough to be a name
+func looksLikeName(entry string) bool {
+	words := len(strings.Fields(entry))
+	return words > 0 && words <= maxNameWords
+}
+
+// uniqueNames returns the non-empty names in order, leaving out repeats that differ only by case
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, name)
+	}
+	return unique
+}
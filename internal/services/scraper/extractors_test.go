@@ -0,0 +1,265 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// parseFixture parses an HTML page from testdata as if it had been fetched from pageURL
+func parseFixture(t *testing.T, name, pageURL string) *ParsedDocument {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	doc, err := NewGoqueryParser(quietLogger()).Parse(string(raw))
+	if err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+	doc.URL = pageURL
+	return doc
+}
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	return logger
+}
+
+func TestBuiltinExtractors(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		url       string
+		extractor string
+		want      []string
+	}{
+		{
+			fixture:   "reddit_thread.html",
+			url:       "https://www.reddit.com/r/Music/comments/abc123/what_bands_should_i_see/",
+			extractor: "reddit",
+			want:      []string{"Big Thief", "Japanese Breakfast", "Squid", "Wet Leg", "Fontaines D.C."},
+		},
+		{
+			fixture:   "reddit_old_thread.html",
+			url:       "https://old.reddit.com/r/shoegaze/comments/def456/favourite_shoegaze_records/",
+			extractor: "reddit",
+			want:      []string{"My Bloody Valentine", "Ride", "Lush", "Cocteau Twins"},
+		},
+		{
+			fixture:   "bandcamp_label_artists.html",
+			url:       "https://sacredbonesrecords.bandcamp.com/artists",
+			extractor: "bandcamp",
+			want:      []string{"Molly Nilsson", "Zola Jesus", "Boy Harsher"},
+		},
+		{
+			fixture:   "bandcamp_album.html",
+			url:       "https://phoebebridgers.bandcamp.com/album/punisher",
+			extractor: "bandcamp",
+			want:      []string{"Phoebe Bridgers"},
+		},
+		{
+			fixture:   "bandcamp_compilation.html",
+			url:       "https://somelabel.bandcamp.com/album/summer-sampler-2024",
+			extractor: "bandcamp",
+			want:      []string{"Khruangbin", "Altin Gün", "Jungle"},
+		},
+		{
+			fixture:   "wikipedia_lineup.html",
+			url:       "https://en.wikipedia.org/wiki/Glastonbury_Festival_2019",
+			extractor: "wikipedia",
+			want:      []string{"Stormzy", "The Killers", "Lauryn Hill", "Janet Jackson", "Tame Impala", "Chvrches"},
+		},
+		{
+			fixture:   "wikipedia_discography.html",
+			url:       "https://en.wikipedia.org/wiki/Radiohead_discography",
+			extractor: "wikipedia",
+			want:      []string{"Radiohead"},
+		},
+		{
+			fixture:   "festival_lineup.html",
+			url:       "https://www.examplefest.com/lineup",
+			extractor: "lineup",
+			want:      []string{"LCD Soundsystem", "Floating Points", "Caroline Polachek"},
+		},
+		{
+			fixture:   "festival_lineup_cards.html",
+			url:       "https://www.otherfest.example/2025/line-up/",
+			extractor: "lineup",
+			want:      []string{"Fever Ray", "Mdou Moctar", "Arooj Aftab"},
+		},
+	}
+
+	registry := DefaultExtractorRegistry()
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			extractor := registry.Lookup(tt.url)
+			if extractor == nil || extractor.Name() != tt.extractor {
+				t.Fatalf("Lookup(%q) = %v, want the %s extractor", tt.url, extractor, tt.extractor)
+			}
+
+			got := extractor.Extract(parseFixture(t, tt.fixture, tt.url))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractorRegistry_Lookup(t *testing.T) {
+	registry := DefaultExtractorRegistry()
+
+	for _, pageURL := range []string{
+		"https://www.reddit.com/r/Music/",
+		"https://notbandcamp.com/album/x",
+		"https://example.com/artists",
+		"://not a url",
+	} {
+		if extractor := registry.Lookup(pageURL); extractor != nil {
+			t.Errorf("Lookup(%q) = %s, want no extractor", pageURL, extractor.Name())
+		}
+	}
+}
+
+func TestReadSelectorExtractors(t *testing.T) {
+	extractors, err := ReadSelectorExtractors(strings.NewReader(`
+extractors:
+  - name: venue
+    hosts: [Venue.example.com]
+    selector: .performer
+  - name: events
+    url_pattern: ^https://events\.example\.org/shows/
+    selector: h3.act
+`))
+	if err != nil {
+		t.Fatalf("ReadSelectorExtractors() unexpected error: %v", err)
+	}
+
+	registry := NewExtractorRegistry()
+	for _, extractor := range extractors {
+		registry.Register(extractor)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://venue.example.com/calendar", want: "venue"},
+		{url: "https://tickets.venue.example.com/", want: "venue"},
+		{url: "https://events.example.org/shows/42", want: "events"},
+		{url: "https://events.example.org/about"},
+		{url: "https://othervenue.example.com/"},
+	}
+	for _, tt := range tests {
+		var got string
+		if extractor := registry.Lookup(tt.url); extractor != nil {
+			got = extractor.Name()
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestReadSelectorExtractors_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing name":     "extractors:\n  - hosts: [example.com]\n    selector: .act\n",
+		"missing selector": "extractors:\n  - name: venue\n    hosts: [example.com]\n",
+		"missing target":   "extractors:\n  - name: venue\n    selector: .act\n",
+		"invalid pattern":  "extractors:\n  - name: venue\n    url_pattern: \"[unclosed\"\n    selector: .act\n",
+		"unknown field":    "extractors:\n  - name: venue\n    hosts: [example.com]\n    selector: .act\n    css: .act\n",
+	}
+
+	for name, yaml := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadSelectorExtractors(strings.NewReader(yaml)); err == nil {
+				t.Error("ReadSelectorExtractors() expected error")
+			}
+		})
+	}
+}
+
+func TestWebScraper_ScrapeArtists_SiteExtractor(t *testing.T) {
+	page := `<html><body>
+		<nav>Home, Calendar, Tickets</nav>
+		<div class="show"><span class="act">Nilüfer Yanya</span><span class="time">20:00</span></div>
+		<div class="show"><span class="act">The Band</span><span class="time">21:30</span></div>
+		<div class="show"><span class="act">Nilufer Yanya</span></div>
+	</body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	extractor, err := NewSelectorExtractor("venue", []string{"127.0.0.1"}, "", ".act")
+	if err != nil {
+		t.Fatalf("NewSelectorExtractor() unexpected error: %v", err)
+	}
+	logger := quietLogger()
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), nil, nil, logger)
+	scraper.SetExtractors(NewExtractorRegistry(extractor))
+
+	tests := []struct {
+		name        string
+		cssSelector string
+		want        []string
+	}{
+		{
+			name: "site extractor",
+			want: []string{"Nilüfer Yanya", "The Band", "Nilufer Yanya"},
+		},
+		{
+			// An explicit selector skips the site extractor for the generic text extraction
+			name:        "explicit selector",
+			cssSelector: "nav",
+			want:        []string{"Calendar", "Home", "Home, Calendar, Tickets", "Tickets"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scraper.ScrapeArtists(context.Background(), server.URL+"/calendar", tt.cssSelector)
+			if err != nil {
+				t.Fatalf("ScrapeArtists() unexpected error: %v", err)
+			}
+			if tt.cssSelector != "" {
+				slices.Sort(got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScrapeArtists() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebScraper_ScrapeArtists_SiteExtractorFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><p>Radiohead</p></body></html>`))
+	}))
+	defer server.Close()
+
+	extractor, err := NewSelectorExtractor("venue", []string{"127.0.0.1"}, "", ".act")
+	if err != nil {
+		t.Fatalf("NewSelectorExtractor() unexpected error: %v", err)
+	}
+	logger := quietLogger()
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), nil, nil, logger)
+	scraper.SetExtractors(NewExtractorRegistry(extractor))
+
+	// The page has no .act elements, so the text is read with the generic strategies
+	got, err := scraper.ScrapeArtists(context.Background(), server.URL, "")
+	if err != nil {
+		t.Fatalf("ScrapeArtists() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"Radiohead"}) {
+		t.Errorf("ScrapeArtists() = %q, want the generic extraction", got)
+	}
+}
@@ -0,0 +1,271 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// ScrapePreview describes what scraping a page would add to a playlist, worked out
+// without changing anything. Artists holds every name found with its candidates; the
+// matched ones that are neither blocked nor already in the playlist would be added.
+type ScrapePreview struct {
+	URL            string              `json:"url"`
+	CSSSelector    string              `json:"css_selector,omitempty"`
+	PlaylistID     string              `json:"playlist_id"`
+	ArtistsFound   []string            `json:"artists_found"`
+	Artists        []ArtistMatchResult `json:"artists"`
+	MatchedCount   int                 `json:"matched_count"`
+	DuplicateCount int                 `json:"duplicate_count"`
+	BlockedCount   int                 `json:"blocked_count"`
+	// DisambiguationCount counts matched artists whose candidates are too close to pick between
+	DisambiguationCount int `json:"disambiguation_count"`
+	// ReviewCount counts the artists that would be held in the review queue instead of added
+	ReviewCount int    `json:"review_count,omitempty"`
+	Message     string `json:"message"`
+}
+
+// PreviewScrape scrapes a page and matches the artists found like
+// ScrapeAndAddToPlaylistWithProgress, but only reports each artist's candidates and
+// whether it is blocked, routed or already in the playlist. No tracks are added, so
+// the artists to add can be picked from the preview and passed to CommitScrape.
+func (w *WebScraper) PreviewScrape(ctx context.Context, url, cssSelector, playlistID string, progress ProgressFunc) (*ScrapePreview, error) {
+	if progress == nil {
+		progress = func(ScrapeProgress) {}
+	}
+	emit := func(snapshot ScrapeProgress, event types.ProgressEvent) {
+		event.Time = time.Now()
+		snapshot.Event = &event
+		progress(snapshot)
+	}
+	startTime := time.Now()
+
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":    "scraper",
+		"operation":    "preview_start",
+		"url":          url,
+		"css_selector": cssSelector,
+		"playlist_id":  playlistID,
+	}).Info("Starting scrape preview")
+
+	if playlistID == types.AutoPlaylistID && w.router == nil {
+		return nil, errors.New("automatic playlist routing is not configured")
+	}
+
+	preview := &ScrapePreview{
+		URL:         url,
+		CSSSelector: cssSelector,
+		PlaylistID:  playlistID,
+		Artists:     []ArtistMatchResult{},
+	}
+
+	progress(ScrapeProgress{Phase: PhaseFetching})
+	artists, err := w.extractArtists(ctx, url, cssSelector)
+	if err != nil {
+		preview.Message = fmt.Sprintf("Failed to scrape artists: %v", err)
+		emit(ScrapeProgress{Phase: PhaseDone}, types.ProgressEvent{Type: types.EventError, Message: preview.Message})
+		return preview, err
+	}
+
+	preview.ArtistsFound = artistNames(artists)
+
+	if len(artists) == 0 {
+		preview.Message = "No artists found in the scraped content"
+		emit(ScrapeProgress{Phase: PhaseDone}, types.ProgressEvent{Type: types.EventDone, Message: preview.Message})
+		return preview, nil
+	}
+
+	matching := ScrapeProgress{Phase: PhaseMatching, ArtistsTotal: len(artists)}
+	for _, artist := range artists {
+		emit(matching, types.ProgressEvent{Type: types.EventArtistFound, Query: artist.Name, Source: artist.Source})
+	}
+	preview.Artists = w.matchArtists(ctx, artists,
+		func(ctx context.Context, query string) ArtistMatchResult {
+			result := w.matchCandidates(ctx, query)
+			if result.Matched {
+				w.previewMatchedArtist(ctx, &result, playlistID)
+			}
+			return result
+		},
+		func(match ArtistMatchResult) {
+			event := matchEvent(match)
+			event.Message = match.Error
+			event.Route = match.Route
+			emit(matching, event)
+		})
+
+	for _, artist := range preview.Artists {
+		switch {
+		case !artist.Matched:
+			continue
+		case artist.Blocked:
+			preview.BlockedCount++
+		case artist.WasDuplicate:
+			preview.DuplicateCount++
+		case artist.NeedsReview:
+			preview.ReviewCount++
+		}
+		preview.MatchedCount++
+		if artist.NeedsDisambiguation {
+			preview.DisambiguationCount++
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		preview.Message = fmt.Sprintf("Preview cancelled: %d artists found, %d matched before cancelling", len(preview.ArtistsFound), preview.MatchedCount)
+		emit(newScrapeProgress(PhaseDone, preview.Artists, len(preview.Artists)), types.ProgressEvent{Type: types.EventDone, Message: preview.Message})
+		return preview, err
+	}
+
+	preview.Message = fmt.Sprintf("Preview complete: %d artists found, %d matched, %d already in playlist, %d blocked, %d need a closer look",
+		len(preview.ArtistsFound), preview.MatchedCount, preview.DuplicateCount, preview.BlockedCount, preview.DisambiguationCount)
+	if preview.ReviewCount > 0 {
+		preview.Message += fmt.Sprintf(", %d would be held for review", preview.ReviewCount)
+	}
+	emit(newScrapeProgress(PhaseDone, preview.Artists, len(preview.Artists)), types.ProgressEvent{Type: types.EventDone, Message: preview.Message})
+
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":       "scraper",
+		"operation":       "preview_complete",
+		"url":             url,
+		"artists_found":   len(preview.ArtistsFound),
+		"matched_count":   preview.MatchedCount,
+		"duplicate_count": preview.DuplicateCount,
+		"blocked_count":   preview.BlockedCount,
+		"duration_ms":     time.Since(startTime).Milliseconds(),
+	}).Info("Scrape preview completed")
+
+	return preview, nil
+}
+
+// matchCandidates matches a single artist query like matchSingleArtist, keeping the
+// other candidates so a preview can offer them instead
+func (w *WebScraper) matchCandidates(ctx context.Context, query string) ArtistMatchResult {
+	result := ArtistMatchResult{Query: query}
+
+	candidates, err := w.searcher.FindCandidates(ctx, query, types.DefaultCandidateLimit)
+	if err == nil && len(candidates) == 0 {
+		err = fmt.Errorf("no artists found for %q", query)
+	}
+	if err != nil {
+		w.logger.WithContext(ctx).WithError(err).WithField("query", query).Warn("Failed to find artist candidates")
+		result.Error = err.Error()
+		return result
+	}
+
+	best := candidates[0].Artist
+	result.Artist = &best
+	result.Confidence = candidates[0].Confidence
+	result.Candidates = candidates
+	result.NeedsDisambiguation = types.NeedsDisambiguation(candidates)
+	w.acceptMatch(ctx, &result)
+	return result
+}
+
+// previewMatchedArtist records whether a matched artist would be blocked, held for
+// review, where it would be routed and whether it is already in the playlist, without
+// adding anything.
+// Unlike a scrape, the playlist is checked for the artist whatever the add mode.
+func (w *WebScraper) previewMatchedArtist(ctx context.Context, matchResult *ArtistMatchResult, playlistID string) {
+	_ = w.screenMatch(ctx, matchResult, playlistID, types.AddModeSkip)
+	if !matchResult.Blocked {
+		matchResult.NeedsReview = w.needsReview(*matchResult)
+	}
+}
+
+// CommitScrape adds the artists approved from a scrape preview to the playlist, each
+// by its Spotify ID through the playlist manager, so the blocklist, routing, add mode
+// and history apply as they do to a single addition; being approved, they are not
+// held for review. url is recorded as the source.
+// Once ctx is cancelled no further artists are added and the partial result is
+// returned with the context's error.
+func (w *WebScraper) CommitScrape(ctx context.Context, url, playlistID string, artists []types.ApprovedArtist, mode types.AddMode, selection types.TrackSelection, source types.AddSource, progress ProgressFunc) (*ScrapeResult, error) {
+	if progress == nil {
+		progress = func(ScrapeProgress) {}
+	}
+	emit := func(snapshot ScrapeProgress, event types.ProgressEvent) {
+		event.Time = time.Now()
+		snapshot.Event = &event
+		progress(snapshot)
+	}
+	startTime := time.Now()
+	selection = selection.WithDefaults()
+	source.URL = url
+	if mode == "" {
+		mode = types.AddModeSkip
+	}
+
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":   "scraper",
+		"operation":   "commit_start",
+		"url":         url,
+		"playlist_id": playlistID,
+		"artists":     len(artists),
+		"mode":        mode,
+	}).Info("Adding approved artists from scrape preview")
+
+	if err := mode.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid add mode: %w", err)
+	}
+	if err := selection.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid track selection: %w", err)
+	}
+	if playlistID == types.AutoPlaylistID && w.router == nil {
+		return nil, errors.New("automatic playlist routing is not configured")
+	}
+
+	result := &ScrapeResult{
+		URL:          url,
+		ArtistsFound: make([]string, len(artists)),
+		MatchResults: make([]ArtistMatchResult, len(artists)),
+		Errors:       []string{},
+	}
+	for i, approved := range artists {
+		query := approved.Query
+		if query == "" {
+			query = approved.ArtistID
+		}
+		result.ArtistsFound[i] = query
+		result.MatchResults[i] = ArtistMatchResult{Query: query}
+	}
+
+	for i, approved := range artists {
+		progress(newScrapeProgress(PhaseAdding, result.MatchResults, i))
+		if err := ctx.Err(); err != nil {
+			partial, err := w.cancelled(ctx, result, result.MatchResults[i:], err)
+			emit(newScrapeProgress(PhaseDone, result.MatchResults, i), types.ProgressEvent{Type: types.EventDone, Message: partial.Message})
+			return partial, err
+		}
+
+		event := w.addArtistByID(ctx, result, &result.MatchResults[i], approved.ArtistID, playlistID, mode, selection, source)
+		emit(newScrapeProgress(PhaseAdding, result.MatchResults, i+1), event)
+	}
+
+	result.Message = fmt.Sprintf("Added approved artists: %d approved, %d added, %d duplicates, %d failed",
+		len(artists), result.SuccessCount, result.DuplicateCount, result.FailureCount)
+	if result.BlockedCount > 0 {
+		result.Message += fmt.Sprintf(", %d blocked", result.BlockedCount)
+	}
+	if result.TotalTracksSkipped > 0 {
+		result.Message += fmt.Sprintf(", %d tracks already present", result.TotalTracksSkipped)
+	}
+	emit(newScrapeProgress(PhaseDone, result.MatchResults, len(result.MatchResults)), types.ProgressEvent{Type: types.EventDone, Message: result.Message})
+
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":       "scraper",
+		"operation":       "commit_complete",
+		"url":             url,
+		"success_count":   result.SuccessCount,
+		"failure_count":   result.FailureCount,
+		"duplicate_count": result.DuplicateCount,
+		"blocked_count":   result.BlockedCount,
+		"total_tracks":    result.TotalTracksAdded,
+		"duration_ms":     time.Since(startTime).Milliseconds(),
+	}).Info("Approved artists added")
+
+	return result, nil
+}
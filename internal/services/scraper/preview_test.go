@@ -0,0 +1,240 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/services/blocklist"
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+// mockSearcher returns fixed candidates for each query
+type mockSearcher struct {
+	candidates map[string][]types.ArtistCandidate
+}
+
+func (m *mockSearcher) FindBestMatch(ctx context.Context, query string) (*types.Artist, float64, error) {
+	candidates, err := m.FindCandidates(ctx, query, types.DefaultCandidateLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &candidates[0].Artist, candidates[0].Confidence, nil
+}
+
+func (m *mockSearcher) FindCandidates(ctx context.Context, query string, limit int) ([]types.ArtistCandidate, error) {
+	candidates, ok := m.candidates[query]
+	if !ok {
+		return nil, errors.New("no artists found")
+	}
+	return candidates, nil
+}
+
+// mockPlaylistManager records the artists added by ID, returning fixed results for them
+type mockPlaylistManager struct {
+	results map[string]*types.AddResult
+	tracks  map[string][]types.Track
+	added   []string
+}
+
+func (m *mockPlaylistManager) AddArtistToPlaylist(ctx context.Context, artistName, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockPlaylistManager) AddArtistByID(ctx context.Context, artistID, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*types.AddResult, error) {
+	m.added = append(m.added, artistID)
+	result, ok := m.results[artistID]
+	if !ok {
+		return &types.AddResult{Message: "Failed to find artist: not found"}, errors.New("not found")
+	}
+	return result, nil
+}
+
+func (m *mockPlaylistManager) GetIncomingPlaylists(ctx context.Context) ([]types.Playlist, error) {
+	return nil, nil
+}
+
+func (m *mockPlaylistManager) ListPlaylists(ctx context.Context) ([]types.Playlist, error) {
+	return nil, nil
+}
+
+func (m *mockPlaylistManager) MarkIncoming(ctx context.Context, playlistID string) error {
+	return nil
+}
+
+func (m *mockPlaylistManager) UnmarkIncoming(ctx context.Context, playlistID string) error {
+	return nil
+}
+
+func (m *mockPlaylistManager) GetArtistTracks(ctx context.Context, artistID string, selection types.TrackSelection) ([]types.Track, error) {
+	return m.tracks[artistID], nil
+}
+
+func (m *mockPlaylistManager) FilterPlaylistsBySearch(playlists []types.Playlist, searchTerm string) []types.Playlist {
+	return playlists
+}
+
+func (m *mockPlaylistManager) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockPlaylistManager) CheckForDuplicates(ctx context.Context, playlistID string, trackIDs []string) (*types.DuplicateResult, error) {
+	return &types.DuplicateResult{}, nil
+}
+
+func (m *mockPlaylistManager) FilterMissingTracks(ctx context.Context, playlistID string, tracks []types.Track) ([]types.Track, []types.Track, error) {
+	return tracks, nil, nil
+}
+
+func TestWebScraper_PreviewScrape(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><ul>
+			<li>Big Thief</li><li>Nirvana</li><li>Various Artists</li><li>Squid</li><li>Blurry Band</li>
+		</ul></body></html>`))
+	}))
+	defer server.Close()
+
+	searcher := &mockSearcher{candidates: map[string][]types.ArtistCandidate{
+		"Big Thief": {{Artist: types.Artist{ID: "bigthief", Name: "Big Thief"}, NameScore: 1, Confidence: 1}},
+		"Nirvana": {
+			{Artist: types.Artist{ID: "nirvana-us", Name: "Nirvana"}, NameScore: 1, Confidence: 0.9},
+			{Artist: types.Artist{ID: "nirvana-uk", Name: "Nirvana"}, NameScore: 1, Confidence: 0.85},
+		},
+		"Various Artists": {{Artist: types.Artist{ID: "various", Name: "Various Artists"}, NameScore: 1, Confidence: 1}},
+		"Squid":           {{Artist: types.Artist{ID: "squid", Name: "Squid"}, NameScore: 1, Confidence: 1}},
+		"Blurry Band":     {{Artist: types.Artist{ID: "blurry", Name: "Blurred"}, NameScore: 0.3, Confidence: 0.3}},
+	}}
+	playlist := &mockPlaylistManager{}
+	block, err := blocklist.New(config.BlocklistConfig{NamePattern: "^Various Artists$"})
+	if err != nil {
+		t.Fatalf("blocklist.New() unexpected error: %v", err)
+	}
+
+	logger := quietLogger()
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), searcher, playlist, logger)
+	scraper.SetBlocklist(block)
+	scraper.duplicateChecker = func(ctx context.Context, playlistID, artistID string) (*types.DuplicateResult, error) {
+		return &types.DuplicateResult{HasDuplicates: artistID == "squid"}, nil
+	}
+
+	var events []types.ProgressEvent
+	preview, err := scraper.PreviewScrape(context.Background(), server.URL, "li", "playlist1", func(progress ScrapeProgress) {
+		if progress.Event != nil {
+			events = append(events, *progress.Event)
+		}
+	})
+	if err != nil {
+		t.Fatalf("PreviewScrape() unexpected error: %v", err)
+	}
+
+	if preview.MatchedCount != 4 || preview.DuplicateCount != 1 || preview.BlockedCount != 1 || preview.DisambiguationCount != 1 {
+		t.Errorf("PreviewScrape() counts = %d matched, %d duplicates, %d blocked, %d ambiguous; want 4, 1, 1, 1",
+			preview.MatchedCount, preview.DuplicateCount, preview.BlockedCount, preview.DisambiguationCount)
+	}
+
+	byQuery := make(map[string]ArtistMatchResult, len(preview.Artists))
+	for _, artist := range preview.Artists {
+		byQuery[artist.Query] = artist
+	}
+	if got := byQuery["Nirvana"]; !got.Matched || !got.NeedsDisambiguation || len(got.Candidates) != 2 {
+		t.Errorf("Nirvana = %+v, want a match with both candidates", got)
+	}
+	if got := byQuery["Various Artists"]; !got.Blocked || got.BlockReason != types.BlockReasonNamePattern {
+		t.Errorf("Various Artists = %+v, want it blocked by name", got)
+	}
+	if got := byQuery["Squid"]; !got.WasDuplicate {
+		t.Errorf("Squid = %+v, want it reported as already in the playlist", got)
+	}
+	if got := byQuery["Blurry Band"]; got.Matched || got.Artist == nil || got.Error == "" {
+		t.Errorf("Blurry Band = %+v, want an unmatched low confidence candidate", got)
+	}
+	if got := byQuery["Big Thief"]; !got.Matched || got.Blocked || got.WasDuplicate || got.Error != "" {
+		t.Errorf("Big Thief = %+v, want a clean match", got)
+	}
+
+	if len(playlist.added) != 0 {
+		t.Errorf("PreviewScrape() added %v, want nothing added", playlist.added)
+	}
+	for _, event := range events {
+		if event.Type == types.EventTracksAdded {
+			t.Errorf("PreviewScrape() reported %+v, want no additions", event)
+		}
+	}
+	if last := events[len(events)-1]; last.Type != types.EventDone {
+		t.Errorf("Last event = %+v, want done", last)
+	}
+}
+
+func TestWebScraper_CommitScrape(t *testing.T) {
+	playlist := &mockPlaylistManager{results: map[string]*types.AddResult{
+		"bigthief": {
+			Success:     true,
+			Artist:      types.Artist{ID: "bigthief", Name: "Big Thief"},
+			TracksAdded: []types.Track{{ID: "t1"}, {ID: "t2"}},
+		},
+		"squid": {
+			Artist:       types.Artist{ID: "squid", Name: "Squid"},
+			WasDuplicate: true,
+			Message:      "Squid is already in the playlist",
+		},
+		"various": {
+			Artist:      types.Artist{ID: "various", Name: "Various Artists"},
+			Blocked:     true,
+			BlockReason: types.BlockReasonNamePattern,
+			Message:     "Artist name matches the blocklist",
+		},
+	}}
+	logger := quietLogger()
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), nil, playlist, logger)
+
+	approved := []types.ApprovedArtist{
+		{Query: "Big Thief", ArtistID: "bigthief"},
+		{Query: "Squid", ArtistID: "squid"},
+		{Query: "Various Artists", ArtistID: "various"},
+		{ArtistID: "missing"},
+	}
+	var events []types.EventType
+	result, err := scraper.CommitScrape(context.Background(), "https://example.com/lineup", "playlist1", approved, types.AddModeSkip, types.TrackSelection{}, types.AddSource{Kind: types.SourceAPI},
+		func(progress ScrapeProgress) {
+			if progress.Event != nil {
+				events = append(events, progress.Event.Type)
+			}
+		})
+	if err != nil {
+		t.Fatalf("CommitScrape() unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(playlist.added, []string{"bigthief", "squid", "various", "missing"}) {
+		t.Errorf("CommitScrape() added %v, want each approved artist in order", playlist.added)
+	}
+	if result.SuccessCount != 1 || result.DuplicateCount != 1 || result.BlockedCount != 1 || result.FailureCount != 1 || result.TotalTracksAdded != 2 {
+		t.Errorf("CommitScrape() = %+v, want 1 added with 2 tracks, 1 duplicate, 1 blocked and 1 failed", result)
+	}
+	if got := result.MatchResults[3]; got.Query != "missing" || got.Matched || got.Error == "" {
+		t.Errorf("Unknown artist = %+v, want a failure reported against its ID", got)
+	}
+	wantEvents := []types.EventType{types.EventTracksAdded, types.EventDuplicateSkipped, types.EventArtistBlocked, types.EventError, types.EventDone}
+	if !reflect.DeepEqual(events, wantEvents) {
+		t.Errorf("CommitScrape() events = %v, want %v", events, wantEvents)
+	}
+}
+
+func TestWebScraper_CommitScrape_Cancelled(t *testing.T) {
+	playlist := &mockPlaylistManager{}
+	logger := quietLogger()
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), nil, playlist, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := scraper.CommitScrape(ctx, "", "playlist1", []types.ApprovedArtist{{ArtistID: "bigthief"}}, "", types.TrackSelection{}, types.AddSource{}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("CommitScrape() error = %v, want context.Canceled", err)
+	}
+	if len(playlist.added) != 0 || result.MatchResults[0].Error == "" {
+		t.Errorf("CommitScrape() = %+v after adding %v, want nothing added", result, playlist.added)
+	}
+}
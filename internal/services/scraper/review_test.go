@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/services/blocklist"
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+// mockReviewQueue records the items queued, numbering them from 1
+type mockReviewQueue struct {
+	items []types.ReviewItem
+}
+
+func (m *mockReviewQueue) Enqueue(item *types.ReviewItem) error {
+	item.ID = int64(len(m.items) + 1)
+	m.items = append(m.items, *item)
+	return nil
+}
+
+func TestWebScraper_ScrapeAndAdd_ReviewQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><ul>
+			<li>Big Thief</li><li>Blurry Band</li><li>Noise</li><li>Nirvana</li><li>Various Artists</li><li>Known Band</li>
+		</ul></body></html>`))
+	}))
+	defer server.Close()
+
+	searcher := &mockSearcher{candidates: map[string][]types.ArtistCandidate{
+		"Big Thief":   {{Artist: types.Artist{ID: "bigthief", Name: "Big Thief"}, NameScore: 1, Confidence: 1}},
+		"Blurry Band": {{Artist: types.Artist{ID: "blurry", Name: "Blurred"}, NameScore: 0.4, Confidence: 0.4}},
+		"Noise":       {{Artist: types.Artist{ID: "noise", Name: "Noisettes"}, NameScore: 0.2, Confidence: 0.2}},
+		"Nirvana": {
+			{Artist: types.Artist{ID: "nirvana-us", Name: "Nirvana"}, NameScore: 1, Confidence: 0.9},
+			{Artist: types.Artist{ID: "nirvana-uk", Name: "Nirvana"}, NameScore: 1, Confidence: 0.85},
+		},
+		"Various Artists": {{Artist: types.Artist{ID: "various", Name: "Various Artists"}, NameScore: 1, Confidence: 0.6}},
+		"Known Band":      {{Artist: types.Artist{ID: "known", Name: "The Known Band"}, NameScore: 0.6, Confidence: 0.6}},
+	}}
+	block, err := blocklist.New(config.BlocklistConfig{NamePattern: "^Various Artists$"})
+	if err != nil {
+		t.Fatalf("blocklist.New() unexpected error: %v", err)
+	}
+	queue := &mockReviewQueue{}
+
+	logger := quietLogger()
+	playlist := &mockPlaylistManager{results: map[string]*types.AddResult{
+		"bigthief": {Success: true, Artist: types.Artist{ID: "bigthief", Name: "Big Thief"}, TracksAdded: []types.Track{{ID: "track1"}}},
+	}}
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), searcher, playlist, logger)
+	scraper.SetBlocklist(block)
+	scraper.SetReviewQueue(queue, 0.3, 0.8)
+	// An uncertain match already in the playlist would add nothing, so it is not queued
+	scraper.duplicateChecker = func(ctx context.Context, playlistID, artistID string) (*types.DuplicateResult, error) {
+		return &types.DuplicateResult{HasDuplicates: artistID == "known"}, nil
+	}
+
+	source := types.AddSource{Kind: types.SourceCLI, User: "user1"}
+	result, err := scraper.ScrapeAndAddToPlaylist(context.Background(), server.URL, "li", "playlist1", types.AddModeSkip, types.TrackSelection{}, source)
+	if err != nil {
+		t.Fatalf("ScrapeAndAddToPlaylist() unexpected error: %v", err)
+	}
+
+	// Confident matches are added, uncertain ones held and the least confident dropped
+	if result.SuccessCount != 1 || result.ReviewCount != 2 || result.BlockedCount != 1 || result.DuplicateCount != 1 || len(playlist.added) != 1 {
+		t.Errorf("ScrapeAndAddToPlaylist() = %+v adding %v, want 1 added, 2 held for review, 1 blocked and 1 duplicate", result, playlist.added)
+	}
+
+	if len(queue.items) != 2 {
+		t.Fatalf("Queued %+v, want Blurry Band and Nirvana", queue.items)
+	}
+	byQuery := make(map[string]types.ReviewItem, len(queue.items))
+	for _, item := range queue.items {
+		byQuery[item.Query] = item
+	}
+	blurry := byQuery["Blurry Band"]
+	if blurry.Artist.ID != "blurry" || blurry.PlaylistID != "playlist1" || blurry.SourceURL != server.URL ||
+		blurry.FoundBy != "text" || blurry.User != "user1" || blurry.Selection.Count != types.DefaultTrackCount {
+		t.Errorf("Blurry Band review item = %+v, want its match, target and source", blurry)
+	}
+	if nirvana := byQuery["Nirvana"]; len(nirvana.Candidates) != 2 {
+		t.Errorf("Nirvana review item = %+v, want both candidates to choose from", nirvana)
+	}
+
+	for _, match := range result.MatchResults {
+		switch match.Query {
+		case "Blurry Band", "Nirvana":
+			if !match.NeedsReview || match.ReviewID == 0 || match.TracksAdded != 0 {
+				t.Errorf("%s = %+v, want it held for review", match.Query, match)
+			}
+		case "Known Band":
+			if match.NeedsReview || !match.WasDuplicate {
+				t.Errorf("Known Band = %+v, want it skipped as a duplicate rather than held for review", match)
+			}
+		case "Noise":
+			if match.Matched {
+				t.Errorf("Noise = %+v, want it dropped below the minimum confidence", match)
+			}
+		}
+	}
+}
@@ -6,25 +6,46 @@
 package scraper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/parallel"
 	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/logging"
 )
 
-// ScraperService defines the interface for web scraping operations.
+// ScraperService defines the interface for web scraping operations. Every operation
+// gives up once its context is cancelled or its deadline passes.
 type ScraperService interface {
 	// ScrapeArtists fetches a URL and extracts potential artist names from the content.
-	ScrapeArtists(url, cssSelector string) ([]string, error)
+	ScrapeArtists(ctx context.Context, url, cssSelector string) ([]string, error)
 
 	// ScrapeAndAddToPlaylist performs a complete scraping workflow: fetch URL,
 	// extract artists, fuzzy match against Spotify, and add to playlist.
-	ScrapeAndAddToPlaylist(url, cssSelector, playlistID string, force bool) (*ScrapeResult, error)
+	ScrapeAndAddToPlaylist(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*ScrapeResult, error)
+
+	// ScrapeAndAddToPlaylistWithProgress is ScrapeAndAddToPlaylist, reporting progress
+	// after each step.
+	ScrapeAndAddToPlaylistWithProgress(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, progress ProgressFunc) (*ScrapeResult, error)
+
+	// ScrapeNewArtists is ScrapeAndAddToPlaylistWithProgress for the names found on the
+	// page that seen reports as not seen before; the others are only counted.
+	ScrapeNewArtists(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, seen func(name string) bool, progress ProgressFunc) (*ScrapeResult, error)
+
+	// PreviewScrape scrapes and matches artists like ScrapeAndAddToPlaylistWithProgress
+	// without adding any, reporting each artist's candidates and duplicate status.
+	PreviewScrape(ctx context.Context, url, cssSelector, playlistID string, progress ProgressFunc) (*ScrapePreview, error)
+
+	// CommitScrape adds the artists approved from a preview, reporting progress after each.
+	CommitScrape(ctx context.Context, url, playlistID string, artists []types.ApprovedArtist, mode types.AddMode, selection types.TrackSelection, source types.AddSource, progress ProgressFunc) (*ScrapeResult, error)
 }
 
 // WebScraper implements the ScraperService interface.
@@ -32,19 +53,24 @@ type WebScraper struct {
 	httpClient       *http.Client
 	parser           HTMLParser
 	extractor        ArtistExtractor
+	extractors       *ExtractorRegistry
 	searcher         types.ArtistSearcher
 	playlist         types.PlaylistManager
-	logger           *logrus.Logger
+	logger           *logging.Logger
 	config           ScraperConfig
 	duplicateChecker DuplicateChecker
-	trackAdder       TrackAdder
+	history          types.HistoryStore
+	blocklist        types.ArtistBlocklist
+	router           types.PlaylistRouter
+	review           types.ReviewQueue
+	// minConfidence is the confidence below which a match is dropped, and
+	// autoAddConfidence the one from which it is added without review
+	minConfidence     float64
+	autoAddConfidence float64
 }
 
 // DuplicateChecker is a function type for checking duplicates (allows testing override)
-type DuplicateChecker func(playlistID, artistID string) (*types.DuplicateResult, error)
-
-// TrackAdder is a function type for adding tracks to playlist (allows testing override)
-type TrackAdder func(playlistID string, trackIDs []string) error
+type DuplicateChecker func(ctx context.Context, playlistID, artistID string) (*types.DuplicateResult, error)
 
 // ScraperConfig holds configuration for the web scraper.
 type ScraperConfig struct {
@@ -53,16 +79,19 @@ type ScraperConfig struct {
 	RetryBackoff   time.Duration
 	UserAgent      string
 	MaxContentSize int64
+	// MatchConcurrency is how many artists are matched against Spotify at the same time
+	MatchConcurrency int
 }
 
 // DefaultScraperConfig returns the default scraper configuration.
 func DefaultScraperConfig() ScraperConfig {
 	return ScraperConfig{
-		Timeout:        30 * time.Second,
-		MaxRetries:     3,
-		RetryBackoff:   2 * time.Second,
-		UserAgent:      "go-listen/1.0 (Web Scraper)",
-		MaxContentSize: 10 * 1024 * 1024, // 10MB
+		Timeout:          30 * time.Second,
+		MaxRetries:       3,
+		RetryBackoff:     2 * time.Second,
+		UserAgent:        "go-listen/1.0 (Web Scraper)",
+		MaxContentSize:   10 * 1024 * 1024, // 10MB
+		MatchConcurrency: types.DefaultSearchConcurrency,
 	}
 }
 
@@ -89,25 +118,77 @@ func NewWebScraper(
 		httpClient: httpClient,
 		parser:     parser,
 		extractor:  extractor,
+		extractors: DefaultExtractorRegistry(),
 		searcher:   searcher,
 		playlist:   playlist,
-		logger:     logger,
+		logger:     logging.Wrap(logger),
 		config:     config,
+
+		minConfidence: MinConfidenceThreshold,
 	}
 
 	// Set default implementations
 	ws.duplicateChecker = ws.checkDuplicateDefault
-	ws.trackAdder = ws.addTracksToPlaylistDefault
 
 	return ws
 }
 
-// MinConfidenceThreshold is the minimum confidence score required for a fuzzy match.
+// SetHistory sets the store looked up for when duplicate artists were last added;
+// additions are recorded by the playlist manager
+func (w *WebScraper) SetHistory(history types.HistoryStore) {
+	w.history = history
+}
+
+// SetExtractors sets the registry of site extractors tried before the generic text
+// extraction, replacing the built-in extractors
+func (w *WebScraper) SetExtractors(extractors *ExtractorRegistry) {
+	w.extractors = extractors
+}
+
+// SetBlocklist sets the blocklist of artists that are never added
+func (w *WebScraper) SetBlocklist(blocklist types.ArtistBlocklist) {
+	w.blocklist = blocklist
+}
+
+// SetRouter sets the router spreading artists across playlists when a scrape targets types.AutoPlaylistID
+func (w *WebScraper) SetRouter(router types.PlaylistRouter) {
+	w.router = router
+}
+
+// SetDuplicateDetector makes the scraper check matches held for review or previewed
+// for duplicates with the detector's artist-level check instead of comparing the
+// artist's top tracks
+func (w *WebScraper) SetDuplicateDetector(detector types.DuplicateDetector) {
+	w.duplicateChecker = detector.CheckArtistInPlaylist
+}
+
+// SetReviewQueue holds matches less confident than autoAddConfidence, or with
+// candidates too close to pick between, in queue until they are approved, instead
+// of adding them. Matches less confident than minConfidence are dropped.
+func (w *WebScraper) SetReviewQueue(queue types.ReviewQueue, minConfidence, autoAddConfidence float64) {
+	w.review = queue
+	w.minConfidence = minConfidence
+	w.autoAddConfidence = autoAddConfidence
+}
+
+// MinConfidenceThreshold is the minimum confidence score required for a fuzzy match
+// when no review queue is set.
 const MinConfidenceThreshold = 0.5
 
-// ScrapeArtists fetches a URL and extracts potential artist names.
-func (w *WebScraper) ScrapeArtists(url, cssSelector string) ([]string, error) {
-	w.logger.WithFields(logrus.Fields{
+// ScrapeArtists fetches a URL and extracts potential artist names, giving up once ctx is cancelled
+func (w *WebScraper) ScrapeArtists(ctx context.Context, url, cssSelector string) ([]string, error) {
+	artists, err := w.extractArtists(ctx, url, cssSelector)
+	if err != nil {
+		return nil, err
+	}
+	return artistNames(artists), nil
+}
+
+// extractArtists fetches a URL and extracts potential artist names with where on the
+// page each was found. Without a CSS selector the page's structured data is read
+// first, then its site extractor; the text is only read when neither finds artists.
+func (w *WebScraper) extractArtists(ctx context.Context, url, cssSelector string) ([]ExtractedArtist, error) {
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":    "scraper",
 		"operation":    "scrape_start",
 		"url":          url,
@@ -115,56 +196,214 @@ func (w *WebScraper) ScrapeArtists(url, cssSelector string) ([]string, error) {
 	}).Info("Starting web scraping operation")
 
 	// Fetch HTML content with retry logic
-	htmlContent, err := w.fetchWithRetry(url)
+	htmlContent, err := w.fetchWithRetry(ctx, url)
 	if err != nil {
-		w.logger.WithError(err).WithField("url", url).Error("Failed to fetch URL")
+		w.logger.WithContext(ctx).WithError(err).WithField("url", url).Error("Failed to fetch URL")
 		return nil, fmt.Errorf("failed to fetch URL: %w", err)
 	}
 
 	// Parse HTML content
 	doc, err := w.parser.Parse(htmlContent)
 	if err != nil {
-		w.logger.WithError(err).Error("Failed to parse HTML content")
+		w.logger.WithContext(ctx).WithError(err).Error("Failed to parse HTML content")
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
+	doc.URL = url
+
+	return w.extractDocumentArtists(ctx, doc, cssSelector)
+}
+
+// extractDocumentArtists extracts potential artist names from a fetched page, as
+// described for extractArtists
+func (w *WebScraper) extractDocumentArtists(ctx context.Context, doc *ParsedDocument, cssSelector string) ([]ExtractedArtist, error) {
+	// Prefer structured data, then the page's site extractor, unless a CSS selector narrows the page down
+	if cssSelector == "" {
+		if artists := w.extractStructuredArtists(ctx, doc); len(artists) > 0 {
+			return artists, nil
+		}
+		if artists := w.extractSiteArtists(ctx, doc); len(artists) > 0 {
+			return artists, nil
+		}
+	}
 
 	// Extract text using CSS selector
 	text, err := w.parser.ExtractText(doc, cssSelector)
 	if err != nil {
-		w.logger.WithError(err).WithField("css_selector", cssSelector).Error("Failed to extract text")
+		w.logger.WithContext(ctx).WithError(err).WithField("css_selector", cssSelector).Error("Failed to extract text")
 		return nil, fmt.Errorf("failed to extract text: %w", err)
 	}
 
 	// Extract artist names from text
-	artists, err := w.extractor.ExtractArtists(text)
+	names, err := w.extractor.ExtractArtists(text)
 	if err != nil {
-		w.logger.WithError(err).Error("Failed to extract artists")
+		w.logger.WithContext(ctx).WithError(err).Error("Failed to extract artists")
 		return nil, fmt.Errorf("failed to extract artists: %w", err)
 	}
 
-	w.logger.WithFields(logrus.Fields{
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":     "scraper",
 		"operation":     "extract_artists",
-		"artists_found": len(artists),
-		"artists":       artists,
+		"artists_found": len(names),
+		"artists":       names,
 	}).Info("Artists extracted from content")
 
+	artists := make([]ExtractedArtist, len(names))
+	for i, name := range names {
+		artists[i] = ExtractedArtist{Name: name, Source: sourceText}
+	}
 	return artists, nil
 }
 
+// extractStructuredArtists extracts the artists described by the document's schema.org
+// JSON-LD and microdata, returning none when it has no such data
+func (w *WebScraper) extractStructuredArtists(ctx context.Context, doc *ParsedDocument) []ExtractedArtist {
+	artists := w.cleanArtists(w.parser.ExtractStructuredArtists(doc))
+	if len(artists) == 0 {
+		return nil
+	}
+
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":     "scraper",
+		"operation":     "extract_artists",
+		"artists_found": len(artists),
+		"artists":       artistNames(artists),
+	}).Info("Artists extracted from structured data")
+
+	return artists
+}
+
+// extractSiteArtists extracts artist names with the site extractor matching the
+// document's URL, returning none when no extractor matches or it finds no artists
+func (w *WebScraper) extractSiteArtists(ctx context.Context, doc *ParsedDocument) []ExtractedArtist {
+	if w.extractors == nil {
+		return nil
+	}
+	siteExtractor := w.extractors.Lookup(doc.URL)
+	if siteExtractor == nil {
+		return nil
+	}
+
+	var artists []ExtractedArtist
+	for _, name := range siteExtractor.Extract(doc) {
+		artists = append(artists, ExtractedArtist{Name: name, Source: sourceExtractorPrefix + siteExtractor.Name()})
+	}
+	artists = w.cleanArtists(artists)
+
+	if len(artists) == 0 {
+		w.logger.WithContext(ctx).WithField("extractor", siteExtractor.Name()).Debug("Site extractor found no artists, falling back to text extraction")
+		return nil
+	}
+
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":     "scraper",
+		"operation":     "extract_artists",
+		"extractor":     siteExtractor.Name(),
+		"artists_found": len(artists),
+		"artists":       artistNames(artists),
+	}).Info("Artists extracted with site extractor")
+
+	return artists
+}
+
+// cleanArtists cleans the artists' names, leaving out those that aren't artist names
+// and repeats that differ only by case; a repeated artist keeps its first source
+func (w *WebScraper) cleanArtists(artists []ExtractedArtist) []ExtractedArtist {
+	seen := make(map[string]bool, len(artists))
+	cleaned := make([]ExtractedArtist, 0, len(artists))
+	for _, artist := range artists {
+		artist.Name = w.extractor.CleanArtistName(artist.Name)
+		key := strings.ToLower(artist.Name)
+		if artist.Name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		cleaned = append(cleaned, artist)
+	}
+	return cleaned
+}
+
+// artistNames returns the names of the artists
+func artistNames(artists []ExtractedArtist) []string {
+	names := make([]string, len(artists))
+	for i, artist := range artists {
+		names[i] = artist.Name
+	}
+	return names
+}
+
+// unseenArtists returns the artists whose names seen reports as not seen before
+func unseenArtists(artists []ExtractedArtist, seen func(name string) bool) []ExtractedArtist {
+	var unseen []ExtractedArtist
+	for _, artist := range artists {
+		if !seen(artist.Name) {
+			unseen = append(unseen, artist)
+		}
+	}
+	return unseen
+}
+
 // ScrapeAndAddToPlaylist performs the complete scraping workflow.
-func (w *WebScraper) ScrapeAndAddToPlaylist(url, cssSelector, playlistID string, force bool) (*ScrapeResult, error) {
+func (w *WebScraper) ScrapeAndAddToPlaylist(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (*ScrapeResult, error) {
+	return w.ScrapeAndAddToPlaylistWithProgress(ctx, url, cssSelector, playlistID, mode, selection, source, nil)
+}
+
+// ScrapeAndAddToPlaylistWithProgress performs the complete scraping workflow, reporting
+// progress after each step when progress is non-nil. Once ctx is cancelled no further
+// artists are added and the partial result is returned with the context's error.
+func (w *WebScraper) ScrapeAndAddToPlaylistWithProgress(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, progress ProgressFunc) (*ScrapeResult, error) {
+	return w.scrapeAndAdd(ctx, url, cssSelector, playlistID, mode, selection, source, nil, progress)
+}
+
+// ScrapeNewArtists performs the complete scraping workflow for the names seen reports
+// as not seen before. Every name found is listed in ArtistsFound, but only the new
+// ones are matched and added; the others are counted in SeenCount.
+func (w *WebScraper) ScrapeNewArtists(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, seen func(name string) bool, progress ProgressFunc) (*ScrapeResult, error) {
+	if seen == nil {
+		return nil, errors.New("seen artist check is required")
+	}
+	return w.scrapeAndAdd(ctx, url, cssSelector, playlistID, mode, selection, source, seen, progress)
+}
+
+// scrapeAndAdd performs the scraping workflow, leaving out the names seen reports
+// as seen before when it is non-nil
+func (w *WebScraper) scrapeAndAdd(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, seen func(name string) bool, progress ProgressFunc) (*ScrapeResult, error) {
+	if progress == nil {
+		progress = func(ScrapeProgress) {}
+	}
+	// emit reports a snapshot together with the event that produced it
+	emit := func(snapshot ScrapeProgress, event types.ProgressEvent) {
+		event.Time = time.Now()
+		snapshot.Event = &event
+		progress(snapshot)
+	}
 	startTime := time.Now()
+	selection = selection.WithDefaults()
+	source.URL = url
+	if mode == "" {
+		mode = types.AddModeSkip
+	}
 
-	w.logger.WithFields(logrus.Fields{
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":    "scraper",
 		"operation":    "scrape_and_add_start",
 		"url":          url,
 		"css_selector": cssSelector,
 		"playlist_id":  playlistID,
-		"force":        force,
+		"mode":         mode,
+		"track_count":  selection.Count,
+		"strategy":     selection.Strategy,
 	}).Info("Starting complete scraping workflow")
 
+	if err := mode.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid add mode: %w", err)
+	}
+	if err := selection.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid track selection: %w", err)
+	}
+	if playlistID == types.AutoPlaylistID && w.router == nil {
+		return nil, errors.New("automatic playlist routing is not configured")
+	}
+
 	result := &ScrapeResult{
 		URL:         url,
 		CSSSelector: cssSelector,
@@ -172,104 +411,85 @@ func (w *WebScraper) ScrapeAndAddToPlaylist(url, cssSelector, playlistID string,
 	}
 
 	// Step 1: Scrape artists from URL
-	artists, err := w.ScrapeArtists(url, cssSelector)
+	progress(ScrapeProgress{Phase: PhaseFetching})
+	artists, err := w.extractArtists(ctx, url, cssSelector)
 	if err != nil {
 		result.Message = fmt.Sprintf("Failed to scrape artists: %v", err)
 		result.Errors = append(result.Errors, err.Error())
+		emit(ScrapeProgress{Phase: PhaseDone}, types.ProgressEvent{Type: types.EventError, Message: result.Message})
 		return result, err
 	}
 
-	result.ArtistsFound = artists
+	result.ArtistsFound = artistNames(artists)
 
 	if len(artists) == 0 {
 		result.Message = "No artists found in the scraped content"
-		w.logger.Info("No artists found in scraped content")
+		w.logger.WithContext(ctx).Info("No artists found in scraped content")
+		emit(ScrapeProgress{Phase: PhaseDone}, types.ProgressEvent{Type: types.EventDone, Message: result.Message})
 		return result, nil
 	}
 
+	// Only process the names that are new since the page was last scraped
+	if seen != nil {
+		artists = unseenArtists(artists, seen)
+		result.SeenCount = len(result.ArtistsFound) - len(artists)
+		if len(artists) == 0 {
+			result.Message = fmt.Sprintf("No new artists: all %d artists found were seen before", result.SeenCount)
+			w.logger.WithContext(ctx).WithField("seen_count", result.SeenCount).Info("No new artists in scraped content")
+			emit(ScrapeProgress{Phase: PhaseDone}, types.ProgressEvent{Type: types.EventDone, Message: result.Message})
+			return result, nil
+		}
+	}
+
 	// Step 2: Fuzzy match artists against Spotify
-	matchResults := w.matchArtists(artists)
+	matching := ScrapeProgress{Phase: PhaseMatching, ArtistsTotal: len(artists)}
+	for _, artist := range artists {
+		emit(matching, types.ProgressEvent{Type: types.EventArtistFound, Query: artist.Name, Source: artist.Source})
+	}
+	// Matches held for review keep their candidates to choose from
+	match := w.matchSingleArtist
+	if w.review != nil {
+		match = w.matchCandidates
+	}
+	matchResults := w.matchArtists(ctx, artists, match, func(match ArtistMatchResult) {
+		emit(matching, matchEvent(match))
+	})
 	result.MatchResults = matchResults
 
 	// Step 3: Add matched artists to playlist
 	for i := range matchResults {
-		matchResult := &matchResults[i]
-
-		// Skip if not matched
-		if !matchResult.Matched {
-			result.FailureCount++
-			continue
-		}
-
-		// Check for duplicates unless force flag is set
-		if !force && w.playlist != nil {
-			dupResult, err := w.duplicateChecker(playlistID, matchResult.Artist.ID)
-			if err != nil {
-				w.logger.WithError(err).WithFields(logrus.Fields{
-					"artist_id":   matchResult.Artist.ID,
-					"artist_name": matchResult.Artist.Name,
-				}).Warn("Failed to check for duplicates, continuing anyway")
-			} else if dupResult != nil && dupResult.HasDuplicates {
-				// Mark as duplicate and skip
-				matchResult.WasDuplicate = true
-				matchResult.Error = "Artist already in playlist"
-				result.DuplicateCount++
-				w.logger.WithFields(logrus.Fields{
-					"artist_id":   matchResult.Artist.ID,
-					"artist_name": matchResult.Artist.Name,
-					"playlist_id": playlistID,
-				}).Info("Skipping duplicate artist")
-				continue
-			}
-		}
-
-		// Get top 5 tracks for the artist
-		tracks, err := w.playlist.GetTop5Tracks(matchResult.Artist.ID)
-		if err != nil {
-			matchResult.Error = fmt.Sprintf("Failed to get tracks: %v", err)
-			result.FailureCount++
-			result.Errors = append(result.Errors, fmt.Sprintf("Artist %s: %v", matchResult.Artist.Name, err))
-			w.logger.WithError(err).WithField("artist_id", matchResult.Artist.ID).Error("Failed to get top tracks")
-			continue
-		}
-
-		// Add tracks to playlist
-		trackIDs := make([]string, len(tracks))
-		for i, track := range tracks {
-			trackIDs[i] = track.ID
+		progress(newScrapeProgress(PhaseAdding, matchResults, i))
+		if err := ctx.Err(); err != nil {
+			partial, err := w.cancelled(ctx, result, matchResults[i:], err)
+			emit(newScrapeProgress(PhaseDone, matchResults, i), types.ProgressEvent{Type: types.EventDone, Message: partial.Message})
+			return partial, err
 		}
 
-		err = w.trackAdder(playlistID, trackIDs)
-		if err != nil {
-			matchResult.Error = fmt.Sprintf("Failed to add tracks: %v", err)
-			result.FailureCount++
-			result.Errors = append(result.Errors, fmt.Sprintf("Artist %s: %v", matchResult.Artist.Name, err))
-			w.logger.WithError(err).WithFields(logrus.Fields{
-				"artist_id":   matchResult.Artist.ID,
-				"playlist_id": playlistID,
-			}).Error("Failed to add tracks to playlist")
-			continue
+		event, ok := w.addMatchedArtist(ctx, result, &matchResults[i], playlistID, mode, selection, source)
+		if ok {
+			emit(newScrapeProgress(PhaseAdding, matchResults, i+1), event)
 		}
-
-		// Success!
-		matchResult.TracksAdded = len(tracks)
-		result.SuccessCount++
-		result.TotalTracksAdded += len(tracks)
-
-		w.logger.WithFields(logrus.Fields{
-			"artist_id":    matchResult.Artist.ID,
-			"artist_name":  matchResult.Artist.Name,
-			"tracks_added": len(tracks),
-			"playlist_id":  playlistID,
-		}).Info("Successfully added artist tracks to playlist")
 	}
 
 	// Build summary message
 	duration := time.Since(startTime)
 	result.Message = fmt.Sprintf("Scraping complete: %d artists found, %d matched, %d added, %d duplicates, %d failed",
 		len(result.ArtistsFound), w.countMatched(result.MatchResults), result.SuccessCount, result.DuplicateCount, result.FailureCount)
+	if result.BlockedCount > 0 {
+		result.Message += fmt.Sprintf(", %d blocked", result.BlockedCount)
+	}
+	if result.ReviewCount > 0 {
+		result.Message += fmt.Sprintf(", %d held for review", result.ReviewCount)
+	}
+	if result.SeenCount > 0 {
+		result.Message += fmt.Sprintf(", %d seen before", result.SeenCount)
+	}
+	if result.TotalTracksSkipped > 0 {
+		result.Message += fmt.Sprintf(", %d tracks already present", result.TotalTracksSkipped)
+	}
+	emit(newScrapeProgress(PhaseDone, matchResults, len(matchResults)), types.ProgressEvent{Type: types.EventDone, Message: result.Message})
 
-	w.logger.WithFields(logrus.Fields{
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":       "scraper",
 		"operation":       "scrape_complete",
 		"url":             url,
@@ -277,36 +497,296 @@ func (w *WebScraper) ScrapeAndAddToPlaylist(url, cssSelector, playlistID string,
 		"success_count":   result.SuccessCount,
 		"failure_count":   result.FailureCount,
 		"duplicate_count": result.DuplicateCount,
+		"blocked_count":   result.BlockedCount,
+		"review_count":    result.ReviewCount,
+		"seen_count":      result.SeenCount,
 		"total_tracks":    result.TotalTracksAdded,
+		"skipped_tracks":  result.TotalTracksSkipped,
 		"duration_ms":     duration.Milliseconds(),
 	}).Info("Web scraping operation completed")
 
 	return result, nil
 }
 
+// addMatchedArtist adds a single matched artist through the playlist manager, so the
+// blocklist, routing, add mode and history apply as they do to a single addition, unless
+// the match is held for review. The outcome is recorded in matchResult and the scrape's
+// totals. It returns the event describing the outcome; ok is false for artists that were
+// not matched, which were already reported while matching.
+func (w *WebScraper) addMatchedArtist(ctx context.Context, result *ScrapeResult, matchResult *ArtistMatchResult, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) (event types.ProgressEvent, ok bool) {
+	// Skip if not matched
+	if !matchResult.Matched {
+		result.FailureCount++
+		return types.ProgressEvent{}, false
+	}
+
+	if w.needsReview(*matchResult) {
+		return w.holdForReview(ctx, result, matchResult, playlistID, mode, selection, source), true
+	}
+	return w.addArtistByID(ctx, result, matchResult, matchResult.Artist.ID, playlistID, mode, selection, source), true
+}
+
+// addArtistByID adds a single artist by its Spotify ID through the playlist manager,
+// recording the outcome in matchResult and the scrape's totals
+func (w *WebScraper) addArtistByID(ctx context.Context, result *ScrapeResult, matchResult *ArtistMatchResult, artistID, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) types.ProgressEvent {
+	addResult, err := w.playlist.AddArtistByID(ctx, artistID, playlistID, mode, selection, source)
+	if addResult == nil {
+		addResult = &types.AddResult{}
+		if err != nil {
+			addResult.Message = err.Error()
+		}
+	}
+	if addResult.Artist.ID != "" {
+		matchResult.Matched = true
+		matchResult.Artist = &addResult.Artist
+	}
+	matchResult.Route = addResult.Route
+	matchResult.TracksSkipped = len(addResult.TracksSkipped)
+	result.TotalTracksSkipped += len(addResult.TracksSkipped)
+
+	event := types.ProgressEvent{
+		Query:         matchResult.Query,
+		Artist:        matchResult.Artist,
+		Confidence:    matchResult.Confidence,
+		Route:         addResult.Route,
+		TracksSkipped: matchResult.TracksSkipped,
+		Message:       addResult.Message,
+	}
+
+	switch {
+	case addResult.Success:
+		matchResult.TracksAdded = len(addResult.TracksAdded)
+		result.SuccessCount++
+		result.TotalTracksAdded += len(addResult.TracksAdded)
+		event.Type = types.EventTracksAdded
+		event.TracksAdded = matchResult.TracksAdded
+	case addResult.Blocked:
+		matchResult.Blocked = true
+		matchResult.BlockReason = addResult.BlockReason
+		matchResult.Error = addResult.Message
+		result.BlockedCount++
+		event.Type = types.EventArtistBlocked
+	case addResult.WasDuplicate:
+		matchResult.WasDuplicate = true
+		matchResult.LastAdded = addResult.LastAdded
+		matchResult.Error = addResult.Message
+		result.DuplicateCount++
+		event.Type = types.EventDuplicateSkipped
+	default:
+		matchResult.Error = addResult.Message
+		result.FailureCount++
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Artist %s: %v", matchResult.Query, err))
+		}
+		w.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"artist_id": artistID,
+			"reason":    addResult.Message,
+		}).Warn("Failed to add artist")
+		event.Type = types.EventError
+	}
+	return event
+}
+
+// holdForReview queues an uncertain match until someone approves it, once the blocklist,
+// routing and the duplicate check show it would be added. The review item keeps the
+// requested playlist, so routing and the add mode apply again when it is approved.
+func (w *WebScraper) holdForReview(ctx context.Context, result *ScrapeResult, matchResult *ArtistMatchResult, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) types.ProgressEvent {
+	event := types.ProgressEvent{Query: matchResult.Query, Artist: matchResult.Artist, Confidence: matchResult.Confidence}
+
+	err := w.screenMatch(ctx, matchResult, playlistID, mode)
+	event.Route = matchResult.Route
+	event.Message = matchResult.Error
+	switch {
+	case err != nil:
+		result.FailureCount++
+		result.Errors = append(result.Errors, fmt.Sprintf("Artist %s: %v", matchResult.Artist.Name, err))
+		event.Type = types.EventError
+		return event
+	case matchResult.Blocked:
+		result.BlockedCount++
+		event.Type = types.EventArtistBlocked
+		return event
+	case matchResult.WasDuplicate:
+		result.DuplicateCount++
+		event.Type = types.EventDuplicateSkipped
+		return event
+	}
+
+	if err := w.enqueueReview(ctx, matchResult, playlistID, mode, selection, source); err != nil {
+		matchResult.Error = fmt.Sprintf("Failed to queue artist for review: %v", err)
+		result.FailureCount++
+		result.Errors = append(result.Errors, fmt.Sprintf("Artist %s: %v", matchResult.Artist.Name, err))
+		w.logger.WithContext(ctx).WithError(err).WithField("query", matchResult.Query).Error("Failed to queue artist for review")
+		event.Type = types.EventError
+		event.Message = matchResult.Error
+		return event
+	}
+	result.ReviewCount++
+	event.Type = types.EventArtistQueued
+	event.Message = matchResult.Error
+	return event
+}
+
+// screenMatch records whether a matched artist is blocked, where it would be routed and,
+// in skip mode, whether it is already in the playlist, without adding anything. The
+// error is returned, and recorded in matchResult, when the artist can't be routed.
+func (w *WebScraper) screenMatch(ctx context.Context, matchResult *ArtistMatchResult, playlistID string, mode types.AddMode) error {
+	// Blocked artists are never added, whatever the add mode
+	if w.blocklist != nil {
+		if blocked, reason := w.blocklist.Blocked(*matchResult.Artist); blocked {
+			matchResult.Blocked = true
+			matchResult.BlockReason = reason
+			matchResult.Error = reason.Message()
+			return nil
+		}
+	}
+
+	if playlistID == types.AutoPlaylistID {
+		route, err := w.router.Route(ctx, *matchResult.Artist)
+		if err != nil {
+			matchResult.Error = fmt.Sprintf("Failed to route artist: %v", err)
+			w.logger.WithContext(ctx).WithError(err).WithField("artist_id", matchResult.Artist.ID).Error("Failed to route artist to a playlist")
+			return err
+		}
+		if route == nil {
+			matchResult.Error = "No routing rule matches the artist's genres and no default playlist is configured"
+			return errors.New(matchResult.Error)
+		}
+		matchResult.Route = route
+		playlistID = route.PlaylistID
+	}
+
+	if mode != types.AddModeSkip || w.playlist == nil {
+		return nil
+	}
+	dupResult, err := w.duplicateChecker(ctx, playlistID, matchResult.Artist.ID)
+	if err != nil {
+		w.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"artist_id":   matchResult.Artist.ID,
+			"artist_name": matchResult.Artist.Name,
+		}).Warn("Failed to check for duplicates, continuing anyway")
+		return nil
+	}
+	if dupResult != nil && dupResult.HasDuplicates {
+		matchResult.WasDuplicate = true
+		matchResult.Error = "Artist already in playlist"
+		matchResult.LastAdded = w.lastAdded(ctx, playlistID, matchResult.Artist.ID)
+	}
+	return nil
+}
+
+// needsReview reports whether a match must be approved before its artist is added
+func (w *WebScraper) needsReview(match ArtistMatchResult) bool {
+	return w.review != nil && (match.Confidence < w.autoAddConfidence || match.NeedsDisambiguation)
+}
+
+// enqueueReview queues a match for review with what is needed to add it once approved
+func (w *WebScraper) enqueueReview(ctx context.Context, matchResult *ArtistMatchResult, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource) error {
+	item := &types.ReviewItem{
+		Query:      matchResult.Query,
+		Artist:     *matchResult.Artist,
+		Confidence: matchResult.Confidence,
+		Candidates: matchResult.Candidates,
+		PlaylistID: playlistID,
+		Mode:       mode,
+		Selection:  selection,
+		Source:     source.Kind,
+		SourceURL:  source.URL,
+		FoundBy:    matchResult.Source,
+		User:       source.User,
+	}
+	if err := w.review.Enqueue(item); err != nil {
+		return err
+	}
+
+	matchResult.NeedsReview = true
+	matchResult.ReviewID = item.ID
+	matchResult.Error = "Held for review"
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":  "scraper",
+		"operation":  "queue_review",
+		"query":      matchResult.Query,
+		"artist_id":  matchResult.Artist.ID,
+		"confidence": matchResult.Confidence,
+		"review_id":  item.ID,
+	}).Info("Holding artist match for review")
+	return nil
+}
+
+// matchEvent describes the outcome of matching a single artist
+func matchEvent(match ArtistMatchResult) types.ProgressEvent {
+	event := types.ProgressEvent{
+		Type:       types.EventArtistMatched,
+		Query:      match.Query,
+		Artist:     match.Artist,
+		Confidence: match.Confidence,
+	}
+	if !match.Matched {
+		event.Type = types.EventArtistNotMatched
+		event.Message = match.Error
+	}
+	return event
+}
+
+// cancelled finishes a scrape stopped before the remaining artists were processed
+func (w *WebScraper) cancelled(ctx context.Context, result *ScrapeResult, remaining []ArtistMatchResult, err error) (*ScrapeResult, error) {
+	for i := range remaining {
+		if remaining[i].Error == "" {
+			remaining[i].Error = "Scrape cancelled"
+		}
+	}
+	result.Message = fmt.Sprintf("Scraping cancelled: %d artists found, %d added, %d duplicates, %d failed, %d not processed",
+		len(result.ArtistsFound), result.SuccessCount, result.DuplicateCount, result.FailureCount, len(remaining))
+	result.Errors = append(result.Errors, err.Error())
+
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":     "scraper",
+		"operation":     "scrape_cancelled",
+		"url":           result.URL,
+		"success_count": result.SuccessCount,
+		"not_processed": len(remaining),
+	}).Warn("Web scraping operation cancelled")
+
+	return result, err
+}
+
+// newScrapeProgress snapshots the progress of a scrape that has processed the first done artists
+func newScrapeProgress(phase string, results []ArtistMatchResult, done int) ScrapeProgress {
+	return ScrapeProgress{
+		Phase:            phase,
+		ArtistsTotal:     len(results),
+		ArtistsProcessed: done,
+		Artists:          append([]ArtistMatchResult(nil), results[:done]...),
+	}
+}
+
 // fetchWithRetry fetches a URL with exponential backoff retry logic.
-func (w *WebScraper) fetchWithRetry(url string) (string, error) {
+func (w *WebScraper) fetchWithRetry(ctx context.Context, url string) (string, error) {
 	var lastErr error
 	backoff := w.config.RetryBackoff
 
 	for attempt := 0; attempt <= w.config.MaxRetries; attempt++ {
 		if attempt > 0 {
-			w.logger.WithFields(logrus.Fields{
+			w.logger.WithContext(ctx).WithFields(logrus.Fields{
 				"attempt": attempt,
 				"backoff": backoff,
 				"url":     url,
 			}).Info("Retrying HTTP request")
-			time.Sleep(backoff)
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(backoff):
+			}
 			backoff *= 2 // Exponential backoff
 		}
 
-		htmlContent, err := w.fetchURL(url)
+		htmlContent, err := w.fetchURL(ctx, url)
 		if err == nil {
 			return htmlContent, nil
 		}
 
 		lastErr = err
-		w.logger.WithError(err).WithFields(logrus.Fields{
+		w.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
 			"attempt": attempt + 1,
 			"max":     w.config.MaxRetries + 1,
 			"url":     url,
@@ -317,8 +797,8 @@ func (w *WebScraper) fetchWithRetry(url string) (string, error) {
 }
 
 // fetchURL fetches HTML content from a URL.
-func (w *WebScraper) fetchURL(url string) (string, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (w *WebScraper) fetchURL(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -334,7 +814,7 @@ func (w *WebScraper) fetchURL(url string) (string, error) {
 	}
 	defer resp.Body.Close()
 
-	w.logger.WithFields(logrus.Fields{
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":      "scraper",
 		"operation":      "http_fetch",
 		"url":            url,
@@ -358,9 +838,9 @@ func (w *WebScraper) fetchURL(url string) (string, error) {
 }
 
 // checkDuplicateDefault is the default implementation for checking duplicates.
-func (w *WebScraper) checkDuplicateDefault(playlistID, artistID string) (*types.DuplicateResult, error) {
+func (w *WebScraper) checkDuplicateDefault(ctx context.Context, playlistID, artistID string) (*types.DuplicateResult, error) {
 	// Get the artist's top tracks
-	tracks, err := w.playlist.GetTop5Tracks(artistID)
+	tracks, err := w.playlist.GetArtistTracks(ctx, artistID, types.DefaultTrackSelection())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tracks for duplicate check: %w", err)
 	}
@@ -378,9 +858,9 @@ func (w *WebScraper) checkDuplicateDefault(playlistID, artistID string) (*types.
 	}
 
 	// Use the playlist manager to check for duplicates
-	duplicateResult, err := w.playlist.CheckForDuplicates(playlistID, trackIDs)
+	duplicateResult, err := w.playlist.CheckForDuplicates(ctx, playlistID, trackIDs)
 	if err != nil {
-		w.logger.WithError(err).WithFields(logrus.Fields{
+		w.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
 			"playlist_id": playlistID,
 			"artist_id":   artistID,
 		}).Warn("Failed to check for duplicates, assuming no duplicates")
@@ -394,50 +874,54 @@ func (w *WebScraper) checkDuplicateDefault(playlistID, artistID string) (*types.
 	return duplicateResult, nil
 }
 
-// addTracksToPlaylistDefault is the default implementation for adding tracks to a playlist.
-func (w *WebScraper) addTracksToPlaylistDefault(playlistID string, trackIDs []string) error {
-	if len(trackIDs) == 0 {
-		return fmt.Errorf("no tracks provided to add")
+// lastAdded returns when the artist was last added to the playlist according to history
+func (w *WebScraper) lastAdded(ctx context.Context, playlistID, artistID string) *time.Time {
+	if w.history == nil {
+		return nil
 	}
 
-	// Use the playlist manager to add tracks to the playlist
-	err := w.playlist.AddTracksToPlaylist(playlistID, trackIDs)
+	entry, err := w.history.LastAdded(playlistID, artistID)
 	if err != nil {
-		w.logger.WithError(err).WithFields(logrus.Fields{
-			"playlist_id": playlistID,
-			"track_count": len(trackIDs),
-			"track_ids":   trackIDs,
-		}).Error("Failed to add tracks to playlist via playlist manager")
-		return fmt.Errorf("failed to add tracks to playlist: %w", err)
+		w.logger.WithContext(ctx).WithError(err).WithField("artist_id", artistID).Warn("Failed to look up artist history")
+		return nil
 	}
-
-	w.logger.WithFields(logrus.Fields{
-		"playlist_id": playlistID,
-		"track_count": len(trackIDs),
-		"track_ids":   trackIDs,
-	}).Info("Successfully added tracks to playlist via playlist manager")
-
-	return nil
+	if entry == nil {
+		return nil
+	}
+	return &entry.AddedAt
 }
 
-// matchArtists performs batch fuzzy matching of artist names against Spotify.
+// matchArtists performs batch fuzzy matching of artist names against Spotify, matching
+// up to MatchConcurrency artists at a time and returning the results in input order.
 // It filters out low confidence matches and selects the best match for each query.
-func (w *WebScraper) matchArtists(artistNames []string) []ArtistMatchResult {
-	if len(artistNames) == 0 {
+// Each artist is matched with match. Artists not yet matched when ctx is cancelled
+// are left unmatched. onMatch, if non-nil, is called with each artist's result as
+// soon as it is matched.
+func (w *WebScraper) matchArtists(ctx context.Context, artists []ExtractedArtist, match func(ctx context.Context, query string) ArtistMatchResult, onMatch func(ArtistMatchResult)) []ArtistMatchResult {
+	if len(artists) == 0 {
 		return []ArtistMatchResult{}
 	}
 
-	w.logger.WithField("artist_count", len(artistNames)).Info("Starting batch artist matching")
-
-	results := make([]ArtistMatchResult, 0, len(artistNames))
+	w.logger.WithContext(ctx).WithField("artist_count", len(artists)).Info("Starting batch artist matching")
 
-	for _, query := range artistNames {
-		result := w.matchSingleArtist(query)
-		results = append(results, result)
-	}
+	// Results are reported one at a time, so onMatch needn't be safe for concurrent use
+	var reportMu sync.Mutex
+	results := parallel.Map(artists, types.ClampSearchConcurrency(w.config.MatchConcurrency), func(artist ExtractedArtist) ArtistMatchResult {
+		if ctx.Err() != nil {
+			return ArtistMatchResult{Query: artist.Name, Source: artist.Source}
+		}
+		result := match(ctx, artist.Name)
+		result.Source = artist.Source
+		if onMatch != nil {
+			reportMu.Lock()
+			onMatch(result)
+			reportMu.Unlock()
+		}
+		return result
+	})
 
-	w.logger.WithFields(logrus.Fields{
-		"total_queries":  len(artistNames),
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"total_queries":  len(artists),
 		"matched":        w.countMatched(results),
 		"low_confidence": w.countLowConfidence(results),
 		"failed":         w.countFailed(results),
@@ -447,48 +931,52 @@ func (w *WebScraper) matchArtists(artistNames []string) []ArtistMatchResult {
 }
 
 // matchSingleArtist matches a single artist query against Spotify with confidence filtering.
-func (w *WebScraper) matchSingleArtist(query string) ArtistMatchResult {
+func (w *WebScraper) matchSingleArtist(ctx context.Context, query string) ArtistMatchResult {
 	result := ArtistMatchResult{
 		Query:   query,
 		Matched: false,
 	}
 
 	// Use the fuzzy searcher to find the best match
-	artist, confidence, err := w.searcher.FindBestMatch(query)
+	artist, confidence, err := w.searcher.FindBestMatch(ctx, query)
 	if err != nil {
-		w.logger.WithError(err).WithField("query", query).Warn("Failed to find artist match")
+		w.logger.WithContext(ctx).WithError(err).WithField("query", query).Warn("Failed to find artist match")
 		result.Error = err.Error()
 		return result
 	}
 
 	result.Artist = artist
 	result.Confidence = confidence
+	w.acceptMatch(ctx, &result)
+	return result
+}
 
+// acceptMatch marks a result's artist as matched when its confidence reaches the minimum confidence
+func (w *WebScraper) acceptMatch(ctx context.Context, result *ArtistMatchResult) {
 	// Apply confidence threshold filtering
-	if confidence < MinConfidenceThreshold {
-		w.logger.WithFields(logrus.Fields{
+	if result.Confidence < w.minConfidence {
+		w.logger.WithContext(ctx).WithFields(logrus.Fields{
 			"component":  "scraper",
 			"operation":  "skip_low_confidence",
-			"query":      query,
-			"artist":     artist.Name,
-			"confidence": confidence,
-			"threshold":  MinConfidenceThreshold,
+			"query":      result.Query,
+			"artist":     result.Artist.Name,
+			"confidence": result.Confidence,
+			"threshold":  w.minConfidence,
 		}).Warn("Skipping artist due to low confidence match")
-		result.Error = fmt.Sprintf("confidence %.2f below threshold %.2f", confidence, MinConfidenceThreshold)
-		return result
+		result.Error = fmt.Sprintf("confidence %.2f below threshold %.2f", result.Confidence, w.minConfidence)
+		return
 	}
 
 	// Log successful match
-	w.logger.WithFields(logrus.Fields{
+	w.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":  "scraper",
 		"operation":  "fuzzy_match",
-		"query":      query,
-		"artist":     artist.Name,
-		"confidence": confidence,
+		"query":      result.Query,
+		"artist":     result.Artist.Name,
+		"confidence": result.Confidence,
 	}).Info("Artist matched")
 
 	result.Matched = true
-	return result
 }
 
 // countMatched counts the number of successfully matched artists.
@@ -506,7 +994,7 @@ func (w *WebScraper) countMatched(results []ArtistMatchResult) int {
 func (w *WebScraper) countLowConfidence(results []ArtistMatchResult) int {
 	count := 0
 	for _, r := range results {
-		if !r.Matched && r.Artist != nil && r.Confidence < MinConfidenceThreshold {
+		if !r.Matched && r.Artist != nil && r.Confidence < w.minConfidence {
 			count++
 		}
 	}
@@ -526,33 +1014,83 @@ func (w *WebScraper) countFailed(results []ArtistMatchResult) int {
 
 // ScrapeResult contains the results of a scraping operation.
 type ScrapeResult struct {
-	URL              string              `json:"url"`
-	CSSSelector      string              `json:"css_selector,omitempty"`
-	ArtistsFound     []string            `json:"artists_found"`
-	MatchResults     []ArtistMatchResult `json:"match_results"`
-	SuccessCount     int                 `json:"success_count"`
-	FailureCount     int                 `json:"failure_count"`
-	DuplicateCount   int                 `json:"duplicate_count"`
-	TotalTracksAdded int                 `json:"total_tracks_added"`
-	Message          string              `json:"message"`
-	Errors           []string            `json:"errors,omitempty"`
+	URL            string              `json:"url"`
+	CSSSelector    string              `json:"css_selector,omitempty"`
+	ArtistsFound   []string            `json:"artists_found"`
+	MatchResults   []ArtistMatchResult `json:"match_results"`
+	SuccessCount   int                 `json:"success_count"`
+	FailureCount   int                 `json:"failure_count"`
+	DuplicateCount int                 `json:"duplicate_count"`
+	// BlockedCount counts matched artists left out because they are on the blocklist
+	BlockedCount int `json:"blocked_count"`
+	// ReviewCount counts matched artists held in the review queue instead of being added
+	ReviewCount int `json:"review_count,omitempty"`
+	// SeenCount counts the names found that were left out because they were seen before
+	SeenCount        int `json:"seen_count,omitempty"`
+	TotalTracksAdded int `json:"total_tracks_added"`
+	// TotalTracksSkipped counts tracks left out in fill-gaps mode because they were already in the playlist
+	TotalTracksSkipped int      `json:"total_tracks_skipped,omitempty"`
+	Message            string   `json:"message"`
+	Errors             []string `json:"errors,omitempty"`
 }
 
+// Scrape phases reported through ScrapeProgress
+const (
+	PhaseFetching = "fetching"
+	PhaseMatching = "matching"
+	PhaseAdding   = "adding"
+	PhaseDone     = "done"
+)
+
+// ScrapeProgress is a snapshot of how far a scrape has got. Artists holds the
+// results of the artists processed so far, in page order.
+type ScrapeProgress struct {
+	Phase            string              `json:"phase"`
+	ArtistsTotal     int                 `json:"artists_total"`
+	ArtistsProcessed int                 `json:"artists_processed"`
+	Artists          []ArtistMatchResult `json:"artists,omitempty"`
+	// Event is the step that produced this snapshot, if any
+	Event *types.ProgressEvent `json:"event,omitempty"`
+}
+
+// ProgressFunc receives progress snapshots while a scrape runs
+type ProgressFunc func(progress ScrapeProgress)
+
 // ArtistMatchResult contains the result of matching a single artist.
 type ArtistMatchResult struct {
-	Query        string        `json:"query"`
-	Matched      bool          `json:"matched"`
-	Artist       *types.Artist `json:"artist,omitempty"`
-	Confidence   float64       `json:"confidence"`
-	TracksAdded  int           `json:"tracks_added"`
-	WasDuplicate bool          `json:"was_duplicate"`
-	Error        string        `json:"error,omitempty"`
+	Query string `json:"query"`
+	// Source is where on the page the name was found, such as "text" or "jsonld:MusicEvent.performer"
+	Source     string        `json:"source,omitempty"`
+	Matched    bool          `json:"matched"`
+	Artist     *types.Artist `json:"artist,omitempty"`
+	Confidence float64       `json:"confidence"`
+	// Candidates lists the artists the name could refer to, most confident first; only
+	// previews and scrapes with a review queue fill it in, setting NeedsDisambiguation
+	// when the choice is unclear
+	Candidates          []types.ArtistCandidate `json:"candidates,omitempty"`
+	NeedsDisambiguation bool                    `json:"needs_disambiguation,omitempty"`
+	TracksAdded         int                     `json:"tracks_added"`
+	// TracksSkipped counts the artist's tracks left out in fill-gaps mode because they were already in the playlist
+	TracksSkipped int        `json:"tracks_skipped,omitempty"`
+	WasDuplicate  bool       `json:"was_duplicate"`
+	LastAdded     *time.Time `json:"last_added,omitempty"`
+	// Blocked is set when the matched artist is on the blocklist, with the rule that blocked it
+	Blocked     bool              `json:"blocked,omitempty"`
+	BlockReason types.BlockReason `json:"block_reason,omitempty"`
+	// NeedsReview is set when the match must be approved before the artist is added,
+	// with the ID of the review queue item it waits in once a scrape queued it
+	NeedsReview bool  `json:"needs_review,omitempty"`
+	ReviewID    int64 `json:"review_id,omitempty"`
+	// Route is the playlist the artist was routed to when the scrape targeted types.AutoPlaylistID
+	Route *types.PlaylistRoute `json:"route,omitempty"`
+	Error string               `json:"error,omitempty"`
 }
 
 // HTMLParser defines the interface for HTML parsing operations.
 type HTMLParser interface {
 	Parse(htmlContent string) (*ParsedDocument, error)
 	ExtractText(doc *ParsedDocument, cssSelector string) (string, error)
+	ExtractStructuredArtists(doc *ParsedDocument) []ExtractedArtist
 	ValidateSelector(cssSelector string) error
 }
 
@@ -0,0 +1,235 @@
+package scraper
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/sirupsen/logrus"
+)
+
+// Provenance of names found by text extraction and site extractors; names read from
+// structured data are tagged with the format, type and property they came from, such
+// as "jsonld:MusicEvent.performer" or "microdata:MusicGroup"
+const (
+	sourceText            = "text"
+	sourceExtractorPrefix = "extractor:"
+	sourceJSONLD          = "jsonld"
+	sourceMicrodata       = "microdata"
+)
+
+// ExtractedArtist is an artist name found on a page, with where on the page it came from
+type ExtractedArtist struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// artistProperties are the schema.org properties naming the artists of an event or release
+var artistProperties = []string{"performer", "performers", "byArtist"}
+
+// placeholderArtists are names standing in for an artist, such as the byArtist of a
+// compilation, which name no one and would hide the page's real artists
+var placeholderArtists = map[string]bool{
+	"various artists": true,
+	"various":         true,
+	"va":              true,
+	"v.a.":            true,
+	"unknown artist":  true,
+}
+
+// isPlaceholderArtist reports whether name stands in for an artist rather than naming one
+func isPlaceholderArtist(name string) bool {
+	return placeholderArtists[strings.ToLower(strings.Join(strings.Fields(name), " "))]
+}
+
+// jsonLDContainers are the properties followed to find artists nested in other things,
+// such as the events of a festival or the entries of a list. Authors, organizers and
+// publishers are left out as they are rarely artists.
+var jsonLDContainers = []string{"@graph", "subEvent", "itemListElement", "item", "mainEntity", "track", "event", "events"}
+
+// groupTypes are the schema.org types of performing groups, which name an artist
+// wherever they appear
+var groupTypes = map[string]bool{
+	"MusicGroup":      true,
+	"PerformingGroup": true,
+}
+
+// ExtractStructuredArtists returns the artists described by the schema.org JSON-LD and
+// microdata of the document: the performers of events, the artists of albums and
+// recordings, and music groups. Placeholders such as "Various Artists" are left out,
+// and JSON-LD blocks that fail to parse are skipped.
+func (g *GoqueryParser) ExtractStructuredArtists(doc *ParsedDocument) []ExtractedArtist {
+	if doc == nil || doc.Document == nil {
+		return nil
+	}
+
+	var artists []ExtractedArtist
+	emit := func(name, source string) {
+		if name = strings.Join(strings.Fields(name), " "); name != "" && !isPlaceholderArtist(name) {
+			artists = append(artists, ExtractedArtist{Name: name, Source: source})
+		}
+	}
+
+	doc.Document.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			g.logger.WithError(err).WithField("block", i).Debug("Skipping JSON-LD block that failed to parse")
+			return
+		}
+		walkJSONLD(data, "", "", emit)
+	})
+
+	extractMicrodata(doc.Document, emit)
+
+	g.logger.WithFields(logrus.Fields{
+		"artists_found": len(artists),
+	}).Debug("Extracted artists from structured data")
+
+	return artists
+}
+
+// walkJSONLD walks a JSON-LD node found in property of a parentType node, emitting the
+// artists it names
+func walkJSONLD(node any, parentType, property string, emit func(name, source string)) {
+	switch node := node.(type) {
+	case []any:
+		for _, child := range node {
+			walkJSONLD(child, parentType, property, emit)
+		}
+	case string:
+		// Artists may be given by name alone
+		if slices.Contains(artistProperties, property) {
+			emit(node, provenance(sourceJSONLD, parentType, property))
+		}
+	case map[string]any:
+		nodeTypes := jsonLDTypes(node["@type"])
+		name, _ := node["name"].(string)
+
+		switch {
+		case slices.Contains(artistProperties, property):
+			emit(name, provenance(sourceJSONLD, parentType, property))
+			return
+		case firstGroupType(nodeTypes) != "":
+			emit(name, provenance(sourceJSONLD, firstGroupType(nodeTypes), ""))
+		}
+
+		// Nodes without a type, like @graph wrappers, keep their parent's
+		nodeType := parentType
+		if len(nodeTypes) > 0 {
+			nodeType = nodeTypes[0]
+		}
+		for _, key := range jsonLDContainers {
+			if child, ok := node[key]; ok {
+				walkJSONLD(child, nodeType, key, emit)
+			}
+		}
+		for _, key := range artistProperties {
+			if child, ok := node[key]; ok {
+				walkJSONLD(child, nodeType, key, emit)
+			}
+		}
+	}
+}
+
+// jsonLDTypes returns a node's @type, a string or a list of strings, without schema.org prefixes
+func jsonLDTypes(value any) []string {
+	var types []string
+	switch value := value.(type) {
+	case string:
+		types = append(types, schemaType(value))
+	case []any:
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				types = append(types, schemaType(s))
+			}
+		}
+	}
+	return types
+}
+
+// schemaType strips the schema.org URL prefix from a type, so
+// "https://schema.org/MusicEvent" becomes "MusicEvent"
+func schemaType(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.LastIndexAny(value, "/:"); i >= 0 {
+		return value[i+1:]
+	}
+	return value
+}
+
+// firstGroupType returns the first of the types that is a performing group, if any
+func firstGroupType(types []string) string {
+	for _, t := range types {
+		if groupTypes[t] {
+			return t
+		}
+	}
+	return ""
+}
+
+// provenance formats where a name was found: the format, then the type of the thing
+// holding it and the property naming it, like "jsonld:MusicEvent.performer"
+func provenance(format, thingType, property string) string {
+	if thingType == "" {
+		thingType = "Thing"
+	}
+	if property == "" {
+		return format + ":" + thingType
+	}
+	return format + ":" + thingType + "." + property
+}
+
+// extractMicrodata emits the artists described by the document's microdata: the
+// performers and artists of items, and music groups that are items of their own
+func extractMicrodata(doc *goquery.Document, emit func(name, source string)) {
+	doc.Find("[itemprop]").Each(func(_ int, s *goquery.Selection) {
+		for _, property := range strings.Fields(s.AttrOr("itemprop", "")) {
+			if !slices.Contains(artistProperties, property) {
+				continue
+			}
+			parentType := schemaType(s.Parent().Closest("[itemscope]").AttrOr("itemtype", ""))
+			emit(microdataName(s), provenance(sourceMicrodata, parentType, property))
+			return
+		}
+	})
+
+	doc.Find("[itemscope][itemtype]").Each(func(_ int, s *goquery.Selection) {
+		if _, isProperty := s.Attr("itemprop"); isProperty {
+			// Groups that are the value of a property were handled above, or aren't artists
+			return
+		}
+		var itemTypes []string
+		for _, itemType := range strings.Fields(s.AttrOr("itemtype", "")) {
+			itemTypes = append(itemTypes, schemaType(itemType))
+		}
+		if groupType := firstGroupType(itemTypes); groupType != "" {
+			emit(microdataName(s), provenance(sourceMicrodata, groupType, ""))
+		}
+	})
+}
+
+// microdataName returns the name of a microdata item: its own name property, leaving
+// out those of nested items, or its text when it is a plain value rather than an item
+func microdataName(s *goquery.Selection) string {
+	if _, isItem := s.Attr("itemscope"); !isItem {
+		return microdataValue(s)
+	}
+
+	name := s.Find(`[itemprop~="name"]`).FilterFunction(func(_ int, prop *goquery.Selection) bool {
+		return prop.Parent().Closest("[itemscope]").IsSelection(s)
+	}).First()
+	if name.Length() == 0 {
+		return ""
+	}
+	return microdataValue(name)
+}
+
+// microdataValue returns the value of a microdata property, from its content attribute
+// when it has one, as meta elements do, or else its text
+func microdataValue(s *goquery.Selection) string {
+	if content, ok := s.Attr("content"); ok {
+		return content
+	}
+	return s.Text()
+}
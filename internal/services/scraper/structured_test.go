@@ -0,0 +1,143 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGoqueryParser_ExtractStructuredArtists(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []ExtractedArtist
+	}{
+		{
+			fixture: "jsonld_event.html",
+			want: []ExtractedArtist{
+				{Name: "Alvvays", Source: "jsonld:MusicEvent.performer"},
+				{Name: "Hannah Jadagu", Source: "jsonld:MusicEvent.performer"},
+				{Name: "Slow Pulp", Source: "jsonld:MusicEvent.performer"},
+			},
+		},
+		{
+			fixture: "jsonld_festival_graph.html",
+			want: []ExtractedArtist{
+				{Name: "Khruangbin", Source: "jsonld:MusicEvent.performer"},
+				{Name: "Big Thief", Source: "jsonld:MusicEvent.performer"},
+				{Name: "khruangbin", Source: "jsonld:MusicEvent.performer"},
+				{Name: "Jungle", Source: "jsonld:MusicGroup"},
+				{Name: "Sampha", Source: "jsonld:MusicAlbum.byArtist"},
+			},
+		},
+		{
+			fixture: "microdata_event.html",
+			want: []ExtractedArtist{
+				{Name: "Yo La Tengo", Source: "microdata:MusicEvent.performer"},
+				{Name: "Cassandra Jenkins", Source: "microdata:MusicEvent.performer"},
+				{Name: "Wednesday", Source: "microdata:MusicGroup"},
+			},
+		},
+		{
+			// A compilation's "Various Artists" names no one
+			fixture: "bandcamp_compilation.html",
+		},
+		{
+			fixture: "festival_lineup.html",
+		},
+	}
+
+	parser := NewGoqueryParser(quietLogger())
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got := parser.ExtractStructuredArtists(parseFixture(t, tt.fixture, "https://example.com/"))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractStructuredArtists() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebScraper_ScrapeArtists_StructuredData(t *testing.T) {
+	page := `<html><head><script type="application/ld+json">
+		{"@context": "https://schema.org", "@type": "MusicEvent", "performer": [{"@type": "MusicGroup", "name": "Alvvays"}, "alvvays", "The"]}
+	</script></head><body>
+		<span class="act">Not Structured</span>
+		<p>Tickets, Alvvays, Doors 7pm</p>
+	</body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	extractor, err := NewSelectorExtractor("venue", []string{"127.0.0.1"}, "", ".act")
+	if err != nil {
+		t.Fatalf("NewSelectorExtractor() unexpected error: %v", err)
+	}
+	logger := quietLogger()
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), nil, nil, logger)
+	scraper.SetExtractors(NewExtractorRegistry(extractor))
+
+	// Structured data is read before the site extractor and the text, and its names
+	// are cleaned like any others
+	got, err := scraper.extractArtists(context.Background(), server.URL, "")
+	if err != nil {
+		t.Fatalf("extractArtists() unexpected error: %v", err)
+	}
+	want := []ExtractedArtist{{Name: "Alvvays", Source: "jsonld:MusicEvent.performer"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractArtists() = %+v, want %+v", got, want)
+	}
+
+	// A CSS selector reads the selected text instead
+	got, err = scraper.extractArtists(context.Background(), server.URL, "span.act")
+	if err != nil {
+		t.Fatalf("extractArtists() unexpected error: %v", err)
+	}
+	want = []ExtractedArtist{{Name: "Not Structured", Source: "text"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractArtists() with a selector = %+v, want %+v", got, want)
+	}
+}
+
+func TestWebScraper_ExtractDocumentArtists_Bandcamp(t *testing.T) {
+	logger := quietLogger()
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), nil, nil, logger)
+	scraper.SetExtractors(DefaultExtractorRegistry())
+
+	tests := []struct {
+		fixture string
+		url     string
+		want    []ExtractedArtist
+	}{
+		{
+			fixture: "bandcamp_album.html",
+			url:     "https://phoebebridgers.bandcamp.com/album/punisher",
+			want:    []ExtractedArtist{{Name: "Phoebe Bridgers", Source: "jsonld:MusicAlbum.byArtist"}},
+		},
+		{
+			// The compilation's JSON-LD only names "Various Artists", so the site
+			// extractor reads the artist of each track instead
+			fixture: "bandcamp_compilation.html",
+			url:     "https://somelabel.bandcamp.com/album/summer-sampler-2024",
+			want: []ExtractedArtist{
+				{Name: "Khruangbin", Source: "extractor:bandcamp"},
+				{Name: "Altin Gün", Source: "extractor:bandcamp"},
+				{Name: "Jungle", Source: "extractor:bandcamp"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := scraper.extractDocumentArtists(context.Background(), parseFixture(t, tt.fixture, tt.url), "")
+			if err != nil {
+				t.Fatalf("extractDocumentArtists() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractDocumentArtists() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
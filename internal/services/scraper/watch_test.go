@@ -0,0 +1,76 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/types"
+)
+
+func TestWebScraper_ScrapeNewArtists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><ul><li>Big Thief</li><li>Nirvana</li></ul></body></html>`))
+	}))
+	defer server.Close()
+
+	searcher := &mockSearcher{candidates: map[string][]types.ArtistCandidate{
+		"Big Thief": {{Artist: types.Artist{ID: "bigthief", Name: "Big Thief"}, NameScore: 1, Confidence: 1}},
+		"Nirvana":   {{Artist: types.Artist{ID: "nirvana", Name: "Nirvana"}, NameScore: 1, Confidence: 1}},
+	}}
+	logger := quietLogger()
+	playlist := &mockPlaylistManager{results: map[string]*types.AddResult{
+		"bigthief": {Success: true, Artist: types.Artist{ID: "bigthief", Name: "Big Thief"}},
+		"nirvana":  {Success: true, Artist: types.Artist{ID: "nirvana", Name: "Nirvana"}},
+	}}
+	scraper := NewWebScraper(DefaultScraperConfig(), NewGoqueryParser(logger), NewPatternArtistExtractor(logger), searcher, playlist, logger)
+	source := types.AddSource{Kind: types.SourceWatch}
+
+	tests := []struct {
+		name          string
+		seen          map[string]bool
+		wantProcessed []string
+	}{
+		{name: "nothing seen", seen: map[string]bool{}, wantProcessed: []string{"Big Thief", "Nirvana"}},
+		{name: "one seen", seen: map[string]bool{"Nirvana": true}, wantProcessed: []string{"Big Thief"}},
+		{name: "all seen", seen: map[string]bool{"Big Thief": true, "Nirvana": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := func(name string) bool { return tt.seen[name] }
+			result, err := scraper.ScrapeNewArtists(context.Background(), server.URL, "li", "playlist1", types.AddModeSkip, types.TrackSelection{}, source, seen, nil)
+			if err != nil {
+				t.Fatalf("ScrapeNewArtists() unexpected error: %v", err)
+			}
+
+			// Every name found is reported, but only the new ones are matched
+			found := make(map[string]bool)
+			for _, name := range result.ArtistsFound {
+				found[name] = true
+			}
+			if !found["Big Thief"] || !found["Nirvana"] || result.SeenCount != len(tt.seen) {
+				t.Errorf("ScrapeNewArtists() found %q with %d seen, want both names with %d seen", result.ArtistsFound, result.SeenCount, len(tt.seen))
+			}
+			processed := make(map[string]bool)
+			for _, match := range result.MatchResults {
+				if match.Matched {
+					processed[match.Query] = true
+				}
+			}
+			if len(processed) != len(tt.wantProcessed) {
+				t.Errorf("ScrapeNewArtists() matched %v, want %v", processed, tt.wantProcessed)
+			}
+			for _, name := range tt.wantProcessed {
+				if !processed[name] {
+					t.Errorf("ScrapeNewArtists() did not match new artist %s", name)
+				}
+			}
+		})
+	}
+
+	if _, err := scraper.ScrapeNewArtists(context.Background(), server.URL, "li", "playlist1", types.AddModeSkip, types.TrackSelection{}, source, nil, nil); err == nil {
+		t.Error("ScrapeNewArtists() without a seen check expected error but got none")
+	}
+}
@@ -1,129 +1,214 @@
 package search
 
 import (
+	"cmp"
+	"context"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 
-	"github.com/sahilm/fuzzy"
 	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/parallel"
 	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/logging"
 )
 
 // FuzzyArtistSearcher implements fuzzy matching for artist search
 type FuzzyArtistSearcher struct {
-	spotify types.SpotifyService
-	logger  *logrus.Logger
+	spotify     types.SpotifyService
+	logger      *logging.Logger
+	concurrency int
+	weights     Weights
+	aliases     types.AliasResolver
 }
 
 // NewFuzzyArtistSearcher creates a new fuzzy artist searcher
 func NewFuzzyArtistSearcher(spotifyService types.SpotifyService, logger *logrus.Logger) *FuzzyArtistSearcher {
 	return &FuzzyArtistSearcher{
-		spotify: spotifyService,
-		logger:  logger,
+		spotify:     spotifyService,
+		logger:      logging.Wrap(logger),
+		concurrency: types.DefaultSearchConcurrency,
+		weights:     DefaultWeights(),
 	}
 }
 
-// FindBestMatch searches for an artist and returns the best fuzzy match with confidence score
-func (f *FuzzyArtistSearcher) FindBestMatch(query string) (*types.Artist, float64, error) {
-	if strings.TrimSpace(query) == "" {
-		return nil, 0.0, fmt.Errorf("search query cannot be empty")
-	}
+// SetConcurrency sets how many searches SearchMultipleArtists runs at the same time,
+// clamped to between 1 and types.MaxSearchConcurrency
+func (f *FuzzyArtistSearcher) SetConcurrency(n int) {
+	f.concurrency = types.ClampSearchConcurrency(n)
+}
 
-	f.logger.WithField("query", query).Debug("Starting fuzzy artist search")
+// SetWeights sets the weights of the name scoring model
+func (f *FuzzyArtistSearcher) SetWeights(weights Weights) {
+	f.weights = weights
+}
 
-	// First, try direct search through Spotify
-	artist, err := f.spotify.SearchArtist(query)
+// SetAliases sets the aliases consulted before searching, so aliased names always
+// resolve to the artist they stand for
+func (f *FuzzyArtistSearcher) SetAliases(aliases types.AliasResolver) {
+	f.aliases = aliases
+}
+
+// FindBestMatch searches for an artist and returns the best fuzzy match with confidence score
+func (f *FuzzyArtistSearcher) FindBestMatch(ctx context.Context, query string) (*types.Artist, float64, error) {
+	candidates, err := f.FindCandidates(ctx, query, types.DefaultCandidateLimit)
 	if err != nil {
-		f.logger.WithError(err).WithField("query", query).Error("Failed to search for artist")
-		return nil, 0.0, fmt.Errorf("failed to search for artist: %w", err)
+		return nil, 0.0, err
 	}
 
-	// Calculate fuzzy match confidence score
-	confidence := f.calculateMatchConfidence(query, artist.Name)
-
-	f.logger.WithFields(logrus.Fields{
+	best := candidates[0]
+	f.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"query":       query,
-		"artist_name": artist.Name,
-		"confidence":  confidence,
+		"artist_name": best.Artist.Name,
+		"confidence":  best.Confidence,
+		"candidates":  len(candidates),
 	}).Info("Found artist match")
 
-	return artist, confidence, nil
+	return &best.Artist, best.Confidence, nil
 }
 
-// calculateMatchConfidence calculates a confidence score between 0.0 and 1.0
-// for how well the found artist matches the search query
-func (f *FuzzyArtistSearcher) calculateMatchConfidence(query, artistName string) float64 {
-	// Normalize strings for comparison
-	normalizedQuery := strings.ToLower(strings.TrimSpace(query))
-	normalizedArtist := strings.ToLower(strings.TrimSpace(artistName))
+// FindCandidates searches for an artist and returns up to limit candidates, most
+// confident first. A candidate's confidence is its name score, reduced by up to
+// the prominence weight when it is less prominent than the other candidates, so of
+// two artists sharing a name the one with more listeners comes first. A query
+// with an alias has the aliased artist as its only, fully confident, candidate.
+func (f *FuzzyArtistSearcher) FindCandidates(ctx context.Context, query string, limit int) ([]types.ArtistCandidate, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
 
-	// Exact match gets perfect score
-	if normalizedQuery == normalizedArtist {
-		return 1.0
+	if f.aliases != nil {
+		if alias, ok := f.aliases.Resolve(query); ok {
+			return f.aliasCandidate(ctx, query, alias)
+		}
 	}
 
-	// Check if query is contained in artist name
-	if strings.Contains(normalizedArtist, normalizedQuery) {
-		// Calculate ratio based on length difference
-		ratio := float64(len(normalizedQuery)) / float64(len(normalizedArtist))
-		return 0.8 + (ratio * 0.2) // Score between 0.8 and 1.0
+	f.logger.WithContext(ctx).WithField("query", query).Debug("Starting fuzzy artist search")
+
+	artists, err := f.spotify.SearchArtists(ctx, query, limit)
+	if err != nil {
+		f.logger.WithContext(ctx).WithError(err).WithField("query", query).Error("Failed to search for artist")
+		return nil, fmt.Errorf("failed to search for artist: %w", err)
 	}
+	if len(artists) == 0 {
+		return nil, fmt.Errorf("no artists found for query: %s", query)
+	}
+
+	candidates := f.scoreCandidates(query, artists)
+
+	f.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"query":      query,
+		"candidates": len(candidates),
+		"best":       candidates[0].Artist.Name,
+		"confidence": candidates[0].Confidence,
+	}).Debug("Scored artist candidates")
+
+	return candidates, nil
+}
 
-	// Check if artist name is contained in query
-	if strings.Contains(normalizedQuery, normalizedArtist) {
-		ratio := float64(len(normalizedArtist)) / float64(len(normalizedQuery))
-		return 0.7 + (ratio * 0.2) // Score between 0.7 and 0.9
+// aliasCandidate returns the artist an alias stands for as the only candidate for a query
+func (f *FuzzyArtistSearcher) aliasCandidate(ctx context.Context, query string, alias types.ArtistAlias) ([]types.ArtistCandidate, error) {
+	artist, err := f.spotify.GetArtist(ctx, alias.ArtistID)
+	if err != nil {
+		f.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"query":     query,
+			"artist_id": alias.ArtistID,
+		}).Error("Failed to get aliased artist")
+		return nil, fmt.Errorf("failed to get artist aliased to %s: %w", alias.Name, err)
+	}
+
+	f.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"query":     query,
+		"artist_id": artist.ID,
+		"artist":    artist.Name,
+	}).Debug("Resolved artist from alias")
+
+	return []types.ArtistCandidate{{Artist: *artist, NameScore: 1.0, Confidence: 1.0, Alias: true}}, nil
+}
+
+// scoreCandidates scores every artist against the query and sorts them by confidence,
+// keeping Spotify's order between equally confident candidates
+func (f *FuzzyArtistSearcher) scoreCandidates(query string, artists []types.Artist) []types.ArtistCandidate {
+	prominences := make([]float64, len(artists))
+	var mostProminent float64
+	for i, artist := range artists {
+		prominences[i] = prominence(artist)
+		mostProminent = max(mostProminent, prominences[i])
 	}
 
-	// Use fuzzy matching for more complex cases
-	matches := fuzzy.Find(normalizedQuery, []string{normalizedArtist})
-	if len(matches) > 0 {
-		// Convert fuzzy score to confidence (fuzzy scores are typically 0-100+)
-		// We'll normalize to 0.0-0.7 range for fuzzy matches
-		fuzzyScore := float64(matches[0].Score)
-		maxExpectedScore := float64(len(normalizedQuery) * 2) // Rough estimate
-		confidence := (fuzzyScore / maxExpectedScore) * 0.7
-
-		// Cap at 0.7 and ensure minimum of 0.1 for any match
-		if confidence > 0.7 {
-			confidence = 0.7
+	candidates := make([]types.ArtistCandidate, len(artists))
+	for i, artist := range artists {
+		relative := 1.0
+		if mostProminent > 0 {
+			relative = prominences[i] / mostProminent
 		}
-		if confidence < 0.1 {
-			confidence = 0.1
+		nameScore := f.calculateMatchConfidence(query, artist.Name)
+		candidates[i] = types.ArtistCandidate{
+			Artist:     artist,
+			NameScore:  nameScore,
+			Confidence: nameScore * (1 - f.weights.Prominence*(1-relative)),
 		}
+	}
 
-		return confidence
+	slices.SortStableFunc(candidates, func(a, b types.ArtistCandidate) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+	return candidates
+}
+
+// prominence rates how established an artist is from 0.0 to 1.0, from their
+// popularity, follower count and whether Spotify has classified them into genres
+func prominence(artist types.Artist) float64 {
+	popularity := float64(min(max(artist.Popularity, 0), 100)) / 100
+	// Ten million followers or more count fully
+	followers := min(math.Log10(float64(max(artist.Followers, 0))+1)/7, 1)
+	var genres float64
+	if len(artist.Genres) > 0 {
+		genres = 1
 	}
+	return 0.5*popularity + 0.35*followers + 0.15*genres
+}
 
-	// If no fuzzy match found, return low confidence
-	return 0.1
+// calculateMatchConfidence calculates a confidence score between 0.0 and 1.0
+// for how well the found artist matches the search query
+func (f *FuzzyArtistSearcher) calculateMatchConfidence(query, artistName string) float64 {
+	return scoreName(query, artistName, f.weights)
 }
 
-// SearchMultipleArtists searches for multiple artists and returns them with confidence scores
-func (f *FuzzyArtistSearcher) SearchMultipleArtists(queries []string) ([]ArtistMatch, error) {
+// SearchMultipleArtists searches for multiple artists and returns them with confidence
+// scores, in query order. Queries are searched concurrently; those that fail are left out.
+func (f *FuzzyArtistSearcher) SearchMultipleArtists(ctx context.Context, queries []string) ([]ArtistMatch, error) {
 	if len(queries) == 0 {
 		return []ArtistMatch{}, nil
 	}
 
-	f.logger.WithField("query_count", len(queries)).Debug("Searching for multiple artists")
-
-	results := make([]ArtistMatch, 0, len(queries))
+	f.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"query_count": len(queries),
+		"concurrency": f.concurrency,
+	}).Debug("Searching for multiple artists")
 
-	for _, query := range queries {
-		artist, confidence, err := f.FindBestMatch(query)
+	matches := parallel.Map(queries, f.concurrency, func(query string) *ArtistMatch {
+		artist, confidence, err := f.FindBestMatch(ctx, query)
 		if err != nil {
-			f.logger.WithError(err).WithField("query", query).Warn("Failed to find artist match")
-			continue
+			f.logger.WithContext(ctx).WithError(err).WithField("query", query).Warn("Failed to find artist match")
+			return nil
 		}
-
-		results = append(results, ArtistMatch{
+		return &ArtistMatch{
 			Artist:     artist,
 			Query:      query,
 			Confidence: confidence,
-		})
+		}
+	})
+
+	results := make([]ArtistMatch, 0, len(queries))
+	for _, match := range matches {
+		if match != nil {
+			results = append(results, *match)
+		}
 	}
 
-	f.logger.WithFields(logrus.Fields{
+	f.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"queries_processed": len(queries),
 		"matches_found":     len(results),
 	}).Info("Completed multiple artist search")
@@ -1,42 +1,72 @@
 package search
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/toozej/go-listen/internal/server"
+	"github.com/toozej/go-listen/internal/types"
 )
 
 // MockSpotifyService implements SpotifyService for testing
 type MockSpotifyService struct {
-	searchArtistFunc func(query string) (*server.Artist, error)
+	searchArtistFunc  func(query string) (*server.Artist, error)
+	searchArtistsFunc func(query string, limit int) ([]server.Artist, error)
+	getArtistFunc     func(artistID string) (*server.Artist, error)
 }
 
-func (m *MockSpotifyService) SearchArtist(query string) (*server.Artist, error) {
+func (m *MockSpotifyService) SearchArtist(ctx context.Context, query string) (*server.Artist, error) {
 	if m.searchArtistFunc != nil {
 		return m.searchArtistFunc(query)
 	}
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockSpotifyService) GetArtistTopTracks(artistID string) ([]server.Track, error) {
+func (m *MockSpotifyService) SearchArtists(ctx context.Context, query string, limit int) ([]server.Artist, error) {
+	if m.searchArtistsFunc != nil {
+		return m.searchArtistsFunc(query, limit)
+	}
+	artist, err := m.SearchArtist(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return []server.Artist{*artist}, nil
+}
+
+func (m *MockSpotifyService) GetArtist(ctx context.Context, artistID string) (*server.Artist, error) {
+	if m.getArtistFunc != nil {
+		return m.getArtistFunc(artistID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockSpotifyService) GetArtistTracks(ctx context.Context, artistID string, selection server.TrackSelection) ([]server.Track, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockSpotifyService) GetUserPlaylists(folderName string) ([]server.Playlist, error) {
+func (m *MockSpotifyService) GetUserPlaylists(ctx context.Context) ([]server.Playlist, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockSpotifyService) AddTracksToPlaylist(playlistID string, trackIDs []string) error {
+func (m *MockSpotifyService) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
 	return errors.New("not implemented")
 }
 
-func (m *MockSpotifyService) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]bool, error) {
+func (m *MockSpotifyService) CheckTracksInPlaylist(ctx context.Context, playlistID string, trackIDs []string) ([]bool, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockSpotifyService) GetPlaylistTracks(ctx context.Context, playlistID string) ([]server.Track, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockSpotifyService) GetAuthURL() string {
+func (m *MockSpotifyService) GetAuthURL(state, codeVerifier string) string {
 	return "mock-auth-url"
 }
 
@@ -44,10 +74,14 @@ func (m *MockSpotifyService) IsAuthenticated() bool {
 	return true
 }
 
-func (m *MockSpotifyService) CompleteAuth(code, state string) error {
+func (m *MockSpotifyService) CompleteAuth(ctx context.Context, code, codeVerifier string) error {
 	return nil
 }
 
+func (m *MockSpotifyService) CurrentUserID(ctx context.Context) (string, error) {
+	return "test-user", nil
+}
+
 func TestNewFuzzyArtistSearcher(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
@@ -65,7 +99,7 @@ func TestNewFuzzyArtistSearcher(t *testing.T) {
 		t.Error("NewFuzzyArtistSearcher() did not set spotify service correctly")
 	}
 
-	if searcher.logger != logger {
+	if searcher.logger.Logger != logger {
 		t.Error("NewFuzzyArtistSearcher() did not set logger correctly")
 	}
 }
@@ -130,8 +164,8 @@ func TestFuzzyArtistSearcher_FindBestMatch(t *testing.T) {
 				Name: "Taylor Swift",
 				URI:  "spotify:artist:06HL4z0CvFAxyc27GXpf02",
 			},
-			minConfidence: 0.8,
-			maxConfidence: 1.0,
+			minConfidence: 0.4,
+			maxConfidence: 0.6,
 		},
 		{
 			name:  "artist name contained in query",
@@ -141,8 +175,8 @@ func TestFuzzyArtistSearcher_FindBestMatch(t *testing.T) {
 				Name: "Taylor Swift",
 				URI:  "spotify:artist:06HL4z0CvFAxyc27GXpf02",
 			},
-			minConfidence: 0.7,
-			maxConfidence: 0.9,
+			minConfidence: 0.6,
+			maxConfidence: 0.8,
 		},
 		{
 			name:  "fuzzy match",
@@ -152,8 +186,8 @@ func TestFuzzyArtistSearcher_FindBestMatch(t *testing.T) {
 				Name: "Taylor Swift",
 				URI:  "spotify:artist:06HL4z0CvFAxyc27GXpf02",
 			},
-			minConfidence: 0.1,
-			maxConfidence: 0.7,
+			minConfidence: 0.8,
+			maxConfidence: 1.0,
 		},
 		{
 			name:  "poor match",
@@ -163,8 +197,8 @@ func TestFuzzyArtistSearcher_FindBestMatch(t *testing.T) {
 				Name: "Taylor Swift",
 				URI:  "spotify:artist:06HL4z0CvFAxyc27GXpf02",
 			},
-			minConfidence: 0.1,
-			maxConfidence: 0.7,
+			minConfidence: 0.0,
+			maxConfidence: 0.2,
 		},
 	}
 
@@ -180,7 +214,7 @@ func TestFuzzyArtistSearcher_FindBestMatch(t *testing.T) {
 			}
 
 			searcher := NewFuzzyArtistSearcher(mockSpotify, logger)
-			artist, confidence, err := searcher.FindBestMatch(tt.query)
+			artist, confidence, err := searcher.FindBestMatch(context.Background(), tt.query)
 
 			if tt.wantErr {
 				if err == nil {
@@ -216,6 +250,114 @@ func TestFuzzyArtistSearcher_FindBestMatch(t *testing.T) {
 	}
 }
 
+func TestFuzzyArtistSearcher_FindCandidates(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	mockSpotify := &MockSpotifyService{
+		searchArtistsFunc: func(query string, limit int) ([]server.Artist, error) {
+			if limit != 3 {
+				t.Errorf("SearchArtists() limit = %d, want 3", limit)
+			}
+			return []server.Artist{
+				{ID: "obscure", Name: "Low", Followers: 120, Popularity: 4},
+				{ID: "famous", Name: "Low", Genres: []string{"slowcore"}, Followers: 400000, Popularity: 55},
+				{ID: "other", Name: "Lower Than Low", Followers: 90000, Popularity: 40},
+			}, nil
+		},
+	}
+
+	searcher := NewFuzzyArtistSearcher(mockSpotify, logger)
+	candidates, err := searcher.FindCandidates(context.Background(), "Low", 3)
+	if err != nil {
+		t.Fatalf("FindCandidates() unexpected error: %v", err)
+	}
+	if len(candidates) != 3 {
+		t.Fatalf("FindCandidates() returned %d candidates, want 3", len(candidates))
+	}
+
+	if candidates[0].Artist.ID != "famous" {
+		t.Errorf("FindCandidates() best candidate = %s, want famous", candidates[0].Artist.ID)
+	}
+	if candidates[0].Confidence != 1.0 {
+		t.Errorf("FindCandidates() best confidence = %v, want 1.0", candidates[0].Confidence)
+	}
+	for i := 1; i < len(candidates); i++ {
+		if candidates[i].Confidence > candidates[i-1].Confidence {
+			t.Errorf("FindCandidates() candidates not sorted by confidence: %v", candidates)
+		}
+		if candidates[i].Confidence > candidates[i].NameScore {
+			t.Errorf("FindCandidates() confidence %v exceeds name score %v", candidates[i].Confidence, candidates[i].NameScore)
+		}
+	}
+
+	obscure := candidates[slices.IndexFunc(candidates, func(c types.ArtistCandidate) bool { return c.Artist.ID == "obscure" })]
+	if obscure.NameScore != 1.0 || obscure.Confidence >= 1.0-DefaultWeights().Prominence/2 {
+		t.Errorf("FindCandidates() obscure namesake scored %+v, want an exact name with reduced confidence", obscure)
+	}
+
+	if _, err := searcher.FindCandidates(context.Background(), "  ", 3); err == nil {
+		t.Error("FindCandidates() expected error for empty query")
+	}
+}
+
+// mockAliasResolver resolves names through a map keyed by the exact name
+type mockAliasResolver map[string]types.ArtistAlias
+
+func (m mockAliasResolver) Resolve(name string) (types.ArtistAlias, bool) {
+	alias, ok := m[name]
+	return alias, ok
+}
+
+func TestFuzzyArtistSearcher_Aliases(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	var searched []string
+	mockSpotify := &MockSpotifyService{
+		searchArtistsFunc: func(query string, limit int) ([]server.Artist, error) {
+			searched = append(searched, query)
+			return []server.Artist{{ID: "searched", Name: query}}, nil
+		},
+		getArtistFunc: func(artistID string) (*server.Artist, error) {
+			if artistID == "missing" {
+				return nil, errors.New("artist not found")
+			}
+			return &server.Artist{ID: artistID, Name: "Ye"}, nil
+		},
+	}
+
+	searcher := NewFuzzyArtistSearcher(mockSpotify, logger)
+	searcher.SetAliases(mockAliasResolver{
+		"Kanye":  {Name: "Kanye", ArtistID: "5K4W6rqBFWDnAN6FQUkS6x"},
+		"Broken": {Name: "Broken", ArtistID: "missing"},
+	})
+
+	candidates, err := searcher.FindCandidates(context.Background(), "Kanye", 5)
+	if err != nil {
+		t.Fatalf("FindCandidates() unexpected error: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Artist.ID != "5K4W6rqBFWDnAN6FQUkS6x" || !candidates[0].Alias || candidates[0].Confidence != 1.0 {
+		t.Errorf("FindCandidates() = %+v, want only the aliased artist with full confidence", candidates)
+	}
+
+	artist, confidence, err := searcher.FindBestMatch(context.Background(), "Kanye")
+	if err != nil || artist.ID != "5K4W6rqBFWDnAN6FQUkS6x" || confidence != 1.0 {
+		t.Errorf("FindBestMatch() = %v, %v, %v, want the aliased artist with confidence 1.0", artist, confidence, err)
+	}
+
+	if _, err := searcher.FindCandidates(context.Background(), "Broken", 5); err == nil {
+		t.Error("FindCandidates() expected error for an alias of a missing artist")
+	}
+
+	if _, err := searcher.FindCandidates(context.Background(), "Radiohead", 5); err != nil {
+		t.Fatalf("FindCandidates() unexpected error: %v", err)
+	}
+	if !slices.Equal(searched, []string{"Radiohead"}) {
+		t.Errorf("searched for %v, want only the name without an alias", searched)
+	}
+}
+
 func TestFuzzyArtistSearcher_calculateMatchConfidence(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
@@ -247,22 +389,22 @@ func TestFuzzyArtistSearcher_calculateMatchConfidence(t *testing.T) {
 			name:          "query contained in artist",
 			query:         "Taylor",
 			artistName:    "Taylor Swift",
-			minConfidence: 0.8,
-			maxConfidence: 1.0,
+			minConfidence: 0.4,
+			maxConfidence: 0.6,
 		},
 		{
 			name:          "artist contained in query",
 			query:         "Taylor Swift songs",
 			artistName:    "Taylor Swift",
-			minConfidence: 0.7,
-			maxConfidence: 0.9,
+			minConfidence: 0.6,
+			maxConfidence: 0.8,
 		},
 		{
 			name:          "fuzzy match",
 			query:         "Tylor",
 			artistName:    "Taylor",
-			minConfidence: 0.1,
-			maxConfidence: 0.7,
+			minConfidence: 0.8,
+			maxConfidence: 1.0,
 		},
 	}
 
@@ -349,7 +491,7 @@ func TestFuzzyArtistSearcher_SearchMultipleArtists(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := searcher.SearchMultipleArtists(tt.queries)
+			results, err := searcher.SearchMultipleArtists(context.Background(), tt.queries)
 
 			if tt.wantErr {
 				if err == nil {
@@ -383,6 +525,96 @@ func TestFuzzyArtistSearcher_SearchMultipleArtists(t *testing.T) {
 	}
 }
 
+// slowSpotifyService returns a mock whose searches take latency each, simulating
+// Spotify's response time, and fail for names starting with "Unknown"
+func slowSpotifyService(latency time.Duration) *MockSpotifyService {
+	return &MockSpotifyService{
+		searchArtistFunc: func(query string) (*server.Artist, error) {
+			time.Sleep(latency)
+			if strings.HasPrefix(query, "Unknown") {
+				return nil, errors.New("artist not found")
+			}
+			return &server.Artist{ID: "id-" + query, Name: query}, nil
+		},
+	}
+}
+
+func TestFuzzyArtistSearcher_SearchMultipleArtists_Concurrent(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	queries := []string{"Artist 1", "Unknown 1", "Artist 2", "Artist 3", "Unknown 2", "Artist 4", "Artist 5"}
+	want := []string{"Artist 1", "Artist 2", "Artist 3", "Artist 4", "Artist 5"}
+
+	for _, concurrency := range []int{1, 3, types.MaxSearchConcurrency} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			searcher := NewFuzzyArtistSearcher(slowSpotifyService(time.Millisecond), logger)
+			searcher.SetConcurrency(concurrency)
+
+			results, err := searcher.SearchMultipleArtists(context.Background(), queries)
+			if err != nil {
+				t.Fatalf("SearchMultipleArtists() unexpected error = %v", err)
+			}
+
+			got := make([]string, len(results))
+			for i, result := range results {
+				got[i] = result.Query
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("SearchMultipleArtists() returned %v, want %v in query order", got, want)
+			}
+		})
+	}
+}
+
+func TestFuzzyArtistSearcher_SetConcurrency(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{
+		{name: "within range", n: 8, want: 8},
+		{name: "zero", n: 0, want: 1},
+		{name: "negative", n: -3, want: 1},
+		{name: "above maximum", n: 100, want: types.MaxSearchConcurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := NewFuzzyArtistSearcher(&MockSpotifyService{}, logrus.New())
+			searcher.SetConcurrency(tt.n)
+			if searcher.concurrency != tt.want {
+				t.Errorf("concurrency = %d, want %d", searcher.concurrency, tt.want)
+			}
+		})
+	}
+}
+
+// BenchmarkSearchMultipleArtists searches a 150 artist lineup against a mock with
+// 2ms of latency per search, to compare sequential and concurrent matching
+func BenchmarkSearchMultipleArtists(b *testing.B) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	queries := make([]string, 150)
+	for i := range queries {
+		queries[i] = fmt.Sprintf("Artist %d", i)
+	}
+
+	for _, concurrency := range []int{1, 4, types.MaxSearchConcurrency} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			searcher := NewFuzzyArtistSearcher(slowSpotifyService(2*time.Millisecond), logger)
+			searcher.SetConcurrency(concurrency)
+
+			for b.Loop() {
+				if _, err := searcher.SearchMultipleArtists(context.Background(), queries); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func TestArtistMatch_IsHighConfidence(t *testing.T) {
 	tests := []struct {
 		name       string
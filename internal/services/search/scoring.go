@@ -0,0 +1,342 @@
+package search
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+// Weights sets how much each metric contributes to a name score, and how much
+// unmatched words and prominence count against it. The metric weights are
+// relative: only their proportions matter.
+type Weights struct {
+	// JaroWinkler rewards names spelled alike, especially from the start, so it
+	// forgives typos
+	JaroWinkler float64
+	// TokenSet compares the names' words regardless of their order, and rates a
+	// name whose words are all in the other as a full match
+	TokenSet float64
+	// Levenshtein is the share of characters that needn't be edited to turn one
+	// name into the other, so it penalizes any difference in length
+	Levenshtein float64
+	// UnmatchedWords is the most the score is reduced when no word of either name
+	// has a close counterpart in the other, from 0.0 to 1.0. "Big Thief" and
+	// "Thief" share one of their three words.
+	UnmatchedWords float64
+	// Prominence is the most a candidate's confidence is reduced for being less
+	// prominent than the most prominent candidate, from 0.0 to 1.0
+	Prominence float64
+}
+
+// DefaultWeights returns the weights tuned against the evaluation set in testdata
+func DefaultWeights() Weights {
+	return Weights{
+		JaroWinkler:    0.3,
+		TokenSet:       0.2,
+		Levenshtein:    0.5,
+		UnmatchedWords: 0.8,
+		Prominence:     0.3,
+	}
+}
+
+// WeightsFromConfig returns the weights set in the configuration. Negative
+// weights count as zero, the penalties are capped at 1.0, and the default
+// metric weights are used if all of them are zero.
+func WeightsFromConfig(cfg config.SearchConfig) Weights {
+	weights := Weights{
+		JaroWinkler:    max(cfg.JaroWinklerWeight, 0),
+		TokenSet:       max(cfg.TokenSetWeight, 0),
+		Levenshtein:    max(cfg.LevenshteinWeight, 0),
+		UnmatchedWords: min(max(cfg.UnmatchedWordWeight, 0), 1),
+		Prominence:     min(max(cfg.ProminenceWeight, 0), 1),
+	}
+	if weights.JaroWinkler+weights.TokenSet+weights.Levenshtein == 0 {
+		defaults := DefaultWeights()
+		weights.JaroWinkler, weights.TokenSet, weights.Levenshtein = defaults.JaroWinkler, defaults.TokenSet, defaults.Levenshtein
+	}
+	return weights
+}
+
+// wordMatchThreshold is the Jaro-Winkler similarity above which two words count
+// as the same word misspelled
+const wordMatchThreshold = 0.8
+
+// scoreName rates from 0.0 to 1.0 how well an artist's name matches a query: the
+// weighted mean of the metrics, reduced for words only one of the names has.
+// Names that are the same once normalized, ignoring spaces, score 1.0.
+func scoreName(query, name string, weights Weights) float64 {
+	normalizedQuery, normalizedName := normalizeName(query), normalizeName(name)
+	// Names made only of symbols normalize to nothing; compare them as they are
+	if normalizedQuery == "" || normalizedName == "" {
+		normalizedQuery = strings.ToLower(strings.TrimSpace(query))
+		normalizedName = strings.ToLower(strings.TrimSpace(name))
+	}
+	if strings.ReplaceAll(normalizedQuery, " ", "") == strings.ReplaceAll(normalizedName, " ", "") {
+		return 1.0
+	}
+
+	total := weights.JaroWinkler + weights.TokenSet + weights.Levenshtein
+	if total <= 0 {
+		return 0
+	}
+
+	queryRunes, nameRunes := []rune(normalizedQuery), []rune(normalizedName)
+	score := (weights.JaroWinkler*jaroWinkler(queryRunes, nameRunes) +
+		weights.TokenSet*tokenSetRatio(normalizedQuery, normalizedName) +
+		weights.Levenshtein*levenshteinSimilarity(queryRunes, nameRunes)) / total
+	score *= 1 - weights.UnmatchedWords*(1-wordCoverage(normalizedQuery, normalizedName))
+	return min(score, 1.0)
+}
+
+// featuringCredit matches a featured artist credit and everything after it
+var featuringCredit = regexp.MustCompile(`[\s(\[]+(feat|ft|featuring)(\.|\s).*$`)
+
+// leadingArticles are dropped from the start of a name, so "The National" matches "National"
+var leadingArticles = []string{"the ", "a ", "an "}
+
+// symbolWords spell out symbols used in place of letters or words
+var symbolWords = map[rune]string{
+	'$': "s",
+	'&': " and ",
+	'+': " and ",
+	'@': "a",
+}
+
+// normalizeName lowercases a name and strips what shouldn't affect matching:
+// featured artist credits, diacritics, punctuation, a leading article and extra
+// whitespace. "The Beatles (feat. Billy Preston)" becomes "beatles" and "A$AP
+// Rocky" becomes "asap rocky".
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = featuringCredit.ReplaceAllString(name, "")
+
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == '\'', r == '’', r == '.':
+			// Combining marks, apostrophes and periods join what's around them: "guns n' roses", "r.e.m."
+		case r == '!' && i > 0 && i < len(runes)-1 && unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1]):
+			// An exclamation mark inside a word stands for an i: "p!nk"
+			b.WriteByte('i')
+		case symbolWords[r] != "":
+			b.WriteString(symbolWords[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if folded, ok := foldedLetters[r]; ok {
+				b.WriteString(folded)
+			} else {
+				b.WriteRune(r)
+			}
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	normalized := strings.Join(strings.Fields(b.String()), " ")
+	for _, article := range leadingArticles {
+		if rest, ok := strings.CutPrefix(normalized, article); ok && rest != "" {
+			return rest
+		}
+	}
+	return normalized
+}
+
+// foldedLetters maps lowercase letters with diacritics, and ligatures, to plain Latin letters
+var foldedLetters = func() map[rune]string {
+	groups := map[string]string{
+		"a":  "àáâãäåāăą",
+		"c":  "çćĉċč",
+		"d":  "ďđð",
+		"e":  "èéêëēĕėęě",
+		"g":  "ĝğġģ",
+		"h":  "ĥħ",
+		"i":  "ìíîïĩīĭįı",
+		"j":  "ĵ",
+		"k":  "ķ",
+		"l":  "ĺļľŀł",
+		"n":  "ñńņňŉ",
+		"o":  "òóôõöøōŏő",
+		"r":  "ŕŗř",
+		"s":  "śŝşšș",
+		"t":  "ţťŧț",
+		"u":  "ùúûüũūŭůűų",
+		"w":  "ŵ",
+		"y":  "ýÿŷ",
+		"z":  "źżž",
+		"ae": "æ",
+		"oe": "œ",
+		"ss": "ß",
+		"th": "þ",
+	}
+
+	folded := make(map[rune]string)
+	for plain, letters := range groups {
+		for _, r := range letters {
+			folded[r] = plain
+		}
+	}
+	return folded
+}()
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings, from 0.0 to 1.0
+func jaroWinkler(a, b []rune) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	window := max(max(len(a), len(b))/2-1, 0)
+	aMatched := make([]bool, len(a))
+	bMatched := make([]bool, len(b))
+	matches := 0
+	for i, r := range a {
+		for j := max(i-window, 0); j < min(i+window+1, len(b)); j++ {
+			if !bMatched[j] && b[j] == r {
+				aMatched[i], bMatched[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// Count matched characters that appear in a different order
+	transpositions, j := 0, 0
+	for i := range a {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions/2))/m) / 3
+
+	// Boost strings sharing a prefix of up to four characters
+	prefix := 0
+	for prefix < min(len(a), len(b), 4) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// tokenSetRatio compares the words of two strings regardless of their order. The
+// shared words are compared with each string's full set of words, so a string
+// whose words are all in the other scores 1.0.
+func tokenSetRatio(a, b string) float64 {
+	aTokens, bTokens := uniqueTokens(a), uniqueTokens(b)
+
+	var shared, onlyA, onlyB []string
+	for _, token := range aTokens {
+		if _, found := slices.BinarySearch(bTokens, token); found {
+			shared = append(shared, token)
+		} else {
+			onlyA = append(onlyA, token)
+		}
+	}
+	for _, token := range bTokens {
+		if _, found := slices.BinarySearch(aTokens, token); !found {
+			onlyB = append(onlyB, token)
+		}
+	}
+
+	sharedText := strings.Join(shared, " ")
+	withA := strings.TrimSpace(sharedText + " " + strings.Join(onlyA, " "))
+	withB := strings.TrimSpace(sharedText + " " + strings.Join(onlyB, " "))
+
+	best := ratio([]rune(withA), []rune(withB))
+	if sharedText != "" {
+		best = max(best, ratio([]rune(sharedText), []rune(withA)), ratio([]rune(sharedText), []rune(withB)))
+	}
+	return best
+}
+
+// wordCoverage returns the share of the words of both strings that have a close
+// counterpart in the other, each counted by how close it is
+func wordCoverage(a, b string) float64 {
+	aWords, bWords := strings.Fields(a), strings.Fields(b)
+	if len(aWords)+len(bWords) == 0 {
+		return 1
+	}
+
+	var covered float64
+	for _, pair := range [][2][]string{{aWords, bWords}, {bWords, aWords}} {
+		for _, word := range pair[0] {
+			var best float64
+			for _, other := range pair[1] {
+				best = max(best, jaroWinkler([]rune(word), []rune(other)))
+			}
+			if best >= wordMatchThreshold {
+				covered += best
+			}
+		}
+	}
+	return covered / float64(len(aWords)+len(bWords))
+}
+
+// uniqueTokens returns the sorted, distinct words of a string
+func uniqueTokens(s string) []string {
+	tokens := strings.Fields(s)
+	slices.Sort(tokens)
+	return slices.Compact(tokens)
+}
+
+// levenshteinSimilarity returns one minus the edit distance between two strings
+// over the length of the longer one, from 0.0 to 1.0
+func levenshteinSimilarity(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	// Dynamic programming over two rows of the edit distance table
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range a {
+		curr[0] = i + 1
+		for j := range b {
+			substitution := prev[j]
+			if a[i] != b[j] {
+				substitution++
+			}
+			curr[j+1] = min(substitution, prev[j+1]+1, curr[j]+1)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(b)])/float64(max(len(a), len(b)))
+}
+
+// ratio returns how similar two strings are from 0.0 to 1.0: twice the length of
+// their longest common subsequence over their combined length
+func ratio(a, b []rune) float64 {
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+
+	// Dynamic programming over two rows of the longest common subsequence table
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				curr[j+1] = prev[j] + 1
+			} else {
+				curr[j+1] = max(prev[j+1], curr[j])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return 2 * float64(prev[len(b)]) / float64(len(a)+len(b))
+}
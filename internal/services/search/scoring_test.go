@@ -0,0 +1,242 @@
+package search
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+)
+
+// evalPair is a labelled query and artist name from testdata/name_eval.json
+type evalPair struct {
+	Query string `json:"query"`
+	Name  string `json:"name"`
+	Match bool   `json:"match"`
+	Note  string `json:"note"`
+}
+
+// TestScoreName_EvaluationSet reports how well the default weights separate the
+// labelled matches from the non-matches at the candidate confidence threshold
+func TestScoreName_EvaluationSet(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "name_eval.json"))
+	if err != nil {
+		t.Fatalf("failed to read evaluation set: %v", err)
+	}
+	var pairs []evalPair
+	if err := json.Unmarshal(raw, &pairs); err != nil {
+		t.Fatalf("failed to parse evaluation set: %v", err)
+	}
+
+	weights := DefaultWeights()
+	var truePositives, falsePositives, falseNegatives int
+	for _, pair := range pairs {
+		score := scoreName(pair.Query, pair.Name, weights)
+		predicted := score >= types.MinCandidateConfidence
+		switch {
+		case predicted && pair.Match:
+			truePositives++
+		case predicted && !pair.Match:
+			falsePositives++
+			t.Logf("false positive: %q vs %q (%s) scored %.3f", pair.Query, pair.Name, pair.Note, score)
+		case !predicted && pair.Match:
+			falseNegatives++
+			t.Logf("false negative: %q vs %q (%s) scored %.3f", pair.Query, pair.Name, pair.Note, score)
+		}
+	}
+
+	precision := float64(truePositives) / float64(truePositives+falsePositives)
+	recall := float64(truePositives) / float64(truePositives+falseNegatives)
+	t.Logf("%d pairs: precision %.3f, recall %.3f", len(pairs), precision, recall)
+
+	if precision < 0.9 {
+		t.Errorf("expected precision of at least 0.9, got %.3f", precision)
+	}
+	if recall < 0.95 {
+		t.Errorf("expected recall of at least 0.95, got %.3f", recall)
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"lowercases", "Radiohead", "radiohead"},
+		{"strips diacritics", "Mötley Crüe", "motley crue"},
+		{"strips combining marks", "Beyoncé", "beyonce"},
+		{"folds ligatures", "Æther Realm", "aether realm"},
+		{"drops leading article", "The Beatles", "beatles"},
+		{"keeps lone article", "The", "the"},
+		{"keeps inner article", "Florence and the Machine", "florence and the machine"},
+		{"drops featuring credit", "Calvin Harris feat. Rihanna", "calvin harris"},
+		{"drops bracketed featuring credit", "Drake (ft. Rihanna)", "drake"},
+		{"keeps words starting with feat", "Featurette", "featurette"},
+		{"joins apostrophes", "Guns N' Roses", "guns n roses"},
+		{"joins periods", "R.E.M.", "rem"},
+		{"spells out symbols", "A$AP Rocky", "asap rocky"},
+		{"spells out ampersand", "Simon & Garfunkel", "simon and garfunkel"},
+		{"reads exclamation mark as i", "P!nk", "pink"},
+		{"drops trailing exclamation mark", "Panic! at the Disco", "panic at the disco"},
+		{"splits on other punctuation", "AC/DC", "ac dc"},
+		{"collapses whitespace", "  Bon   Iver ", "bon iver"},
+		{"keeps digits", "blink-182", "blink 182"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeName(tt.input); got != tt.expected {
+				t.Errorf("normalizeName(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScoreName(t *testing.T) {
+	weights := DefaultWeights()
+	tests := []struct {
+		name     string
+		query    string
+		artist   string
+		minScore float64
+		maxScore float64
+	}{
+		{"exact", "Taylor Swift", "Taylor Swift", 1.0, 1.0},
+		{"same once normalized", "the beatles", "The Beatles", 1.0, 1.0},
+		{"same without spaces", "ACDC", "AC/DC", 1.0, 1.0},
+		{"typo", "Radiohed", "Radiohead", 0.8, 1.0},
+		{"missing word", "Taylor", "Taylor Swift", 0.3, 0.6},
+		{"unrelated", "Taylor Swift", "Metallica", 0.0, 0.2},
+		{"symbols only", "!!!", "!!!", 1.0, 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := scoreName(tt.query, tt.artist, weights)
+			if score < tt.minScore || score > tt.maxScore {
+				t.Errorf("scoreName(%q, %q) = %.3f, expected between %.1f and %.1f", tt.query, tt.artist, score, tt.minScore, tt.maxScore)
+			}
+		})
+	}
+}
+
+func TestScoreName_ZeroWeights(t *testing.T) {
+	if score := scoreName("Radiohed", "Radiohead", Weights{}); score != 0 {
+		t.Errorf("expected score 0 with zero weights, got %.3f", score)
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"martha", "marhta", 0.961},
+		{"dwayne", "duane", 0.840},
+		{"dixon", "dicksonx", 0.813},
+		{"abc", "abc", 1.0},
+		{"abc", "xyz", 0.0},
+		{"", "abc", 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := jaroWinkler([]rune(tt.a), []rune(tt.b)); math.Abs(got-tt.expected) > 0.001 {
+				t.Errorf("jaroWinkler(%q, %q) = %.3f, expected %.3f", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTokenSetRatio(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"swift taylor", "taylor swift", 1.0},
+		{"taylor", "taylor swift", 1.0},
+		{"radiohead", "metallica", 2 * 3.0 / 18},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := tokenSetRatio(tt.a, tt.b); math.Abs(got-tt.expected) > 0.001 {
+				t.Errorf("tokenSetRatio(%q, %q) = %.3f, expected %.3f", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevenshteinSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"abc", "abc", 1.0},
+		{"", "", 1.0},
+		{"", "abc", 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := levenshteinSimilarity([]rune(tt.a), []rune(tt.b)); math.Abs(got-tt.expected) > 0.001 {
+				t.Errorf("levenshteinSimilarity(%q, %q) = %.3f, expected %.3f", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWeightsFromConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.SearchConfig
+		expected Weights
+	}{
+		{
+			name: "configured weights",
+			cfg: config.SearchConfig{
+				JaroWinklerWeight:   0.5,
+				TokenSetWeight:      0.25,
+				LevenshteinWeight:   0.25,
+				UnmatchedWordWeight: 0.6,
+				ProminenceWeight:    0.1,
+			},
+			expected: Weights{JaroWinkler: 0.5, TokenSet: 0.25, Levenshtein: 0.25, UnmatchedWords: 0.6, Prominence: 0.1},
+		},
+		{
+			name: "negative and oversized weights",
+			cfg: config.SearchConfig{
+				JaroWinklerWeight:   -1,
+				TokenSetWeight:      2,
+				LevenshteinWeight:   1,
+				UnmatchedWordWeight: 1.5,
+				ProminenceWeight:    -0.5,
+			},
+			expected: Weights{JaroWinkler: 0, TokenSet: 2, Levenshtein: 1, UnmatchedWords: 1, Prominence: 0},
+		},
+		{
+			name: "zero metric weights fall back to defaults",
+			cfg:  config.SearchConfig{UnmatchedWordWeight: 0.5, ProminenceWeight: 0.2},
+			expected: Weights{
+				JaroWinkler:    DefaultWeights().JaroWinkler,
+				TokenSet:       DefaultWeights().TokenSet,
+				Levenshtein:    DefaultWeights().Levenshtein,
+				UnmatchedWords: 0.5,
+				Prominence:     0.2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeightsFromConfig(tt.cfg); got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
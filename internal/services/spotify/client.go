@@ -2,13 +2,14 @@ package spotify
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/toozej/go-listen/pkg/config"
+	"github.com/toozej/go-listen/pkg/logging"
 	"github.com/zmb3/spotify/v2"
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
 	"golang.org/x/oauth2"
@@ -18,23 +19,42 @@ import (
 type Client struct {
 	client     *spotify.Client
 	config     config.SpotifyConfig
-	logger     *logrus.Logger
+	logger     *logging.Logger
 	token      *oauth2.Token
 	tokenMu    sync.RWMutex
-	ctx        context.Context
 	auth       *spotifyauth.Authenticator
 	isUserAuth bool
-	authURL    string
-	state      string
+	tokenStore TokenStore
+	limits     *rateLimiter
+	apiOptions []spotify.ClientOption // applied to every API client, e.g. a base URL in tests
+
+	profileMu     sync.Mutex
+	cachedProfile *spotify.PrivateUser
 }
 
-// NewClient creates a new Spotify client with user authentication flow
+// NewClient creates a new Spotify client with user authentication flow,
+// persisting tokens in the store selected by the configuration
 func NewClient(cfg config.SpotifyConfig, logger *logrus.Logger) (*Client, error) {
 	if cfg.ClientID == "" || cfg.ClientSecret == "" {
 		return nil, fmt.Errorf("spotify client ID and secret are required")
 	}
 
-	ctx := context.Background()
+	store, err := NewTokenStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token store: %w", err)
+	}
+
+	return NewClientWithTokenStore(cfg, store, logger)
+}
+
+// NewClientWithTokenStore creates a new Spotify client that loads and saves tokens using the given store
+func NewClientWithTokenStore(cfg config.SpotifyConfig, store TokenStore, logger *logrus.Logger) (*Client, error) {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, fmt.Errorf("spotify client ID and secret are required")
+	}
+	if store == nil {
+		store = NewMemoryTokenStore()
+	}
 
 	// Set up Authorization Code flow for user authentication following the library examples
 	auth := spotifyauth.New(
@@ -49,18 +69,10 @@ func NewClient(cfg config.SpotifyConfig, logger *logrus.Logger) (*Client, error)
 		spotifyauth.WithClientSecret(cfg.ClientSecret),
 	)
 
-	// Generate state for security
-	state := "go-listen-auth-state"
-
-	// Use the library's AuthURL method as shown in examples
-	authURL := auth.AuthURL(state)
-
 	logger.WithFields(logrus.Fields{
 		"client_id":    cfg.ClientID,
 		"redirect_url": cfg.RedirectURL,
-		"state":        state,
-		"auth_url":     authURL,
-	}).Info("Generated Spotify auth URL using library method")
+	}).Info("Configured Spotify authenticator using library method")
 
 	// Validate redirect URL is configured
 	if cfg.RedirectURL == "" {
@@ -71,24 +83,86 @@ func NewClient(cfg config.SpotifyConfig, logger *logrus.Logger) (*Client, error)
 	client := &Client{
 		client:     nil, // Will be set after authentication
 		config:     cfg,
-		logger:     logger,
+		logger:     logging.Wrap(logger),
 		token:      nil,
-		ctx:        ctx,
 		auth:       auth,
 		isUserAuth: false,
-		authURL:    authURL,
-		state:      state,
+		tokenStore: store,
+		limits:     newRateLimiter(cfg, logging.Wrap(logger)),
+	}
+
+	if client.restoreToken() {
+		logger.Info("Spotify client initialized from stored token")
+		return client, nil
 	}
 
 	logger.Info("Spotify client initialized, user authentication required")
-	logger.WithField("auth_url", client.authURL).Info("Visit this URL to authenticate with Spotify")
+	logger.Info("Visit /auth on the go-listen server to authenticate with Spotify")
 
 	return client, nil
 }
 
-// GetAuthURL returns the URL for user authentication
-func (c *Client) GetAuthURL() string {
-	return c.authURL
+// restoreToken loads a previously saved token from the token store and,
+// if one exists, marks the client as authenticated
+func (c *Client) restoreToken() bool {
+	token, err := c.tokenStore.Load()
+	if err != nil {
+		if !errors.Is(err, ErrNoToken) {
+			c.logger.WithError(err).Warn("Failed to load stored Spotify token, user authentication required")
+		}
+		return false
+	}
+
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.token = token
+	c.isUserAuth = true
+	c.client = c.newAPIClient(token)
+
+	c.logger.WithField("token_expiry", token.Expiry).Debug("Restored Spotify token from token store")
+	return true
+}
+
+// newAPIClient creates a Spotify API client authenticated with token whose
+// requests are paced and retried by the client's rate limiter. Each API call
+// carries its own context; the one given to the OAuth client only refreshes tokens,
+// so it must outlive any single request.
+func (c *Client) newAPIClient(token *oauth2.Token) *spotify.Client {
+	httpClient := c.auth.Client(context.Background(), token)
+	if c.limits != nil {
+		httpClient.Transport = c.limits.transport(httpClient.Transport)
+	}
+	return spotify.New(httpClient, c.apiOptions...)
+}
+
+// api returns the current Spotify API client. Logins and token refreshes replace
+// it, so it is read under the token lock rather than through c.client directly.
+func (c *Client) api() *spotify.Client {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.client
+}
+
+// saveToken writes the current token to the token store. Failures are logged
+// rather than returned since the in-memory token remains usable.
+func (c *Client) saveToken(ctx context.Context, token *oauth2.Token) {
+	if c.tokenStore == nil {
+		return
+	}
+	if err := c.tokenStore.Save(token); err != nil {
+		c.logger.WithContext(ctx).WithError(err).Warn("Failed to persist Spotify token")
+	}
+}
+
+// GetAuthURL returns the URL for user authentication bound to the given state.
+// When codeVerifier is non-empty, a PKCE S256 code challenge is included.
+func (c *Client) GetAuthURL(state, codeVerifier string) string {
+	var opts []oauth2.AuthCodeOption
+	if codeVerifier != "" {
+		opts = append(opts, oauth2.S256ChallengeOption(codeVerifier))
+	}
+	return c.auth.AuthURL(state, opts...)
 }
 
 // IsAuthenticated returns whether the user is authenticated
@@ -98,54 +172,61 @@ func (c *Client) IsAuthenticated() bool {
 	return c.isUserAuth && c.client != nil
 }
 
-// CompleteAuth completes the authentication process with the authorization code
-func (c *Client) CompleteAuth(code, state string) error {
+// CompleteAuth completes the authentication process with the authorization code.
+// The OAuth state must already have been verified by the caller; codeVerifier is
+// the PKCE verifier used to build the auth URL, or empty if PKCE was not used.
+func (c *Client) CompleteAuth(ctx context.Context, code, codeVerifier string) error {
 	c.tokenMu.Lock()
 	defer c.tokenMu.Unlock()
 
-	if state != c.state {
-		return fmt.Errorf("invalid state parameter")
-	}
+	c.logger.WithContext(ctx).WithField("pkce", codeVerifier != "").Debug("Completing Spotify authentication")
 
-	c.logger.Debug("Completing Spotify authentication")
+	var opts []oauth2.AuthCodeOption
+	if codeVerifier != "" {
+		opts = append(opts, oauth2.VerifierOption(codeVerifier))
+	}
 
 	// Exchange authorization code for token using the library method
-	token, err := c.auth.Exchange(c.ctx, code)
+	token, err := c.auth.Exchange(ctx, code, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to exchange code for token: %w", err)
 	}
 
 	c.token = token
 	c.isUserAuth = true
+	c.saveToken(ctx, token)
 
 	// Create authenticated Spotify client following library examples
-	httpClient := c.auth.Client(c.ctx, token)
-	c.client = spotify.New(httpClient)
+	c.client = c.newAPIClient(token)
 
-	c.logger.Info("Spotify user authentication completed successfully")
+	c.logger.WithContext(ctx).Info("Spotify user authentication completed successfully")
 
 	// Test the authentication by fetching current user info
-	user, err := c.client.CurrentUser(c.ctx)
+	user, err := c.client.CurrentUser(ctx)
 	if err != nil {
-		c.logger.WithError(err).Error("Failed to verify authentication by getting current user")
-		return fmt.Errorf("authentication verification failed: %w", err)
+		c.logger.WithContext(ctx).WithError(err).Error("Failed to verify authentication by getting current user")
+		return fmt.Errorf("authentication verification failed: %w", apiError(err))
 	}
 
-	c.logger.WithFields(logrus.Fields{
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"user_id":           user.ID,
 		"user_display_name": user.DisplayName,
+		"user_country":      user.Country,
 	}).Info("Authentication verified successfully")
 
+	// A different user may have logged in, so replace any cached profile
+	c.setProfile(user)
+
 	return nil
 }
 
 // RefreshToken refreshes the access token if needed
-func (c *Client) RefreshToken() error {
+func (c *Client) RefreshToken(ctx context.Context) error {
 	c.tokenMu.Lock()
 	defer c.tokenMu.Unlock()
 
 	if !c.isUserAuth {
-		return fmt.Errorf("user not authenticated")
+		return errNotAuthenticated
 	}
 
 	// Check if token needs refresh (refresh 5 minutes before expiry)
@@ -153,269 +234,202 @@ func (c *Client) RefreshToken() error {
 		return nil
 	}
 
-	c.logger.Debug("Refreshing Spotify access token")
+	c.logger.WithContext(ctx).Debug("Refreshing Spotify access token")
 
 	// Use the authenticator to refresh the token following library patterns
-	newToken, err := c.auth.RefreshToken(c.ctx, c.token)
+	newToken, err := c.auth.RefreshToken(ctx, c.token)
 	if err != nil {
-		return fmt.Errorf("failed to refresh token: %w", err)
+		return fmt.Errorf("failed to refresh token: %w", apiError(err))
 	}
 
 	c.token = newToken
+	c.saveToken(ctx, newToken)
 
 	// Update the client with new token following library examples
-	httpClient := c.auth.Client(c.ctx, newToken)
-	c.client = spotify.New(httpClient)
+	c.client = c.newAPIClient(newToken)
 
-	c.logger.Info("Spotify access token refreshed successfully")
+	c.logger.WithContext(ctx).Info("Spotify access token refreshed successfully")
 	return nil
 }
 
+// maxSearchResults is the most artists Spotify returns for a single search
+const maxSearchResults = 50
+
 // SearchArtist searches for an artist by name and returns the best match
-func (c *Client) SearchArtist(query string) (*Artist, error) {
+func (c *Client) SearchArtist(ctx context.Context, query string) (*Artist, error) {
+	artists, err := c.SearchArtists(ctx, query, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &artists[0], nil
+}
+
+// SearchArtists searches for artists by name and returns up to limit of them,
+// in Spotify's order of relevance
+func (c *Client) SearchArtists(ctx context.Context, query string, limit int) ([]Artist, error) {
 	if !c.IsAuthenticated() {
-		return nil, fmt.Errorf("user not authenticated to Spotify")
+		return nil, errNotAuthenticated
 	}
 
-	if err := c.RefreshToken(); err != nil {
+	if err := c.RefreshToken(ctx); err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	c.logger.WithField("query", query).Debug("Searching for artist using Spotify library")
+	limit = min(max(limit, 1), maxSearchResults)
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"query": query,
+		"limit": limit,
+	}).Debug("Searching for artist using Spotify library")
 
-	// Use the library's Search method following the examples
-	results, err := c.client.Search(c.ctx, query, spotify.SearchTypeArtist)
+	results, err := c.api().Search(ctx, query, spotify.SearchTypeArtist, spotify.Market(c.resolveMarket(ctx, "")), spotify.Limit(limit))
 	if err != nil {
-		c.logger.WithError(err).WithField("query", query).Error("Failed to search for artist")
-		return nil, fmt.Errorf("failed to search for artist: %w", err)
+		c.logger.WithContext(ctx).WithError(err).WithField("query", query).Error("Failed to search for artist")
+		return nil, fmt.Errorf("failed to search for artist: %w", apiError(err))
 	}
 
 	if results.Artists == nil || len(results.Artists.Artists) == 0 {
-		c.logger.WithField("query", query).Warn("No artists found")
-		return nil, fmt.Errorf("no artists found for query: %s", query)
+		c.logger.WithContext(ctx).WithField("query", query).Warn("No artists found")
+		return nil, fmt.Errorf("%w: no artists found for query: %s", ErrNotFound, query)
 	}
 
-	// Return the first (most relevant) result following library patterns
-	spotifyArtist := results.Artists.Artists[0]
-
-	artist := &Artist{
-		ID:     string(spotifyArtist.ID),
-		Name:   spotifyArtist.Name,
-		URI:    string(spotifyArtist.URI),
-		Genres: spotifyArtist.Genres,
+	found := results.Artists.Artists
+	artists := make([]Artist, 0, min(len(found), limit))
+	for i := range found[:min(len(found), limit)] {
+		artists = append(artists, convertArtist(&found[i]))
 	}
 
-	c.logger.WithFields(logrus.Fields{
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"query":       query,
-		"artist_id":   artist.ID,
-		"artist_name": artist.Name,
-		"genres":      artist.Genres,
+		"artist_id":   artists[0].ID,
+		"artist_name": artists[0].Name,
+		"genres":      artists[0].Genres,
+		"candidates":  len(artists),
 	}).Info("Artist found using Spotify library")
 
-	return artist, nil
+	return artists, nil
 }
 
-// GetArtistTopTracks retrieves the top tracks for an artist (limited to 5)
-func (c *Client) GetArtistTopTracks(artistID string) ([]Track, error) {
+// GetArtist looks up an artist by Spotify ID
+func (c *Client) GetArtist(ctx context.Context, artistID string) (*Artist, error) {
 	if !c.IsAuthenticated() {
-		return nil, fmt.Errorf("user not authenticated to Spotify")
+		return nil, errNotAuthenticated
 	}
 
-	if err := c.RefreshToken(); err != nil {
+	if err := c.RefreshToken(ctx); err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	c.logger.WithField("artist_id", artistID).Debug("Getting artist top tracks using Spotify library")
+	c.logger.WithContext(ctx).WithField("artist_id", artistID).Debug("Getting artist using Spotify library")
 
-	// Get top tracks for the artist using library method with market parameter
-	topTracks, err := c.client.GetArtistsTopTracks(c.ctx, spotify.ID(artistID), spotify.CountryUSA)
+	found, err := c.api().GetArtist(ctx, spotify.ID(artistID))
 	if err != nil {
-		c.logger.WithError(err).WithField("artist_id", artistID).Error("Failed to get artist top tracks")
-		return nil, fmt.Errorf("failed to get top tracks for artist %s: %w", artistID, err)
-	}
-
-	if len(topTracks) == 0 {
-		c.logger.WithField("artist_id", artistID).Warn("No top tracks found for artist")
-		return []Track{}, nil
-	}
-
-	// Limit to top 5 tracks as per requirements
-	maxTracks := 5
-	if len(topTracks) < maxTracks {
-		maxTracks = len(topTracks)
-	}
-
-	tracks := make([]Track, maxTracks)
-	trackNames := make([]string, maxTracks)
-
-	for i := range topTracks[:maxTracks] {
-		spotifyTrack := &topTracks[i]
-		// Convert Spotify artists to our Artist type
-		artists := make([]Artist, len(spotifyTrack.Artists))
-		for j, spotifyArtist := range spotifyTrack.Artists {
-			artists[j] = Artist{
-				ID:     string(spotifyArtist.ID),
-				Name:   spotifyArtist.Name,
-				URI:    string(spotifyArtist.URI),
-				Genres: []string{}, // SimpleArtist doesn't include genres, would need full artist lookup
-			}
-		}
-
-		tracks[i] = Track{
-			ID:       string(spotifyTrack.ID),
-			Name:     spotifyTrack.Name,
-			URI:      string(spotifyTrack.URI),
-			Artists:  artists,
-			Duration: int(spotifyTrack.Duration),
-		}
-		trackNames[i] = spotifyTrack.Name
+		c.logger.WithContext(ctx).WithError(err).WithField("artist_id", artistID).Error("Failed to get artist")
+		return nil, fmt.Errorf("failed to get artist: %w", apiError(err))
 	}
 
-	c.logger.WithFields(logrus.Fields{
-		"artist_id":    artistID,
-		"tracks_found": len(tracks),
-		"track_names":  trackNames,
-	}).Info("Retrieved artist top tracks using Spotify library")
+	artist := convertArtist(found)
+	return &artist, nil
+}
 
-	return tracks, nil
+// convertArtist converts a Spotify artist to our Artist type
+func convertArtist(spotifyArtist *spotify.FullArtist) Artist {
+	artist := Artist{
+		ID:         string(spotifyArtist.ID),
+		Name:       spotifyArtist.Name,
+		URI:        string(spotifyArtist.URI),
+		Genres:     spotifyArtist.Genres,
+		Followers:  int(spotifyArtist.Followers.Count),
+		Popularity: int(spotifyArtist.Popularity),
+	}
+	// Spotify lists an artist's images widest first
+	if len(spotifyArtist.Images) > 0 {
+		artist.ImageURL = spotifyArtist.Images[0].URL
+	}
+	return artist
 }
 
-// GetUserPlaylists retrieves playlists from a specific folder (for now, returns all user playlists)
-func (c *Client) GetUserPlaylists(folderName string) ([]Playlist, error) {
+// GetUserPlaylists retrieves every playlist owned by the authenticated user.
+// Followed playlists are excluded since tracks can't be added to them.
+func (c *Client) GetUserPlaylists(ctx context.Context) ([]Playlist, error) {
 	if !c.IsAuthenticated() {
-		return nil, fmt.Errorf("user not authenticated to Spotify")
+		return nil, errNotAuthenticated
 	}
 
-	if err := c.RefreshToken(); err != nil {
+	if err := c.RefreshToken(ctx); err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	c.logger.WithField("folder_name", folderName).Debug("Getting user playlists using Spotify library")
+	c.logger.WithContext(ctx).Debug("Getting user playlists using Spotify library")
 
 	// Get current user first to validate authentication and for filtering
-	currentUser, err := c.client.CurrentUser(c.ctx)
+	currentUser, err := c.api().CurrentUser(ctx)
 	if err != nil {
-		c.logger.WithError(err).Error("Failed to get current user - authentication may have failed")
-		return nil, fmt.Errorf("failed to get current user: %w", err)
+		c.logger.WithContext(ctx).WithError(err).Error("Failed to get current user - authentication may have failed")
+		return nil, fmt.Errorf("failed to get current user: %w", apiError(err))
 	}
 
-	c.logger.WithFields(logrus.Fields{
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"user_id":           currentUser.ID,
 		"user_display_name": currentUser.DisplayName,
 	}).Info("Successfully validated user authentication")
 
-	// Get all user playlists using the library method
-	playlistPage, err := c.client.CurrentUsersPlaylists(c.ctx)
+	// Get all user playlists, walking every page
+	allPlaylists, err := c.allUserPlaylists(ctx)
 	if err != nil {
-		c.logger.WithError(err).WithField("folder_name", folderName).Error("Failed to get user playlists")
+		c.logger.WithContext(ctx).WithError(err).Error("Failed to get user playlists")
 		return nil, fmt.Errorf("failed to get user playlists: %w", err)
 	}
 
-	allPlaylists := playlistPage.Playlists
-
-	c.logger.WithFields(logrus.Fields{
-		"total_playlists": len(allPlaylists),
-		"folder_name":     folderName,
-	}).Info("Retrieved all user playlists using Spotify library")
-
 	// Log playlist names for debugging
 	playlistNames := make([]string, len(allPlaylists))
 	for i := range allPlaylists {
-		pl := &allPlaylists[i]
-		playlistNames[i] = pl.Name
+		playlistNames[i] = allPlaylists[i].Name
 	}
-	c.logger.WithFields(logrus.Fields{
-		"playlist_names": playlistNames,
-	}).Debug("Available playlists")
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"total_playlists": len(allPlaylists),
+		"playlist_names":  playlistNames,
+	}).Debug("Retrieved all user playlists using Spotify library")
 
-	var filteredPlaylists []Playlist
+	var ownedPlaylists []Playlist
 	for i := range allPlaylists {
 		spotifyPlaylist := &allPlaylists[i]
 		// Only include playlists owned by the user (not followed playlists)
-		isUserOwned := spotifyPlaylist.Owner.ID == currentUser.ID
-
-		// If a folder name is specified, try basic filtering
-		// Since Spotify Web API doesn't provide direct folder access, we'll use multiple strategies:
-		// 1. Check if playlist name contains folder name
-		// 2. Check if playlist description contains folder name
-		// 3. For "Incoming" specifically, look for playlists that start with "i" (common pattern)
-		matchesFolder := folderName == ""
-
-		if folderName != "" {
-			folderLower := strings.ToLower(folderName)
-			playlistNameLower := strings.ToLower(spotifyPlaylist.Name)
-			playlistDescLower := strings.ToLower(spotifyPlaylist.Description)
-
-			// Basic name/description matching
-			matchesFolder = strings.Contains(playlistNameLower, folderLower) ||
-				strings.Contains(playlistDescLower, folderLower)
-
-			// Special case for "Incoming" folder - look for playlists starting with "i"
-			if !matchesFolder && folderLower == "incoming" {
-				matchesFolder = strings.HasPrefix(playlistNameLower, "i") && len(spotifyPlaylist.Name) > 1
-			}
-		}
-
-		if isUserOwned && matchesFolder {
-			playlist := Playlist{
-				ID:         string(spotifyPlaylist.ID),
-				Name:       spotifyPlaylist.Name,
-				URI:        string(spotifyPlaylist.URI),
-				TrackCount: int(spotifyPlaylist.Tracks.Total),
-				EmbedURL:   fmt.Sprintf("https://open.spotify.com/embed/playlist/%s", spotifyPlaylist.ID),
-				IsIncoming: true, // Mark as incoming since it passed our filter
-			}
-			filteredPlaylists = append(filteredPlaylists, playlist)
+		if spotifyPlaylist.Owner.ID != currentUser.ID {
+			continue
 		}
-	}
-
-	c.logger.WithFields(logrus.Fields{
-		"folder_name":     folderName,
-		"filtered_count":  len(filteredPlaylists),
+		ownedPlaylists = append(ownedPlaylists, Playlist{
+			ID:          string(spotifyPlaylist.ID),
+			Name:        spotifyPlaylist.Name,
+			Description: spotifyPlaylist.Description,
+			URI:         string(spotifyPlaylist.URI),
+			TrackCount:  int(spotifyPlaylist.Tracks.Total),
+			EmbedURL:    fmt.Sprintf("https://open.spotify.com/embed/playlist/%s", spotifyPlaylist.ID),
+		})
+	}
+
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"owned_count":     len(ownedPlaylists),
 		"total_playlists": len(allPlaylists),
-	}).Info("Filtered user playlists")
-
-	// If no playlists match the folder filter but we have playlists,
-	// return all user playlists as a fallback
-	if len(filteredPlaylists) == 0 && len(allPlaylists) > 0 && folderName != "" {
-		c.logger.WithField("folder_name", folderName).Warn("No playlists found matching folder name, returning all user playlists")
-
-		for i := range allPlaylists {
-			spotifyPlaylist := &allPlaylists[i]
-			isUserOwned := spotifyPlaylist.Owner.ID == currentUser.ID
-
-			if isUserOwned {
-				playlist := Playlist{
-					ID:         string(spotifyPlaylist.ID),
-					Name:       spotifyPlaylist.Name,
-					URI:        string(spotifyPlaylist.URI),
-					TrackCount: int(spotifyPlaylist.Tracks.Total),
-					EmbedURL:   fmt.Sprintf("https://open.spotify.com/embed/playlist/%s", spotifyPlaylist.ID),
-					IsIncoming: true,
-				}
-				filteredPlaylists = append(filteredPlaylists, playlist)
-			}
-		}
-	}
+	}).Info("Retrieved user owned playlists")
 
-	return filteredPlaylists, nil
+	return ownedPlaylists, nil
 }
 
 // AddTracksToPlaylist adds tracks to a specified playlist
-func (c *Client) AddTracksToPlaylist(playlistID string, trackIDs []string) error {
+func (c *Client) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
 	if len(trackIDs) == 0 {
 		return fmt.Errorf("no tracks provided to add")
 	}
 
 	if !c.IsAuthenticated() {
-		return fmt.Errorf("user not authenticated to Spotify")
+		return errNotAuthenticated
 	}
 
-	if err := c.RefreshToken(); err != nil {
+	if err := c.RefreshToken(ctx); err != nil {
 		return fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	c.logger.WithFields(logrus.Fields{
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"playlist_id": playlistID,
 		"track_count": len(trackIDs),
 		"track_ids":   trackIDs,
@@ -428,17 +442,17 @@ func (c *Client) AddTracksToPlaylist(playlistID string, trackIDs []string) error
 	}
 
 	// Add tracks to playlist using library method
-	_, err := c.client.AddTracksToPlaylist(c.ctx, spotify.ID(playlistID), spotifyIDs...)
+	_, err := c.api().AddTracksToPlaylist(ctx, spotify.ID(playlistID), spotifyIDs...)
 	if err != nil {
-		c.logger.WithError(err).WithFields(logrus.Fields{
+		c.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
 			"playlist_id": playlistID,
 			"track_count": len(trackIDs),
 			"track_ids":   trackIDs,
 		}).Error("Failed to add tracks to playlist")
-		return fmt.Errorf("failed to add tracks to playlist %s: %w", playlistID, err)
+		return fmt.Errorf("failed to add tracks to playlist %s: %w", playlistID, apiError(err))
 	}
 
-	c.logger.WithFields(logrus.Fields{
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"playlist_id": playlistID,
 		"track_count": len(trackIDs),
 		"track_ids":   trackIDs,
@@ -448,29 +462,29 @@ func (c *Client) AddTracksToPlaylist(playlistID string, trackIDs []string) error
 }
 
 // CheckTracksInPlaylist checks if tracks already exist in a playlist
-func (c *Client) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]bool, error) {
+func (c *Client) CheckTracksInPlaylist(ctx context.Context, playlistID string, trackIDs []string) ([]bool, error) {
 	if len(trackIDs) == 0 {
 		return []bool{}, nil
 	}
 
 	if !c.IsAuthenticated() {
-		return nil, fmt.Errorf("user not authenticated to Spotify")
+		return nil, errNotAuthenticated
 	}
 
-	if err := c.RefreshToken(); err != nil {
+	if err := c.RefreshToken(ctx); err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	c.logger.WithFields(logrus.Fields{
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"playlist_id": playlistID,
 		"track_count": len(trackIDs),
 		"track_ids":   trackIDs,
 	}).Debug("Checking tracks in playlist using Spotify library")
 
-	// Get all tracks from the playlist using library method
-	items, err := c.client.GetPlaylistItems(c.ctx, spotify.ID(playlistID))
+	// Get all tracks from the playlist, walking every page
+	items, err := c.allPlaylistItems(ctx, playlistID)
 	if err != nil {
-		c.logger.WithError(err).WithField("playlist_id", playlistID).Error("Failed to get playlist items")
+		c.logger.WithContext(ctx).WithError(err).WithField("playlist_id", playlistID).Error("Failed to get playlist items")
 		return nil, fmt.Errorf("failed to get playlist items: %w", err)
 	}
 
@@ -478,8 +492,8 @@ func (c *Client) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]
 	existingTracks := make(map[string]bool)
 	existingTrackNames := make([]string, 0)
 
-	for i := range items.Items {
-		playlistItem := &items.Items[i]
+	for i := range items {
+		playlistItem := &items[i]
 		if playlistItem.Track.Track != nil && playlistItem.Track.Track.ID != "" {
 			trackID := string(playlistItem.Track.Track.ID)
 			existingTracks[trackID] = true
@@ -487,8 +501,9 @@ func (c *Client) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]
 		}
 	}
 
-	c.logger.WithFields(logrus.Fields{
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"playlist_id":          playlistID,
+		"playlist_item_count":  len(items),
 		"existing_track_count": len(existingTracks),
 		"existing_track_names": existingTrackNames,
 	}).Debug("Retrieved existing tracks from playlist")
@@ -506,7 +521,7 @@ func (c *Client) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]
 		}
 	}
 
-	c.logger.WithFields(logrus.Fields{
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"playlist_id":     playlistID,
 		"track_count":     len(trackIDs),
 		"duplicate_count": duplicateCount,
@@ -514,3 +529,62 @@ func (c *Client) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]
 
 	return results, nil
 }
+
+// GetPlaylistTracks retrieves every track in a playlist. Episodes and local
+// files are skipped since they have no Spotify track ID.
+func (c *Client) GetPlaylistTracks(ctx context.Context, playlistID string) ([]Track, error) {
+	if !c.IsAuthenticated() {
+		return nil, errNotAuthenticated
+	}
+
+	if err := c.RefreshToken(ctx); err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	c.logger.WithContext(ctx).WithField("playlist_id", playlistID).Debug("Getting playlist tracks using Spotify library")
+
+	items, err := c.allPlaylistItems(ctx, playlistID)
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithField("playlist_id", playlistID).Error("Failed to get playlist items")
+		return nil, fmt.Errorf("failed to get playlist items: %w", err)
+	}
+
+	tracks := make([]Track, 0, len(items))
+	for i := range items {
+		track := items[i].Track.Track
+		if track == nil || track.ID == "" {
+			continue
+		}
+		tracks = append(tracks, convertFullTrack(track))
+	}
+
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"playlist_id":         playlistID,
+		"playlist_item_count": len(items),
+		"track_count":         len(tracks),
+	}).Info("Retrieved playlist tracks using Spotify library")
+
+	return tracks, nil
+}
+
+// allUserPlaylists retrieves every page of the current user's playlists
+func (c *Client) allUserPlaylists(ctx context.Context) ([]spotify.SimplePlaylist, error) {
+	return fetchAllPages(ctx, playlistPageSize, c.pagePrefetch(), func(ctx context.Context, offset int) ([]spotify.SimplePlaylist, int, error) {
+		page, err := c.api().CurrentUsersPlaylists(ctx, spotify.Limit(playlistPageSize), spotify.Offset(offset))
+		if err != nil {
+			return nil, 0, apiError(err)
+		}
+		return page.Playlists, int(page.Total), nil
+	})
+}
+
+// allPlaylistItems retrieves every page of a playlist's items
+func (c *Client) allPlaylistItems(ctx context.Context, playlistID string) ([]spotify.PlaylistItem, error) {
+	return fetchAllPages(ctx, playlistItemsPageSize, c.pagePrefetch(), func(ctx context.Context, offset int) ([]spotify.PlaylistItem, int, error) {
+		page, err := c.api().GetPlaylistItems(ctx, spotify.ID(playlistID), spotify.Limit(playlistItemsPageSize), spotify.Offset(offset))
+		if err != nil {
+			return nil, 0, apiError(err)
+		}
+		return page.Items, int(page.Total), nil
+	})
+}
@@ -2,11 +2,19 @@ package spotify
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/types"
 	"github.com/toozej/go-listen/pkg/config"
+	"github.com/toozej/go-listen/pkg/logging"
+	"github.com/zmb3/spotify/v2"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
 	"golang.org/x/oauth2"
 )
 
@@ -87,12 +95,11 @@ func TestClient_RefreshToken(t *testing.T) {
 
 	client := &Client{
 		config: cfg,
-		logger: logger,
+		logger: logging.Wrap(logger),
 		token:  nil, // No token, should trigger refresh
-		ctx:    context.Background(),
 	}
 
-	err := client.RefreshToken()
+	err := client.RefreshToken(context.Background())
 	if err == nil {
 		t.Error("RefreshToken() expected error with invalid credentials but got none")
 	}
@@ -112,17 +119,16 @@ func TestClient_RefreshToken_NotNeeded(t *testing.T) {
 
 	client := &Client{
 		config: cfg,
-		logger: logger,
+		logger: logging.Wrap(logger),
 		token: &oauth2.Token{
 			AccessToken: "test-token",
 			TokenType:   "Bearer",
 			Expiry:      futureTime,
 		},
-		ctx:        context.Background(),
 		isUserAuth: true, // Set to true to simulate authenticated state
 	}
 
-	err := client.RefreshToken()
+	err := client.RefreshToken(context.Background())
 	if err != nil {
 		t.Errorf("RefreshToken() unexpected error when token doesn't need refresh: %v", err)
 	}
@@ -139,18 +145,17 @@ func TestClient_SearchArtist_NoToken(t *testing.T) {
 
 	client := &Client{
 		config: cfg,
-		logger: logger,
+		logger: logging.Wrap(logger),
 		token:  nil,
-		ctx:    context.Background(),
 	}
 
-	_, err := client.SearchArtist("test artist")
+	_, err := client.SearchArtist(context.Background(), "test artist")
 	if err == nil {
 		t.Error("SearchArtist() expected error when no valid token but got none")
 	}
 }
 
-func TestClient_GetArtistTopTracks_NoToken(t *testing.T) {
+func TestClient_GetArtistTracks_NoToken(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
 
@@ -161,14 +166,13 @@ func TestClient_GetArtistTopTracks_NoToken(t *testing.T) {
 
 	client := &Client{
 		config: cfg,
-		logger: logger,
+		logger: logging.Wrap(logger),
 		token:  nil,
-		ctx:    context.Background(),
 	}
 
-	_, err := client.GetArtistTopTracks("test-artist-id")
+	_, err := client.GetArtistTracks(context.Background(), "test-artist-id", types.DefaultTrackSelection())
 	if err == nil {
-		t.Error("GetArtistTopTracks() expected error when no valid token but got none")
+		t.Error("GetArtistTracks() expected error when no valid token but got none")
 	}
 }
 
@@ -183,17 +187,44 @@ func TestClient_GetUserPlaylists_NoToken(t *testing.T) {
 
 	client := &Client{
 		config: cfg,
-		logger: logger,
+		logger: logging.Wrap(logger),
 		token:  nil,
-		ctx:    context.Background(),
 	}
 
-	_, err := client.GetUserPlaylists("Incoming")
+	_, err := client.GetUserPlaylists(context.Background())
 	if err == nil {
 		t.Error("GetUserPlaylists() expected error when no valid token but got none")
 	}
 }
 
+func TestClient_GetUserPlaylists_OwnedOnly(t *testing.T) {
+	client := newFakeServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/me":
+			_, _ = w.Write([]byte(`{"id":"user-1"}`))
+		case "/me/playlists":
+			_, _ = w.Write([]byte(`{"items":[
+				{"id":"mine","name":"Road Trip","description":"#incoming","owner":{"id":"user-1"},"tracks":{"total":3}},
+				{"id":"followed","name":"Incoming Hits","owner":{"id":"someone-else"},"tracks":{"total":50}}
+			],"limit":50,"offset":0,"total":2}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	playlists, err := client.GetUserPlaylists(context.Background())
+	if err != nil {
+		t.Fatalf("GetUserPlaylists() unexpected error: %v", err)
+	}
+	if len(playlists) != 1 || playlists[0].ID != "mine" {
+		t.Fatalf("GetUserPlaylists() = %+v, want only the owned playlist", playlists)
+	}
+	if playlists[0].Description != "#incoming" || playlists[0].IsIncoming {
+		t.Errorf("GetUserPlaylists() = %+v, want description kept and no incoming classification", playlists[0])
+	}
+}
+
 func TestClient_AddTracksToPlaylist_NoTracks(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
@@ -205,11 +236,10 @@ func TestClient_AddTracksToPlaylist_NoTracks(t *testing.T) {
 
 	client := &Client{
 		config: cfg,
-		logger: logger,
-		ctx:    context.Background(),
+		logger: logging.Wrap(logger),
 	}
 
-	err := client.AddTracksToPlaylist("test-playlist-id", []string{})
+	err := client.AddTracksToPlaylist(context.Background(), "test-playlist-id", []string{})
 	if err == nil {
 		t.Error("AddTracksToPlaylist() expected error when no tracks provided but got none")
 	}
@@ -231,11 +261,10 @@ func TestClient_CheckTracksInPlaylist_NoTracks(t *testing.T) {
 
 	client := &Client{
 		config: cfg,
-		logger: logger,
-		ctx:    context.Background(),
+		logger: logging.Wrap(logger),
 	}
 
-	results, err := client.CheckTracksInPlaylist("test-playlist-id", []string{})
+	results, err := client.CheckTracksInPlaylist(context.Background(), "test-playlist-id", []string{})
 	if err != nil {
 		t.Errorf("CheckTracksInPlaylist() unexpected error with empty tracks: %v", err)
 	}
@@ -244,3 +273,150 @@ func TestClient_CheckTracksInPlaylist_NoTracks(t *testing.T) {
 		t.Errorf("CheckTracksInPlaylist() expected empty results but got %v", results)
 	}
 }
+
+func TestClient_SearchArtists(t *testing.T) {
+	var limit string
+	client := newFakeServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit = r.URL.Query().Get("limit")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"artists":{"items":[
+			{"id":"low-1","name":"Low","uri":"spotify:artist:low-1","genres":["slowcore"],"popularity":48,
+			 "followers":{"total":310000},"images":[{"url":"https://i.scdn.co/image/large","width":640,"height":640},{"url":"https://i.scdn.co/image/small","width":160,"height":160}]},
+			{"id":"low-2","name":"Low","uri":"spotify:artist:low-2","popularity":3,"followers":{"total":120}}
+		],"total":2}}`))
+	}))
+	client.config.Market = "US"
+
+	artists, err := client.SearchArtists(context.Background(), "Low", 5)
+	if err != nil {
+		t.Fatalf("SearchArtists() unexpected error: %v", err)
+	}
+	if limit != "5" {
+		t.Errorf("SearchArtists() limit = %q, want %q", limit, "5")
+	}
+
+	want := []Artist{
+		{ID: "low-1", Name: "Low", URI: "spotify:artist:low-1", Genres: []string{"slowcore"}, Followers: 310000, Popularity: 48, ImageURL: "https://i.scdn.co/image/large"},
+		{ID: "low-2", Name: "Low", URI: "spotify:artist:low-2", Followers: 120, Popularity: 3},
+	}
+	if len(artists) != len(want) {
+		t.Fatalf("SearchArtists() returned %d artists, want %d", len(artists), len(want))
+	}
+	for i := range want {
+		got := artists[i]
+		if got.ID != want[i].ID || got.Followers != want[i].Followers || got.Popularity != want[i].Popularity ||
+			got.ImageURL != want[i].ImageURL || len(got.Genres) != len(want[i].Genres) {
+			t.Errorf("SearchArtists()[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestClient_GetArtist(t *testing.T) {
+	var path string
+	client := newFakeServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"low-1","name":"Low","uri":"spotify:artist:low-1","popularity":48,"followers":{"total":310000}}`))
+	}))
+
+	artist, err := client.GetArtist(context.Background(), "low-1")
+	if err != nil {
+		t.Fatalf("GetArtist() unexpected error: %v", err)
+	}
+	if path != "/artists/low-1" {
+		t.Errorf("GetArtist() requested %q, want %q", path, "/artists/low-1")
+	}
+	if artist.Name != "Low" || artist.Followers != 310000 || artist.Popularity != 48 {
+		t.Errorf("GetArtist() = %+v", artist)
+	}
+}
+
+// handlerTransport serves requests in memory with handler, so concurrent requests
+// share no connection pool that would hide races from the race detector
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (h handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func TestClient_SearchArtists_ConcurrentRefresh(t *testing.T) {
+	var refreshes atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/token" {
+			refreshes.Add(1)
+			_, _ = w.Write([]byte(`{"access_token":"fresh","token_type":"Bearer","refresh_token":"refresh","expires_in":3600}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"artists":{"items":[{"id":"low-1","name":"Low","uri":"spotify:artist:low-1"}],"total":1}}`))
+	})
+	// The API client built on refresh talks to a real server at the same base URL
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	apiOptions := []spotify.ClientOption{spotify.WithBaseURL(srv.URL + "/")}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	store := NewMemoryTokenStore()
+	client := &Client{
+		client:     spotify.New(&http.Client{Transport: handlerTransport{handler: handler}}, apiOptions...),
+		config:     config.SpotifyConfig{Market: "US"},
+		logger:     logging.Wrap(logger),
+		auth:       spotifyauth.New(spotifyauth.WithClientID("test-id"), spotifyauth.WithClientSecret("test-secret")),
+		tokenStore: store,
+		apiOptions: apiOptions,
+		token:      &oauth2.Token{AccessToken: "stale", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)},
+		isUserAuth: true,
+	}
+	// Token refreshes reach the handler in place of Spotify's accounts service
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: handlerTransport{handler: handler}})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := client.SearchArtists(ctx, "Low", 1); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	// The token runs out while searches are under way, so one of them replaces the API client the others use
+	time.Sleep(10 * time.Millisecond)
+	client.tokenMu.Lock()
+	client.token.Expiry = time.Now().Add(time.Second)
+	client.tokenMu.Unlock()
+	for start := time.Now(); refreshes.Load() == 0 && time.Since(start) < 5*time.Second; {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("SearchArtists() unexpected error: %v", err)
+	}
+
+	if got := refreshes.Load(); got != 1 {
+		t.Errorf("Token refreshed %d times, want once", got)
+	}
+	if saved, err := store.Load(); err != nil || saved.AccessToken != "fresh" {
+		t.Errorf("Stored token = %+v, %v, want the refreshed token", saved, err)
+	}
+}
@@ -0,0 +1,53 @@
+package spotify
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// ErrRateLimited is returned when Spotify keeps rate limiting a request after
+	// every retry, or asks for a longer wait than the client is willing to make
+	ErrRateLimited = errors.New("rate limited by Spotify")
+	// ErrUnauthorized is returned when the user has not logged in or Spotify
+	// rejects their token
+	ErrUnauthorized = errors.New("not authorized by Spotify")
+	// ErrNotFound is returned when Spotify has no such artist, playlist or track
+	ErrNotFound = errors.New("not found on Spotify")
+)
+
+// errNotAuthenticated is returned by every request made before the user logs in
+var errNotAuthenticated = fmt.Errorf("%w: user not authenticated to Spotify", ErrUnauthorized)
+
+// apiError classifies an error returned by the Spotify library so callers can
+// check it against ErrRateLimited, ErrUnauthorized or ErrNotFound
+func apiError(err error) error {
+	if err == nil || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	var spotifyErr spotify.Error
+	if errors.As(err, &spotifyErr) {
+		switch spotifyErr.Status {
+		case http.StatusTooManyRequests:
+			return fmt.Errorf("%w: %w", ErrRateLimited, err)
+		case http.StatusUnauthorized:
+			return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+	}
+
+	// A refresh token that was revoked or expired can't be used to get a new access token
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.Response != nil &&
+		(retrieveErr.Response.StatusCode == http.StatusBadRequest || retrieveErr.Response.StatusCode == http.StatusUnauthorized) {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+
+	return err
+}
@@ -1,8 +1,10 @@
 package spotify
 
 import (
+	"context"
 	"time"
 
+	"github.com/toozej/go-listen/internal/types"
 	"golang.org/x/oauth2"
 )
 
@@ -12,6 +14,10 @@ type Artist struct {
 	Name   string   `json:"name"`
 	URI    string   `json:"uri"`
 	Genres []string `json:"genres"`
+	// Followers, Popularity and ImageURL are only known for artists found by a search or lookup
+	Followers  int    `json:"followers,omitempty"`
+	Popularity int    `json:"popularity,omitempty"`
+	ImageURL   string `json:"image_url,omitempty"`
 }
 
 // Track represents a Spotify track
@@ -21,25 +27,42 @@ type Track struct {
 	URI      string   `json:"uri"`
 	Artists  []Artist `json:"artists"`
 	Duration int      `json:"duration_ms"`
+	// ISRC identifies the recording across every release it appears on, when Spotify reports it
+	ISRC  string `json:"isrc,omitempty"`
+	Album *Album `json:"album,omitempty"`
+}
+
+// Album represents the release a track appears on
+type Album struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	URI         string `json:"uri,omitempty"`
+	AlbumType   string `json:"album_type,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"`
 }
 
 // Playlist represents a Spotify playlist
 type Playlist struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	URI        string `json:"uri"`
-	TrackCount int    `json:"track_count"`
-	EmbedURL   string `json:"embed_url"`
-	IsIncoming bool   `json:"is_incoming"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URI         string `json:"uri"`
+	TrackCount  int    `json:"track_count"`
+	EmbedURL    string `json:"embed_url"`
+	IsIncoming  bool   `json:"is_incoming"`
 }
 
 // SpotifyService defines the interface for Spotify operations
 type SpotifyService interface {
-	SearchArtist(query string) (*Artist, error)
-	GetArtistTopTracks(artistID string) ([]Track, error)
-	GetUserPlaylists(folderName string) ([]Playlist, error)
-	AddTracksToPlaylist(playlistID string, trackIDs []string) error
-	CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]bool, error)
+	SearchArtist(ctx context.Context, query string) (*Artist, error)
+	SearchArtists(ctx context.Context, query string, limit int) ([]Artist, error)
+	GetArtist(ctx context.Context, artistID string) (*Artist, error)
+	GetArtistTracks(ctx context.Context, artistID string, selection types.TrackSelection) ([]Track, error)
+	GetUserPlaylists(ctx context.Context) ([]Playlist, error)
+	AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error
+	CheckTracksInPlaylist(ctx context.Context, playlistID string, trackIDs []string) ([]bool, error)
+	GetPlaylistTracks(ctx context.Context, playlistID string) ([]Track, error)
+	CurrentUserID(ctx context.Context) (string, error)
 }
 
 // AuthResult represents the result of authentication
@@ -0,0 +1,67 @@
+package spotify
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zmb3/spotify/v2"
+)
+
+// defaultMarket is used when no market is configured and the user's country is unknown
+const defaultMarket = spotify.CountryUSA
+
+// resolveMarket returns the market to use for a request. The per-request override
+// wins, then the configured market, then the country from the user's profile.
+func (c *Client) resolveMarket(ctx context.Context, override string) string {
+	if override != "" {
+		return strings.ToUpper(override)
+	}
+	if c.config.Market != "" {
+		return strings.ToUpper(c.config.Market)
+	}
+	if market := c.userMarket(ctx); market != "" {
+		return market
+	}
+	return defaultMarket
+}
+
+// userMarket returns the authenticated user's country from their cached profile
+func (c *Client) userMarket(ctx context.Context) string {
+	user, err := c.profile(ctx)
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithField("default_market", defaultMarket).Warn("Failed to get user country, using default market")
+		return ""
+	}
+
+	market := strings.ToUpper(user.Country)
+	c.logger.WithContext(ctx).WithField("market", market).Debug("Using market from user profile")
+	return market
+}
+
+// playableIn reports whether a track can be played in market. Spotify reports
+// is_playable when a market is passed to the endpoint; otherwise the track's
+// available markets are checked. Tracks without either are assumed playable.
+func playableIn(track *spotify.SimpleTrack, isPlayable *bool, market string) bool {
+	if isPlayable != nil {
+		return *isPlayable
+	}
+	if len(track.AvailableMarkets) > 0 {
+		return slices.Contains(track.AvailableMarkets, market)
+	}
+	return true
+}
+
+// logUnplayable records tracks skipped because they cannot be played in market
+func (c *Client) logUnplayable(ctx context.Context, artistID, market string, skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"artist_id":     artistID,
+		"market":        market,
+		"skipped_count": len(skipped),
+		"skipped":       skipped,
+	}).Debug("Skipped tracks not playable in market")
+}
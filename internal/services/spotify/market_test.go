@@ -0,0 +1,156 @@
+package spotify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/zmb3/spotify/v2"
+)
+
+func TestClient_ResolveMarket(t *testing.T) {
+	tests := []struct {
+		name           string
+		override       string
+		configMarket   string
+		profileCountry string
+		want           string
+		wantProfile    bool
+	}{
+		{
+			name:           "request override wins",
+			override:       "gb",
+			configMarket:   "DE",
+			profileCountry: "SE",
+			want:           "GB",
+		},
+		{
+			name:           "configured market",
+			configMarket:   "de",
+			profileCountry: "SE",
+			want:           "DE",
+		},
+		{
+			name:           "user profile country",
+			profileCountry: "SE",
+			want:           "SE",
+			wantProfile:    true,
+		},
+		{
+			name:        "default when profile is unavailable",
+			want:        defaultMarket,
+			wantProfile: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := newFakeCatalog(t)
+			catalog.profileCountry = tt.profileCountry
+			client := newFakeServerClient(t, catalog)
+			client.config.Market = tt.configMarket
+
+			if got := client.resolveMarket(context.Background(), tt.override); got != tt.want {
+				t.Errorf("resolveMarket(%q) = %q, want %q", tt.override, got, tt.want)
+			}
+			if (catalog.profileCalls > 0) != tt.wantProfile {
+				t.Errorf("resolveMarket() profile requests = %d, want profile lookup %v", catalog.profileCalls, tt.wantProfile)
+			}
+		})
+	}
+}
+
+func TestClient_ResolveMarket_CachesProfileCountry(t *testing.T) {
+	catalog := newFakeCatalog(t)
+	catalog.profileCountry = "NZ"
+	client := newFakeServerClient(t, catalog)
+
+	for range 3 {
+		if got := client.resolveMarket(context.Background(), ""); got != "NZ" {
+			t.Fatalf("resolveMarket() = %q, want %q", got, "NZ")
+		}
+	}
+	if catalog.profileCalls != 1 {
+		t.Errorf("resolveMarket() fetched the user profile %d times, want 1", catalog.profileCalls)
+	}
+
+	// Logging in again replaces the cached country
+	client.setProfile(&spotify.PrivateUser{User: spotify.User{ID: "user-2"}, Country: "au"})
+	if got := client.resolveMarket(context.Background(), ""); got != "AU" {
+		t.Errorf("resolveMarket() after new login = %q, want %q", got, "AU")
+	}
+}
+
+func TestClient_GetArtistTracks_FiltersUnplayable(t *testing.T) {
+	catalog := newFakeCatalog(t)
+	catalog.topTracks[1].unplayable = true
+	catalog.topTracks[3].unplayable = true
+	client := newFakeServerClient(t, catalog)
+
+	tracks, err := client.GetArtistTracks(context.Background(), catalog.artistID, types.TrackSelection{Count: 3, Market: "JP"})
+	if err != nil {
+		t.Fatalf("GetArtistTracks() unexpected error: %v", err)
+	}
+
+	want := "top-0,top-2,top-4"
+	if got := strings.Join(trackIDs(tracks), ","); got != want {
+		t.Errorf("GetArtistTracks() = %s, want %s", got, want)
+	}
+	if catalog.topCountry != "JP" {
+		t.Errorf("top tracks requested for country %q, want %q", catalog.topCountry, "JP")
+	}
+	if catalog.profileCalls != 0 {
+		t.Error("GetArtistTracks() looked up the user profile despite a market override")
+	}
+}
+
+func TestClient_GetArtistTracks_FiltersUnavailableAlbumTracks(t *testing.T) {
+	catalog := newFakeCatalog(t)
+	catalog.profileCountry = "FR"
+	// album-new is the newest release; restrict two of its tracks
+	for i := range catalog.albums[2].tracks {
+		track := &catalog.albums[2].tracks[i]
+		switch track.id {
+		case "new-0":
+			track.markets = []string{"US", "CA"}
+		case "new-1":
+			track.markets = []string{"FR", "US"}
+		case "new-2":
+			track.markets = []string{}
+		}
+	}
+	client := newFakeServerClient(t, catalog)
+
+	tracks, err := client.GetArtistTracks(context.Background(), catalog.artistID, types.TrackSelection{Count: 3, Strategy: types.TrackStrategyLatest})
+	if err != nil {
+		t.Fatalf("GetArtistTracks() unexpected error: %v", err)
+	}
+
+	// new-0 is not available in FR; an empty market list is treated as unknown
+	want := "new-1,new-song-album,new-2"
+	if got := strings.Join(trackIDs(tracks), ","); got != want {
+		t.Errorf("GetArtistTracks() = %s, want %s", got, want)
+	}
+	if catalog.albumsMarket != "FR" {
+		t.Errorf("albums requested for market %q, want %q", catalog.albumsMarket, "FR")
+	}
+}
+
+func TestClient_SearchArtist_UsesMarket(t *testing.T) {
+	var market string
+	client := newFakeServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		market = r.URL.Query().Get("market")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"artists":{"items":[{"id":"artist-1","name":"Artist","uri":"spotify:artist:artist-1"}],"total":1}}`))
+	}))
+	client.config.Market = "br"
+
+	if _, err := client.SearchArtist(context.Background(), "Artist"); err != nil {
+		t.Fatalf("SearchArtist() unexpected error: %v", err)
+	}
+	if market != "BR" {
+		t.Errorf("SearchArtist() market = %q, want %q", market, "BR")
+	}
+}
@@ -0,0 +1,109 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const (
+	// playlistPageSize is the maximum page size of the current user's playlists endpoint
+	playlistPageSize = 50
+	// playlistItemsPageSize is the maximum page size of the playlist items endpoint
+	playlistItemsPageSize = 100
+	// defaultPagePrefetch is the number of page requests kept in flight when none is configured
+	defaultPagePrefetch = 4
+)
+
+// pageFetcher fetches the page starting at offset, returning its items and
+// the total number of items reported by the endpoint
+type pageFetcher[T any] func(ctx context.Context, offset int) ([]T, int, error)
+
+// fetchAllPages walks every page of a paginated endpoint. The first page is
+// fetched on its own to learn the total; the remaining pages are then fetched
+// with at most prefetch requests in flight. Items are returned in page order.
+func fetchAllPages[T any](ctx context.Context, pageSize, prefetch int, fetch pageFetcher[T]) ([]T, error) {
+	if prefetch < 1 {
+		prefetch = 1
+	}
+
+	first, total, err := fetch(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	if total <= len(first) || len(first) == 0 {
+		return first, nil
+	}
+
+	// Pages are addressed by offset so they can be requested out of order. The
+	// first page's length is used as the step in case the endpoint capped the limit.
+	if len(first) < pageSize {
+		pageSize = len(first)
+	}
+	pageCount := (total - 1) / pageSize // excluding the first page
+	pages := make([][]T, pageCount)
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	sem := make(chan struct{}, prefetch)
+
+dispatch:
+	for i := 0; i < pageCount; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-fetchCtx.Done():
+			break dispatch
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			offset := (i + 1) * pageSize
+			items, _, err := fetch(fetchCtx, offset)
+			if err != nil {
+				fail(fmt.Errorf("failed to fetch page at offset %d: %w", offset, err))
+				return
+			}
+			pages[i] = items
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	all := make([]T, 0, total)
+	all = append(all, first...)
+	for _, items := range pages {
+		all = append(all, items...)
+	}
+
+	return all, nil
+}
+
+// pagePrefetch returns the configured number of concurrent page requests
+func (c *Client) pagePrefetch() int {
+	if c.config.PagePrefetch > 0 {
+		return c.config.PagePrefetch
+	}
+	return defaultPagePrefetch
+}
@@ -0,0 +1,329 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/pkg/config"
+	"github.com/toozej/go-listen/pkg/logging"
+	"github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
+)
+
+// pagedInts returns a fetcher serving total sequential ints in pages of pageSize
+func pagedInts(total, pageSize int) pageFetcher[int] {
+	return func(_ context.Context, offset int) ([]int, int, error) {
+		end := min(offset+pageSize, total)
+		items := make([]int, 0, pageSize)
+		for i := offset; i < end; i++ {
+			items = append(items, i)
+		}
+		return items, total, nil
+	}
+}
+
+func TestFetchAllPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		pageSize int
+		prefetch int
+	}{
+		{name: "empty", total: 0, pageSize: 50, prefetch: 4},
+		{name: "single partial page", total: 7, pageSize: 50, prefetch: 4},
+		{name: "exactly one page", total: 50, pageSize: 50, prefetch: 4},
+		{name: "many pages", total: 1234, pageSize: 100, prefetch: 4},
+		{name: "sequential", total: 301, pageSize: 100, prefetch: 1},
+		{name: "prefetch below one", total: 301, pageSize: 100, prefetch: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := fetchAllPages(context.Background(), tt.pageSize, tt.prefetch, pagedInts(tt.total, tt.pageSize))
+			if err != nil {
+				t.Fatalf("fetchAllPages() unexpected error: %v", err)
+			}
+			if len(items) != tt.total {
+				t.Fatalf("fetchAllPages() returned %d items, want %d", len(items), tt.total)
+			}
+			for i, item := range items {
+				if item != i {
+					t.Fatalf("fetchAllPages() item %d = %d, items are out of order", i, item)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchAllPages_CappedPageSize(t *testing.T) {
+	// The endpoint returns fewer items per page than requested
+	items, err := fetchAllPages(context.Background(), 100, 4, pagedInts(95, 20))
+	if err != nil {
+		t.Fatalf("fetchAllPages() unexpected error: %v", err)
+	}
+	if len(items) != 95 {
+		t.Fatalf("fetchAllPages() returned %d items, want 95", len(items))
+	}
+	for i, item := range items {
+		if item != i {
+			t.Fatalf("fetchAllPages() item %d = %d, items are out of order", i, item)
+		}
+	}
+}
+
+func TestFetchAllPages_BoundedPrefetch(t *testing.T) {
+	const prefetch = 3
+
+	var inFlight, maxInFlight atomic.Int32
+	inner := pagedInts(1000, 10)
+	fetch := func(ctx context.Context, offset int) ([]int, int, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return inner(ctx, offset)
+	}
+
+	items, err := fetchAllPages(context.Background(), 10, prefetch, fetch)
+	if err != nil {
+		t.Fatalf("fetchAllPages() unexpected error: %v", err)
+	}
+	if len(items) != 1000 {
+		t.Fatalf("fetchAllPages() returned %d items, want 1000", len(items))
+	}
+	if got := maxInFlight.Load(); got > prefetch {
+		t.Errorf("fetchAllPages() had %d requests in flight, want at most %d", got, prefetch)
+	}
+}
+
+func TestFetchAllPages_Errors(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	t.Run("first page", func(t *testing.T) {
+		fetch := func(context.Context, int) ([]int, int, error) {
+			return nil, 0, errBoom
+		}
+		if _, err := fetchAllPages(context.Background(), 10, 4, fetch); !errors.Is(err, errBoom) {
+			t.Errorf("fetchAllPages() error = %v, want %v", err, errBoom)
+		}
+	})
+
+	t.Run("later page", func(t *testing.T) {
+		inner := pagedInts(500, 10)
+		var calls atomic.Int32
+		fetch := func(ctx context.Context, offset int) ([]int, int, error) {
+			calls.Add(1)
+			if offset == 200 {
+				return nil, 0, errBoom
+			}
+			return inner(ctx, offset)
+		}
+
+		_, err := fetchAllPages(context.Background(), 10, 2, fetch)
+		if !errors.Is(err, errBoom) {
+			t.Fatalf("fetchAllPages() error = %v, want %v", err, errBoom)
+		}
+		if !strings.Contains(err.Error(), "offset 200") {
+			t.Errorf("fetchAllPages() error = %v, want it to name the failing offset", err)
+		}
+		if calls.Load() >= 50 {
+			t.Error("fetchAllPages() kept fetching after a page failed")
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		inner := pagedInts(500, 10)
+		fetch := func(ctx context.Context, offset int) ([]int, int, error) {
+			if offset > 0 {
+				cancel()
+			}
+			return inner(ctx, offset)
+		}
+
+		if _, err := fetchAllPages(ctx, 10, 1, fetch); !errors.Is(err, context.Canceled) {
+			t.Errorf("fetchAllPages() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+// fakePaginatedSpotify serves the playlist endpoints used by the client,
+// honouring limit/offset and capping page sizes like the real API
+type fakePaginatedSpotify struct {
+	playlistCount int
+	itemCount     int
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (f *fakePaginatedSpotify) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests[r.URL.Path]++
+	f.mu.Unlock()
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	page := func(total, maxLimit int, item func(i int) any) map[string]any {
+		if limit <= 0 || limit > maxLimit {
+			limit = 20
+		}
+		items := []any{}
+		for i := offset; i < min(offset+limit, total); i++ {
+			items = append(items, item(i))
+		}
+		return map[string]any{"items": items, "limit": limit, "offset": offset, "total": total}
+	}
+
+	var body any
+	switch {
+	case r.URL.Path == "/me":
+		body = map[string]any{"id": "user-1", "display_name": "Test User"}
+	case r.URL.Path == "/me/playlists":
+		body = page(f.playlistCount, playlistPageSize, func(i int) any {
+			return map[string]any{
+				"id":     fmt.Sprintf("playlist-%d", i),
+				"name":   fmt.Sprintf("Incoming %d", i),
+				"owner":  map[string]any{"id": "user-1"},
+				"tracks": map[string]any{"total": 0},
+			}
+		})
+	case strings.HasPrefix(r.URL.Path, "/playlists/") && strings.HasSuffix(r.URL.Path, "/tracks"):
+		body = page(f.itemCount, playlistItemsPageSize, func(i int) any {
+			return map[string]any{"track": map[string]any{
+				"type": "track",
+				"id":   fmt.Sprintf("track-%d", i),
+				"name": fmt.Sprintf("Track %d", i),
+				"artists": []any{map[string]any{
+					"id":   fmt.Sprintf("artist-%d", i%10),
+					"name": fmt.Sprintf("Artist %d", i%10),
+				}},
+				"album": map[string]any{
+					"id":           fmt.Sprintf("album-%d", i/10),
+					"name":         fmt.Sprintf("Album %d", i/10),
+					"album_type":   "album",
+					"release_date": "2024-01-01",
+				},
+				"external_ids": map[string]any{"isrc": fmt.Sprintf("USABC%07d", i)},
+			}}
+		})
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// newPaginationTestClient creates an authenticated client talking to the fake server
+func newPaginationTestClient(t *testing.T, fake *fakePaginatedSpotify) *Client {
+	t.Helper()
+
+	fake.requests = make(map[string]int)
+	return newFakeServerClient(t, fake)
+}
+
+// newFakeServerClient creates an authenticated client whose API requests are served by handler
+func newFakeServerClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	return &Client{
+		client:     spotify.New(srv.Client(), spotify.WithBaseURL(srv.URL+"/")),
+		config:     config.SpotifyConfig{PagePrefetch: 2},
+		logger:     logging.Wrap(logger),
+		token:      &oauth2.Token{AccessToken: "test", Expiry: time.Now().Add(time.Hour)},
+		isUserAuth: true,
+	}
+}
+
+func TestClient_GetUserPlaylists_AllPages(t *testing.T) {
+	fake := &fakePaginatedSpotify{playlistCount: 173}
+	client := newPaginationTestClient(t, fake)
+
+	playlists, err := client.GetUserPlaylists(context.Background())
+	if err != nil {
+		t.Fatalf("GetUserPlaylists() unexpected error: %v", err)
+	}
+	if len(playlists) != 173 {
+		t.Fatalf("GetUserPlaylists() returned %d playlists, want 173", len(playlists))
+	}
+	if playlists[0].ID != "playlist-0" || playlists[172].ID != "playlist-172" {
+		t.Errorf("GetUserPlaylists() playlists out of order: first %q, last %q", playlists[0].ID, playlists[172].ID)
+	}
+	if got := fake.requests["/me/playlists"]; got != 4 {
+		t.Errorf("GetUserPlaylists() made %d playlist page requests, want 4", got)
+	}
+}
+
+func TestClient_CheckTracksInPlaylist_AllPages(t *testing.T) {
+	fake := &fakePaginatedSpotify{itemCount: 250}
+	client := newPaginationTestClient(t, fake)
+
+	// Tracks beyond the first page of 100 must still be detected as duplicates
+	results, err := client.CheckTracksInPlaylist(context.Background(), "playlist-1", []string{"track-5", "track-150", "track-249", "track-250"})
+	if err != nil {
+		t.Fatalf("CheckTracksInPlaylist() unexpected error: %v", err)
+	}
+
+	want := []bool{true, true, true, false}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("CheckTracksInPlaylist() result %d = %v, want %v", i, results[i], want[i])
+		}
+	}
+	if got := fake.requests["/playlists/playlist-1/tracks"]; got != 3 {
+		t.Errorf("CheckTracksInPlaylist() made %d item page requests, want 3", got)
+	}
+}
+
+func TestClient_GetPlaylistTracks_AllPages(t *testing.T) {
+	fake := &fakePaginatedSpotify{itemCount: 250}
+	client := newPaginationTestClient(t, fake)
+
+	tracks, err := client.GetPlaylistTracks(context.Background(), "playlist-1")
+	if err != nil {
+		t.Fatalf("GetPlaylistTracks() unexpected error: %v", err)
+	}
+	if len(tracks) != 250 {
+		t.Fatalf("GetPlaylistTracks() returned %d tracks, want 250", len(tracks))
+	}
+	if tracks[249].ID != "track-249" {
+		t.Errorf("GetPlaylistTracks() last track = %q, want %q", tracks[249].ID, "track-249")
+	}
+	if len(tracks[249].Artists) != 1 || tracks[249].Artists[0].ID != "artist-9" {
+		t.Errorf("GetPlaylistTracks() last track artists = %+v, want artist-9", tracks[249].Artists)
+	}
+	if tracks[249].ISRC != "USABC0000249" {
+		t.Errorf("GetPlaylistTracks() last track ISRC = %q, want %q", tracks[249].ISRC, "USABC0000249")
+	}
+	if tracks[249].Album == nil || tracks[249].Album.ID != "album-24" || tracks[249].Album.AlbumType != "album" {
+		t.Errorf("GetPlaylistTracks() last track album = %+v, want album-24", tracks[249].Album)
+	}
+	if got := fake.requests["/playlists/playlist-1/tracks"]; got != 3 {
+		t.Errorf("GetPlaylistTracks() made %d item page requests, want 3", got)
+	}
+}
@@ -0,0 +1,46 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// profile returns the authenticated user's profile, fetching it once and caching it until the next login
+func (c *Client) profile(ctx context.Context) (*spotify.PrivateUser, error) {
+	c.profileMu.Lock()
+	defer c.profileMu.Unlock()
+
+	if c.cachedProfile != nil {
+		return c.cachedProfile, nil
+	}
+
+	user, err := c.api().CurrentUser(ctx)
+	if err != nil {
+		return nil, apiError(err)
+	}
+	c.cachedProfile = user
+	return user, nil
+}
+
+// setProfile replaces the cached profile of the authenticated user
+func (c *Client) setProfile(user *spotify.PrivateUser) {
+	c.profileMu.Lock()
+	defer c.profileMu.Unlock()
+
+	c.cachedProfile = user
+}
+
+// CurrentUserID returns the Spotify ID of the authenticated user
+func (c *Client) CurrentUserID(ctx context.Context) (string, error) {
+	if !c.IsAuthenticated() {
+		return "", errNotAuthenticated
+	}
+
+	user, err := c.profile(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %w", err)
+	}
+	return user.ID, nil
+}
@@ -1,25 +1,27 @@
 package spotify
 
 import (
+	"context"
 	"errors"
 
 	"github.com/sirupsen/logrus"
 	"github.com/toozej/go-listen/internal/types"
 	"github.com/toozej/go-listen/pkg/config"
+	"github.com/toozej/go-listen/pkg/logging"
 )
 
 // Service implements the types.SpotifyService interface
 type Service struct {
 	client *Client
-	logger *logrus.Logger
+	logger *logging.Logger
 }
 
-// GetAuthURL returns the URL for user authentication
-func (s *Service) GetAuthURL() string {
+// GetAuthURL returns the URL for user authentication bound to the given state
+func (s *Service) GetAuthURL(state, codeVerifier string) string {
 	if s.client == nil {
 		return ""
 	}
-	return s.client.GetAuthURL()
+	return s.client.GetAuthURL(state, codeVerifier)
 }
 
 // IsAuthenticated returns whether the user is authenticated
@@ -31,11 +33,19 @@ func (s *Service) IsAuthenticated() bool {
 }
 
 // CompleteAuth completes the authentication process
-func (s *Service) CompleteAuth(code, state string) error {
+func (s *Service) CompleteAuth(ctx context.Context, code, codeVerifier string) error {
 	if s.client == nil {
 		return errors.New("spotify client not available")
 	}
-	return s.client.CompleteAuth(code, state)
+	return s.client.CompleteAuth(ctx, code, codeVerifier)
+}
+
+// CurrentUserID returns the Spotify ID of the authenticated user
+func (s *Service) CurrentUserID(ctx context.Context) (string, error) {
+	if s.client == nil {
+		return "", errors.New("spotify client not available")
+	}
+	return s.client.CurrentUserID(ctx)
 }
 
 // NewService creates a new Spotify service that implements types.SpotifyService
@@ -52,31 +62,31 @@ func NewService(cfg config.SpotifyConfig, logger *logrus.Logger) *Service {
 		// Return service with nil client for now - will be handled in actual implementation
 		return &Service{
 			client: nil,
-			logger: logger,
+			logger: logging.Wrap(logger),
 		}
 	}
 
 	return &Service{
 		client: client,
-		logger: logger,
+		logger: logging.Wrap(logger),
 	}
 }
 
 // SearchArtist searches for an artist by name and returns the best match
-func (s *Service) SearchArtist(query string) (*types.Artist, error) {
+func (s *Service) SearchArtist(ctx context.Context, query string) (*types.Artist, error) {
 	if s.client == nil {
 		return nil, errors.New("spotify client not available")
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component": "spotify_service",
 		"operation": "search_artist",
 		"query":     query,
 	}).Debug("Searching for artist")
 
-	artist, err := s.client.SearchArtist(query)
+	artist, err := s.client.SearchArtist(ctx, query)
 	if err != nil {
-		s.logger.WithFields(logrus.Fields{
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
 			"component": "spotify_service",
 			"operation": "search_artist",
 			"query":     query,
@@ -84,7 +94,7 @@ func (s *Service) SearchArtist(query string) (*types.Artist, error) {
 		return nil, err
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":      "spotify_service",
 		"operation":      "search_artist",
 		"query":          query,
@@ -92,89 +102,120 @@ func (s *Service) SearchArtist(query string) (*types.Artist, error) {
 		"artist_id":      artist.ID,
 	}).Info("Artist search completed successfully")
 
-	return &types.Artist{
-		ID:     artist.ID,
-		Name:   artist.Name,
-		URI:    artist.URI,
-		Genres: artist.Genres,
-	}, nil
+	converted := toTypesArtist(*artist)
+	return &converted, nil
 }
 
-// GetArtistTopTracks retrieves the top 5 tracks for an artist
-func (s *Service) GetArtistTopTracks(artistID string) ([]types.Track, error) {
+// SearchArtists searches for an artist by name and returns up to limit candidates,
+// most relevant first
+func (s *Service) SearchArtists(ctx context.Context, query string, limit int) ([]types.Artist, error) {
 	if s.client == nil {
 		return nil, errors.New("spotify client not available")
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component": "spotify_service",
-		"operation": "get_top_tracks",
-		"artist_id": artistID,
-	}).Debug("Retrieving artist top tracks")
+		"operation": "search_artists",
+		"query":     query,
+		"limit":     limit,
+	}).Debug("Searching for artist candidates")
+
+	artists, err := s.client.SearchArtists(ctx, query, limit)
+	if err != nil {
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"component": "spotify_service",
+			"operation": "search_artists",
+			"query":     query,
+		}).WithError(err).Error("Failed to search for artist candidates")
+		return nil, err
+	}
+
+	converted := make([]types.Artist, len(artists))
+	for i, artist := range artists {
+		converted[i] = toTypesArtist(artist)
+	}
+	return converted, nil
+}
+
+// GetArtist looks up an artist by Spotify ID
+func (s *Service) GetArtist(ctx context.Context, artistID string) (*types.Artist, error) {
+	if s.client == nil {
+		return nil, errors.New("spotify client not available")
+	}
+
+	artist, err := s.client.GetArtist(ctx, artistID)
+	if err != nil {
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"component": "spotify_service",
+			"operation": "get_artist",
+			"artist_id": artistID,
+		}).WithError(err).Error("Failed to get artist")
+		return nil, err
+	}
 
-	tracks, err := s.client.GetArtistTopTracks(artistID)
+	converted := toTypesArtist(*artist)
+	return &converted, nil
+}
+
+// GetArtistTracks retrieves tracks for an artist according to the track selection
+func (s *Service) GetArtistTracks(ctx context.Context, artistID string, selection types.TrackSelection) ([]types.Track, error) {
+	if s.client == nil {
+		return nil, errors.New("spotify client not available")
+	}
+
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":   "spotify_service",
+		"operation":   "get_artist_tracks",
+		"artist_id":   artistID,
+		"track_count": selection.Count,
+		"strategy":    selection.Strategy,
+	}).Debug("Retrieving artist tracks")
+
+	tracks, err := s.client.GetArtistTracks(ctx, artistID, selection)
 	if err != nil {
-		s.logger.WithFields(logrus.Fields{
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
 			"component": "spotify_service",
-			"operation": "get_top_tracks",
+			"operation": "get_artist_tracks",
 			"artist_id": artistID,
-		}).WithError(err).Error("Failed to retrieve artist top tracks")
+			"strategy":  selection.Strategy,
+		}).WithError(err).Error("Failed to retrieve artist tracks")
 		return nil, err
 	}
 
-	serverTracks := make([]types.Track, len(tracks))
+	serverTracks := toTypesTracks(tracks)
 	trackNames := make([]string, len(tracks))
 	for i, track := range tracks {
-		// Convert artists
-		artists := make([]types.Artist, len(track.Artists))
-		for j, artist := range track.Artists {
-			artists[j] = types.Artist{
-				ID:     artist.ID,
-				Name:   artist.Name,
-				URI:    artist.URI,
-				Genres: artist.Genres,
-			}
-		}
-
-		serverTracks[i] = types.Track{
-			ID:       track.ID,
-			Name:     track.Name,
-			URI:      track.URI,
-			Artists:  artists,
-			Duration: track.Duration,
-		}
 		trackNames[i] = track.Name
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":   "spotify_service",
-		"operation":   "get_top_tracks",
+		"operation":   "get_artist_tracks",
 		"artist_id":   artistID,
+		"strategy":    selection.Strategy,
 		"track_count": len(serverTracks),
 		"track_names": trackNames,
-	}).Info("Retrieved artist top tracks successfully")
+	}).Info("Retrieved artist tracks successfully")
 
 	return serverTracks, nil
 }
 
-// GetUserPlaylists retrieves playlists from a specific folder
-func (s *Service) GetUserPlaylists(folderName string) ([]types.Playlist, error) {
+// GetUserPlaylists retrieves the playlists owned by the user
+func (s *Service) GetUserPlaylists(ctx context.Context) ([]types.Playlist, error) {
 	if s.client == nil {
 		return nil, errors.New("spotify client not available")
 	}
 
-	s.logger.WithFields(logrus.Fields{
-		"component":   "spotify_service",
-		"operation":   "get_playlists",
-		"folder_name": folderName,
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component": "spotify_service",
+		"operation": "get_playlists",
 	}).Debug("Retrieving user playlists")
 
-	playlists, err := s.client.GetUserPlaylists(folderName)
+	playlists, err := s.client.GetUserPlaylists(ctx)
 	if err != nil {
-		s.logger.WithFields(logrus.Fields{
-			"component":   "spotify_service",
-			"operation":   "get_playlists",
-			"folder_name": folderName,
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"component": "spotify_service",
+			"operation": "get_playlists",
 		}).WithError(err).Error("Failed to retrieve user playlists")
 		return nil, err
 	}
@@ -183,20 +224,20 @@ func (s *Service) GetUserPlaylists(folderName string) ([]types.Playlist, error)
 	playlistNames := make([]string, len(playlists))
 	for i, playlist := range playlists {
 		serverPlaylists[i] = types.Playlist{
-			ID:         playlist.ID,
-			Name:       playlist.Name,
-			URI:        playlist.URI,
-			TrackCount: playlist.TrackCount,
-			EmbedURL:   playlist.EmbedURL,
-			IsIncoming: playlist.IsIncoming,
+			ID:          playlist.ID,
+			Name:        playlist.Name,
+			Description: playlist.Description,
+			URI:         playlist.URI,
+			TrackCount:  playlist.TrackCount,
+			EmbedURL:    playlist.EmbedURL,
+			IsIncoming:  playlist.IsIncoming,
 		}
 		playlistNames[i] = playlist.Name
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":      "spotify_service",
 		"operation":      "get_playlists",
-		"folder_name":    folderName,
 		"playlist_count": len(serverPlaylists),
 		"playlist_names": playlistNames,
 	}).Info("Retrieved user playlists successfully")
@@ -205,12 +246,12 @@ func (s *Service) GetUserPlaylists(folderName string) ([]types.Playlist, error)
 }
 
 // AddTracksToPlaylist adds tracks to a specified playlist
-func (s *Service) AddTracksToPlaylist(playlistID string, trackIDs []string) error {
+func (s *Service) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
 	if s.client == nil {
 		return errors.New("spotify client not available")
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":   "spotify_service",
 		"operation":   "add_tracks",
 		"playlist_id": playlistID,
@@ -218,9 +259,9 @@ func (s *Service) AddTracksToPlaylist(playlistID string, trackIDs []string) erro
 		"track_ids":   trackIDs,
 	}).Debug("Adding tracks to playlist")
 
-	err := s.client.AddTracksToPlaylist(playlistID, trackIDs)
+	err := s.client.AddTracksToPlaylist(ctx, playlistID, trackIDs)
 	if err != nil {
-		s.logger.WithFields(logrus.Fields{
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
 			"component":   "spotify_service",
 			"operation":   "add_tracks",
 			"playlist_id": playlistID,
@@ -229,7 +270,7 @@ func (s *Service) AddTracksToPlaylist(playlistID string, trackIDs []string) erro
 		return err
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":   "spotify_service",
 		"operation":   "add_tracks",
 		"playlist_id": playlistID,
@@ -240,21 +281,21 @@ func (s *Service) AddTracksToPlaylist(playlistID string, trackIDs []string) erro
 }
 
 // CheckTracksInPlaylist checks if tracks already exist in a playlist
-func (s *Service) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]bool, error) {
+func (s *Service) CheckTracksInPlaylist(ctx context.Context, playlistID string, trackIDs []string) ([]bool, error) {
 	if s.client == nil {
 		return nil, errors.New("spotify client not available")
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":   "spotify_service",
 		"operation":   "check_tracks",
 		"playlist_id": playlistID,
 		"track_count": len(trackIDs),
 	}).Debug("Checking for duplicate tracks in playlist")
 
-	results, err := s.client.CheckTracksInPlaylist(playlistID, trackIDs)
+	results, err := s.client.CheckTracksInPlaylist(ctx, playlistID, trackIDs)
 	if err != nil {
-		s.logger.WithFields(logrus.Fields{
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
 			"component":   "spotify_service",
 			"operation":   "check_tracks",
 			"playlist_id": playlistID,
@@ -270,7 +311,7 @@ func (s *Service) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([
 		}
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"component":       "spotify_service",
 		"operation":       "check_tracks",
 		"playlist_id":     playlistID,
@@ -280,3 +321,78 @@ func (s *Service) CheckTracksInPlaylist(playlistID string, trackIDs []string) ([
 
 	return results, nil
 }
+
+// GetPlaylistTracks retrieves every track in a playlist
+func (s *Service) GetPlaylistTracks(ctx context.Context, playlistID string) ([]types.Track, error) {
+	if s.client == nil {
+		return nil, errors.New("spotify client not available")
+	}
+
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":   "spotify_service",
+		"operation":   "get_playlist_tracks",
+		"playlist_id": playlistID,
+	}).Debug("Retrieving playlist tracks")
+
+	tracks, err := s.client.GetPlaylistTracks(ctx, playlistID)
+	if err != nil {
+		s.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"component":   "spotify_service",
+			"operation":   "get_playlist_tracks",
+			"playlist_id": playlistID,
+		}).WithError(err).Error("Failed to retrieve playlist tracks")
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":   "spotify_service",
+		"operation":   "get_playlist_tracks",
+		"playlist_id": playlistID,
+		"track_count": len(tracks),
+	}).Info("Retrieved playlist tracks successfully")
+
+	return toTypesTracks(tracks), nil
+}
+
+// toTypesArtist converts a Spotify client artist to the shared artist type
+func toTypesArtist(artist Artist) types.Artist {
+	return types.Artist{
+		ID:         artist.ID,
+		Name:       artist.Name,
+		URI:        artist.URI,
+		Genres:     artist.Genres,
+		Followers:  artist.Followers,
+		Popularity: artist.Popularity,
+		ImageURL:   artist.ImageURL,
+	}
+}
+
+// toTypesTracks converts Spotify client tracks to the shared track type
+func toTypesTracks(tracks []Track) []types.Track {
+	converted := make([]types.Track, len(tracks))
+	for i, track := range tracks {
+		artists := make([]types.Artist, len(track.Artists))
+		for j, artist := range track.Artists {
+			artists[j] = types.Artist{
+				ID:     artist.ID,
+				Name:   artist.Name,
+				URI:    artist.URI,
+				Genres: artist.Genres,
+			}
+		}
+
+		converted[i] = types.Track{
+			ID:       track.ID,
+			Name:     track.Name,
+			URI:      track.URI,
+			Artists:  artists,
+			Duration: track.Duration,
+			ISRC:     track.ISRC,
+		}
+		if track.Album != nil {
+			album := types.Album(*track.Album)
+			converted[i].Album = &album
+		}
+	}
+	return converted
+}
@@ -1,10 +1,12 @@
 package spotify
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/types"
 	"github.com/toozej/go-listen/pkg/config"
 )
 
@@ -63,7 +65,7 @@ func testCompleteArtistWorkflow(t *testing.T, service *Service) {
 	}
 
 	// Test 1: Search for artist (should fail with invalid credentials)
-	artist, err := service.SearchArtist("Taylor Swift")
+	artist, err := service.SearchArtist(context.Background(), "Taylor Swift")
 	if err == nil {
 		t.Error("Expected error with invalid credentials")
 	}
@@ -72,7 +74,7 @@ func testCompleteArtistWorkflow(t *testing.T, service *Service) {
 	}
 
 	// Test 2: Get artist top tracks (should fail with invalid credentials)
-	tracks, err := service.GetArtistTopTracks("test-artist-id")
+	tracks, err := service.GetArtistTracks(context.Background(), "test-artist-id", types.DefaultTrackSelection())
 	if err == nil {
 		t.Error("Expected error with invalid credentials")
 	}
@@ -81,7 +83,7 @@ func testCompleteArtistWorkflow(t *testing.T, service *Service) {
 	}
 
 	// Test 3: Get user playlists (should fail with invalid credentials)
-	playlists, err := service.GetUserPlaylists("Incoming")
+	playlists, err := service.GetUserPlaylists(context.Background())
 	if err == nil {
 		t.Error("Expected error with invalid credentials")
 	}
@@ -90,13 +92,13 @@ func testCompleteArtistWorkflow(t *testing.T, service *Service) {
 	}
 
 	// Test 4: Add tracks to playlist (should fail with invalid credentials)
-	err = service.AddTracksToPlaylist("test-playlist", []string{"track1", "track2"})
+	err = service.AddTracksToPlaylist(context.Background(), "test-playlist", []string{"track1", "track2"})
 	if err == nil {
 		t.Error("Expected error with invalid credentials")
 	}
 
 	// Test 5: Check tracks in playlist (should fail with invalid credentials)
-	results, err := service.CheckTracksInPlaylist("test-playlist", []string{"track1", "track2"})
+	results, err := service.CheckTracksInPlaylist(context.Background(), "test-playlist", []string{"track1", "track2"})
 	if err == nil {
 		t.Error("Expected error with invalid credentials")
 	}
@@ -115,27 +117,27 @@ func testErrorHandlingWorkflow(t *testing.T, service *Service) {
 	}
 
 	// Test empty/invalid inputs
-	_, err := service.SearchArtist("")
+	_, err := service.SearchArtist(context.Background(), "")
 	if err == nil {
 		t.Error("Expected error with empty artist name")
 	}
 
-	_, err = service.GetArtistTopTracks("")
+	_, err = service.GetArtistTracks(context.Background(), "", types.DefaultTrackSelection())
 	if err == nil {
 		t.Error("Expected error with empty artist ID")
 	}
 
-	_, err = service.GetUserPlaylists("")
+	_, err = service.GetUserPlaylists(context.Background())
 	if err == nil {
 		t.Error("Expected error with empty folder name")
 	}
 
-	err = service.AddTracksToPlaylist("", []string{})
+	err = service.AddTracksToPlaylist(context.Background(), "", []string{})
 	if err == nil {
 		t.Error("Expected error with empty playlist ID and tracks")
 	}
 
-	_, err = service.CheckTracksInPlaylist("", []string{})
+	_, err = service.CheckTracksInPlaylist(context.Background(), "", []string{})
 	if err != nil {
 		t.Error("CheckTracksInPlaylist should handle empty tracks gracefully")
 	}
@@ -152,7 +154,7 @@ func testRateLimitingScenarios(t *testing.T, service *Service) {
 	}
 
 	for i := 0; i < 5; i++ {
-		_, err := service.SearchArtist("test artist")
+		_, err := service.SearchArtist(context.Background(), "test artist")
 		if err == nil {
 			t.Error("Expected error with invalid credentials")
 		}
@@ -181,27 +183,27 @@ func TestSpotifyService_DataTransformation(t *testing.T) {
 	}
 
 	// Test service methods with nil client
-	_, err := service.SearchArtist("test")
+	_, err := service.SearchArtist(context.Background(), "test")
 	if err == nil {
 		t.Error("Expected error with nil client")
 	}
 
-	_, err = service.GetArtistTopTracks("test")
+	_, err = service.GetArtistTracks(context.Background(), "test", types.DefaultTrackSelection())
 	if err == nil {
 		t.Error("Expected error with nil client")
 	}
 
-	_, err = service.GetUserPlaylists("test")
+	_, err = service.GetUserPlaylists(context.Background())
 	if err == nil {
 		t.Error("Expected error with nil client")
 	}
 
-	err = service.AddTracksToPlaylist("test", []string{"track1"})
+	err = service.AddTracksToPlaylist(context.Background(), "test", []string{"track1"})
 	if err == nil {
 		t.Error("Expected error with nil client")
 	}
 
-	_, err = service.CheckTracksInPlaylist("test", []string{"track1"})
+	_, err = service.CheckTracksInPlaylist(context.Background(), "test", []string{"track1"})
 	if err == nil {
 		t.Error("Expected error with nil client")
 	}
@@ -226,7 +228,7 @@ func TestSpotifyService_LoggingBehavior(t *testing.T) {
 	}
 
 	// Make a call that will generate logs
-	_, err := service.SearchArtist("test artist")
+	_, err := service.SearchArtist(context.Background(), "test artist")
 	if err == nil {
 		t.Error("Expected error with invalid credentials")
 	}
@@ -320,7 +322,7 @@ func TestSpotifyService_TypeConversions(t *testing.T) {
 	}
 
 	// Test that methods return proper types even on error
-	artist, err := service.SearchArtist("test")
+	artist, err := service.SearchArtist(context.Background(), "test")
 	if err == nil {
 		t.Error("Expected error")
 	}
@@ -328,7 +330,7 @@ func TestSpotifyService_TypeConversions(t *testing.T) {
 		t.Error("Expected nil artist on error")
 	}
 
-	tracks, err := service.GetArtistTopTracks("test")
+	tracks, err := service.GetArtistTracks(context.Background(), "test", types.DefaultTrackSelection())
 	if err == nil {
 		t.Error("Expected error")
 	}
@@ -336,7 +338,7 @@ func TestSpotifyService_TypeConversions(t *testing.T) {
 		t.Error("Expected nil tracks on error")
 	}
 
-	playlists, err := service.GetUserPlaylists("test")
+	playlists, err := service.GetUserPlaylists(context.Background())
 	if err == nil {
 		t.Error("Expected error")
 	}
@@ -344,7 +346,7 @@ func TestSpotifyService_TypeConversions(t *testing.T) {
 		t.Error("Expected nil playlists on error")
 	}
 
-	results, err := service.CheckTracksInPlaylist("test", []string{"track1"})
+	results, err := service.CheckTracksInPlaylist(context.Background(), "test", []string{"track1"})
 	if err == nil {
 		t.Error("Expected error")
 	}
@@ -377,13 +379,13 @@ func TestSpotifyService_ConcurrentAccess(t *testing.T) {
 			// Each goroutine makes different calls
 			switch id % 4 {
 			case 0:
-				_, _ = service.SearchArtist("test")
+				_, _ = service.SearchArtist(context.Background(), "test")
 			case 1:
-				_, _ = service.GetArtistTopTracks("test")
+				_, _ = service.GetArtistTracks(context.Background(), "test", types.DefaultTrackSelection())
 			case 2:
-				_, _ = service.GetUserPlaylists("test")
+				_, _ = service.GetUserPlaylists(context.Background())
 			case 3:
-				_ = service.AddTracksToPlaylist("test", []string{"track1"})
+				_ = service.AddTracksToPlaylist(context.Background(), "test", []string{"track1"})
 			}
 		}(i)
 	}
@@ -416,21 +418,21 @@ func TestSpotifyService_EdgeCases(t *testing.T) {
 		longString = longString[:i] + "a" + longString[i+1:]
 	}
 
-	_, err := service.SearchArtist(longString)
+	_, err := service.SearchArtist(context.Background(), longString)
 	if err == nil {
 		t.Error("Expected error with very long artist name")
 	}
 
 	// Test with special characters
 	specialChars := "!@#$%^&*()_+-=[]{}|;':\",./<>?"
-	_, err = service.SearchArtist(specialChars)
+	_, err = service.SearchArtist(context.Background(), specialChars)
 	if err == nil {
 		t.Error("Expected error with special characters")
 	}
 
 	// Test with unicode characters
 	unicode := "测试艺术家"
-	_, err = service.SearchArtist(unicode)
+	_, err = service.SearchArtist(context.Background(), unicode)
 	if err == nil {
 		t.Error("Expected error with unicode characters")
 	}
@@ -441,7 +443,7 @@ func TestSpotifyService_EdgeCases(t *testing.T) {
 		largeTracks[i] = "track" + string(rune(i))
 	}
 
-	err = service.AddTracksToPlaylist("test", largeTracks)
+	err = service.AddTracksToPlaylist(context.Background(), "test", largeTracks)
 	if err == nil {
 		t.Error("Expected error with very large track list")
 	}
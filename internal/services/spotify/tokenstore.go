@@ -0,0 +1,198 @@
+package spotify
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/toozej/go-listen/internal/fileutil"
+	"github.com/toozej/go-listen/pkg/config"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoToken is returned by a TokenStore when no token has been saved yet
+var ErrNoToken = errors.New("no stored token")
+
+// TokenStore persists OAuth tokens so authentication survives process restarts
+type TokenStore interface {
+	// Load returns the stored token, or ErrNoToken if none has been saved
+	Load() (*oauth2.Token, error)
+	// Save replaces the stored token
+	Save(token *oauth2.Token) error
+	// Clear removes the stored token
+	Clear() error
+}
+
+// NewTokenStore creates the token store selected by the Spotify configuration.
+// An empty store type falls back to an in-memory store.
+func NewTokenStore(cfg config.SpotifyConfig) (TokenStore, error) {
+	switch strings.ToLower(cfg.TokenStore) {
+	case "", "memory":
+		return NewMemoryTokenStore(), nil
+	case "file":
+		key := cfg.TokenEncryptionKey
+		if key == "" {
+			// Fall back to the client secret so a minimal configuration still encrypts at rest
+			key = cfg.ClientSecret
+		}
+		return NewFileTokenStore(cfg.TokenFile, key)
+	default:
+		return nil, fmt.Errorf("unknown token store type: %s", cfg.TokenStore)
+	}
+}
+
+// MemoryTokenStore keeps the token in process memory only
+type MemoryTokenStore struct {
+	mu    sync.RWMutex
+	token *oauth2.Token
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+// Load returns a copy of the stored token
+func (m *MemoryTokenStore) Load() (*oauth2.Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.token == nil {
+		return nil, ErrNoToken
+	}
+	token := *m.token
+	return &token, nil
+}
+
+// Save stores a copy of the token
+func (m *MemoryTokenStore) Save(token *oauth2.Token) error {
+	if token == nil {
+		return fmt.Errorf("cannot save nil token")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *token
+	m.token = &stored
+	return nil
+}
+
+// Clear removes the stored token
+func (m *MemoryTokenStore) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.token = nil
+	return nil
+}
+
+// FileTokenStore persists the token to disk encrypted with AES-256-GCM
+type FileTokenStore struct {
+	mu   sync.Mutex
+	path string
+	aead cipher.AEAD
+}
+
+// NewFileTokenStore creates a file-backed token store at path, encrypting with a key derived from secret
+func NewFileTokenStore(path, secret string) (*FileTokenStore, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("token file path is required")
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("token encryption key is required")
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token cipher: %w", err)
+	}
+
+	return &FileTokenStore{
+		path: filepath.Clean(path),
+		aead: aead,
+	}, nil
+}
+
+// Load reads and decrypts the token file
+func (f *FileTokenStore) Load() (*oauth2.Token, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoToken
+		}
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	nonceSize := f.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("token file is corrupt")
+	}
+
+	plaintext, err := f.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token file (was the encryption key changed?): %w", err)
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(plaintext, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// Save encrypts the token and atomically replaces the token file
+func (f *FileTokenStore) Save(token *oauth2.Token) error {
+	if token == nil {
+		return fmt.Errorf("cannot save nil token")
+	}
+
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	nonce := make([]byte, f.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data := f.aead.Seal(nonce, nonce, plaintext, nil)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := fileutil.WriteAtomic(f.path, data); err != nil {
+		return fmt.Errorf("failed to save token file: %w", err)
+	}
+
+	return nil
+}
+
+// Clear deletes the token file
+func (f *FileTokenStore) Clear() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove token file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package spotify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/pkg/config"
+	"golang.org/x/oauth2"
+)
+
+func testToken() *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  "access-token",
+		TokenType:    "Bearer",
+		RefreshToken: "refresh-token",
+		Expiry:       time.Now().Add(time.Hour).Round(time.Second),
+	}
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	store := NewMemoryTokenStore()
+
+	if _, err := store.Load(); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("Load() on empty store error = %v, want ErrNoToken", err)
+	}
+
+	token := testToken()
+	if err := store.Save(token); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	// Mutating the caller's token must not affect the stored copy
+	token.AccessToken = "mutated"
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if loaded.AccessToken != "access-token" {
+		t.Errorf("Load() access token = %q, want %q", loaded.AccessToken, "access-token")
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear() unexpected error: %v", err)
+	}
+	if _, err := store.Load(); !errors.Is(err, ErrNoToken) {
+		t.Errorf("Load() after Clear() error = %v, want ErrNoToken", err)
+	}
+
+	if err := store.Save(nil); err == nil {
+		t.Error("Save(nil) expected error but got none")
+	}
+}
+
+func TestFileTokenStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "token.enc")
+
+	store, err := NewFileTokenStore(path, "secret")
+	if err != nil {
+		t.Fatalf("NewFileTokenStore() unexpected error: %v", err)
+	}
+
+	if _, err := store.Load(); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("Load() with missing file error = %v, want ErrNoToken", err)
+	}
+
+	token := testToken()
+	if err := store.Save(token); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("token file not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		t.Errorf("token file permissions = %o, want no group/other access", perm)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read token file: %v", err)
+	}
+	if bytes.Contains(raw, []byte("refresh-token")) {
+		t.Error("token file contains plaintext refresh token")
+	}
+
+	// A new store with the same key must be able to read the token back
+	reopened, err := NewFileTokenStore(path, "secret")
+	if err != nil {
+		t.Fatalf("NewFileTokenStore() unexpected error: %v", err)
+	}
+	loaded, err := reopened.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if loaded.AccessToken != token.AccessToken || loaded.RefreshToken != token.RefreshToken {
+		t.Errorf("Load() = %+v, want %+v", loaded, token)
+	}
+	if !loaded.Expiry.Equal(token.Expiry) {
+		t.Errorf("Load() expiry = %v, want %v", loaded.Expiry, token.Expiry)
+	}
+
+	if err := reopened.Clear(); err != nil {
+		t.Fatalf("Clear() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Clear() did not remove token file")
+	}
+	if err := reopened.Clear(); err != nil {
+		t.Errorf("Clear() on missing file unexpected error: %v", err)
+	}
+}
+
+func TestFileTokenStore_WrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.enc")
+
+	store, err := NewFileTokenStore(path, "secret")
+	if err != nil {
+		t.Fatalf("NewFileTokenStore() unexpected error: %v", err)
+	}
+	if err := store.Save(testToken()); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	other, err := NewFileTokenStore(path, "different-secret")
+	if err != nil {
+		t.Fatalf("NewFileTokenStore() unexpected error: %v", err)
+	}
+	_, err = other.Load()
+	if err == nil {
+		t.Fatal("Load() with wrong key expected error but got none")
+	}
+	if errors.Is(err, ErrNoToken) {
+		t.Error("Load() with wrong key should not report ErrNoToken")
+	}
+}
+
+func TestNewFileTokenStore_Validation(t *testing.T) {
+	if _, err := NewFileTokenStore("", "secret"); err == nil {
+		t.Error("NewFileTokenStore() with empty path expected error but got none")
+	}
+	if _, err := NewFileTokenStore("token.enc", ""); err == nil {
+		t.Error("NewFileTokenStore() with empty secret expected error but got none")
+	}
+}
+
+func TestNewTokenStore(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   config.SpotifyConfig
+		wantType string
+		wantErr  bool
+	}{
+		{
+			name:     "empty defaults to memory",
+			config:   config.SpotifyConfig{},
+			wantType: "memory",
+		},
+		{
+			name:     "memory",
+			config:   config.SpotifyConfig{TokenStore: "memory"},
+			wantType: "memory",
+		},
+		{
+			name: "file falls back to client secret",
+			config: config.SpotifyConfig{
+				TokenStore:   "file",
+				TokenFile:    filepath.Join(t.TempDir(), "token.enc"),
+				ClientSecret: "client-secret",
+			},
+			wantType: "file",
+		},
+		{
+			name: "file without any key",
+			config: config.SpotifyConfig{
+				TokenStore: "file",
+				TokenFile:  filepath.Join(t.TempDir(), "token.enc"),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			config:  config.SpotifyConfig{TokenStore: "redis"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewTokenStore(tt.config)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("NewTokenStore() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTokenStore() unexpected error: %v", err)
+			}
+
+			switch tt.wantType {
+			case "memory":
+				if _, ok := store.(*MemoryTokenStore); !ok {
+					t.Errorf("NewTokenStore() = %T, want *MemoryTokenStore", store)
+				}
+			case "file":
+				if _, ok := store.(*FileTokenStore); !ok {
+					t.Errorf("NewTokenStore() = %T, want *FileTokenStore", store)
+				}
+			}
+		})
+	}
+}
+
+func TestNewClientWithTokenStore_RestoresToken(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	cfg := config.SpotifyConfig{
+		ClientID:     "test-id",
+		ClientSecret: "test-secret",
+		RedirectURL:  "http://127.0.0.1:8080/callback",
+	}
+
+	t.Run("no stored token", func(t *testing.T) {
+		client, err := NewClientWithTokenStore(cfg, NewMemoryTokenStore(), logger)
+		if err != nil {
+			t.Fatalf("NewClientWithTokenStore() unexpected error: %v", err)
+		}
+		if client.IsAuthenticated() {
+			t.Error("client should not be authenticated without a stored token")
+		}
+	})
+
+	t.Run("stored token", func(t *testing.T) {
+		store := NewMemoryTokenStore()
+		if err := store.Save(testToken()); err != nil {
+			t.Fatalf("Save() unexpected error: %v", err)
+		}
+
+		client, err := NewClientWithTokenStore(cfg, store, logger)
+		if err != nil {
+			t.Fatalf("NewClientWithTokenStore() unexpected error: %v", err)
+		}
+		if !client.IsAuthenticated() {
+			t.Error("client should be authenticated from the stored token")
+		}
+
+		// A fresh token does not need refreshing and must be left untouched
+		if err := client.RefreshToken(context.Background()); err != nil {
+			t.Errorf("RefreshToken() unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unreadable stored token", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token.enc")
+		if err := os.WriteFile(path, []byte("garbage"), 0o600); err != nil {
+			t.Fatalf("failed to write token file: %v", err)
+		}
+		store, err := NewFileTokenStore(path, "secret")
+		if err != nil {
+			t.Fatalf("NewFileTokenStore() unexpected error: %v", err)
+		}
+
+		client, err := NewClientWithTokenStore(cfg, store, logger)
+		if err != nil {
+			t.Fatalf("NewClientWithTokenStore() unexpected error: %v", err)
+		}
+		if client.IsAuthenticated() {
+			t.Error("client should not be authenticated from a corrupt token file")
+		}
+	})
+}
@@ -0,0 +1,314 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/zmb3/spotify/v2"
+)
+
+const (
+	// artistAlbumsPageSize is the maximum page size of the artist albums endpoint
+	artistAlbumsPageSize = 50
+	// albumTracksPageSize is the maximum page size of the album tracks endpoint
+	albumTracksPageSize = 50
+	// randomPoolFactor controls how many candidate tracks are gathered per requested
+	// track before sampling, trading catalog coverage against API calls
+	randomPoolFactor = 3
+	// trackLookupBatchSize is the maximum number of IDs accepted by the several tracks endpoint
+	trackLookupBatchSize = 50
+)
+
+// GetArtistTracks retrieves up to selection.Count tracks for an artist using the selection's strategy
+func (c *Client) GetArtistTracks(ctx context.Context, artistID string, selection types.TrackSelection) ([]Track, error) {
+	selection = selection.WithDefaults()
+	if err := selection.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !c.IsAuthenticated() {
+		return nil, errNotAuthenticated
+	}
+
+	if err := c.RefreshToken(ctx); err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	market := c.resolveMarket(ctx, selection.Market)
+
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"artist_id":   artistID,
+		"track_count": selection.Count,
+		"strategy":    selection.Strategy,
+		"market":      market,
+	}).Debug("Getting artist tracks using Spotify library")
+
+	var (
+		tracks []Track
+		err    error
+	)
+	switch selection.Strategy {
+	case types.TrackStrategyLatest:
+		tracks, err = c.latestTracks(ctx, artistID, selection.Count, market)
+	case types.TrackStrategyRandom:
+		tracks, err = c.randomTracks(ctx, artistID, selection.Count, market)
+	default:
+		tracks, err = c.topTracks(ctx, artistID, selection.Count, market)
+	}
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"artist_id": artistID,
+			"strategy":  selection.Strategy,
+		}).Error("Failed to get artist tracks")
+		return nil, fmt.Errorf("failed to get %s tracks for artist %s: %w", selection.Strategy, artistID, err)
+	}
+
+	trackNames := make([]string, len(tracks))
+	for i := range tracks {
+		trackNames[i] = tracks[i].Name
+	}
+
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"artist_id":    artistID,
+		"strategy":     selection.Strategy,
+		"market":       market,
+		"tracks_found": len(tracks),
+		"track_names":  trackNames,
+	}).Info("Retrieved artist tracks using Spotify library")
+
+	return tracks, nil
+}
+
+// topTracks returns the artist's most popular tracks that are playable in market
+func (c *Client) topTracks(ctx context.Context, artistID string, count int, market string) ([]Track, error) {
+	topTracks, err := c.api().GetArtistsTopTracks(ctx, spotify.ID(artistID), market)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	tracks := make([]Track, 0, min(count, len(topTracks)))
+	var skipped []string
+	for i := range topTracks {
+		if len(tracks) == count {
+			break
+		}
+		if !playableIn(&topTracks[i].SimpleTrack, topTracks[i].IsPlayable, market) {
+			skipped = append(skipped, topTracks[i].Name)
+			continue
+		}
+		tracks = append(tracks, convertFullTrack(&topTracks[i]))
+	}
+	c.logUnplayable(ctx, artistID, market, skipped)
+
+	return tracks, nil
+}
+
+// latestTracks returns tracks from the artist's releases, newest release first
+func (c *Client) latestTracks(ctx context.Context, artistID string, count int, market string) ([]Track, error) {
+	albums, err := c.allArtistAlbums(ctx, artistID, market)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(albums, func(i, j int) bool {
+		return albums[i].ReleaseDateTime().After(albums[j].ReleaseDateTime())
+	})
+
+	tracks, err := c.collectAlbumTracks(ctx, artistID, albums, count, market)
+	if err != nil {
+		return nil, err
+	}
+	c.fillISRCs(ctx, tracks)
+	return tracks, nil
+}
+
+// randomTracks returns a random sample of tracks from across the artist's catalog
+func (c *Client) randomTracks(ctx context.Context, artistID string, count int, market string) ([]Track, error) {
+	albums, err := c.allArtistAlbums(ctx, artistID, market)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G404 -- track sampling is not security sensitive
+	rand.Shuffle(len(albums), func(i, j int) { albums[i], albums[j] = albums[j], albums[i] })
+
+	pool, err := c.collectAlbumTracks(ctx, artistID, albums, count*randomPoolFactor, market)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G404 -- track sampling is not security sensitive
+	rand.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	if len(pool) > count {
+		pool = pool[:count]
+	}
+	c.fillISRCs(ctx, pool)
+	return pool, nil
+}
+
+// collectAlbumTracks gathers up to limit of the artist's tracks from albums in order.
+// Tracks the artist is not credited on or that can't be played in market are skipped,
+// as are re-releases of a track with the same name (e.g. a single that also appears on the album).
+func (c *Client) collectAlbumTracks(ctx context.Context, artistID string, albums []spotify.SimpleAlbum, limit int, market string) ([]Track, error) {
+	tracks := make([]Track, 0, limit)
+	seen := make(map[string]bool)
+	var skipped []string
+	defer func() { c.logUnplayable(ctx, artistID, market, skipped) }()
+
+	for i := range albums {
+		if len(tracks) >= limit {
+			break
+		}
+
+		albumTracks, err := c.allAlbumTracks(ctx, string(albums[i].ID))
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range albumTracks {
+			if len(tracks) >= limit {
+				break
+			}
+			track := &albumTracks[j]
+			key := strings.ToLower(strings.TrimSpace(track.Name))
+			if seen[key] || !creditsArtist(track.Artists, artistID) {
+				continue
+			}
+			if !playableIn(track, nil, market) {
+				skipped = append(skipped, track.Name)
+				continue
+			}
+			seen[key] = true
+			converted := convertTrack(track)
+			// Album track listings don't repeat the album, so take it from the listing's album
+			converted.Album = convertAlbum(&albums[i])
+			tracks = append(tracks, converted)
+		}
+	}
+
+	return tracks, nil
+}
+
+// allArtistAlbums retrieves every page of the artist's albums and singles available in market
+func (c *Client) allArtistAlbums(ctx context.Context, artistID, market string) ([]spotify.SimpleAlbum, error) {
+	albumTypes := []spotify.AlbumType{spotify.AlbumTypeAlbum, spotify.AlbumTypeSingle}
+	return fetchAllPages(ctx, artistAlbumsPageSize, c.pagePrefetch(), func(ctx context.Context, offset int) ([]spotify.SimpleAlbum, int, error) {
+		page, err := c.api().GetArtistAlbums(ctx, spotify.ID(artistID), albumTypes,
+			spotify.Market(market), spotify.Limit(artistAlbumsPageSize), spotify.Offset(offset))
+		if err != nil {
+			return nil, 0, apiError(err)
+		}
+		return page.Albums, int(page.Total), nil
+	})
+}
+
+// allAlbumTracks retrieves every page of an album's tracks. No market is passed so
+// that Spotify includes each track's available markets for playability checks.
+func (c *Client) allAlbumTracks(ctx context.Context, albumID string) ([]spotify.SimpleTrack, error) {
+	return fetchAllPages(ctx, albumTracksPageSize, c.pagePrefetch(), func(ctx context.Context, offset int) ([]spotify.SimpleTrack, int, error) {
+		page, err := c.api().GetAlbumTracks(ctx, spotify.ID(albumID),
+			spotify.Limit(albumTracksPageSize), spotify.Offset(offset))
+		if err != nil {
+			return nil, 0, apiError(err)
+		}
+		return page.Tracks, int(page.Total), nil
+	})
+}
+
+// fillISRCs looks up the ISRCs of tracks that lack one, since album track listings
+// don't include external IDs. A failed lookup is logged and leaves the tracks as they
+// are, so duplicate checks fall back to comparing titles.
+func (c *Client) fillISRCs(ctx context.Context, tracks []Track) {
+	var ids []spotify.ID
+	for i := range tracks {
+		if tracks[i].ISRC == "" && tracks[i].ID != "" {
+			ids = append(ids, spotify.ID(tracks[i].ID))
+		}
+	}
+
+	isrcs := make(map[string]string, len(ids))
+	for start := 0; start < len(ids); start += trackLookupBatchSize {
+		fullTracks, err := c.api().GetTracks(ctx, ids[start:min(start+trackLookupBatchSize, len(ids))])
+		if err != nil {
+			c.logger.WithContext(ctx).WithError(err).WithField("track_count", len(ids)).Warn("Failed to look up track ISRCs")
+			return
+		}
+		for _, fullTrack := range fullTracks {
+			if fullTrack != nil {
+				isrcs[string(fullTrack.ID)] = fullTrack.ExternalIDs["isrc"]
+			}
+		}
+	}
+
+	for i := range tracks {
+		if isrc := isrcs[tracks[i].ID]; isrc != "" {
+			tracks[i].ISRC = isrc
+		}
+	}
+}
+
+// creditsArtist reports whether artistID is among the track's artists
+func creditsArtist(artists []spotify.SimpleArtist, artistID string) bool {
+	for i := range artists {
+		if string(artists[i].ID) == artistID {
+			return true
+		}
+	}
+	return false
+}
+
+// convertTrack converts a Spotify library track to our Track type
+func convertTrack(spotifyTrack *spotify.SimpleTrack) Track {
+	artists := make([]Artist, len(spotifyTrack.Artists))
+	for j, spotifyArtist := range spotifyTrack.Artists {
+		artists[j] = Artist{
+			ID:     string(spotifyArtist.ID),
+			Name:   spotifyArtist.Name,
+			URI:    string(spotifyArtist.URI),
+			Genres: []string{}, // SimpleArtist doesn't include genres, would need full artist lookup
+		}
+	}
+
+	track := Track{
+		ID:       string(spotifyTrack.ID),
+		Name:     spotifyTrack.Name,
+		URI:      string(spotifyTrack.URI),
+		Artists:  artists,
+		Duration: int(spotifyTrack.Duration),
+		ISRC:     spotifyTrack.ExternalIDs.ISRC,
+	}
+	if spotifyTrack.Album.ID != "" {
+		track.Album = convertAlbum(&spotifyTrack.Album)
+	}
+	return track
+}
+
+// convertFullTrack converts a full Spotify track, whose album and external IDs
+// shadow the empty ones of its embedded simple track
+func convertFullTrack(spotifyTrack *spotify.FullTrack) Track {
+	track := convertTrack(&spotifyTrack.SimpleTrack)
+	if isrc := spotifyTrack.ExternalIDs["isrc"]; isrc != "" {
+		track.ISRC = isrc
+	}
+	if spotifyTrack.Album.ID != "" {
+		track.Album = convertAlbum(&spotifyTrack.Album)
+	}
+	return track
+}
+
+// convertAlbum converts a Spotify album to our Album type
+func convertAlbum(spotifyAlbum *spotify.SimpleAlbum) *Album {
+	return &Album{
+		ID:          string(spotifyAlbum.ID),
+		Name:        spotifyAlbum.Name,
+		URI:         string(spotifyAlbum.URI),
+		AlbumType:   spotifyAlbum.AlbumType,
+		ReleaseDate: spotifyAlbum.ReleaseDate,
+	}
+}
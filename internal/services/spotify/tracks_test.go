@@ -0,0 +1,276 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/toozej/go-listen/internal/types"
+)
+
+type fakeAlbum struct {
+	id          string
+	releaseDate string
+	precision   string
+	tracks      []fakeTrack
+}
+
+type fakeTrack struct {
+	id       string
+	name     string
+	artistID string
+	// markets is reported as available_markets when set
+	markets []string
+	// unplayable is reported as is_playable=false
+	unplayable bool
+}
+
+// fakeCatalog serves an artist's top tracks, albums and album tracks
+type fakeCatalog struct {
+	t         *testing.T
+	artistID  string
+	topTracks []fakeTrack
+	albums    []fakeAlbum
+
+	mu             sync.Mutex
+	topCountry     string
+	albumsMarket   string
+	profileCountry string
+	profileCalls   int
+}
+
+func (f *fakeCatalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	trackJSON := func(track fakeTrack) map[string]any {
+		body := map[string]any{
+			"type":        "track",
+			"id":          track.id,
+			"name":        track.name,
+			"uri":         "spotify:track:" + track.id,
+			"duration_ms": 180000,
+			"artists":     []map[string]any{{"id": track.artistID, "name": "Artist " + track.artistID}},
+		}
+		if track.markets != nil {
+			body["available_markets"] = track.markets
+		}
+		if track.unplayable {
+			body["is_playable"] = false
+		}
+		return body
+	}
+	page := func(items []any) map[string]any {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(offset+limit, len(items))
+		return map[string]any{"items": items[min(offset, end):end], "limit": limit, "offset": offset, "total": len(items)}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body any
+	switch path := r.URL.Path; {
+	case path == "/me":
+		f.profileCalls++
+		if f.profileCountry == "" {
+			http.Error(w, `{"error":{"status":500,"message":"boom"}}`, http.StatusInternalServerError)
+			return
+		}
+		body = map[string]any{"id": "user-1", "country": f.profileCountry}
+	case path == "/artists/"+f.artistID+"/top-tracks":
+		f.topCountry = r.URL.Query().Get("country")
+		tracks := make([]any, len(f.topTracks))
+		for i, track := range f.topTracks {
+			tracks[i] = trackJSON(track)
+		}
+		body = map[string]any{"tracks": tracks}
+	case path == "/artists/"+f.artistID+"/albums":
+		if got := r.URL.Query().Get("include_groups"); got != "album,single" {
+			f.t.Errorf("albums requested with include_groups = %q, want %q", got, "album,single")
+		}
+		f.albumsMarket = r.URL.Query().Get("market")
+		albums := make([]any, len(f.albums))
+		for i, album := range f.albums {
+			albums[i] = map[string]any{
+				"id":                     album.id,
+				"name":                   album.id,
+				"release_date":           album.releaseDate,
+				"release_date_precision": album.precision,
+			}
+		}
+		body = page(albums)
+	case strings.HasPrefix(path, "/albums/") && strings.HasSuffix(path, "/tracks"):
+		albumID := strings.TrimSuffix(strings.TrimPrefix(path, "/albums/"), "/tracks")
+		var tracks []any
+		for _, album := range f.albums {
+			if album.id == albumID {
+				for _, track := range album.tracks {
+					tracks = append(tracks, trackJSON(track))
+				}
+			}
+		}
+		body = page(tracks)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newFakeCatalog(t *testing.T) *fakeCatalog {
+	const artist = "artist-1"
+
+	top := make([]fakeTrack, 10)
+	for i := range top {
+		top[i] = fakeTrack{id: fmt.Sprintf("top-%d", i), name: fmt.Sprintf("Top %d", i), artistID: artist}
+	}
+
+	return &fakeCatalog{
+		t:         t,
+		artistID:  artist,
+		topTracks: top,
+		// Albums are deliberately not in release order
+		albums: []fakeAlbum{
+			{id: "album-old", releaseDate: "2015-01-01", precision: "day", tracks: []fakeTrack{
+				{id: "old-0", name: "Old 0", artistID: artist},
+				{id: "old-1", name: "Old 1", artistID: artist},
+				{id: "old-2", name: "Old 2", artistID: artist},
+			}},
+			{id: "single-new", releaseDate: "2024-05-01", precision: "day", tracks: []fakeTrack{
+				{id: "new-song-single", name: "New Song", artistID: artist},
+			}},
+			{id: "album-new", releaseDate: "2024-06-01", precision: "day", tracks: []fakeTrack{
+				{id: "new-0", name: "New 0", artistID: artist},
+				{id: "feature", name: "Somebody Else's Song", artistID: "artist-2"},
+				{id: "new-1", name: "New 1", artistID: artist},
+				{id: "new-song-album", name: "New Song", artistID: artist},
+				{id: "new-2", name: "New 2", artistID: artist},
+			}},
+			{id: "album-mid", releaseDate: "2019", precision: "year", tracks: []fakeTrack{
+				{id: "mid-0", name: "Mid 0", artistID: artist},
+				{id: "mid-1", name: "Mid 1", artistID: artist},
+			}},
+		},
+	}
+}
+
+func trackIDs(tracks []Track) []string {
+	ids := make([]string, len(tracks))
+	for i := range tracks {
+		ids[i] = tracks[i].ID
+	}
+	return ids
+}
+
+func TestClient_GetArtistTracks_Top(t *testing.T) {
+	catalog := newFakeCatalog(t)
+	client := newFakeServerClient(t, catalog)
+
+	tests := []struct {
+		name      string
+		selection types.TrackSelection
+		want      []string
+	}{
+		{
+			name:      "defaults to five top tracks",
+			selection: types.TrackSelection{},
+			want:      []string{"top-0", "top-1", "top-2", "top-3", "top-4"},
+		},
+		{
+			name:      "explicit count",
+			selection: types.TrackSelection{Count: 2, Strategy: types.TrackStrategyTop},
+			want:      []string{"top-0", "top-1"},
+		},
+		{
+			name:      "maximum count",
+			selection: types.TrackSelection{Count: types.MaxTrackCount, Strategy: types.TrackStrategyTop},
+			want:      []string{"top-0", "top-1", "top-2", "top-3", "top-4", "top-5", "top-6", "top-7", "top-8", "top-9"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracks, err := client.GetArtistTracks(context.Background(), catalog.artistID, tt.selection)
+			if err != nil {
+				t.Fatalf("GetArtistTracks() unexpected error: %v", err)
+			}
+			if got := strings.Join(trackIDs(tracks), ","); got != strings.Join(tt.want, ",") {
+				t.Errorf("GetArtistTracks() = %s, want %s", got, strings.Join(tt.want, ","))
+			}
+		})
+	}
+}
+
+func TestClient_GetArtistTracks_Latest(t *testing.T) {
+	catalog := newFakeCatalog(t)
+	client := newFakeServerClient(t, catalog)
+
+	tracks, err := client.GetArtistTracks(context.Background(), catalog.artistID, types.TrackSelection{Count: 5, Strategy: types.TrackStrategyLatest})
+	if err != nil {
+		t.Fatalf("GetArtistTracks() unexpected error: %v", err)
+	}
+
+	// Newest release first, skipping tracks the artist isn't credited on and the
+	// single that is repeated on the album, then continuing into older releases
+	want := "new-0,new-1,new-song-album,new-2,mid-0"
+	if got := strings.Join(trackIDs(tracks), ","); got != want {
+		t.Errorf("GetArtistTracks() = %s, want %s", got, want)
+	}
+}
+
+func TestClient_GetArtistTracks_Random(t *testing.T) {
+	catalog := newFakeCatalog(t)
+	client := newFakeServerClient(t, catalog)
+
+	for _, count := range []int{1, 4, types.MaxTrackCount} {
+		t.Run(strconv.Itoa(count), func(t *testing.T) {
+			tracks, err := client.GetArtistTracks(context.Background(), catalog.artistID, types.TrackSelection{Count: count, Strategy: types.TrackStrategyRandom})
+			if err != nil {
+				t.Fatalf("GetArtistTracks() unexpected error: %v", err)
+			}
+
+			// The catalog has 9 distinct tracks credited to the artist
+			if len(tracks) != min(count, 9) {
+				t.Fatalf("GetArtistTracks() returned %d tracks, want %d", len(tracks), min(count, 9))
+			}
+
+			names := make(map[string]bool)
+			for _, track := range tracks {
+				if track.ID == "feature" {
+					t.Error("GetArtistTracks() picked a track the artist is not credited on")
+				}
+				if names[track.Name] {
+					t.Errorf("GetArtistTracks() picked %q twice", track.Name)
+				}
+				names[track.Name] = true
+			}
+		})
+	}
+}
+
+func TestClient_GetArtistTracks_InvalidSelection(t *testing.T) {
+	client := newFakeServerClient(t, http.NotFoundHandler())
+
+	tests := []struct {
+		name      string
+		selection types.TrackSelection
+	}{
+		{name: "count too high", selection: types.TrackSelection{Count: types.MaxTrackCount + 1}},
+		{name: "negative count", selection: types.TrackSelection{Count: -1}},
+		{name: "unknown strategy", selection: types.TrackSelection{Count: 3, Strategy: "popular"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.GetArtistTracks(context.Background(), "artist-1", tt.selection); err == nil {
+				t.Error("GetArtistTracks() expected error but got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/pkg/config"
+	"github.com/toozej/go-listen/pkg/logging"
+	"golang.org/x/time/rate"
+)
+
+// retryBaseBackoff is the wait before the first retry of a request Spotify
+// failed without saying how long to wait; it doubles on every further retry
+const retryBaseBackoff = time.Second
+
+// rateLimiter paces every request made by a Client. It is shared by all of the
+// client's transports, so a Retry-After from one request holds back the others
+// and the token bucket survives token refreshes.
+type rateLimiter struct {
+	limiter    *rate.Limiter
+	maxRetries int
+	maxWait    time.Duration
+	logger     *logging.Logger
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func(d time.Duration) time.Duration
+}
+
+// newRateLimiter creates a rate limiter from the Spotify configuration. A
+// non-positive request rate disables the token bucket.
+func newRateLimiter(cfg config.SpotifyConfig, logger *logging.Logger) *rateLimiter {
+	limit, burst := rate.Inf, 0
+	if cfg.RequestsPerSecond > 0 {
+		limit, burst = rate.Limit(cfg.RequestsPerSecond), max(cfg.Burst, 1)
+	}
+
+	return &rateLimiter{
+		limiter:    rate.NewLimiter(limit, burst),
+		maxRetries: max(cfg.MaxRetries, 0),
+		maxWait:    time.Duration(max(cfg.MaxRetryWaitSeconds, 0)) * time.Second,
+		logger:     logger,
+		now:        time.Now,
+		sleep:      sleepContext,
+		jitter:     addJitter,
+	}
+}
+
+// transport wraps base so its requests are paced and retried by the limiter
+func (l *rateLimiter) transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitTransport{base: base, limits: l}
+}
+
+// wait blocks until a request may be sent: after any Retry-After another request
+// received has passed, then until the token bucket has a token
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if delay := l.blockedFor(); delay > 0 {
+		if delay > l.maxWait {
+			return fmt.Errorf("%w: retry after %s", ErrRateLimited, delay.Round(time.Second))
+		}
+		if err := l.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// blockedFor returns how long requests are held back by the latest Retry-After
+func (l *rateLimiter) blockedFor() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.blockedUntil.Sub(l.now())
+}
+
+// block holds back every request for d
+func (l *rateLimiter) block(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := l.now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// retryDelay returns how long to wait before retrying a request after the
+// attempt-th failed response: the Retry-After Spotify sent, or an exponential backoff
+func (l *rateLimiter) retryDelay(resp *http.Response, attempt int) time.Duration {
+	if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), l.now()); ok {
+		return l.jitter(delay)
+	}
+	return l.jitter(retryBaseBackoff << min(attempt, 10))
+}
+
+// rateLimitTransport sends requests through the rate limiter and retries the ones
+// Spotify rate limits or fails with a transient server error
+type rateLimitTransport struct {
+	base   http.RoundTripper
+	limits *rateLimiter
+}
+
+// RoundTrip implements http.RoundTripper. A request that is still rate limited
+// once its retries run out fails with ErrRateLimited rather than returning the
+// 429 response, since Spotify often sends those without a body to decode.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if err := t.limits.wait(ctx); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+		if !shouldRetry(req.Method, resp.StatusCode) {
+			return resp, nil
+		}
+
+		delay := t.limits.retryDelay(resp, attempt)
+		rateLimited := resp.StatusCode == http.StatusTooManyRequests
+		if rateLimited {
+			t.limits.block(delay)
+		}
+
+		logger := t.limits.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"component":   "spotify",
+			"operation":   "retry",
+			"method":      req.Method,
+			"path":        req.URL.Path,
+			"status_code": resp.StatusCode,
+			"attempt":     attempt + 1,
+			"delay_ms":    delay.Milliseconds(),
+		})
+
+		if attempt >= t.limits.maxRetries || delay > t.limits.maxWait || !rewindable {
+			if !rateLimited {
+				return resp, nil
+			}
+			discardBody(resp)
+			logger.Warn("Spotify API rate limit encountered, giving up")
+			return nil, fmt.Errorf("%w: retry after %s", ErrRateLimited, delay.Round(time.Second))
+		}
+
+		discardBody(resp)
+		logger.Info("Retrying Spotify API request")
+		if err := t.limits.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry reports whether a response is worth retrying. Rate limited requests
+// were never processed, so any method is retried; server errors are only retried
+// for reads, since a write such as adding tracks may have gone through.
+func shouldRetry(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return method == http.MethodGet || method == http.MethodHead
+	default:
+		return false
+	}
+}
+
+// rewindRequest returns the request to send on the given attempt, with a fresh
+// copy of the body for every retry
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, nil
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// addJitter adds up to a quarter of d so that retries from concurrent requests spread out
+func addJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	// #nosec G404 -- retry jitter is not security sensitive
+	return d + rand.N(d/4+1)
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discardBody drains and closes a response that won't be returned, so its
+// connection can be reused
+func discardBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
+
+// closeRequestBody closes the body of a request that won't be sent, as RoundTrip must
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
@@ -0,0 +1,393 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/types"
+	"github.com/toozej/go-listen/pkg/config"
+	"github.com/toozej/go-listen/pkg/logging"
+	"github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
+)
+
+// testResponse is a canned response served by scriptedServer
+type testResponse struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+// scriptedServer serves its responses in order, repeating the last one, and
+// records the body of every request it receives
+type scriptedServer struct {
+	mu        sync.Mutex
+	responses []testResponse
+	bodies    []string
+}
+
+func (s *scriptedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	resp := s.responses[min(len(s.bodies), len(s.responses)-1)]
+	s.bodies = append(s.bodies, string(body))
+	s.mu.Unlock()
+
+	if resp.retryAfter != "" {
+		w.Header().Set("Retry-After", resp.retryAfter)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.status)
+	_, _ = io.WriteString(w, resp.body)
+}
+
+func (s *scriptedServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+// newTestRateLimiter creates a rate limiter without a token bucket or jitter whose
+// waits are recorded rather than slept
+func newTestRateLimiter(maxRetries int, maxWait time.Duration) (*rateLimiter, *[]time.Duration) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	limits := newRateLimiter(config.SpotifyConfig{MaxRetries: maxRetries}, logging.Wrap(logger))
+	limits.maxWait = maxWait
+	limits.jitter = func(d time.Duration) time.Duration { return d }
+
+	var waits []time.Duration
+	now := time.Now()
+	limits.now = func() time.Time { return now }
+	limits.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		now = now.Add(d)
+		return nil
+	}
+	return limits, &waits
+}
+
+func TestRateLimitTransport_Retries(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		responses      []testResponse
+		maxRetries     int
+		expectedStatus int
+		expectedErr    error
+		expectedWaits  []time.Duration
+		expectedTries  int
+	}{
+		{
+			name:   "honours Retry-After",
+			method: http.MethodGet,
+			responses: []testResponse{
+				{status: http.StatusTooManyRequests, retryAfter: "2"},
+				{status: http.StatusOK, body: "{}"},
+			},
+			maxRetries:     3,
+			expectedStatus: http.StatusOK,
+			expectedWaits:  []time.Duration{2 * time.Second},
+			expectedTries:  2,
+		},
+		{
+			name:   "backs off exponentially without Retry-After",
+			method: http.MethodGet,
+			responses: []testResponse{
+				{status: http.StatusServiceUnavailable},
+				{status: http.StatusBadGateway},
+				{status: http.StatusOK, body: "{}"},
+			},
+			maxRetries:     3,
+			expectedStatus: http.StatusOK,
+			expectedWaits:  []time.Duration{time.Second, 2 * time.Second},
+			expectedTries:  3,
+		},
+		{
+			name:   "retries rate limited writes with their body",
+			method: http.MethodPost,
+			responses: []testResponse{
+				{status: http.StatusTooManyRequests, retryAfter: "1"},
+				{status: http.StatusCreated, body: "{}"},
+			},
+			maxRetries:     3,
+			expectedStatus: http.StatusCreated,
+			expectedWaits:  []time.Duration{time.Second},
+			expectedTries:  2,
+		},
+		{
+			name:           "does not retry failed writes",
+			method:         http.MethodPost,
+			responses:      []testResponse{{status: http.StatusBadGateway}},
+			maxRetries:     3,
+			expectedStatus: http.StatusBadGateway,
+			expectedTries:  1,
+		},
+		{
+			name:           "passes other errors through",
+			method:         http.MethodGet,
+			responses:      []testResponse{{status: http.StatusNotFound}},
+			maxRetries:     3,
+			expectedStatus: http.StatusNotFound,
+			expectedTries:  1,
+		},
+		{
+			name:           "returns the last server error once retries run out",
+			method:         http.MethodGet,
+			responses:      []testResponse{{status: http.StatusServiceUnavailable}},
+			maxRetries:     1,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedWaits:  []time.Duration{time.Second},
+			expectedTries:  2,
+		},
+		{
+			name:          "gives up once retries run out",
+			method:        http.MethodGet,
+			responses:     []testResponse{{status: http.StatusTooManyRequests, retryAfter: "1"}},
+			maxRetries:    2,
+			expectedErr:   ErrRateLimited,
+			expectedWaits: []time.Duration{time.Second, time.Second},
+			expectedTries: 3,
+		},
+		{
+			name:          "gives up when Retry-After is too long",
+			method:        http.MethodGet,
+			responses:     []testResponse{{status: http.StatusTooManyRequests, retryAfter: "3600"}},
+			maxRetries:    3,
+			expectedErr:   ErrRateLimited,
+			expectedTries: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &scriptedServer{responses: tt.responses}
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+
+			limits, waits := newTestRateLimiter(tt.maxRetries, 30*time.Second)
+			client := &http.Client{Transport: limits.transport(srv.Client().Transport)}
+
+			var body io.Reader
+			if tt.method == http.MethodPost {
+				body = strings.NewReader(`{"uris":["spotify:track:1"]}`)
+			}
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, srv.URL, body)
+			if err != nil {
+				t.Fatalf("NewRequest() unexpected error: %v", err)
+			}
+
+			resp, err := client.Do(req)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Do() error = %v, want %v", err, tt.expectedErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Do() unexpected error: %v", err)
+				}
+				_ = resp.Body.Close()
+				if resp.StatusCode != tt.expectedStatus {
+					t.Errorf("StatusCode = %d, want %d", resp.StatusCode, tt.expectedStatus)
+				}
+			}
+
+			if server.requests() != tt.expectedTries {
+				t.Errorf("server received %d requests, want %d", server.requests(), tt.expectedTries)
+			}
+			if len(*waits) != len(tt.expectedWaits) {
+				t.Fatalf("waits = %v, want %v", *waits, tt.expectedWaits)
+			}
+			for i := range tt.expectedWaits {
+				if (*waits)[i] != tt.expectedWaits[i] {
+					t.Errorf("wait %d = %s, want %s", i, (*waits)[i], tt.expectedWaits[i])
+				}
+			}
+			if tt.method == http.MethodPost {
+				for i, got := range server.bodies {
+					if got != `{"uris":["spotify:track:1"]}` {
+						t.Errorf("request %d body = %q, want the original body", i+1, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitTransport_RetryAfterHoldsBackOtherRequests(t *testing.T) {
+	server := &scriptedServer{responses: []testResponse{{status: http.StatusTooManyRequests, retryAfter: "120"}}}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	limits, waits := newTestRateLimiter(3, 30*time.Second)
+	client := &http.Client{Transport: limits.transport(srv.Client().Transport)}
+
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Get() error = %v, want %v", err, ErrRateLimited)
+	}
+
+	// Until Retry-After has passed, requests fail without reaching Spotify
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("second Get() error = %v, want %v", err, ErrRateLimited)
+	}
+	if server.requests() != 1 {
+		t.Errorf("server received %d requests, want 1", server.requests())
+	}
+
+	// A block short enough to wait out delays the next request instead
+	limits.mu.Lock()
+	limits.blockedUntil = limits.now().Add(5 * time.Second)
+	limits.mu.Unlock()
+	server.mu.Lock()
+	server.responses = []testResponse{{status: http.StatusOK, body: "{}"}}
+	server.mu.Unlock()
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("third Get() unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if len(*waits) != 1 || (*waits)[0] != 5*time.Second {
+		t.Errorf("waits = %v, want [5s]", *waits)
+	}
+}
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	limits := newRateLimiter(config.SpotifyConfig{RequestsPerSecond: 20, Burst: 1}, logging.Wrap(logger))
+	client := &http.Client{Transport: limits.transport(srv.Client().Transport)}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// The first request uses the burst; the other two wait 50ms each for a token
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests took %s, want at least 90ms at 20 requests per second", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "", ok: false},
+		{value: "7", expected: 7 * time.Second, ok: true},
+		{value: "-3", expected: 0, ok: true},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), expected: 90 * time.Second, ok: true},
+		{value: "soon", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %t, want %s, %t", tt.value, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestClient_TypedErrors(t *testing.T) {
+	spotifyError := func(status int, message string) testResponse {
+		return testResponse{status: status, body: `{"error":{"status":` + strconv.Itoa(status) + `,"message":"` + message + `"}}`}
+	}
+
+	tests := []struct {
+		name     string
+		response testResponse
+		call     func(c *Client) error
+		expected error
+	}{
+		{
+			name:     "unknown artist",
+			response: spotifyError(http.StatusNotFound, "Resource not found"),
+			call: func(c *Client) error {
+				_, err := c.GetArtistTracks(context.Background(), "missing", types.TrackSelection{Strategy: types.TrackStrategyTop, Count: 5})
+				return err
+			},
+			expected: ErrNotFound,
+		},
+		{
+			name:     "revoked token",
+			response: spotifyError(http.StatusUnauthorized, "The access token expired"),
+			call: func(c *Client) error {
+				_, err := c.SearchArtist(context.Background(), "Radiohead")
+				return err
+			},
+			expected: ErrUnauthorized,
+		},
+		{
+			name:     "no search results",
+			response: testResponse{status: http.StatusOK, body: `{"artists":{"items":[]}}`},
+			call: func(c *Client) error {
+				_, err := c.SearchArtist(context.Background(), "nobody at all")
+				return err
+			},
+			expected: ErrNotFound,
+		},
+		{
+			name:     "rate limited without a body",
+			response: testResponse{status: http.StatusTooManyRequests, retryAfter: "1"},
+			call: func(c *Client) error {
+				return c.AddTracksToPlaylist(context.Background(), "playlist1", []string{"track1"})
+			},
+			expected: ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &scriptedServer{responses: []testResponse{tt.response}}
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+
+			limits, _ := newTestRateLimiter(0, 30*time.Second)
+			httpClient := &http.Client{Transport: limits.transport(srv.Client().Transport)}
+
+			client := &Client{
+				client:     spotify.New(httpClient, spotify.WithBaseURL(srv.URL+"/")),
+				config:     config.SpotifyConfig{PagePrefetch: 2, Market: "US"},
+				logger:     limits.logger,
+				token:      &oauth2.Token{AccessToken: "test", Expiry: time.Now().Add(time.Hour)},
+				isUserAuth: true,
+				limits:     limits,
+			}
+
+			if err := tt.call(client); !errors.Is(err, tt.expected) {
+				t.Errorf("error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+
+	t.Run("not logged in", func(t *testing.T) {
+		client := &Client{logger: logging.Wrap(logrus.New())}
+		if _, err := client.SearchArtist(context.Background(), "Radiohead"); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("error = %v, want %v", err, ErrUnauthorized)
+		}
+	})
+}
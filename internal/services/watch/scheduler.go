@@ -0,0 +1,363 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/services/scraper"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// Defaults for a Scheduler
+const (
+	DefaultCheckInterval = time.Minute
+	DefaultRunRetention  = 100
+)
+
+var (
+	// ErrRunning is returned when scraping a source that is already being scraped
+	ErrRunning = errors.New("watched source is already being scraped")
+	// ErrNoScraper is returned when running a source before a scraper was set
+	ErrNoScraper = errors.New("watched sources have no scraper")
+)
+
+// Scraper scrapes a page, processing only the artists not seen on it before
+type Scraper interface {
+	ScrapeNewArtists(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, seen func(name string) bool, progress scraper.ProgressFunc) (*scraper.ScrapeResult, error)
+}
+
+// ParseSchedule parses how often a source is scraped: hourly, daily, weekly or a
+// duration such as "12h"
+func ParseSchedule(schedule string) (time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(schedule)) {
+	case "hourly":
+		return time.Hour, nil
+	case "daily":
+		return 24 * time.Hour, nil
+	case "weekly":
+		return 7 * 24 * time.Hour, nil
+	}
+	interval, err := time.ParseDuration(strings.TrimSpace(schedule))
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid schedule %q: use hourly, daily, weekly or a duration such as 12h", schedule)
+	}
+	return interval, nil
+}
+
+// NextRun returns when a source scraped every interval is due: an interval after
+// its last run, or now if it never ran
+func NextRun(lastRunAt *time.Time, interval time.Duration, now time.Time) time.Time {
+	if lastRunAt == nil {
+		return now
+	}
+	return lastRunAt.Add(interval)
+}
+
+// Scheduler scrapes the watched sources in its store when they are due, one at a time
+type Scheduler struct {
+	store         *SQLiteStore
+	scraper       Scraper
+	logger        *logrus.Logger
+	checkInterval time.Duration
+	retention     int
+	now           func() time.Time
+
+	mu sync.Mutex
+	// running holds the IDs of the sources being scraped
+	running map[int64]bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewScheduler creates a scheduler for the sources in store; it runs nothing until
+// a scraper is set and it is started
+func NewScheduler(store *SQLiteStore, logger *logrus.Logger) *Scheduler {
+	return &Scheduler{
+		store:         store,
+		logger:        logger,
+		checkInterval: DefaultCheckInterval,
+		retention:     DefaultRunRetention,
+		now:           time.Now,
+		running:       make(map[int64]bool),
+	}
+}
+
+// Store returns the store the scheduler reads the watched sources from
+func (s *Scheduler) Store() *SQLiteStore {
+	return s.store
+}
+
+// SetScraper sets the scraper the sources are scraped with
+func (s *Scheduler) SetScraper(scraper Scraper) {
+	s.scraper = scraper
+}
+
+// SetCheckInterval sets how often the scheduler looks for due sources
+func (s *Scheduler) SetCheckInterval(interval time.Duration) {
+	if interval > 0 {
+		s.checkInterval = interval
+	}
+}
+
+// SetRunRetention sets how many runs are kept for each source; 0 keeps every run
+func (s *Scheduler) SetRunRetention(retention int) {
+	if retention >= 0 {
+		s.retention = retention
+	}
+}
+
+// Start scrapes the due sources now and then every check interval, in the background
+// until Stop is called. It does nothing without a scraper or when already started.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+	if s.scraper == nil {
+		s.logger.WithFields(logrus.Fields{
+			"component": "watch",
+			"operation": "start",
+		}).Warn("No scraper set, watched sources will not be scraped")
+		return
+	}
+
+	// Runs still marked running were cut short when the process last stopped
+	if interrupted, err := s.store.FailInterrupted(); err != nil {
+		s.logger.WithError(err).WithField("component", "watch").Error("Failed to fail interrupted watch runs")
+	} else if interrupted > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"component": "watch",
+			"operation": "start",
+			"runs":      interrupted,
+		}).Warn("Marked interrupted watch runs as failed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.loop(ctx, s.done)
+
+	s.logger.WithFields(logrus.Fields{
+		"component":      "watch",
+		"operation":      "start",
+		"check_interval": s.checkInterval.String(),
+	}).Info("Watching sources")
+}
+
+// Stop cancels the run in progress and waits for the scheduler to stop, or for ctx to be done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop runs the due sources every check interval until ctx is cancelled
+func (s *Scheduler) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.RunDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue scrapes every enabled source that is due, one at a time, logging failures
+func (s *Scheduler) RunDue(ctx context.Context) {
+	sources, err := s.store.Due(s.now())
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).WithField("component", "watch").Error("Failed to find due watched sources")
+		return
+	}
+
+	for _, source := range sources {
+		if ctx.Err() != nil {
+			return
+		}
+		// Failed runs are logged and recorded in the source's history by Run
+		if _, err := s.Run(ctx, source.ID, nil); errors.Is(err, ErrRunning) {
+			s.logger.WithContext(ctx).WithFields(logrus.Fields{
+				"component": "watch",
+				"source_id": source.ID,
+			}).Debug("Skipping watched source that is already being scraped")
+		}
+	}
+}
+
+// Run scrapes a source now, processing only the artists not seen on it before, and
+// schedules its next run. The run is recorded in the source's history and returned,
+// also when it failed.
+func (s *Scheduler) Run(ctx context.Context, id int64, progress scraper.ProgressFunc) (*types.WatchRun, error) {
+	if s.scraper == nil {
+		return nil, ErrNoScraper
+	}
+	if !s.claim(id) {
+		return nil, ErrRunning
+	}
+	defer s.release(id)
+
+	source, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	interval, err := ParseSchedule(source.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	seen, err := s.store.SeenArtists(id)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &types.WatchRun{SourceID: id, StartedAt: s.now()}
+	if err := s.store.StartRun(run); err != nil {
+		return nil, err
+	}
+
+	logger := s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"component":   "watch",
+		"operation":   "run",
+		"source_id":   id,
+		"run_id":      run.ID,
+		"url":         source.URL,
+		"playlist_id": source.PlaylistID,
+	})
+	logger.Info("Scraping watched source")
+
+	addSource := types.AddSource{Kind: types.SourceWatch, User: source.User}
+	isSeen := func(name string) bool { return seen[alias.Key(name)] }
+	result, scrapeErr := s.scraper.ScrapeNewArtists(ctx, source.URL, source.CSSSelector, source.PlaylistID,
+		source.Mode, source.Selection, addSource, isSeen, progress)
+
+	finished := s.now()
+	run.FinishedAt = &finished
+	run.Status = types.WatchRunSucceeded
+	if scrapeErr != nil {
+		run.Status = types.WatchRunFailed
+		run.Error = scrapeErr.Error()
+	}
+	if result != nil {
+		recordResult(run, result)
+		if err := s.store.MarkSeen(id, settledArtists(result, scrapeErr == nil), finished); err != nil {
+			logger.WithError(err).Error("Failed to record seen artists, they will be processed again")
+		}
+	}
+
+	// A run cut short by shutdown is retried as soon as the scheduler starts again
+	next := finished.Add(interval)
+	if ctx.Err() != nil {
+		next = finished
+	}
+	if err := s.store.FinishRun(run, next, s.retention); err != nil {
+		logger.WithError(err).Error("Failed to record watch run")
+		return run, err
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"status":        run.Status,
+		"artists_found": run.ArtistsFound,
+		"new_artists":   len(run.NewArtists),
+		"success_count": run.SuccessCount,
+		"next_run_at":   next,
+	})
+	if scrapeErr != nil {
+		logger.WithError(scrapeErr).Error("Watched source scrape failed")
+		return run, scrapeErr
+	}
+	logger.Info("Watched source scraped")
+	return run, nil
+}
+
+// claim marks a source as being scraped, reporting false if it already was
+func (s *Scheduler) claim(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[id] {
+		return false
+	}
+	s.running[id] = true
+	return true
+}
+
+// release marks a source as no longer being scraped
+func (s *Scheduler) release(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, id)
+}
+
+// Running reports whether a source is being scraped
+func (s *Scheduler) Running(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[id]
+}
+
+// recordResult copies the outcome of a scrape into its run
+func recordResult(run *types.WatchRun, result *scraper.ScrapeResult) {
+	run.ArtistsFound = len(result.ArtistsFound)
+	run.NewArtists = make([]string, len(result.MatchResults))
+	for i, match := range result.MatchResults {
+		run.NewArtists[i] = match.Query
+	}
+	run.SuccessCount = result.SuccessCount
+	run.DuplicateCount = result.DuplicateCount
+	run.BlockedCount = result.BlockedCount
+	run.ReviewCount = result.ReviewCount
+	run.FailureCount = result.FailureCount
+	run.TotalTracksAdded = result.TotalTracksAdded
+	run.Message = result.Message
+}
+
+// settledArtists returns the names of a scrape that need not be processed again:
+// those added, skipped or held for review and, when the scrape completed, those
+// found without a match. Artists that failed to be added are left to be retried,
+// as are the ones an incomplete scrape did not get to.
+func settledArtists(result *scraper.ScrapeResult, completed bool) []string {
+	var settled []string
+	processed := make(map[string]bool, len(result.MatchResults))
+	for _, match := range result.MatchResults {
+		processed[match.Query] = true
+		switch {
+		case match.TracksAdded > 0, match.WasDuplicate, match.Blocked, match.NeedsReview:
+			settled = append(settled, match.Query)
+		case !match.Matched && completed:
+			settled = append(settled, match.Query)
+		}
+	}
+	if !completed {
+		return settled
+	}
+
+	// The names seen on earlier runs were not processed, but are already marked seen
+	for _, name := range result.ArtistsFound {
+		if !processed[name] {
+			settled = append(settled, name)
+		}
+	}
+	return settled
+}
@@ -0,0 +1,281 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/toozej/go-listen/internal/services/scraper"
+	"github.com/toozej/go-listen/internal/types"
+)
+
+// mockScraper finds the same names on every page, adding the new ones unless they are
+// set to fail, and records the sources it scraped
+type mockScraper struct {
+	mu      sync.Mutex
+	names   []string
+	failing map[string]bool
+	err     error
+	// block, when set, holds every scrape until it is closed
+	block   chan struct{}
+	scraped []types.AddSource
+	added   []string
+}
+
+func (m *mockScraper) ScrapeNewArtists(ctx context.Context, url, cssSelector, playlistID string, mode types.AddMode, selection types.TrackSelection, source types.AddSource, seen func(name string) bool, progress scraper.ProgressFunc) (*scraper.ScrapeResult, error) {
+	if m.block != nil {
+		<-m.block
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scraped = append(m.scraped, source)
+	if m.err != nil {
+		return &scraper.ScrapeResult{URL: url}, m.err
+	}
+
+	result := &scraper.ScrapeResult{URL: url, ArtistsFound: m.names}
+	for _, name := range m.names {
+		if seen(name) {
+			result.SeenCount++
+			continue
+		}
+		match := scraper.ArtistMatchResult{Query: name, Matched: true, Artist: &types.Artist{ID: name}}
+		if m.failing[name] {
+			match.Error = "Failed to add tracks: unavailable"
+			result.FailureCount++
+		} else {
+			match.TracksAdded = 3
+			result.SuccessCount++
+			result.TotalTracksAdded += 3
+			m.added = append(m.added, name)
+		}
+		result.MatchResults = append(result.MatchResults, match)
+	}
+	return result, nil
+}
+
+func newTestScheduler(t *testing.T, scrape *mockScraper) *Scheduler {
+	t.Helper()
+	store, _ := openTestStore(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	scheduler := NewScheduler(store, logger)
+	scheduler.SetScraper(scrape)
+	return scheduler
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		schedule string
+		want     time.Duration
+		wantErr  bool
+	}{
+		{schedule: "hourly", want: time.Hour},
+		{schedule: "Daily", want: 24 * time.Hour},
+		{schedule: " weekly ", want: 7 * 24 * time.Hour},
+		{schedule: "12h", want: 12 * time.Hour},
+		{schedule: "90m", want: 90 * time.Minute},
+		{schedule: "", wantErr: true},
+		{schedule: "monthly", wantErr: true},
+		{schedule: "-1h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			got, err := ParseSchedule(tt.schedule)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseSchedule() expected error but got %v", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseSchedule() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	scrape := &mockScraper{names: []string{"Big Thief", "Nirvana"}, failing: map[string]bool{"Nirvana": true}}
+	scheduler := newTestScheduler(t, scrape)
+	source := newSource("https://example.com/lineup")
+	if err := scheduler.Store().Add(source); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	run, err := scheduler.Run(context.Background(), source.ID, nil)
+	if err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+	if run.Status != types.WatchRunSucceeded || run.ArtistsFound != 2 || len(run.NewArtists) != 2 ||
+		run.SuccessCount != 1 || run.FailureCount != 1 || run.FinishedAt == nil {
+		t.Errorf("Run() = %+v, want both artists new, one added and one failed", run)
+	}
+	if len(scrape.scraped) != 1 || scrape.scraped[0].Kind != types.SourceWatch || scrape.scraped[0].User != "user1" {
+		t.Errorf("Scraped with %+v, want a watch scrape credited to the source's user", scrape.scraped)
+	}
+
+	// The next run is an interval after this one
+	got, err := scheduler.Store().Get(source.ID)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	// Times are stored to the millisecond
+	if got.LastRunAt == nil || got.NextRunAt.Sub(run.FinishedAt.Truncate(time.Millisecond)).Round(time.Millisecond) != 7*24*time.Hour {
+		t.Errorf("Source after Run() = %+v, want it due a week after the run", got)
+	}
+
+	// Only the artist that failed to be added is processed again
+	scrape.failing = nil
+	run, err = scheduler.Run(context.Background(), source.ID, nil)
+	if err != nil {
+		t.Fatalf("Run() again unexpected error: %v", err)
+	}
+	if len(run.NewArtists) != 1 || run.NewArtists[0] != "Nirvana" || run.SuccessCount != 1 {
+		t.Errorf("Run() again = %+v, want only Nirvana retried", run)
+	}
+
+	// and then nothing is new
+	run, err = scheduler.Run(context.Background(), source.ID, nil)
+	if err != nil {
+		t.Fatalf("Run() a third time unexpected error: %v", err)
+	}
+	if len(run.NewArtists) != 0 || len(scrape.added) != 2 {
+		t.Errorf("Run() a third time = %+v after adding %v, want nothing new", run, scrape.added)
+	}
+
+	runs, err := scheduler.Store().Runs(source.ID, 0, 0)
+	if err != nil || len(runs) != 3 {
+		t.Errorf("Runs() = %+v, %v, want the three runs", runs, err)
+	}
+
+	if _, err := scheduler.Run(context.Background(), 999, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Run() unknown source error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestScheduler_Run_Failed(t *testing.T) {
+	scrape := &mockScraper{err: errors.New("connection refused")}
+	scheduler := newTestScheduler(t, scrape)
+	source := newSource("https://example.com/lineup")
+	if err := scheduler.Store().Add(source); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	run, err := scheduler.Run(context.Background(), source.ID, nil)
+	if err == nil {
+		t.Fatal("Run() expected error but got none")
+	}
+	if run == nil || run.Status != types.WatchRunFailed || run.Error != "connection refused" {
+		t.Errorf("Run() = %+v, want a failed run recording the error", run)
+	}
+	runs, _ := scheduler.Store().Runs(source.ID, 0, 0)
+	if len(runs) != 1 || runs[0].Status != types.WatchRunFailed {
+		t.Errorf("Runs() = %+v, want the failed run recorded", runs)
+	}
+
+	noScraper := NewScheduler(scheduler.Store(), scheduler.logger)
+	if _, err := noScraper.Run(context.Background(), source.ID, nil); !errors.Is(err, ErrNoScraper) {
+		t.Errorf("Run() without a scraper error = %v, want ErrNoScraper", err)
+	}
+}
+
+func TestScheduler_Run_AlreadyRunning(t *testing.T) {
+	scrape := &mockScraper{names: []string{"Big Thief"}, block: make(chan struct{})}
+	scheduler := newTestScheduler(t, scrape)
+	source := newSource("https://example.com/lineup")
+	if err := scheduler.Store().Add(source); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := scheduler.Run(context.Background(), source.ID, nil)
+		done <- err
+	}()
+
+	// Wait for the first run to claim the source
+	for !scheduler.Running(source.ID) {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := scheduler.Run(context.Background(), source.ID, nil); !errors.Is(err, ErrRunning) {
+		t.Errorf("Run() while running error = %v, want ErrRunning", err)
+	}
+
+	close(scrape.block)
+	if err := <-done; err != nil {
+		t.Errorf("Run() unexpected error: %v", err)
+	}
+	if scheduler.Running(source.ID) {
+		t.Error("Running() after Run() = true, want false")
+	}
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	scrape := &mockScraper{names: []string{"Big Thief"}}
+	scheduler := newTestScheduler(t, scrape)
+	now := time.Now()
+	scheduler.now = func() time.Time { return now }
+
+	due := newSource("https://example.com/due")
+	later := newSource("https://example.com/later")
+	later.NextRunAt = now.Add(time.Hour)
+	disabled := newSource("https://example.com/disabled")
+	disabled.Enabled = false
+	for _, source := range []*types.WatchedSource{due, later, disabled} {
+		if err := scheduler.Store().Add(source); err != nil {
+			t.Fatalf("Add() unexpected error: %v", err)
+		}
+	}
+
+	scheduler.RunDue(context.Background())
+	if len(scrape.scraped) != 1 {
+		t.Fatalf("RunDue() scraped %d sources, want only the due one", len(scrape.scraped))
+	}
+
+	// A source is not due again until its schedule says so
+	scheduler.RunDue(context.Background())
+	if len(scrape.scraped) != 1 {
+		t.Errorf("RunDue() again scraped %d sources, want none", len(scrape.scraped)-1)
+	}
+}
+
+func TestScheduler_StartStop(t *testing.T) {
+	scrape := &mockScraper{names: []string{"Big Thief"}}
+	scheduler := newTestScheduler(t, scrape)
+	source := newSource("https://example.com/lineup")
+	if err := scheduler.Store().Add(source); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	// Starting scrapes the due sources right away
+	scheduler.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runs, err := scheduler.Store().Runs(source.ID, 0, 0)
+		if err != nil {
+			t.Fatalf("Runs() unexpected error: %v", err)
+		}
+		if len(runs) == 1 && runs[0].Status == types.WatchRunSucceeded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Runs() = %+v, want the source scraped after Start()", runs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := scheduler.Stop(ctx); err != nil {
+		t.Errorf("Stop() unexpected error: %v", err)
+	}
+	if err := scheduler.Stop(ctx); err != nil {
+		t.Errorf("Stop() twice unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,494 @@
+// Package watch re-scrapes watched pages on their schedules, adding only the artists
+// that appeared on a page since it was last scraped and keeping a history of each run.
+package watch
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/toozej/go-listen/internal/services/alias"
+	"github.com/toozej/go-listen/internal/types"
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// ErrNotFound is returned for a source that is not watched
+var ErrNotFound = errors.New("watched source not found")
+
+// migrations upgrade the schema in order; the index of the last applied
+// migration plus one is stored in the database's user_version
+var migrations = []string{
+	`CREATE TABLE sources (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		url          TEXT    NOT NULL,
+		css_selector TEXT    NOT NULL DEFAULT '',
+		playlist_id  TEXT    NOT NULL,
+		mode         TEXT    NOT NULL,
+		selection    TEXT    NOT NULL,
+		schedule     TEXT    NOT NULL,
+		enabled      INTEGER NOT NULL,
+		user_id      TEXT    NOT NULL DEFAULT '',
+		created_at   INTEGER NOT NULL,
+		last_run_at  INTEGER,
+		next_run_at  INTEGER NOT NULL
+	);
+	CREATE INDEX sources_next_run_at ON sources (enabled, next_run_at);
+	CREATE TABLE seen_artists (
+		source_id     INTEGER NOT NULL,
+		name_key      TEXT    NOT NULL,
+		name          TEXT    NOT NULL,
+		first_seen_at INTEGER NOT NULL,
+		PRIMARY KEY (source_id, name_key)
+	);
+	CREATE TABLE runs (
+		id              INTEGER PRIMARY KEY AUTOINCREMENT,
+		source_id       INTEGER NOT NULL,
+		status          TEXT    NOT NULL,
+		artists_found   INTEGER NOT NULL DEFAULT 0,
+		new_artists     TEXT    NOT NULL DEFAULT '[]',
+		success_count   INTEGER NOT NULL DEFAULT 0,
+		duplicate_count INTEGER NOT NULL DEFAULT 0,
+		blocked_count   INTEGER NOT NULL DEFAULT 0,
+		review_count    INTEGER NOT NULL DEFAULT 0,
+		failure_count   INTEGER NOT NULL DEFAULT 0,
+		tracks_added    INTEGER NOT NULL DEFAULT 0,
+		message         TEXT    NOT NULL DEFAULT '',
+		error           TEXT    NOT NULL DEFAULT '',
+		started_at      INTEGER NOT NULL,
+		finished_at     INTEGER
+	);
+	CREATE INDEX runs_source_id ON runs (source_id, id);`,
+}
+
+// SQLiteStore keeps the watched sources, the artists seen on each and their runs in a SQLite database
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// Open opens or creates the watch database at path and migrates it to the latest schema
+func Open(path string) (*SQLiteStore, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("watch database path is required")
+	}
+
+	path = filepath.Clean(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create watch directory: %w", err)
+	}
+
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watch database: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids lock contention within the process
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteStore{db: db}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// migrate applies any migrations newer than the database's schema version
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read watch schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("watch database schema version %d is newer than supported version %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to migrate watch database: %w", err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply watch migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply watch migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to apply watch migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Add watches a source, setting its ID and defaulting CreatedAt to now and
+// NextRunAt to CreatedAt, so it is scraped as soon as the scheduler next checks
+func (s *SQLiteStore) Add(source *types.WatchedSource) error {
+	if source == nil {
+		return fmt.Errorf("cannot watch nil source")
+	}
+	if source.CreatedAt.IsZero() {
+		source.CreatedAt = time.Now()
+	}
+	if source.NextRunAt.IsZero() {
+		source.NextRunAt = source.CreatedAt
+	}
+
+	selectionJSON, err := json.Marshal(source.Selection)
+	if err != nil {
+		return fmt.Errorf("failed to encode watched source track selection: %w", err)
+	}
+
+	result, err := s.db.Exec(`INSERT INTO sources
+		(url, css_selector, playlist_id, mode, selection, schedule, enabled, user_id, created_at, next_run_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		source.URL, source.CSSSelector, source.PlaylistID, string(source.Mode), string(selectionJSON),
+		source.Schedule, source.Enabled, source.User, source.CreatedAt.UnixMilli(), source.NextRunAt.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("failed to add watched source: %w", err)
+	}
+
+	if source.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read watched source ID: %w", err)
+	}
+	return nil
+}
+
+// Update replaces the settings of a watched source, keeping when it was created
+// and last run. Changing its URL or CSS selector forgets the artists seen on it,
+// so the next run treats every artist found as new. It returns ErrNotFound for an
+// unknown source.
+func (s *SQLiteStore) Update(source *types.WatchedSource) error {
+	current, err := s.Get(source.ID)
+	if err != nil {
+		return err
+	}
+
+	selectionJSON, err := json.Marshal(source.Selection)
+	if err != nil {
+		return fmt.Errorf("failed to encode watched source track selection: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to update watched source: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`UPDATE sources SET url = ?, css_selector = ?, playlist_id = ?, mode = ?,
+		selection = ?, schedule = ?, enabled = ?, next_run_at = ? WHERE id = ?`,
+		source.URL, source.CSSSelector, source.PlaylistID, string(source.Mode), string(selectionJSON),
+		source.Schedule, source.Enabled, source.NextRunAt.UnixMilli(), source.ID); err != nil {
+		return fmt.Errorf("failed to update watched source: %w", err)
+	}
+	if source.URL != current.URL || source.CSSSelector != current.CSSSelector {
+		if _, err := tx.Exec(`DELETE FROM seen_artists WHERE source_id = ?`, source.ID); err != nil {
+			return fmt.Errorf("failed to reset seen artists: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update watched source: %w", err)
+	}
+
+	source.User = current.User
+	source.CreatedAt = current.CreatedAt
+	source.LastRunAt = current.LastRunAt
+	return nil
+}
+
+// Delete stops watching a source, forgetting the artists seen on it and its runs.
+// It returns ErrNotFound for an unknown source.
+func (s *SQLiteStore) Delete(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to delete watched source: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.Exec(`DELETE FROM sources WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete watched source: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete watched source: %w", err)
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	for _, table := range []string{"seen_artists", "runs"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE source_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete watched source: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete watched source: %w", err)
+	}
+	return nil
+}
+
+// Get returns the source with the given ID, or ErrNotFound
+func (s *SQLiteStore) Get(id int64) (*types.WatchedSource, error) {
+	source, err := scanSource(s.db.QueryRow(`SELECT `+sourceColumns+` FROM sources WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watched source: %w", err)
+	}
+	return source, nil
+}
+
+// List returns every watched source in the order they were added
+func (s *SQLiteStore) List() ([]types.WatchedSource, error) {
+	return s.querySources(`SELECT ` + sourceColumns + ` FROM sources ORDER BY id`)
+}
+
+// Due returns the enabled sources whose next run is at or before now, longest overdue first
+func (s *SQLiteStore) Due(now time.Time) ([]types.WatchedSource, error) {
+	return s.querySources(`SELECT `+sourceColumns+` FROM sources
+		WHERE enabled = 1 AND next_run_at <= ? ORDER BY next_run_at, id`, now.UnixMilli())
+}
+
+// querySources returns the sources selected with sourceColumns by query
+func (s *SQLiteStore) querySources(query string, args ...any) ([]types.WatchedSource, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watched sources: %w", err)
+	}
+	defer rows.Close()
+
+	sources := []types.WatchedSource{}
+	for rows.Next() {
+		source, err := scanSource(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read watched source: %w", err)
+		}
+		sources = append(sources, *source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read watched sources: %w", err)
+	}
+	return sources, nil
+}
+
+// SeenArtists returns the keys, as given by alias.Key, of the artist names seen on a source
+func (s *SQLiteStore) SeenArtists(sourceID int64) (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT name_key FROM seen_artists WHERE source_id = ?`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seen artists: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to read seen artist: %w", err)
+		}
+		seen[key] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read seen artists: %w", err)
+	}
+	return seen, nil
+}
+
+// MarkSeen records artist names as seen on a source; names seen before keep when they were first seen
+func (s *SQLiteStore) MarkSeen(sourceID int64, names []string, at time.Time) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record seen artists: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, name := range names {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO seen_artists (source_id, name_key, name, first_seen_at)
+			VALUES (?, ?, ?, ?)`, sourceID, alias.Key(name), name, at.UnixMilli()); err != nil {
+			return fmt.Errorf("failed to record seen artist: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record seen artists: %w", err)
+	}
+	return nil
+}
+
+// StartRun records a run of a source as running, setting its ID
+func (s *SQLiteStore) StartRun(run *types.WatchRun) error {
+	run.Status = types.WatchRunRunning
+	result, err := s.db.Exec(`INSERT INTO runs (source_id, status, started_at) VALUES (?, ?, ?)`,
+		run.SourceID, string(run.Status), run.StartedAt.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("failed to record watch run: %w", err)
+	}
+	if run.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read watch run ID: %w", err)
+	}
+	return nil
+}
+
+// FinishRun records the outcome of a run, schedules its source's next run at nextRunAt
+// and keeps the newest retention runs of the source, or every run when retention is 0
+func (s *SQLiteStore) FinishRun(run *types.WatchRun, nextRunAt time.Time, retention int) error {
+	newArtists := run.NewArtists
+	if newArtists == nil {
+		newArtists = []string{}
+	}
+	newArtistsJSON, err := json.Marshal(newArtists)
+	if err != nil {
+		return fmt.Errorf("failed to encode new artists: %w", err)
+	}
+	var finishedAt sql.NullInt64
+	if run.FinishedAt != nil {
+		finishedAt = sql.NullInt64{Int64: run.FinishedAt.UnixMilli(), Valid: true}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record watch run: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`UPDATE runs SET status = ?, artists_found = ?, new_artists = ?, success_count = ?,
+		duplicate_count = ?, blocked_count = ?, review_count = ?, failure_count = ?, tracks_added = ?,
+		message = ?, error = ?, finished_at = ? WHERE id = ?`,
+		string(run.Status), run.ArtistsFound, string(newArtistsJSON), run.SuccessCount, run.DuplicateCount,
+		run.BlockedCount, run.ReviewCount, run.FailureCount, run.TotalTracksAdded, run.Message, run.Error,
+		finishedAt, run.ID); err != nil {
+		return fmt.Errorf("failed to record watch run: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE sources SET last_run_at = ?, next_run_at = ? WHERE id = ?`,
+		run.StartedAt.UnixMilli(), nextRunAt.UnixMilli(), run.SourceID); err != nil {
+		return fmt.Errorf("failed to schedule watched source: %w", err)
+	}
+	if retention > 0 {
+		if _, err := tx.Exec(`DELETE FROM runs WHERE source_id = ? AND id NOT IN
+			(SELECT id FROM runs WHERE source_id = ? ORDER BY id DESC LIMIT ?)`,
+			run.SourceID, run.SourceID, retention); err != nil {
+			return fmt.Errorf("failed to prune watch runs: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record watch run: %w", err)
+	}
+	return nil
+}
+
+// FailInterrupted marks the runs left running by a process that stopped mid-run as
+// failed, returning how many there were
+func (s *SQLiteStore) FailInterrupted() (int64, error) {
+	result, err := s.db.Exec(`UPDATE runs SET status = ?, error = ? WHERE status = ?`,
+		string(types.WatchRunFailed), "interrupted before it finished", string(types.WatchRunRunning))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted watch runs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Runs returns a source's runs, newest first
+func (s *SQLiteStore) Runs(sourceID int64, limit, offset int) ([]types.WatchRun, error) {
+	switch {
+	case limit <= 0:
+		limit = types.DefaultWatchRunLimit
+	case limit > types.MaxWatchRunLimit:
+		limit = types.MaxWatchRunLimit
+	}
+
+	rows, err := s.db.Query(`SELECT `+runColumns+` FROM runs WHERE source_id = ?
+		ORDER BY id DESC LIMIT ? OFFSET ?`, sourceID, limit, max(offset, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watch runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []types.WatchRun{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read watch run: %w", err)
+		}
+		runs = append(runs, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read watch runs: %w", err)
+	}
+	return runs, nil
+}
+
+// sourceColumns are the columns read by scanSource, in order
+const sourceColumns = "id, url, css_selector, playlist_id, mode, selection, schedule, enabled, " +
+	"user_id, created_at, last_run_at, next_run_at"
+
+// scanSource reads a watched source from a row selected with sourceColumns
+func scanSource(row interface{ Scan(dest ...any) error }) (*types.WatchedSource, error) {
+	var (
+		source               types.WatchedSource
+		mode, selectionJSON  string
+		createdAt, nextRunAt int64
+		lastRunAt            sql.NullInt64
+	)
+	if err := row.Scan(&source.ID, &source.URL, &source.CSSSelector, &source.PlaylistID, &mode,
+		&selectionJSON, &source.Schedule, &source.Enabled, &source.User, &createdAt, &lastRunAt,
+		&nextRunAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(selectionJSON), &source.Selection); err != nil {
+		return nil, fmt.Errorf("failed to decode watched source track selection: %w", err)
+	}
+	source.Mode = types.AddMode(mode)
+	source.CreatedAt = time.UnixMilli(createdAt).UTC()
+	source.NextRunAt = time.UnixMilli(nextRunAt).UTC()
+	if lastRunAt.Valid {
+		last := time.UnixMilli(lastRunAt.Int64).UTC()
+		source.LastRunAt = &last
+	}
+	return &source, nil
+}
+
+// runColumns are the columns read by scanRun, in order
+const runColumns = "id, source_id, status, artists_found, new_artists, success_count, duplicate_count, " +
+	"blocked_count, review_count, failure_count, tracks_added, message, error, started_at, finished_at"
+
+// scanRun reads a watch run from a row selected with runColumns
+func scanRun(row interface{ Scan(dest ...any) error }) (*types.WatchRun, error) {
+	var (
+		run                    types.WatchRun
+		status, newArtistsJSON string
+		startedAt              int64
+		finishedAt             sql.NullInt64
+	)
+	if err := row.Scan(&run.ID, &run.SourceID, &status, &run.ArtistsFound, &newArtistsJSON,
+		&run.SuccessCount, &run.DuplicateCount, &run.BlockedCount, &run.ReviewCount, &run.FailureCount,
+		&run.TotalTracksAdded, &run.Message, &run.Error, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(newArtistsJSON), &run.NewArtists); err != nil {
+		return nil, fmt.Errorf("failed to decode new artists: %w", err)
+	}
+	run.Status = types.WatchRunStatus(status)
+	run.StartedAt = time.UnixMilli(startedAt).UTC()
+	if finishedAt.Valid {
+		finished := time.UnixMilli(finishedAt.Int64).UTC()
+		run.FinishedAt = &finished
+	}
+	return &run, nil
+}
@@ -0,0 +1,233 @@
+package watch
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-listen/internal/types"
+)
+
+func openTestStore(t *testing.T) (*SQLiteStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data", "watch.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, path
+}
+
+func newSource(url string) *types.WatchedSource {
+	return &types.WatchedSource{
+		URL:        url,
+		PlaylistID: "playlist1",
+		Mode:       types.AddModeSkip,
+		Selection:  types.TrackSelection{Count: 3, Strategy: types.TrackStrategyTop},
+		Schedule:   "weekly",
+		Enabled:    true,
+		User:       "user1",
+	}
+}
+
+func TestOpen(t *testing.T) {
+	store, path := openTestStore(t)
+
+	if err := store.Add(newSource("https://example.com/lineup")); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+
+	// Reopening an existing database must not reapply migrations or lose data
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() on existing database unexpected error: %v", err)
+	}
+	defer reopened.Close()
+
+	sources, err := reopened.List()
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(sources) != 1 || sources[0].URL != "https://example.com/lineup" {
+		t.Errorf("List() after reopen = %+v, want the watched source", sources)
+	}
+
+	if _, err := Open(""); err == nil {
+		t.Error("Open(\"\") expected error but got none")
+	}
+}
+
+func TestSQLiteStore_AddGetUpdateDelete(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	source := newSource("https://example.com/lineup")
+	if err := store.Add(source); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if source.ID == 0 || !source.NextRunAt.Equal(source.CreatedAt) {
+		t.Fatalf("Add() = %+v, want an ID and the first run due right away", source)
+	}
+
+	got, err := store.Get(source.ID)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if got.URL != source.URL || got.Selection.Count != 3 || got.Schedule != "weekly" || !got.Enabled ||
+		got.User != "user1" || got.LastRunAt != nil {
+		t.Errorf("Get() = %+v, want the added source", got)
+	}
+
+	if err := store.MarkSeen(source.ID, []string{"Big Thief"}, time.Now()); err != nil {
+		t.Fatalf("MarkSeen() unexpected error: %v", err)
+	}
+
+	// Changing the schedule keeps the artists seen on the page
+	got.Schedule = "daily"
+	got.Enabled = false
+	if err := store.Update(got); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if seen, _ := store.SeenArtists(source.ID); len(seen) != 1 {
+		t.Errorf("SeenArtists() after a schedule change = %v, want them kept", seen)
+	}
+	updated, err := store.Get(source.ID)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if updated.Schedule != "daily" || updated.Enabled {
+		t.Errorf("Get() after Update() = %+v, want a disabled daily source", updated)
+	}
+
+	// but changing what is scraped forgets them
+	updated.CSSSelector = "ul.lineup li"
+	if err := store.Update(updated); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if seen, _ := store.SeenArtists(source.ID); len(seen) != 0 {
+		t.Errorf("SeenArtists() after a selector change = %v, want none", seen)
+	}
+
+	if err := store.Delete(source.ID); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := store.Get(source.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(source.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrNotFound", err)
+	}
+	if err := store.Update(updated); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update() unknown source error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSQLiteStore_Due(t *testing.T) {
+	store, _ := openTestStore(t)
+	now := time.Now()
+
+	for _, source := range []*types.WatchedSource{
+		{URL: "https://example.com/overdue", NextRunAt: now.Add(-2 * time.Hour), Enabled: true},
+		{URL: "https://example.com/due", NextRunAt: now.Add(-time.Minute), Enabled: true},
+		{URL: "https://example.com/later", NextRunAt: now.Add(time.Hour), Enabled: true},
+		{URL: "https://example.com/disabled", NextRunAt: now.Add(-time.Hour)},
+	} {
+		if err := store.Add(source); err != nil {
+			t.Fatalf("Add() unexpected error: %v", err)
+		}
+	}
+
+	due, err := store.Due(now)
+	if err != nil {
+		t.Fatalf("Due() unexpected error: %v", err)
+	}
+	if len(due) != 2 || due[0].URL != "https://example.com/overdue" || due[1].URL != "https://example.com/due" {
+		t.Errorf("Due() = %+v, want the enabled due sources, longest overdue first", due)
+	}
+}
+
+func TestSQLiteStore_MarkSeen(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	if err := store.MarkSeen(1, []string{"Big Thief", "  big   THIEF ", "Nirvana"}, time.Now()); err != nil {
+		t.Fatalf("MarkSeen() unexpected error: %v", err)
+	}
+	if err := store.MarkSeen(1, []string{"Nirvana"}, time.Now()); err != nil {
+		t.Fatalf("MarkSeen() again unexpected error: %v", err)
+	}
+
+	seen, err := store.SeenArtists(1)
+	if err != nil {
+		t.Fatalf("SeenArtists() unexpected error: %v", err)
+	}
+	if len(seen) != 2 || !seen["big thief"] || !seen["nirvana"] {
+		t.Errorf("SeenArtists() = %v, want big thief and nirvana", seen)
+	}
+	if other, _ := store.SeenArtists(2); len(other) != 0 {
+		t.Errorf("SeenArtists() of another source = %v, want none", other)
+	}
+}
+
+func TestSQLiteStore_Runs(t *testing.T) {
+	store, _ := openTestStore(t)
+	source := newSource("https://example.com/lineup")
+	if err := store.Add(source); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	for i := range 3 {
+		run := &types.WatchRun{SourceID: source.ID, StartedAt: start.Add(time.Duration(i) * time.Minute)}
+		if err := store.StartRun(run); err != nil {
+			t.Fatalf("StartRun() unexpected error: %v", err)
+		}
+		finished := run.StartedAt.Add(time.Second)
+		run.FinishedAt = &finished
+		run.Status = types.WatchRunSucceeded
+		run.ArtistsFound = 10
+		run.NewArtists = []string{"Big Thief"}
+		run.SuccessCount = i
+		if err := store.FinishRun(run, finished.Add(time.Hour), 2); err != nil {
+			t.Fatalf("FinishRun() unexpected error: %v", err)
+		}
+	}
+
+	// Only the newest runs are kept, newest first
+	runs, err := store.Runs(source.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("Runs() unexpected error: %v", err)
+	}
+	if len(runs) != 2 || runs[0].SuccessCount != 2 || runs[1].SuccessCount != 1 {
+		t.Fatalf("Runs() = %+v, want the last two runs, newest first", runs)
+	}
+	if runs[0].Status != types.WatchRunSucceeded || runs[0].ArtistsFound != 10 || len(runs[0].NewArtists) != 1 ||
+		runs[0].FinishedAt == nil {
+		t.Errorf("Runs()[0] = %+v, want the recorded outcome", runs[0])
+	}
+
+	// Finishing a run schedules the source
+	got, err := store.Get(source.ID)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if got.LastRunAt == nil || !got.LastRunAt.Equal(runs[0].StartedAt) || !got.NextRunAt.After(*got.LastRunAt) {
+		t.Errorf("Get() after FinishRun() = %+v, want its last and next run", got)
+	}
+
+	// A run left running by a stopped process is failed
+	interrupted := &types.WatchRun{SourceID: source.ID, StartedAt: time.Now()}
+	if err := store.StartRun(interrupted); err != nil {
+		t.Fatalf("StartRun() unexpected error: %v", err)
+	}
+	if count, err := store.FailInterrupted(); err != nil || count != 1 {
+		t.Errorf("FailInterrupted() = %d, %v, want 1 run", count, err)
+	}
+	runs, _ = store.Runs(source.ID, 1, 0)
+	if len(runs) != 1 || runs[0].Status != types.WatchRunFailed || runs[0].Error == "" {
+		t.Errorf("Runs() after FailInterrupted() = %+v, want the interrupted run failed", runs)
+	}
+}
@@ -1,41 +1,88 @@
 package types
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
-// SpotifyService defines the interface for Spotify API operations
+// SpotifyService defines the interface for Spotify API operations. Every call
+// that reaches Spotify stops when its context is cancelled.
 type SpotifyService interface {
-	SearchArtist(query string) (*Artist, error)
-	GetArtistTopTracks(artistID string) ([]Track, error)
-	GetUserPlaylists(folderName string) ([]Playlist, error)
-	AddTracksToPlaylist(playlistID string, trackIDs []string) error
-	CheckTracksInPlaylist(playlistID string, trackIDs []string) ([]bool, error)
-	GetAuthURL() string
+	SearchArtist(ctx context.Context, query string) (*Artist, error)
+	SearchArtists(ctx context.Context, query string, limit int) ([]Artist, error)
+	GetArtist(ctx context.Context, artistID string) (*Artist, error)
+	GetArtistTracks(ctx context.Context, artistID string, selection TrackSelection) ([]Track, error)
+	GetUserPlaylists(ctx context.Context) ([]Playlist, error)
+	AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error
+	CheckTracksInPlaylist(ctx context.Context, playlistID string, trackIDs []string) ([]bool, error)
+	GetPlaylistTracks(ctx context.Context, playlistID string) ([]Track, error)
+	GetAuthURL(state, codeVerifier string) string
 	IsAuthenticated() bool
-	CompleteAuth(code, state string) error
+	CompleteAuth(ctx context.Context, code, codeVerifier string) error
+	CurrentUserID(ctx context.Context) (string, error)
 }
 
 // PlaylistManager defines the interface for playlist management operations
 type PlaylistManager interface {
-	AddArtistToPlaylist(artistName, playlistID string, force bool) (*AddResult, error)
-	GetIncomingPlaylists() ([]Playlist, error)
-	GetTop5Tracks(artistID string) ([]Track, error)
+	AddArtistToPlaylist(ctx context.Context, artistName, playlistID string, mode AddMode, selection TrackSelection, source AddSource) (*AddResult, error)
+	AddArtistByID(ctx context.Context, artistID, playlistID string, mode AddMode, selection TrackSelection, source AddSource) (*AddResult, error)
+	GetIncomingPlaylists(ctx context.Context) ([]Playlist, error)
+	ListPlaylists(ctx context.Context) ([]Playlist, error)
+	MarkIncoming(ctx context.Context, playlistID string) error
+	UnmarkIncoming(ctx context.Context, playlistID string) error
+	GetArtistTracks(ctx context.Context, artistID string, selection TrackSelection) ([]Track, error)
 	FilterPlaylistsBySearch(playlists []Playlist, searchTerm string) []Playlist
-	AddTracksToPlaylist(playlistID string, trackIDs []string) error
-	CheckForDuplicates(playlistID string, trackIDs []string) (*DuplicateResult, error)
+	AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string) error
+	CheckForDuplicates(ctx context.Context, playlistID string, trackIDs []string) (*DuplicateResult, error)
+	FilterMissingTracks(ctx context.Context, playlistID string, tracks []Track) (missing, present []Track, err error)
 }
 
 // DuplicateDetector defines the interface for duplicate detection
 type DuplicateDetector interface {
-	CheckDuplicates(playlistID string, tracks []Track) (*DuplicateResult, error)
-	CheckArtistInPlaylist(playlistID, artistID string) (*DuplicateResult, error)
+	CheckDuplicates(ctx context.Context, playlistID string, tracks []Track) (*DuplicateResult, error)
+	CheckArtistInPlaylist(ctx context.Context, playlistID, artistID string) (*DuplicateResult, error)
+	InvalidatePlaylist(playlistID string)
+}
+
+// HistoryStore defines the interface for the persistent history of artist additions
+type HistoryStore interface {
+	Record(entry *HistoryEntry) error
+	LastAdded(playlistID, artistID string) (*HistoryEntry, error)
+	List(filter HistoryFilter) ([]HistoryEntry, error)
 }
 
 // ArtistSearcher defines the interface for artist search with fuzzy matching
 type ArtistSearcher interface {
-	FindBestMatch(query string) (*Artist, float64, error)
+	FindBestMatch(ctx context.Context, query string) (*Artist, float64, error)
+	FindCandidates(ctx context.Context, query string, limit int) ([]ArtistCandidate, error)
+}
+
+// ReviewQueue holds scraped artists matched with too little confidence to be added
+// without someone approving the match first
+type ReviewQueue interface {
+	// Enqueue queues an item, setting its ID. An artist name already waiting for
+	// review for the same playlist is not queued twice; the item gets that entry's ID.
+	Enqueue(item *ReviewItem) error
+}
+
+// AliasResolver looks up the artist a name has been aliased to
+type AliasResolver interface {
+	Resolve(name string) (ArtistAlias, bool)
+}
+
+// PlaylistRouter picks the playlist an artist is added to when a request targets AutoPlaylistID
+type PlaylistRouter interface {
+	// Route returns the artist's playlist, or nil if no rule matches and there is no default playlist
+	Route(ctx context.Context, artist Artist) (*PlaylistRoute, error)
+}
+
+// ArtistBlocklist decides which artists are never added to playlists
+type ArtistBlocklist interface {
+	// Blocked reports whether an artist is blocked and the rule that blocked it
+	Blocked(artist Artist) (bool, BlockReason)
 }
 
 // RateLimiter defines the interface for rate limiting functionality
@@ -61,6 +108,32 @@ type Artist struct {
 	Name   string   `json:"name"`
 	URI    string   `json:"uri"`
 	Genres []string `json:"genres"`
+	// Followers, Popularity (0-100) and ImageURL are only known for artists found
+	// by a search or lookup, not for the artists credited on a track
+	Followers  int    `json:"followers,omitempty"`
+	Popularity int    `json:"popularity,omitempty"`
+	ImageURL   string `json:"image_url,omitempty"`
+}
+
+// ArtistCandidate is one of the artists a search for a name could refer to
+type ArtistCandidate struct {
+	Artist Artist `json:"artist"`
+	// NameScore is how closely the artist's name matches the query, from 0.0 to 1.0
+	NameScore float64 `json:"name_score"`
+	// Confidence combines the name score with the artist's popularity, followers and
+	// genres relative to the other candidates, from 0.0 to 1.0
+	Confidence float64 `json:"confidence"`
+	// Alias is set when the query is an alias of the artist, which is then the only candidate
+	Alias bool `json:"alias,omitempty"`
+}
+
+// ArtistAlias maps a name to the Spotify artist it always resolves to, so the
+// fuzzy search is skipped for names it gets wrong
+type ArtistAlias struct {
+	Name     string `json:"name" yaml:"name"`
+	ArtistID string `json:"artist_id" yaml:"artist_id"`
+	// ArtistName is the Spotify artist's name, kept so the alias list is readable
+	ArtistName string `json:"artist_name,omitempty" yaml:"artist_name,omitempty"`
 }
 
 // Track represents a Spotify track
@@ -70,16 +143,280 @@ type Track struct {
 	URI      string   `json:"uri"`
 	Artists  []Artist `json:"artists"`
 	Duration int      `json:"duration_ms"`
+	// ISRC identifies the recording across every release it appears on, when Spotify reports it
+	ISRC  string `json:"isrc,omitempty"`
+	Album *Album `json:"album,omitempty"`
+}
+
+// Album represents the release a track appears on
+type Album struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	URI         string `json:"uri,omitempty"`
+	AlbumType   string `json:"album_type,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"`
+}
+
+// TrackStrategy selects which of an artist's tracks are added to a playlist
+type TrackStrategy string
+
+// Supported track selection strategies
+const (
+	// TrackStrategyTop picks the artist's most popular tracks
+	TrackStrategyTop TrackStrategy = "top"
+	// TrackStrategyLatest picks tracks from the artist's most recent releases
+	TrackStrategyLatest TrackStrategy = "latest"
+	// TrackStrategyRandom picks a random sample of the artist's catalog
+	TrackStrategyRandom TrackStrategy = "random"
+)
+
+// AddMode controls how an artist's tracks that may already be in a playlist are handled
+type AddMode string
+
+// Supported add modes
+const (
+	// AddModeSkip refuses an artist that already has tracks in the playlist
+	AddModeSkip AddMode = "skip"
+	// AddModeForce adds every selected track, even ones already in the playlist
+	AddModeForce AddMode = "force"
+	// AddModeFillGaps adds only the selected tracks that aren't in the playlist yet
+	AddModeFillGaps AddMode = "fill_gaps"
+)
+
+// ParseAddMode parses a mode name, treating an empty name as AddModeSkip. Hyphens
+// are accepted in place of underscores, so "fill-gaps" parses as AddModeFillGaps.
+func ParseAddMode(name string) (AddMode, error) {
+	switch mode := normalizeAddMode(name); mode {
+	case "":
+		return AddModeSkip, nil
+	case AddModeSkip, AddModeForce, AddModeFillGaps:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown add mode %q (must be one of skip, force, fill_gaps)", name)
+	}
+}
+
+// Validate checks the mode is known
+func (m AddMode) Validate() error {
+	_, err := ParseAddMode(string(m))
+	return err
+}
+
+func normalizeAddMode(name string) AddMode {
+	return AddMode(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_"))
+}
+
+// requestAddMode resolves a request's mode, falling back to the force flag when no mode is given
+func requestAddMode(mode string, force bool) AddMode {
+	switch {
+	case mode != "":
+		return normalizeAddMode(mode)
+	case force:
+		return AddModeForce
+	default:
+		return AddModeSkip
+	}
+}
+
+// Track count limits for a TrackSelection
+const (
+	MinTrackCount     = 1
+	MaxTrackCount     = 10
+	DefaultTrackCount = 5
+)
+
+// Limits on how many artist searches run against Spotify at the same time. The
+// maximum keeps a batch of searches well within Spotify's rate limits.
+const (
+	DefaultSearchConcurrency = 4
+	MaxSearchConcurrency     = 16
+)
+
+// ClampSearchConcurrency limits n to between 1 and MaxSearchConcurrency
+func ClampSearchConcurrency(n int) int {
+	return min(max(n, 1), MaxSearchConcurrency)
+}
+
+// Limits on how many candidates an artist search returns
+const (
+	DefaultCandidateLimit = 5
+	MaxCandidateLimit     = 10
+)
+
+// Thresholds deciding when an artist search is too ambiguous to pick an artist automatically
+const (
+	// MinCandidateConfidence is the confidence below which the best candidate isn't trusted
+	MinCandidateConfidence = 0.5
+	// DisambiguationMargin is how close two candidates' confidence must be for either
+	// to be the artist the user meant
+	DisambiguationMargin = 0.15
+)
+
+// NeedsDisambiguation reports whether the user should choose between candidates,
+// sorted most confident first, rather than the first being picked: when none of them
+// is a confident match, or when another candidate's name matches as well as the
+// first's and it is nearly as confident
+func NeedsDisambiguation(candidates []ArtistCandidate) bool {
+	if len(candidates) < 2 {
+		return false
+	}
+	best := candidates[0]
+	if best.Confidence < MinCandidateConfidence {
+		return true
+	}
+	for _, candidate := range candidates[1:] {
+		if candidate.NameScore >= best.NameScore && best.Confidence-candidate.Confidence <= DisambiguationMargin {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrackStrategy parses a strategy name, treating an empty name as TrackStrategyTop
+func ParseTrackStrategy(name string) (TrackStrategy, error) {
+	switch strategy := TrackStrategy(strings.ToLower(strings.TrimSpace(name))); strategy {
+	case "":
+		return TrackStrategyTop, nil
+	case TrackStrategyTop, TrackStrategyLatest, TrackStrategyRandom:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown track strategy %q (must be one of top, latest, random)", name)
+	}
+}
+
+// TrackSelection controls how many and which tracks are added for an artist
+type TrackSelection struct {
+	Count    int           `json:"track_count"`
+	Strategy TrackStrategy `json:"strategy"`
+	// Market overrides the configured market; tracks not playable there are skipped
+	Market string `json:"market,omitempty"`
+}
+
+// DefaultTrackSelection returns the selection used when a request does not specify one
+func DefaultTrackSelection() TrackSelection {
+	return TrackSelection{Count: DefaultTrackCount, Strategy: TrackStrategyTop}
+}
+
+// WithDefaults fills in a zero count and empty strategy with their defaults
+func (s TrackSelection) WithDefaults() TrackSelection {
+	if s.Count == 0 {
+		s.Count = DefaultTrackCount
+	}
+	if s.Strategy == "" {
+		s.Strategy = TrackStrategyTop
+	}
+	return s
+}
+
+// Validate checks the track count is within range, the strategy is known and the market is well formed
+func (s TrackSelection) Validate() error {
+	if s.Count < MinTrackCount || s.Count > MaxTrackCount {
+		return fmt.Errorf("track count must be between %d and %d", MinTrackCount, MaxTrackCount)
+	}
+	if _, err := ParseTrackStrategy(string(s.Strategy)); err != nil {
+		return err
+	}
+	if s.Market != "" && !IsValidMarket(s.Market) {
+		return fmt.Errorf("market must be a two-letter ISO 3166-1 country code")
+	}
+	return nil
+}
+
+// IsValidMarket reports whether market looks like an ISO 3166-1 alpha-2 country code
+func IsValidMarket(market string) bool {
+	if len(market) != 2 {
+		return false
+	}
+	for _, r := range market {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// SpotifyIDLength is the length of Spotify artist, track and playlist IDs
+const SpotifyIDLength = 22
+
+// IsSpotifyID reports whether id is shaped like a Spotify ID: 22 base-62 characters
+func IsSpotifyID(id string) bool {
+	if len(id) != SpotifyIDLength {
+		return false
+	}
+	for _, r := range id {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
 }
 
 // Playlist represents a Spotify playlist
 type Playlist struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	URI        string `json:"uri"`
-	TrackCount int    `json:"track_count"`
-	EmbedURL   string `json:"embed_url"`
-	IsIncoming bool   `json:"is_incoming"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	URI         string `json:"uri"`
+	TrackCount  int    `json:"track_count"`
+	EmbedURL    string `json:"embed_url"`
+	IsIncoming  bool   `json:"is_incoming"`
+	// IncomingReason names the rule that made the playlist incoming or excluded it
+	IncomingReason IncomingReason `json:"incoming_reason,omitempty"`
+}
+
+// IncomingReason records why a playlist is or isn't treated as an incoming playlist
+type IncomingReason string
+
+// Incoming playlist reasons, in order of precedence
+const (
+	// IncomingReasonMarked means the playlist was marked incoming through the admin API
+	IncomingReasonMarked IncomingReason = "marked"
+	// IncomingReasonUnmarked means the playlist was excluded through the admin API
+	IncomingReasonUnmarked IncomingReason = "unmarked"
+	// IncomingReasonConfiguredID means the playlist ID is in the configured allowlist
+	IncomingReasonConfiguredID IncomingReason = "configured_id"
+	// IncomingReasonNamePattern means the playlist name matches the configured pattern
+	IncomingReasonNamePattern IncomingReason = "name_pattern"
+	// IncomingReasonDescriptionMarker means the playlist description contains the configured marker
+	IncomingReasonDescriptionMarker IncomingReason = "description_marker"
+)
+
+// AutoPlaylistID is the playlist ID asking for each artist to be routed to a playlist by genre
+const AutoPlaylistID = "auto"
+
+// PlaylistRoute records where an automatically routed artist was sent
+type PlaylistRoute struct {
+	PlaylistID   string `json:"playlist_id"`
+	PlaylistName string `json:"playlist_name,omitempty"`
+	// Genre is the routing rule's genre the artist matched; empty when the default playlist was used
+	Genre string `json:"genre,omitempty"`
+}
+
+// BlockReason records which blocklist rule blocked an artist
+type BlockReason string
+
+// Blocklist reasons, in order of precedence
+const (
+	// BlockReasonArtistID means the artist's Spotify ID is blocked
+	BlockReasonArtistID BlockReason = "artist_id"
+	// BlockReasonNamePattern means the artist's name matches the blocked name pattern
+	BlockReasonNamePattern BlockReason = "name_pattern"
+	// BlockReasonGenre means one of the artist's genres is blocked
+	BlockReasonGenre BlockReason = "genre"
+)
+
+// Message describes why an artist was blocked
+func (r BlockReason) Message() string {
+	switch r {
+	case BlockReasonArtistID:
+		return "Artist is blocklisted"
+	case BlockReasonNamePattern:
+		return "Artist name is blocklisted"
+	case BlockReasonGenre:
+		return "Artist genre is blocklisted"
+	default:
+		return "Artist is blocked"
+	}
 }
 
 // AddResult represents the result of adding an artist to a playlist
@@ -89,25 +426,311 @@ type AddResult struct {
 	TracksAdded  []Track  `json:"tracks_added"`
 	Playlist     Playlist `json:"playlist"`
 	WasDuplicate bool     `json:"was_duplicate"`
-	Message      string   `json:"message"`
+	// TracksSkipped lists the selected tracks left out because they were already in the playlist
+	TracksSkipped []Track `json:"tracks_skipped,omitempty"`
+	// LastAdded is when the artist was last added to the playlist, if known from history
+	LastAdded *time.Time `json:"last_added,omitempty"`
+	// NeedsDisambiguation is set, with nothing added, when several artists match the
+	// name; Candidates lists them so the request can be repeated with one's artist ID
+	NeedsDisambiguation bool              `json:"needs_disambiguation,omitempty"`
+	Candidates          []ArtistCandidate `json:"candidates,omitempty"`
+	// Blocked is set, with nothing added, when the artist is on the blocklist
+	Blocked     bool        `json:"blocked,omitempty"`
+	BlockReason BlockReason `json:"block_reason,omitempty"`
+	// Route is the playlist the artist was routed to when the request targeted AutoPlaylistID
+	Route   *PlaylistRoute `json:"route,omitempty"`
+	Message string         `json:"message"`
 }
 
 // DuplicateResult represents the result of duplicate detection
 type DuplicateResult struct {
-	HasDuplicates   bool      `json:"has_duplicates"`
-	DuplicateTracks []Track   `json:"duplicate_tracks"`
-	LastAdded       time.Time `json:"last_added"`
-	ArtistName      string    `json:"artist_name"`
-	Message         string    `json:"message"`
+	HasDuplicates   bool    `json:"has_duplicates"`
+	DuplicateTracks []Track `json:"duplicate_tracks"`
+	// LastAdded is when the artist was last added to the playlist, if known from history
+	LastAdded  *time.Time `json:"last_added,omitempty"`
+	ArtistName string     `json:"artist_name"`
+	// PlaylistIDs lists the playlists the artist was found in, the checked playlist first
+	PlaylistIDs []string `json:"playlist_ids,omitempty"`
+	Message     string   `json:"message"`
+}
+
+// SourceKind identifies how an artist addition was requested
+type SourceKind string
+
+// Supported addition sources
+const (
+	// SourceUI is the bundled web interface
+	SourceUI SourceKind = "ui"
+	// SourceAPI is a direct call to the HTTP API
+	SourceAPI SourceKind = "api"
+	// SourceCLI is the go-listen command line
+	SourceCLI SourceKind = "cli"
+	// SourceWatch is a scheduled scrape of a watched source
+	SourceWatch SourceKind = "watch"
+)
+
+// AddSource describes who requested an artist addition and from where
+type AddSource struct {
+	Kind SourceKind `json:"kind"`
+	// URL is the scraped page the artist was found on, empty for direct additions
+	URL string `json:"url,omitempty"`
+	// User is the Spotify ID of the authenticated user
+	User string `json:"user,omitempty"`
+}
+
+// HistoryEntry records a single addition of an artist's tracks to a playlist
+type HistoryEntry struct {
+	ID         int64      `json:"id"`
+	ArtistID   string     `json:"artist_id"`
+	ArtistName string     `json:"artist_name"`
+	PlaylistID string     `json:"playlist_id"`
+	Tracks     []Track    `json:"tracks"`
+	Source     SourceKind `json:"source"`
+	SourceURL  string     `json:"source_url,omitempty"`
+	User       string     `json:"user,omitempty"`
+	AddedAt    time.Time  `json:"added_at"`
+}
+
+// Limits for a HistoryFilter
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 500
+)
+
+// HistoryFilter narrows a history query; zero fields match everything
+type HistoryFilter struct {
+	ArtistID   string
+	PlaylistID string
+	Source     SourceKind
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
+// ReviewStatus is where an item of the review queue stands
+type ReviewStatus string
+
+// Review statuses; an item leaves ReviewPending once, and is never reopened
+const (
+	// ReviewPending items wait for someone to decide on them
+	ReviewPending ReviewStatus = "pending"
+	// ReviewAccepted items had their best candidate added
+	ReviewAccepted ReviewStatus = "accepted"
+	// ReviewRemapped items had another artist than the best candidate added
+	ReviewRemapped ReviewStatus = "remapped"
+	// ReviewRejected items were dismissed without adding anything
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// ReviewItem is a scraped artist name whose match waits for approval before the
+// artist is added, with everything needed to add it once approved
+type ReviewItem struct {
+	ID    int64  `json:"id"`
+	Query string `json:"query"`
+	// Artist is the best candidate, and Confidence how well it matches Query
+	Artist     Artist            `json:"artist"`
+	Confidence float64           `json:"confidence"`
+	Candidates []ArtistCandidate `json:"candidates"`
+	PlaylistID string            `json:"playlist_id"`
+	Mode       AddMode           `json:"mode"`
+	Selection  TrackSelection    `json:"selection"`
+	// Source and SourceURL are how the scrape was requested and the page it read;
+	// FoundBy is where on the page the name was found, such as "text"
+	Source    SourceKind   `json:"source"`
+	SourceURL string       `json:"source_url,omitempty"`
+	FoundBy   string       `json:"found_by,omitempty"`
+	User      string       `json:"user,omitempty"`
+	Status    ReviewStatus `json:"status"`
+	// ResolvedArtistID is the artist added when the item was accepted or remapped
+	ResolvedArtistID string     `json:"resolved_artist_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Limits for a ReviewFilter
+const (
+	DefaultReviewLimit = 50
+	MaxReviewLimit     = 500
+)
+
+// ReviewFilter narrows a review queue query; zero fields match everything
+type ReviewFilter struct {
+	Status     ReviewStatus
+	PlaylistID string
+	Limit      int
+	Offset     int
+}
+
+// ReviewDecision is a decision on a review queue item
+type ReviewDecision struct {
+	// ArtistID, if set, adds this artist instead of the best candidate
+	ArtistID string `json:"artist_id,omitempty"`
+}
+
+// ReviewResolution is the outcome of deciding on a review queue item: the item as
+// it was resolved and, when it was accepted or remapped, the addition of its artist
+type ReviewResolution struct {
+	Item   ReviewItem `json:"item"`
+	Result *AddResult `json:"result,omitempty"`
+}
+
+// WatchedSource is a page the server re-scrapes on a schedule, adding only the
+// artists that appeared on it since it was last scraped
+type WatchedSource struct {
+	ID          int64          `json:"id"`
+	URL         string         `json:"url"`
+	CSSSelector string         `json:"css_selector,omitempty"`
+	PlaylistID  string         `json:"playlist_id"`
+	Mode        AddMode        `json:"mode"`
+	Selection   TrackSelection `json:"selection"`
+	// Schedule is how often the page is scraped: hourly, daily, weekly or a duration such as "12h"
+	Schedule string `json:"schedule"`
+	Enabled  bool   `json:"enabled"`
+	// User is the Spotify ID of the user who added the source, credited with its additions
+	User      string     `json:"user,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	NextRunAt time.Time  `json:"next_run_at"`
+}
+
+// WatchSourceRequest represents a request to watch a page, or to change a watched source
+type WatchSourceRequest struct {
+	URL         string `json:"url" validate:"required,url"`
+	CSSSelector string `json:"css_selector" validate:"max=500"`
+	PlaylistID  string `json:"playlist_id" validate:"required"`
+	Schedule    string `json:"schedule" validate:"required"`
+	// Enabled defaults to true; disabled sources are kept but not scraped
+	Enabled    *bool  `json:"enabled,omitempty"`
+	Force      bool   `json:"force"`
+	Mode       string `json:"mode,omitempty" validate:"omitempty,oneof=skip force fill_gaps"`
+	TrackCount int    `json:"track_count,omitempty" validate:"omitempty,min=1,max=10"`
+	Strategy   string `json:"strategy,omitempty" validate:"omitempty,oneof=top latest random"`
+	Market     string `json:"market,omitempty" validate:"omitempty,len=2"`
+}
+
+// TrackSelection returns the request's track selection with defaults applied
+func (r *WatchSourceRequest) TrackSelection() TrackSelection {
+	return TrackSelection{
+		Count:    r.TrackCount,
+		Strategy: TrackStrategy(strings.ToLower(r.Strategy)),
+		Market:   strings.ToUpper(r.Market),
+	}.WithDefaults()
+}
+
+// AddMode returns the request's mode; Force is shorthand for AddModeForce when no mode is given
+func (r *WatchSourceRequest) AddMode() AddMode {
+	return requestAddMode(r.Mode, r.Force)
+}
+
+// WatchRunStatus is the state of a scheduled scrape of a watched source
+type WatchRunStatus string
+
+// Watch run statuses
+const (
+	WatchRunRunning   WatchRunStatus = "running"
+	WatchRunSucceeded WatchRunStatus = "succeeded"
+	WatchRunFailed    WatchRunStatus = "failed"
+)
+
+// WatchRun records one scrape of a watched source
+type WatchRun struct {
+	ID       int64          `json:"id"`
+	SourceID int64          `json:"source_id"`
+	Status   WatchRunStatus `json:"status"`
+	// ArtistsFound counts every name on the page, and NewArtists lists the ones
+	// not seen on earlier runs, which are the only ones processed
+	ArtistsFound     int        `json:"artists_found"`
+	NewArtists       []string   `json:"new_artists"`
+	SuccessCount     int        `json:"success_count"`
+	DuplicateCount   int        `json:"duplicate_count"`
+	BlockedCount     int        `json:"blocked_count"`
+	ReviewCount      int        `json:"review_count"`
+	FailureCount     int        `json:"failure_count"`
+	TotalTracksAdded int        `json:"total_tracks_added"`
+	Message          string     `json:"message,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+}
+
+// Limits for listing a watched source's runs
+const (
+	DefaultWatchRunLimit = 20
+	MaxWatchRunLimit     = 100
+)
+
+// EventType identifies what happened in a ProgressEvent
+type EventType string
+
+// Progress event types, in the order they usually occur for an artist
+const (
+	// EventArtistFound is an artist name extracted from a scraped page
+	EventArtistFound EventType = "artist_found"
+	// EventArtistMatched is an artist name matched to a Spotify artist
+	EventArtistMatched EventType = "artist_matched"
+	// EventArtistNotMatched is an artist name with no confident Spotify match
+	EventArtistNotMatched EventType = "artist_not_matched"
+	// EventArtistBlocked is an artist left out because it is on the blocklist
+	EventArtistBlocked EventType = "artist_blocked"
+	// EventArtistQueued is a match held in the review queue instead of being added
+	EventArtistQueued EventType = "artist_queued"
+	// EventDuplicateSkipped is an artist left out because it is already in the playlist
+	EventDuplicateSkipped EventType = "duplicate_skipped"
+	// EventTracksAdded is an artist whose tracks were added to the playlist
+	EventTracksAdded EventType = "tracks_added"
+	// EventError is a failure affecting one artist or the whole operation
+	EventError EventType = "error"
+	// EventDone is the summary sent once the operation has stopped
+	EventDone EventType = "done"
+)
+
+// ProgressEvent reports a single step of an operation as it happens, so clients
+// can show a live log instead of waiting for the final result
+type ProgressEvent struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+	// Query is the artist name as it was found or requested
+	Query string `json:"query,omitempty"`
+	// Source is where on the scraped page the name was found, set on EventArtistFound
+	Source     string  `json:"source,omitempty"`
+	Artist     *Artist `json:"artist,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+	// TracksAdded and TracksSkipped are set on EventTracksAdded
+	TracksAdded   int `json:"tracks_added,omitempty"`
+	TracksSkipped int `json:"tracks_skipped,omitempty"`
+	// Route is the playlist an automatically routed artist was sent to
+	Route   *PlaylistRoute `json:"route,omitempty"`
+	Message string         `json:"message,omitempty"`
 }
 
 // API request/response models
 
 // AddArtistRequest represents the request to add an artist
 type AddArtistRequest struct {
-	ArtistName string `json:"artist_name" validate:"required,min=1,max=100"`
+	ArtistName string `json:"artist_name" validate:"required_without=ArtistID,max=100"`
+	// ArtistID picks one of the candidates returned when the name was ambiguous
+	ArtistID   string `json:"artist_id,omitempty"`
 	PlaylistID string `json:"playlist_id" validate:"required"`
 	Force      bool   `json:"force"`
+	Mode       string `json:"mode,omitempty" validate:"omitempty,oneof=skip force fill_gaps"`
+	TrackCount int    `json:"track_count,omitempty" validate:"omitempty,min=1,max=10"`
+	Strategy   string `json:"strategy,omitempty" validate:"omitempty,oneof=top latest random"`
+	Market     string `json:"market,omitempty" validate:"omitempty,len=2"`
+}
+
+// TrackSelection returns the request's track selection with defaults applied
+func (r *AddArtistRequest) TrackSelection() TrackSelection {
+	return TrackSelection{
+		Count:    r.TrackCount,
+		Strategy: TrackStrategy(strings.ToLower(r.Strategy)),
+		Market:   strings.ToUpper(r.Market),
+	}.WithDefaults()
+}
+
+// AddMode returns the request's mode; Force is shorthand for AddModeForce when no mode is given
+func (r *AddArtistRequest) AddMode() AddMode {
+	return requestAddMode(r.Mode, r.Force)
 }
 
 // APIResponse represents a generic API response
@@ -128,6 +751,60 @@ type ScrapeArtistsRequest struct {
 	CSSSelector string `json:"css_selector" validate:"max=500"`
 	PlaylistID  string `json:"playlist_id" validate:"required"`
 	Force       bool   `json:"force"`
+	Mode        string `json:"mode,omitempty" validate:"omitempty,oneof=skip force fill_gaps"`
+	TrackCount  int    `json:"track_count,omitempty" validate:"omitempty,min=1,max=10"`
+	Strategy    string `json:"strategy,omitempty" validate:"omitempty,oneof=top latest random"`
+	Market      string `json:"market,omitempty" validate:"omitempty,len=2"`
+	// DryRun previews the scrape, matching the artists found without adding any
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// TrackSelection returns the request's track selection with defaults applied
+func (r *ScrapeArtistsRequest) TrackSelection() TrackSelection {
+	return TrackSelection{
+		Count:    r.TrackCount,
+		Strategy: TrackStrategy(strings.ToLower(r.Strategy)),
+		Market:   strings.ToUpper(r.Market),
+	}.WithDefaults()
+}
+
+// AddMode returns the request's mode; Force is shorthand for AddModeForce when no mode is given
+func (r *ScrapeArtistsRequest) AddMode() AddMode {
+	return requestAddMode(r.Mode, r.Force)
+}
+
+// ApprovedArtist is an artist picked from a scrape preview to be added
+type ApprovedArtist struct {
+	// Query is the name found on the page, kept to report the outcome against it
+	Query    string `json:"query,omitempty" validate:"max=100"`
+	ArtistID string `json:"artist_id" validate:"required"`
+}
+
+// ScrapeCommitRequest represents a request to add the artists approved from a scrape preview
+type ScrapeCommitRequest struct {
+	// URL is the previewed page, recorded as the source of the additions
+	URL        string           `json:"url,omitempty" validate:"omitempty,url"`
+	PlaylistID string           `json:"playlist_id" validate:"required"`
+	Artists    []ApprovedArtist `json:"artists" validate:"required,min=1,dive"`
+	Force      bool             `json:"force"`
+	Mode       string           `json:"mode,omitempty" validate:"omitempty,oneof=skip force fill_gaps"`
+	TrackCount int              `json:"track_count,omitempty" validate:"omitempty,min=1,max=10"`
+	Strategy   string           `json:"strategy,omitempty" validate:"omitempty,oneof=top latest random"`
+	Market     string           `json:"market,omitempty" validate:"omitempty,len=2"`
+}
+
+// TrackSelection returns the request's track selection with defaults applied
+func (r *ScrapeCommitRequest) TrackSelection() TrackSelection {
+	return TrackSelection{
+		Count:    r.TrackCount,
+		Strategy: TrackStrategy(strings.ToLower(r.Strategy)),
+		Market:   strings.ToUpper(r.Market),
+	}.WithDefaults()
+}
+
+// AddMode returns the request's mode; Force is shorthand for AddModeForce when no mode is given
+func (r *ScrapeCommitRequest) AddMode() AddMode {
+	return requestAddMode(r.Mode, r.Force)
 }
 
 // ScrapeArtistsResponse represents the response from scraping artists
@@ -144,4 +821,6 @@ type WebUIResponse struct {
 	Data        any        `json:"data,omitempty"`
 	IsDuplicate bool       `json:"is_duplicate,omitempty"`
 	LastAdded   *time.Time `json:"last_added,omitempty"`
+	// NeedsDisambiguation asks the user to pick one of the candidates in Data
+	NeedsDisambiguation bool `json:"needs_disambiguation,omitempty"`
 }
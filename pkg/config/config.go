@@ -46,17 +46,37 @@ import (
 //   - Spotify: Spotify API credentials and settings
 //   - Security: Security-related settings (rate limiting)
 //   - Logging: Logging configuration (level, format, output)
+//   - Scraper: Web scraper settings (timeouts, retries, limits)
+//   - Playlists: Rules deciding which playlists are incoming playlists
+//   - History: Local database of every artist addition
+//   - Duplicates: Artist-level duplicate detection across playlists
+//   - Search: Weights of the artist name scoring model
+//   - Aliases: Artist names always resolved to a given Spotify artist
+//   - Blocklist: Artists never added to playlists
+//   - Routing: Genre rules picking the playlist for artists added to playlist "auto"
+//   - Review: Queue of scraped artists matched with too little confidence to add unreviewed
+//   - Watch: Pages re-scraped on a schedule by the server, adding newly appeared artists
 //
 // Example:
 //
 //	conf := config.GetEnvVars()
 //	fmt.Printf("Server will run on: %s\n", conf.Server.Address())
 type Config struct {
-	Server   ServerConfig   `envPrefix:"SERVER_"`
-	Spotify  SpotifyConfig  `envPrefix:"SPOTIFY_"`
-	Security SecurityConfig `envPrefix:"SECURITY_"`
-	Logging  LoggingConfig  `envPrefix:"LOGGING_"`
-	Scraper  ScraperConfig  `envPrefix:"SCRAPER_"`
+	Server     ServerConfig     `envPrefix:"SERVER_"`
+	Spotify    SpotifyConfig    `envPrefix:"SPOTIFY_"`
+	Security   SecurityConfig   `envPrefix:"SECURITY_"`
+	Logging    LoggingConfig    `envPrefix:"LOGGING_"`
+	Scraper    ScraperConfig    `envPrefix:"SCRAPER_"`
+	Playlists  PlaylistsConfig  `envPrefix:"PLAYLISTS_"`
+	History    HistoryConfig    `envPrefix:"HISTORY_"`
+	Duplicates DuplicatesConfig `envPrefix:"DUPLICATES_"`
+	Jobs       JobsConfig       `envPrefix:"JOBS_"`
+	Search     SearchConfig     `envPrefix:"SEARCH_"`
+	Aliases    AliasesConfig    `envPrefix:"ALIASES_"`
+	Blocklist  BlocklistConfig  `envPrefix:"BLOCKLIST_"`
+	Routing    RoutingConfig    `envPrefix:"ROUTING_"`
+	Review     ReviewConfig     `envPrefix:"REVIEW_"`
+	Watch      WatchConfig      `envPrefix:"WATCH_"`
 }
 
 type ServerConfig struct {
@@ -71,6 +91,26 @@ type SpotifyConfig struct {
 	ClientID     string `env:"CLIENT_ID"`
 	ClientSecret string `env:"CLIENT_SECRET"` // #nosec G117 -- OAuth client secret, expected in config
 	RedirectURL  string `env:"REDIRECT_URL" envDefault:"http://127.0.0.1:8080/callback"`
+	// TokenStore selects where OAuth tokens are persisted: "file" or "memory"
+	TokenStore         string `env:"TOKEN_STORE" envDefault:"file"`
+	TokenFile          string `env:"TOKEN_FILE" envDefault:"data/spotify_token.enc"`
+	TokenEncryptionKey string `env:"TOKEN_ENCRYPTION_KEY"` // #nosec G117 -- encryption key, expected in config
+	// StateSecret signs the OAuth state cookie; a random secret is generated when empty
+	StateSecret string `env:"STATE_SECRET"` // #nosec G117 -- signing key, expected in config
+	UsePKCE     bool   `env:"USE_PKCE" envDefault:"false"`
+	// PagePrefetch bounds the number of concurrent page requests when walking paginated endpoints
+	PagePrefetch int `env:"PAGE_PREFETCH" envDefault:"4"`
+	// Market is the ISO 3166-1 alpha-2 country used for search and track availability;
+	// the authenticated user's country is used when empty
+	Market string `env:"MARKET"`
+	// RequestsPerSecond and Burst size the token bucket shared by every Spotify API
+	// request; a rate of zero or less disables it
+	RequestsPerSecond int `env:"REQUESTS_PER_SECOND" envDefault:"10"`
+	Burst             int `env:"BURST" envDefault:"10"`
+	// MaxRetries is how many times a rate limited or failed request is retried
+	MaxRetries int `env:"MAX_RETRIES" envDefault:"3"`
+	// MaxRetryWaitSeconds caps a single wait before retrying; a longer Retry-After fails the request
+	MaxRetryWaitSeconds int `env:"MAX_RETRY_WAIT_SECONDS" envDefault:"30"`
 }
 
 type SecurityConfig struct {
@@ -95,6 +135,124 @@ type ScraperConfig struct {
 	RetryBackoff   int    `env:"RETRY_BACKOFF_SECONDS" envDefault:"2"`
 	UserAgent      string `env:"USER_AGENT" envDefault:"go-listen/1.0 (Web Scraper)"`
 	MaxContentSize int64  `env:"MAX_CONTENT_SIZE" envDefault:"10485760"` // 10MB in bytes
+	// MatchConcurrency is how many scraped artists are searched on Spotify at the same time (1-16)
+	MatchConcurrency int `env:"MATCH_CONCURRENCY" envDefault:"4"`
+	// ExtractorsFile is a YAML file declaring CSS selector extractors for sites the
+	// built-in extractors don't cover; empty uses the built-in extractors only
+	ExtractorsFile string `env:"EXTRACTORS_FILE"`
+}
+
+// PlaylistsConfig decides which of the user's playlists are "incoming" playlists.
+// A playlist is incoming if its ID is listed, its name matches the pattern or its
+// description contains the marker; marks made through the admin API take precedence.
+type PlaylistsConfig struct {
+	IncomingIDs         []string `env:"INCOMING_IDS" envSeparator:","`
+	IncomingNamePattern string   `env:"INCOMING_NAME_PATTERN" envDefault:"(?i)incoming"`
+	// IncomingDescriptionMarker is matched case-insensitively; empty disables the rule
+	IncomingDescriptionMarker string `env:"INCOMING_DESCRIPTION_MARKER" envDefault:"#incoming"`
+	// MarkStore selects where admin marks are persisted: "file" or "memory"
+	MarkStore string `env:"MARK_STORE" envDefault:"file"`
+	MarkFile  string `env:"MARK_FILE" envDefault:"data/incoming_playlists.json"`
+}
+
+// HistoryConfig controls the SQLite database recording every artist addition
+type HistoryConfig struct {
+	Enabled bool   `env:"ENABLED" envDefault:"true"`
+	File    string `env:"FILE" envDefault:"data/history.db"`
+}
+
+// DuplicatesConfig controls artist-level duplicate detection. An artist is a
+// duplicate if any track in the target playlist, or in one of the sibling
+// playlists, credits them.
+type DuplicatesConfig struct {
+	SiblingPlaylists []string `env:"SIBLING_PLAYLISTS" envSeparator:","`
+	// IndexTTLSeconds is how long a playlist's artist index is cached; 0 disables caching
+	IndexTTLSeconds int `env:"INDEX_TTL_SECONDS" envDefault:"300"`
+}
+
+// JobsConfig bounds the background jobs, such as scrapes, run by the server
+type JobsConfig struct {
+	Workers   int `env:"WORKERS" envDefault:"2"`
+	QueueSize int `env:"QUEUE_SIZE" envDefault:"16"`
+	// RetentionSeconds is how long a finished job's result can still be fetched
+	RetentionSeconds int `env:"RETENTION_SECONDS" envDefault:"3600"`
+}
+
+// SearchConfig weighs the metrics scoring how well an artist's name matches a
+// search. The metric weights are relative; only their proportions matter.
+type SearchConfig struct {
+	JaroWinklerWeight float64 `env:"JARO_WINKLER_WEIGHT" envDefault:"0.3"`
+	TokenSetWeight    float64 `env:"TOKEN_SET_WEIGHT" envDefault:"0.2"`
+	LevenshteinWeight float64 `env:"LEVENSHTEIN_WEIGHT" envDefault:"0.5"`
+	// UnmatchedWordWeight is how much words only one of the names has are penalized, from 0 to 1
+	UnmatchedWordWeight float64 `env:"UNMATCHED_WORD_WEIGHT" envDefault:"0.8"`
+	// ProminenceWeight is how much less popular namesakes are penalized, from 0 to 1
+	ProminenceWeight float64 `env:"PROMINENCE_WEIGHT" envDefault:"0.3"`
+}
+
+// AliasesConfig controls the dictionary of artist names always resolved to a
+// given Spotify artist instead of being searched for
+type AliasesConfig struct {
+	// Store selects where aliases are persisted: "file" or "memory"
+	Store string `env:"STORE" envDefault:"file"`
+	File  string `env:"FILE" envDefault:"data/aliases.json"`
+}
+
+// BlocklistConfig lists the artists never added to playlists, by Spotify
+// artist ID, by a regular expression matched against the artist's name, or by genre
+type BlocklistConfig struct {
+	ArtistIDs   []string `env:"ARTIST_IDS" envSeparator:","`
+	NamePattern string   `env:"NAME_PATTERN" envDefault:"(?i)^various artists$"`
+	// Genres are matched case-insensitively against the whole of each of the artist's Spotify genres
+	Genres []string `env:"GENRES" envSeparator:","`
+}
+
+// RoutingConfig routes artists to playlists by genre when an add or scrape
+// targets the "auto" playlist
+type RoutingConfig struct {
+	// Rules are "genre=playlist" pairs tried in order: an artist goes to the playlist
+	// of the first rule whose genre is part of one of the artist's genres. Playlists
+	// are given by ID or name.
+	Rules []string `env:"RULES" envSeparator:","`
+	// DefaultPlaylist receives the artists no rule matches
+	DefaultPlaylist string `env:"DEFAULT_PLAYLIST"`
+}
+
+// Enabled reports whether any routing rule or default playlist is configured
+func (r RoutingConfig) Enabled() bool {
+	return len(r.Rules) > 0 || strings.TrimSpace(r.DefaultPlaylist) != ""
+}
+
+// ReviewConfig controls the queue holding scraped artists whose match needs
+// approval. Matches below MinConfidence are dropped, matches from MinConfidence up
+// to AutoAddConfidence wait in the queue, and more confident ones are added.
+type ReviewConfig struct {
+	Enabled bool   `env:"ENABLED" envDefault:"true"`
+	File    string `env:"FILE" envDefault:"data/review.db"`
+	// MinConfidence and AutoAddConfidence are match confidences from 0 to 1
+	MinConfidence     float64 `env:"MIN_CONFIDENCE" envDefault:"0.3"`
+	AutoAddConfidence float64 `env:"AUTO_ADD_CONFIDENCE" envDefault:"0.8"`
+}
+
+// Validate checks that the confidences are between 0 and 1, in order
+func (r ReviewConfig) Validate() error {
+	if r.MinConfidence < 0 || r.AutoAddConfidence > 1 || r.MinConfidence > r.AutoAddConfidence {
+		return fmt.Errorf("review confidences must satisfy 0 <= REVIEW_MIN_CONFIDENCE (%v) <= REVIEW_AUTO_ADD_CONFIDENCE (%v) <= 1",
+			r.MinConfidence, r.AutoAddConfidence)
+	}
+	return nil
+}
+
+// WatchConfig controls the watched sources the server re-scrapes on their schedules
+type WatchConfig struct {
+	Enabled bool   `env:"ENABLED" envDefault:"true"`
+	File    string `env:"FILE" envDefault:"data/watch.db"`
+	// CheckIntervalSeconds is how often the scheduler looks for sources that are due
+	CheckIntervalSeconds int `env:"CHECK_INTERVAL_SECONDS" envDefault:"60"`
+	// MinIntervalSeconds is the shortest schedule a source may have
+	MinIntervalSeconds int `env:"MIN_INTERVAL_SECONDS" envDefault:"3600"`
+	// RunRetention is the number of runs kept in each source's history
+	RunRetention int `env:"RUN_RETENTION" envDefault:"100"`
 }
 
 // Address returns the server address
@@ -147,6 +147,241 @@ func TestConfigLoading(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Incoming playlist rules",
+			mockEnv: map[string]string{
+				"PLAYLISTS_INCOMING_IDS":          "abc123,def456",
+				"PLAYLISTS_INCOMING_NAME_PATTERN": "^New ",
+				"PLAYLISTS_MARK_STORE":            "memory",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if len(conf.Playlists.IncomingIDs) != 2 || conf.Playlists.IncomingIDs[1] != "def456" {
+					t.Errorf("expected incoming IDs [abc123 def456], got %v", conf.Playlists.IncomingIDs)
+				}
+				if conf.Playlists.IncomingNamePattern != "^New " {
+					t.Errorf("expected name pattern '^New ', got %q", conf.Playlists.IncomingNamePattern)
+				}
+				if conf.Playlists.IncomingDescriptionMarker != "#incoming" {
+					t.Errorf("expected default description marker '#incoming', got %q", conf.Playlists.IncomingDescriptionMarker)
+				}
+				if conf.Playlists.MarkStore != "memory" {
+					t.Errorf("expected mark store 'memory', got %q", conf.Playlists.MarkStore)
+				}
+				if conf.Playlists.MarkFile != "data/incoming_playlists.json" {
+					t.Errorf("expected default mark file, got %q", conf.Playlists.MarkFile)
+				}
+			},
+		},
+		{
+			name: "History settings",
+			mockEnv: map[string]string{
+				"HISTORY_ENABLED": "false",
+				"HISTORY_FILE":    "/var/lib/go-listen/history.db",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if conf.History.Enabled {
+					t.Error("expected history to be disabled")
+				}
+				if conf.History.File != "/var/lib/go-listen/history.db" {
+					t.Errorf("expected history file '/var/lib/go-listen/history.db', got %q", conf.History.File)
+				}
+			},
+		},
+		{
+			name: "Duplicate detection settings",
+			mockEnv: map[string]string{
+				"DUPLICATES_SIBLING_PLAYLISTS": "sibling1,sibling2",
+				"DUPLICATES_INDEX_TTL_SECONDS": "60",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if len(conf.Duplicates.SiblingPlaylists) != 2 || conf.Duplicates.SiblingPlaylists[1] != "sibling2" {
+					t.Errorf("expected 2 sibling playlists, got %v", conf.Duplicates.SiblingPlaylists)
+				}
+				if conf.Duplicates.IndexTTLSeconds != 60 {
+					t.Errorf("expected index TTL 60, got %d", conf.Duplicates.IndexTTLSeconds)
+				}
+			},
+		},
+		{
+			name: "Scraper match concurrency",
+			mockEnv: map[string]string{
+				"SCRAPER_MATCH_CONCURRENCY": "8",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if conf.Scraper.MatchConcurrency != 8 {
+					t.Errorf("expected match concurrency 8, got %d", conf.Scraper.MatchConcurrency)
+				}
+			},
+		},
+		{
+			name: "Spotify rate limit settings",
+			mockEnv: map[string]string{
+				"SPOTIFY_REQUESTS_PER_SECOND": "5",
+				"SPOTIFY_MAX_RETRIES":         "6",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if conf.Spotify.RequestsPerSecond != 5 {
+					t.Errorf("expected 5 Spotify requests per second, got %d", conf.Spotify.RequestsPerSecond)
+				}
+				if conf.Spotify.MaxRetries != 6 {
+					t.Errorf("expected 6 Spotify retries, got %d", conf.Spotify.MaxRetries)
+				}
+				if conf.Spotify.Burst != 10 || conf.Spotify.MaxRetryWaitSeconds != 30 {
+					t.Errorf("expected default burst 10 and retry wait 30, got %d and %d",
+						conf.Spotify.Burst, conf.Spotify.MaxRetryWaitSeconds)
+				}
+			},
+		},
+		{
+			name: "Job settings",
+			mockEnv: map[string]string{
+				"JOBS_WORKERS":    "4",
+				"JOBS_QUEUE_SIZE": "64",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if conf.Jobs.Workers != 4 {
+					t.Errorf("expected 4 job workers, got %d", conf.Jobs.Workers)
+				}
+				if conf.Jobs.QueueSize != 64 {
+					t.Errorf("expected job queue size 64, got %d", conf.Jobs.QueueSize)
+				}
+				if conf.Jobs.RetentionSeconds != 3600 {
+					t.Errorf("expected default job retention 3600, got %d", conf.Jobs.RetentionSeconds)
+				}
+			},
+		},
+		{
+			name: "Search weights",
+			mockEnv: map[string]string{
+				"SEARCH_LEVENSHTEIN_WEIGHT":    "0.7",
+				"SEARCH_UNMATCHED_WORD_WEIGHT": "0.5",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if conf.Search.LevenshteinWeight != 0.7 {
+					t.Errorf("expected Levenshtein weight 0.7, got %v", conf.Search.LevenshteinWeight)
+				}
+				if conf.Search.UnmatchedWordWeight != 0.5 {
+					t.Errorf("expected unmatched word weight 0.5, got %v", conf.Search.UnmatchedWordWeight)
+				}
+				if conf.Search.JaroWinklerWeight != 0.3 || conf.Search.ProminenceWeight != 0.3 {
+					t.Errorf("expected default Jaro-Winkler and prominence weights 0.3, got %v and %v",
+						conf.Search.JaroWinklerWeight, conf.Search.ProminenceWeight)
+				}
+			},
+		},
+		{
+			name: "Blocklist settings",
+			mockEnv: map[string]string{
+				"BLOCKLIST_ARTIST_IDS": "sponsor1,sponsor2",
+				"BLOCKLIST_GENRES":     "children's music,sleep",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if len(conf.Blocklist.ArtistIDs) != 2 || conf.Blocklist.ArtistIDs[1] != "sponsor2" {
+					t.Errorf("expected 2 blocked artist IDs, got %v", conf.Blocklist.ArtistIDs)
+				}
+				if len(conf.Blocklist.Genres) != 2 || conf.Blocklist.Genres[0] != "children's music" {
+					t.Errorf("expected 2 blocked genres, got %v", conf.Blocklist.Genres)
+				}
+				if conf.Blocklist.NamePattern != "(?i)^various artists$" {
+					t.Errorf("expected default blocklist name pattern, got %s", conf.Blocklist.NamePattern)
+				}
+			},
+		},
+		{
+			name: "Alias settings",
+			mockEnv: map[string]string{
+				"ALIASES_FILE": "/var/lib/go-listen/aliases.json",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if conf.Aliases.Store != "file" {
+					t.Errorf("expected default alias store file, got %s", conf.Aliases.Store)
+				}
+				if conf.Aliases.File != "/var/lib/go-listen/aliases.json" {
+					t.Errorf("expected alias file /var/lib/go-listen/aliases.json, got %s", conf.Aliases.File)
+				}
+			},
+		},
+		{
+			name: "Scraper extractors file",
+			mockEnv: map[string]string{
+				"SCRAPER_EXTRACTORS_FILE": "/etc/go-listen/extractors.yaml",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if conf.Scraper.ExtractorsFile != "/etc/go-listen/extractors.yaml" {
+					t.Errorf("expected extractors file /etc/go-listen/extractors.yaml, got %s", conf.Scraper.ExtractorsFile)
+				}
+			},
+		},
+		{
+			name: "Routing settings",
+			mockEnv: map[string]string{
+				"ROUTING_RULES":            "metal=Incoming Heavy,jazz=Incoming Jazz",
+				"ROUTING_DEFAULT_PLAYLIST": "Incoming",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if len(conf.Routing.Rules) != 2 || conf.Routing.Rules[0] != "metal=Incoming Heavy" {
+					t.Errorf("expected 2 routing rules, got %v", conf.Routing.Rules)
+				}
+				if conf.Routing.DefaultPlaylist != "Incoming" {
+					t.Errorf("expected default routing playlist Incoming, got %s", conf.Routing.DefaultPlaylist)
+				}
+				if !conf.Routing.Enabled() {
+					t.Error("expected routing to be enabled")
+				}
+			},
+		},
+		{
+			name: "Review settings",
+			mockEnv: map[string]string{
+				"REVIEW_FILE":                "/var/lib/go-listen/review.db",
+				"REVIEW_AUTO_ADD_CONFIDENCE": "0.9",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if !conf.Review.Enabled {
+					t.Error("expected review queue to be enabled by default")
+				}
+				if conf.Review.File != "/var/lib/go-listen/review.db" {
+					t.Errorf("expected review file /var/lib/go-listen/review.db, got %s", conf.Review.File)
+				}
+				if conf.Review.MinConfidence != 0.3 || conf.Review.AutoAddConfidence != 0.9 {
+					t.Errorf("expected review confidences 0.3 and 0.9, got %v and %v", conf.Review.MinConfidence, conf.Review.AutoAddConfidence)
+				}
+			},
+		},
+		{
+			name: "Watch settings",
+			mockEnv: map[string]string{
+				"WATCH_FILE":                 "/var/lib/go-listen/watch.db",
+				"WATCH_MIN_INTERVAL_SECONDS": "86400",
+			},
+			expectValid: true,
+			checkFunc: func(t *testing.T, conf Config) {
+				if !conf.Watch.Enabled {
+					t.Error("expected watched sources to be enabled by default")
+				}
+				if conf.Watch.File != "/var/lib/go-listen/watch.db" {
+					t.Errorf("expected watch file /var/lib/go-listen/watch.db, got %s", conf.Watch.File)
+				}
+				if conf.Watch.CheckIntervalSeconds != 60 || conf.Watch.MinIntervalSeconds != 86400 {
+					t.Errorf("expected watch intervals 60 and 86400, got %d and %d", conf.Watch.CheckIntervalSeconds, conf.Watch.MinIntervalSeconds)
+				}
+				if conf.Watch.RunRetention != 100 {
+					t.Errorf("expected watch run retention 100, got %d", conf.Watch.RunRetention)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -219,6 +454,29 @@ func TestServerConfigAddress(t *testing.T) {
 	}
 }
 
+func TestReviewConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ReviewConfig
+		wantErr bool
+	}{
+		{name: "Defaults", config: ReviewConfig{MinConfidence: 0.3, AutoAddConfidence: 0.8}},
+		{name: "Review nothing", config: ReviewConfig{MinConfidence: 0.5, AutoAddConfidence: 0.5}},
+		{name: "Out of order", config: ReviewConfig{MinConfidence: 0.8, AutoAddConfidence: 0.3}, wantErr: true},
+		{name: "Negative", config: ReviewConfig{MinConfidence: -0.1, AutoAddConfidence: 0.8}, wantErr: true},
+		{name: "Above one", config: ReviewConfig{MinConfidence: 0.3, AutoAddConfidence: 1.5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestGetEnvVars(t *testing.T) {
 	tests := []struct {
 		name        string
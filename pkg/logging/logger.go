@@ -75,6 +75,23 @@ func NewLogger(cfg config.LoggingConfig) *Logger {
 	return &Logger{Logger: logger}
 }
 
+// Wrap returns a Logger that writes through an existing logrus logger, so services
+// constructed with a plain logrus logger can still log correlation IDs
+func Wrap(logger *logrus.Logger) *Logger {
+	return &Logger{Logger: logger}
+}
+
+// ContextWithCorrelationID returns a copy of ctx carrying the correlation ID
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, CorrelationIDKey, correlationID)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, or an empty string
+func CorrelationID(ctx context.Context) string {
+	correlationID, _ := ctx.Value(CorrelationIDKey).(string)
+	return correlationID
+}
+
 // WithCorrelationID adds a correlation ID to the logger context
 func (l *Logger) WithCorrelationID(correlationID string) *logrus.Entry {
 	return l.WithField("correlation_id", correlationID)
@@ -82,7 +99,7 @@ func (l *Logger) WithCorrelationID(correlationID string) *logrus.Entry {
 
 // WithContext extracts correlation ID from context and adds it to the logger
 func (l *Logger) WithContext(ctx context.Context) *logrus.Entry {
-	if correlationID, ok := ctx.Value(CorrelationIDKey).(string); ok && correlationID != "" {
+	if correlationID := CorrelationID(ctx); correlationID != "" {
 		return l.WithCorrelationID(correlationID)
 	}
 	return l.WithFields(logrus.Fields{})
@@ -137,6 +137,26 @@ func TestLogger_WithContext(t *testing.T) {
 	}
 }
 
+func TestWrap(t *testing.T) {
+	base := logrus.New()
+	logger := Wrap(base)
+
+	if logger.Logger != base {
+		t.Error("Expected Wrap to write through the given logrus logger")
+	}
+
+	ctx := ContextWithCorrelationID(context.Background(), "wrapped-id")
+	if got := CorrelationID(ctx); got != "wrapped-id" {
+		t.Errorf("Expected CorrelationID to return wrapped-id, got %q", got)
+	}
+	if entry := logger.WithContext(ctx); entry.Data["correlation_id"] != "wrapped-id" {
+		t.Errorf("Expected correlation_id wrapped-id, got %v", entry.Data["correlation_id"])
+	}
+	if got := CorrelationID(context.Background()); got != "" {
+		t.Errorf("Expected no correlation ID, got %q", got)
+	}
+}
+
 func TestLogger_WithComponent(t *testing.T) {
 	logger := NewLogger(config.LoggingConfig{
 		Level:  "info",